@@ -0,0 +1,42 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+
+// Conditions and condition Reasons for the NestedControlPlane object.
+
+const (
+	// CertificatesUpToDateCondition documents that the certificates used by
+	// the nested components are not about to expire. The message of the
+	// condition reports the earliest expiry.
+	CertificatesUpToDateCondition clusterv1.ConditionType = "CertificatesUpToDate"
+
+	// CertificatesExpiringReason (Severity=Warning) documents a certificate that
+	// is about to expire but is not managed by the NestedControlPlane, and
+	// therefore cannot be rotated automatically.
+	CertificatesExpiringReason = "CertificatesExpiring"
+
+	// CertificatesRotationFailedReason (Severity=Warning) documents a
+	// NestedControlPlane controller detecting an error while rotating the
+	// certificates.
+	CertificatesRotationFailedReason = "CertificatesRotationFailed"
+
+	// CARotationInProgressReason (Severity=Info) documents a CA rotation whose
+	// overlap window has not passed yet, the previous CA is still trusted.
+	CARotationInProgressReason = "CARotationInProgress"
+)
@@ -14,7 +14,11 @@ limitations under the License.
 // Package certificate contains helpers for managing KeyPairs.
 package certificate
 
-import "sigs.k8s.io/cluster-api/util/secret"
+import (
+	"time"
+
+	"sigs.k8s.io/cluster-api/util/secret"
+)
 
 const (
 	// defaultClusterDomain defines the default that all control planes are
@@ -41,4 +45,24 @@ const (
 
 	// ControllerManagerKubeconfig defines the secret purpose for KCM Kubeconfigs.
 	ControllerManagerKubeconfig secret.Purpose = "controller-manager-kubeconfig"
+
+	// CAOverlapUntilAnnotation is set on a CA secret while a rotation is in
+	// progress, it records the time after which the previous CA certificate is
+	// removed from the trust bundle.
+	CAOverlapUntilAnnotation = "controlplane.cluster.x-k8s.io/ca-overlap-until"
+
+	// CertificatesRotatedAtAnnotation is set on the pod template of the nested
+	// component StatefulSets to roll the pods after a certificate rotation.
+	CertificatesRotatedAtAnnotation = "controlplane.cluster.x-k8s.io/certificates-rotated-at"
+
+	// DefaultCertificateRenewBefore defines how long before expiry the
+	// component certificates are reissued.
+	DefaultCertificateRenewBefore = 90 * 24 * time.Hour
+
+	// DefaultCARenewBefore defines how long before expiry the CAs are rotated.
+	DefaultCARenewBefore = 365 * 24 * time.Hour
+
+	// DefaultCAOverlapWindow defines how long the previous CA is kept in the
+	// trust bundle after a CA rotation.
+	DefaultCAOverlapWindow = 24 * time.Hour
 )
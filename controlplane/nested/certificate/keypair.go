@@ -48,3 +48,12 @@ func (k *KeyPair) AsSecret(clusterName client.ObjectKey, owner metav1.OwnerRefer
 	}
 	return s
 }
+
+// UpdateSecret will write the KeyPair's cert and key into an existing secret.
+func (k *KeyPair) UpdateSecret(s *corev1.Secret) {
+	if s.Data == nil {
+		s.Data = map[string][]byte{}
+	}
+	s.Data[secret.TLSKeyDataName] = util.EncodePrivateKeyPEM(k.Key.(*rsa.PrivateKey))
+	s.Data[secret.TLSCrtDataName] = util.EncodeCertPEM(k.Cert)
+}
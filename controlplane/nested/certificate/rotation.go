@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/certificate/util"
+)
+
+// signers maps the component certificates to the CA that issues them.
+var signers = map[secret.Purpose]secret.Purpose{
+	APIServerClient:  secret.ClusterCA,
+	KubeletClient:    secret.ClusterCA,
+	ProxyClient:      secret.FrontProxyCA,
+	EtcdClient:       secret.EtcdCA,
+	EtcdHealthClient: secret.EtcdCA,
+}
+
+// ComponentPurposes lists the component certificates issued by this package.
+var ComponentPurposes = []secret.Purpose{
+	APIServerClient,
+	KubeletClient,
+	ProxyClient,
+	EtcdClient,
+	EtcdHealthClient,
+}
+
+// CAPurposes lists the CAs that sign the component certificates.
+var CAPurposes = []secret.Purpose{
+	secret.ClusterCA,
+	secret.FrontProxyCA,
+	secret.EtcdCA,
+}
+
+// SignerFor returns the purpose of the CA that issues the given component
+// certificate.
+func SignerFor(purpose secret.Purpose) (secret.Purpose, bool) {
+	signer, ok := signers[purpose]
+	return signer, ok
+}
+
+// KeyPairFromSecret will convert a secret created by AsSecret back into a
+// KeyPair, for CA secrets the first certificate of the bundle is used.
+func KeyPairFromSecret(purpose secret.Purpose, s *corev1.Secret) (*KeyPair, error) {
+	crt, err := certs.DecodeCertPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to decode %s certificate", purpose)
+	}
+	if crt == nil {
+		return nil, errors.Errorf("certificate not found in %s secret", purpose)
+	}
+	key, err := certs.DecodePrivateKeyPEM(s.Data[secret.TLSKeyDataName])
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to decode %s private key", purpose)
+	}
+	if key == nil {
+		return nil, errors.Errorf("private key not found in %s secret", purpose)
+	}
+	return &KeyPair{Purpose: purpose, Cert: crt, Key: key}, nil
+}
+
+// NotAfter returns the time the KeyPair's certificate expires.
+func (k *KeyPair) NotAfter() time.Time {
+	return k.Cert.NotAfter
+}
+
+// NeedsRenewal returns whether the certificate expires within the threshold or
+// is no longer signed by the given ca.
+func (k *KeyPair) NeedsRenewal(ca *x509.Certificate, threshold time.Duration) bool {
+	if time.Now().Add(threshold).After(k.NotAfter()) {
+		return true
+	}
+	return ca != nil && k.Cert.CheckSignatureFrom(ca) != nil
+}
+
+// Renew issues a new KeyPair for the same purpose, subject, SANs and usages
+// signed by the given ca.
+func (k *KeyPair) Renew(ca *KeyPair) (*KeyPair, error) {
+	config := &util.CertConfig{
+		Config: cert.Config{
+			CommonName:   k.Cert.Subject.CommonName,
+			Organization: k.Cert.Subject.Organization,
+			AltNames: cert.AltNames{
+				DNSNames: k.Cert.DNSNames,
+				IPs:      k.Cert.IPAddresses,
+			},
+			Usages: k.Cert.ExtKeyUsage,
+		},
+	}
+	crt, key, err := util.NewCertAndKey(ca.Cert, ca.Key, config)
+	if err != nil {
+		return nil, fmt.Errorf("fail to renew %s crt and key: %v", k.Purpose, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("fail to assert rsa private key")
+	}
+	return &KeyPair{k.Purpose, crt, rsaKey, true, false}, nil
+}
+
+// RotateCA replaces the CA stored in s with a newly generated one. The previous
+// CA certificates are kept at the end of the trust bundle until overlapUntil
+// so that components still presenting certificates issued by them keep
+// working while they are rolled.
+func RotateCA(s *corev1.Secret, purpose secret.Purpose, overlapUntil time.Time) (*KeyPair, error) {
+	old, err := KeyPairFromSecret(purpose, s)
+	if err != nil {
+		return nil, err
+	}
+	crt, key, err := util.NewSelfSignedCACert(old.Cert.Subject.CommonName)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create %s crt and key: %v", purpose, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("fail to assert rsa private key")
+	}
+
+	bundle := bytes.NewBuffer(util.EncodeCertPEM(crt))
+	bundle.Write(s.Data[secret.TLSCrtDataName])
+	s.Data[secret.TLSCrtDataName] = bundle.Bytes()
+	s.Data[secret.TLSKeyDataName] = util.EncodePrivateKeyPEM(rsaKey)
+	if s.Annotations == nil {
+		s.Annotations = map[string]string{}
+	}
+	s.Annotations[CAOverlapUntilAnnotation] = overlapUntil.UTC().Format(time.RFC3339)
+	return &KeyPair{purpose, crt, rsaKey, true, false}, nil
+}
+
+// CAOverlapUntil returns the end of the overlap window of an in-progress CA
+// rotation, the second return value is false if no rotation is in progress.
+func CAOverlapUntil(s *corev1.Secret) (time.Time, bool, error) {
+	val, ok := s.Annotations[CAOverlapUntilAnnotation]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	until, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "invalid %s annotation", CAOverlapUntilAnnotation)
+	}
+	return until, true, nil
+}
+
+// FinishCARotation drops the previous CA certificates from the trust bundle
+// once the overlap window has passed, it returns whether s was modified.
+func FinishCARotation(s *corev1.Secret, now time.Time) (bool, error) {
+	until, inProgress, err := CAOverlapUntil(s)
+	if err != nil || !inProgress || now.Before(until) {
+		return false, err
+	}
+	bundle, err := cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		return false, errors.Wrap(err, "fail to parse CA bundle")
+	}
+	s.Data[secret.TLSCrtDataName] = util.EncodeCertPEM(bundle[0])
+	delete(s.Annotations, CAOverlapUntilAnnotation)
+	return true, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/util/secret"
+
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/certificate/util"
+)
+
+func newSelfSignedCA(t *testing.T) *KeyPair {
+	crt, key, err := util.NewSelfSignedCACert("kubernetes")
+	if err != nil {
+		t.Fatalf("NewSelfSignedCACert() error = %v", err)
+	}
+	return &KeyPair{Purpose: secret.ClusterCA, Cert: crt, Key: key}
+}
+
+func newCASecret(t *testing.T) *corev1.Secret {
+	s := &corev1.Secret{}
+	newSelfSignedCA(t).UpdateSecret(s)
+	return s
+}
+
+func TestKeyPair_NeedsRenewal(t *testing.T) {
+	ca := newSelfSignedCA(t)
+	otherCA := newSelfSignedCA(t)
+	kp, err := NewFrontProxyClientCertAndKey(ca)
+	if err != nil {
+		t.Fatalf("NewFrontProxyClientCertAndKey() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		ca        *KeyPair
+		threshold time.Duration
+		want      bool
+	}{
+		{"TestValidCertificate", ca, time.Hour, false},
+		{"TestExpiringCertificate", ca, 2 * 365 * 24 * time.Hour, true},
+		{"TestCertificateFromRotatedCA", otherCA, time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kp.NeedsRenewal(tt.ca.Cert, tt.threshold); got != tt.want {
+				t.Errorf("KeyPair.NeedsRenewal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyPair_Renew(t *testing.T) {
+	ca := newSelfSignedCA(t)
+	kp, err := NewAPIServerCrtAndKey(ca, "test-cluster", "", "test.example.com", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("NewAPIServerCrtAndKey() error = %v", err)
+	}
+	newCA := newSelfSignedCA(t)
+	renewed, err := kp.Renew(newCA)
+	if err != nil {
+		t.Fatalf("KeyPair.Renew() error = %v", err)
+	}
+	if renewed.Purpose != kp.Purpose {
+		t.Errorf("KeyPair.Renew().Purpose = %v, want %v", renewed.Purpose, kp.Purpose)
+	}
+	if err := renewed.Cert.CheckSignatureFrom(newCA.Cert); err != nil {
+		t.Errorf("KeyPair.Renew() not signed by the new CA: %v", err)
+	}
+	if !reflect.DeepEqual(renewed.Cert.DNSNames, kp.Cert.DNSNames) {
+		t.Errorf("KeyPair.Renew().DNSNames = %v, want %v", renewed.Cert.DNSNames, kp.Cert.DNSNames)
+	}
+	if len(renewed.Cert.IPAddresses) != len(kp.Cert.IPAddresses) {
+		t.Errorf("KeyPair.Renew().IPAddresses = %v, want %v", renewed.Cert.IPAddresses, kp.Cert.IPAddresses)
+	}
+	if !reflect.DeepEqual(renewed.Cert.ExtKeyUsage, kp.Cert.ExtKeyUsage) {
+		t.Errorf("KeyPair.Renew().ExtKeyUsage = %v, want %v", renewed.Cert.ExtKeyUsage, kp.Cert.ExtKeyUsage)
+	}
+}
+
+func TestRotateCA(t *testing.T) {
+	s := newCASecret(t)
+	old, err := KeyPairFromSecret(secret.ClusterCA, s)
+	if err != nil {
+		t.Fatalf("KeyPairFromSecret() error = %v", err)
+	}
+
+	now := time.Now()
+	rotated, err := RotateCA(s, secret.ClusterCA, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("RotateCA() error = %v", err)
+	}
+	bundle, err := cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		t.Fatalf("ParseCertsPEM() error = %v", err)
+	}
+	if len(bundle) != 2 || !bundle[0].Equal(rotated.Cert) || !bundle[1].Equal(old.Cert) {
+		t.Errorf("RotateCA() bundle should contain the new then the previous CA")
+	}
+	current, err := KeyPairFromSecret(secret.ClusterCA, s)
+	if err != nil {
+		t.Fatalf("KeyPairFromSecret() error = %v", err)
+	}
+	if !current.Cert.Equal(rotated.Cert) {
+		t.Errorf("KeyPairFromSecret() should return the new CA during the overlap window")
+	}
+
+	if finished, err := FinishCARotation(s, now); err != nil || finished {
+		t.Errorf("FinishCARotation() = %v, %v, want false during the overlap window", finished, err)
+	}
+	if finished, err := FinishCARotation(s, now.Add(2*time.Hour)); err != nil || !finished {
+		t.Errorf("FinishCARotation() = %v, %v, want true after the overlap window", finished, err)
+	}
+	bundle, err = cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		t.Fatalf("ParseCertsPEM() error = %v", err)
+	}
+	if len(bundle) != 1 || !bundle[0].Equal(rotated.Cert) {
+		t.Errorf("FinishCARotation() bundle should only contain the new CA")
+	}
+	if _, ok := s.Annotations[CAOverlapUntilAnnotation]; ok {
+		t.Errorf("FinishCARotation() should remove the %s annotation", CAOverlapUntilAnnotation)
+	}
+}
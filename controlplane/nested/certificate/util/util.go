@@ -40,6 +40,9 @@ const (
 
 	// certificateValidity defines the validity for all the signed certificates generated by this package.
 	certificateValidity = time.Hour * 24 * 365
+
+	// caCertificateValidity defines the validity for the self-signed CAs generated by this package.
+	caCertificateValidity = time.Hour * 24 * 365 * 10
 )
 
 // CertConfig is a wrapper around certutil.Config extending it with PublicKeyAlgorithm.
@@ -105,6 +108,42 @@ func NewSignedCert(cfg *CertConfig, key crypto.Signer, caCert *x509.Certificate,
 	return x509.ParseCertificate(certDERBytes)
 }
 
+// NewSelfSignedCACert creates a CA certificate and key, mirroring the CAs
+// created by cluster-api.
+func NewSelfSignedCACert(commonName string) (*x509.Certificate, crypto.Signer, error) {
+	key, err := NewPrivateKey(x509.RSA)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to create private key")
+	}
+	serial, err := cryptorand.Int(cryptorand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	certTmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		NotBefore:             now.Add(time.Minute * -5),
+		NotAfter:              now.Add(caCertificateValidity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		MaxPathLenZero:        true,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDERBytes, err := x509.CreateCertificate(cryptorand.Reader, &certTmpl, &certTmpl, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(certDERBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
 // EncodeCertPEM returns PEM-endcoded certificate data.
 func EncodeCertPEM(cert *x509.Certificate) []byte {
 	block := pem.Block{
@@ -0,0 +1,329 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controlplanev1 "sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/api/v1alpha4"
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/certificate"
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/kubeadm"
+)
+
+// componentCertificates lists the certificates mounted by the StatefulSet of
+// each nested component, a component is restarted when any of them rotates.
+var componentCertificates = map[string][]secret.Purpose{
+	kubeadm.APIServer: {
+		secret.ClusterCA,
+		secret.EtcdCA,
+		secret.FrontProxyCA,
+		certificate.APIServerClient,
+		certificate.KubeletClient,
+		certificate.ProxyClient,
+		certificate.EtcdClient,
+	},
+	kubeadm.ControllerManager: {
+		secret.ClusterCA,
+		secret.FrontProxyCA,
+		certificate.APIServerClient,
+		secret.Kubeconfig,
+	},
+	kubeadm.Etcd: {
+		secret.EtcdCA,
+		certificate.EtcdClient,
+		certificate.EtcdHealthClient,
+	},
+}
+
+// certificateExpiry tracks the certificate that expires first.
+type certificateExpiry struct {
+	purpose  secret.Purpose
+	notAfter time.Time
+}
+
+func (e *certificateExpiry) observe(purpose secret.Purpose, notAfter time.Time) {
+	if e.notAfter.IsZero() || notAfter.Before(e.notAfter) {
+		e.purpose = purpose
+		e.notAfter = notAfter
+	}
+}
+
+// reconcileCertificates will rotate the CAs and the component certificates
+// owned by the NestedControlPlane before they expire, and restart the
+// components that mount them.
+func (r *NestedControlPlaneReconciler) reconcileCertificates(ctx context.Context, cluster *clusterv1.Cluster, ncp *controlplanev1.NestedControlPlane) (ctrl.Result, error) {
+	result, err := r.rotateCertificates(ctx, cluster, ncp)
+	if err != nil {
+		conditions.MarkFalse(ncp, controlplanev1.CertificatesUpToDateCondition, controlplanev1.CertificatesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+	}
+	return result, err
+}
+
+func (r *NestedControlPlaneReconciler) rotateCertificates(ctx context.Context, cluster *clusterv1.Cluster, ncp *controlplanev1.NestedControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	clusterName := util.ObjectKey(cluster)
+	now := time.Now()
+
+	var (
+		result   ctrl.Result
+		expiry   certificateExpiry
+		expiring []string
+		overlap  []string
+	)
+	rotated := map[secret.Purpose]bool{}
+	cas := map[secret.Purpose]*certificate.KeyPair{}
+
+	// 1. rotate the CAs, or finish their rotation once the overlap window passed.
+	for _, purpose := range certificate.CAPurposes {
+		s, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, purpose)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve %s secret", purpose)
+		}
+		ca, err := certificate.KeyPairFromSecret(purpose, s)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		cas[purpose] = ca
+
+		if !util.IsControlledBy(s, ncp) {
+			if now.Add(r.caRenewBefore()).After(ca.NotAfter()) {
+				expiring = append(expiring, string(purpose))
+			}
+			expiry.observe(purpose, ca.NotAfter())
+			continue
+		}
+
+		until, inProgress, err := certificate.CAOverlapUntil(s)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		switch {
+		case inProgress:
+			finished, err := certificate.FinishCARotation(s, now)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !finished {
+				overlap = append(overlap, string(purpose))
+				result = minRequeue(result, until.Sub(now))
+				break
+			}
+			log.Info("removing the previous CA from the trust bundle", "purpose", purpose)
+			if err := r.Update(ctx, s); err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to update %s secret", purpose)
+			}
+			rotated[purpose] = true
+		case now.Add(r.caRenewBefore()).After(ca.NotAfter()):
+			log.Info("rotating CA", "purpose", purpose, "notAfter", ca.NotAfter())
+			overlapUntil := now.Add(r.caOverlapWindow())
+			if ca, err = certificate.RotateCA(s, purpose, overlapUntil); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.Update(ctx, s); err != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to update %s secret", purpose)
+			}
+			cas[purpose] = ca
+			rotated[purpose] = true
+			overlap = append(overlap, string(purpose))
+			result = minRequeue(result, overlapUntil.Sub(now))
+		}
+		expiry.observe(purpose, ca.NotAfter())
+	}
+
+	// 2. reissue the component certificates that are about to expire or that
+	// were signed by a CA that has been rotated.
+	for _, purpose := range certificate.ComponentPurposes {
+		signer, _ := certificate.SignerFor(purpose)
+		ca, ok := cas[signer]
+		if !ok {
+			continue
+		}
+		s, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, purpose)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// the component controllers have not created it yet.
+				continue
+			}
+			return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve %s secret", purpose)
+		}
+		kp, err := certificate.KeyPairFromSecret(purpose, s)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !kp.NeedsRenewal(ca.Cert, r.certificateRenewBefore()) {
+			expiry.observe(purpose, kp.NotAfter())
+			continue
+		}
+		if !util.IsControlledBy(s, ncp) {
+			expiring = append(expiring, string(purpose))
+			expiry.observe(purpose, kp.NotAfter())
+			continue
+		}
+		log.Info("renewing certificate", "purpose", purpose, "notAfter", kp.NotAfter())
+		renewed, err := kp.Renew(ca)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		renewed.UpdateSecret(s)
+		if err := r.Update(ctx, s); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update %s secret", purpose)
+		}
+		rotated[purpose] = true
+		expiry.observe(purpose, renewed.NotAfter())
+	}
+
+	// 3. the kubeconfig embeds the cluster CA bundle and a client certificate
+	// issued by it.
+	if rotated[secret.ClusterCA] {
+		if err := r.regenerateKubeconfig(ctx, clusterName); err != nil {
+			return ctrl.Result{}, err
+		}
+		rotated[secret.Kubeconfig] = true
+	}
+
+	// 4. roll the components that mount any of the rotated certificates.
+	for component, purposes := range componentCertificates {
+		for _, purpose := range purposes {
+			if rotated[purpose] {
+				if err := r.restartComponent(ctx, cluster.GetName(), ncp.GetNamespace(), component, now); err != nil {
+					return ctrl.Result{}, err
+				}
+				break
+			}
+		}
+	}
+
+	switch {
+	case len(expiring) != 0:
+		conditions.MarkFalse(ncp, controlplanev1.CertificatesUpToDateCondition, controlplanev1.CertificatesExpiringReason, clusterv1.ConditionSeverityWarning,
+			"certificates %v are not managed by the NestedControlPlane and expire soon, earliest expiry: %s at %s", expiring, expiry.purpose, expiry.notAfter.UTC().Format(time.RFC3339))
+	case len(overlap) != 0:
+		conditions.MarkFalse(ncp, controlplanev1.CertificatesUpToDateCondition, controlplanev1.CARotationInProgressReason, clusterv1.ConditionSeverityInfo,
+			"previous CAs of %v are still trusted, earliest expiry: %s at %s", overlap, expiry.purpose, expiry.notAfter.UTC().Format(time.RFC3339))
+	case !expiry.notAfter.IsZero():
+		conditions.Set(ncp, &clusterv1.Condition{
+			Type:    controlplanev1.CertificatesUpToDateCondition,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf("earliest expiry: %s at %s", expiry.purpose, expiry.notAfter.UTC().Format(time.RFC3339)),
+		})
+	}
+	return result, nil
+}
+
+// regenerateKubeconfig regenerates the kubeconfig secret after a cluster CA
+// rotation, trusting the whole CA bundle during the overlap window.
+func (r *NestedControlPlaneReconciler) regenerateKubeconfig(ctx context.Context, clusterName client.ObjectKey) error {
+	configSecret, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, secret.Kubeconfig)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to retrieve kubeconfig Secret")
+	}
+	if err := kubeconfig.RegenerateSecret(ctx, r.Client, configSecret); err != nil {
+		return errors.Wrap(err, "failed to regenerate kubeconfig")
+	}
+
+	caSecret, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, secret.ClusterCA)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve cluster CA secret")
+	}
+	config, err := clientcmd.Load(configSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		return errors.Wrap(err, "failed to load kubeconfig")
+	}
+	for _, c := range config.Clusters {
+		c.CertificateAuthorityData = caSecret.Data[secret.TLSCrtDataName]
+	}
+	out, err := clientcmd.Write(*config)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize kubeconfig")
+	}
+	configSecret.Data[secret.KubeconfigDataName] = out
+	return r.Update(ctx, configSecret)
+}
+
+// restartComponent triggers a rolling restart of the StatefulSet of the given
+// nested component.
+func (r *NestedControlPlaneReconciler) restartComponent(ctx context.Context, clusterName, namespace, component string, now time.Time) error {
+	var sts appsv1.StatefulSet
+	if err := r.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      clusterName + "-" + component,
+	}, &sts); err != nil {
+		// the StatefulSet will pick up the new certificates once created.
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(sts.DeepCopy())
+	if sts.Spec.Template.Annotations == nil {
+		sts.Spec.Template.Annotations = map[string]string{}
+	}
+	sts.Spec.Template.Annotations[certificate.CertificatesRotatedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	ctrl.LoggerFrom(ctx).Info("restarting component after certificate rotation", "component", component)
+	return r.Patch(ctx, &sts, patch)
+}
+
+func (r *NestedControlPlaneReconciler) certificateRenewBefore() time.Duration {
+	if r.CertificateRenewBefore != 0 {
+		return r.CertificateRenewBefore
+	}
+	return certificate.DefaultCertificateRenewBefore
+}
+
+func (r *NestedControlPlaneReconciler) caRenewBefore() time.Duration {
+	if r.CARenewBefore != 0 {
+		return r.CARenewBefore
+	}
+	return certificate.DefaultCARenewBefore
+}
+
+func (r *NestedControlPlaneReconciler) caOverlapWindow() time.Duration {
+	if r.CAOverlapWindow != 0 {
+		return r.CAOverlapWindow
+	}
+	return certificate.DefaultCAOverlapWindow
+}
+
+// minRequeue returns the result that requeues first.
+func minRequeue(result ctrl.Result, after time.Duration) ctrl.Result {
+	if after <= 0 {
+		after = time.Second
+	}
+	if result.RequeueAfter == 0 || after < result.RequeueAfter {
+		result.RequeueAfter = after
+	}
+	return result
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	certutil "k8s.io/client-go/util/cert"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	controlplanev1 "sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/api/v1alpha4"
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/certificate"
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/certificate/util"
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/kubeadm"
+)
+
+func TestRotateCertificates(t *testing.T) {
+	tests := []struct {
+		name string
+		// caUnmanaged marks the cluster CA as provided by the user.
+		caUnmanaged bool
+		// overlapPassed starts from a cluster CA rotation whose overlap window passed.
+		overlapPassed          bool
+		certificateRenewBefore time.Duration
+		caRenewBefore          time.Duration
+		expectCARotated        bool
+		expectCABundle         int
+		expectRenewed          bool
+		expectRestarted        bool
+		expectReason           string
+		expectRequeue          bool
+	}{
+		{
+			name:           "TestUpToDate",
+			expectCABundle: 1,
+		},
+		{
+			name:                   "TestExpiringCertificate",
+			certificateRenewBefore: 2 * 365 * 24 * time.Hour,
+			expectCABundle:         1,
+			expectRenewed:          true,
+			expectRestarted:        true,
+		},
+		{
+			name:            "TestExpiringCA",
+			caRenewBefore:   20 * 365 * 24 * time.Hour,
+			expectCARotated: true,
+			expectCABundle:  2,
+			expectRenewed:   true,
+			expectRestarted: true,
+			expectReason:    controlplanev1.CARotationInProgressReason,
+			expectRequeue:   true,
+		},
+		{
+			name:           "TestExpiringUnmanagedCA",
+			caUnmanaged:    true,
+			caRenewBefore:  20 * 365 * 24 * time.Hour,
+			expectCABundle: 1,
+			expectReason:   controlplanev1.CertificatesExpiringReason,
+		},
+		{
+			name:            "TestCAOverlapPassed",
+			overlapPassed:   true,
+			expectCABundle:  1,
+			expectRestarted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}}
+			clusterName := client.ObjectKeyFromObject(cluster)
+			ncp := &controlplanev1.NestedControlPlane{
+				TypeMeta:   metav1.TypeMeta{APIVersion: controlplanev1.GroupVersion.String(), Kind: "NestedControlPlane"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ncp"},
+			}
+			owner := *metav1.NewControllerRef(ncp, controlplanev1.GroupVersion.WithKind("NestedControlPlane"))
+
+			crt, key, err := util.NewSelfSignedCACert("kubernetes")
+			if err != nil {
+				t.Fatalf("NewSelfSignedCACert() error = %v", err)
+			}
+			ca := &certificate.KeyPair{Purpose: secret.ClusterCA, Cert: crt, Key: key, Generated: !tt.caUnmanaged}
+			caSecret := ca.AsSecret(clusterName, owner)
+			if tt.overlapPassed {
+				if ca, err = certificate.RotateCA(caSecret, secret.ClusterCA, time.Now().Add(-time.Minute)); err != nil {
+					t.Fatalf("RotateCA() error = %v", err)
+				}
+			}
+			kp, err := certificate.NewAPIServerCrtAndKey(ca, cluster.Name, "", "apiserver.example.com")
+			if err != nil {
+				t.Fatalf("NewAPIServerCrtAndKey() error = %v", err)
+			}
+			apiserver := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: cluster.Name + "-" + kubeadm.APIServer}}
+			etcd := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: cluster.Name + "-" + kubeadm.Etcd}}
+
+			scheme := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(scheme)
+			_ = controlplanev1.AddToScheme(scheme)
+			r := &NestedControlPlaneReconciler{
+				Client:                 fake.NewClientBuilder().WithScheme(scheme).WithObjects(caSecret, kp.AsSecret(clusterName, owner), apiserver, etcd).Build(),
+				Scheme:                 scheme,
+				CertificateRenewBefore: tt.certificateRenewBefore,
+				CARenewBefore:          tt.caRenewBefore,
+				CAOverlapWindow:        time.Hour,
+			}
+
+			result, err := r.rotateCertificates(ctx, cluster, ncp)
+			if err != nil {
+				t.Fatalf("rotateCertificates() error = %v", err)
+			}
+			if (result.RequeueAfter > 0) != tt.expectRequeue || result.RequeueAfter > time.Hour {
+				t.Errorf("rotateCertificates() requeue after %v, expect requeue %v within the overlap window", result.RequeueAfter, tt.expectRequeue)
+			}
+
+			gotCASecret, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, secret.ClusterCA)
+			if err != nil {
+				t.Fatalf("failed to get CA secret: %v", err)
+			}
+			bundle, err := certutil.ParseCertsPEM(gotCASecret.Data[secret.TLSCrtDataName])
+			if err != nil {
+				t.Fatalf("ParseCertsPEM() error = %v", err)
+			}
+			if len(bundle) != tt.expectCABundle {
+				t.Errorf("CA bundle has %d certificates, expect %d", len(bundle), tt.expectCABundle)
+			}
+			if rotated := !bundle[0].Equal(ca.Cert); rotated != tt.expectCARotated {
+				t.Errorf("CA rotated %v, expect %v", rotated, tt.expectCARotated)
+			}
+			_, inProgress := gotCASecret.Annotations[certificate.CAOverlapUntilAnnotation]
+			if inProgress != (tt.expectCABundle == 2) {
+				t.Errorf("CA secret has the %s annotation %v, expect %v", certificate.CAOverlapUntilAnnotation, inProgress, tt.expectCABundle == 2)
+			}
+
+			gotSecret, err := secret.GetFromNamespacedName(ctx, r.Client, clusterName, certificate.APIServerClient)
+			if err != nil {
+				t.Fatalf("failed to get certificate secret: %v", err)
+			}
+			got, err := certificate.KeyPairFromSecret(certificate.APIServerClient, gotSecret)
+			if err != nil {
+				t.Fatalf("KeyPairFromSecret() error = %v", err)
+			}
+			if renewed := !got.Cert.Equal(kp.Cert); renewed != tt.expectRenewed {
+				t.Errorf("certificate renewed %v, expect %v", renewed, tt.expectRenewed)
+			}
+			if err := got.Cert.CheckSignatureFrom(bundle[0]); err != nil && !tt.caUnmanaged {
+				t.Errorf("certificate is not signed by the current CA: %v", err)
+			}
+
+			for _, sts := range []*appsv1.StatefulSet{apiserver, etcd} {
+				var gotSts appsv1.StatefulSet
+				if err := r.Get(ctx, types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}, &gotSts); err != nil {
+					t.Fatalf("failed to get StatefulSet %s: %v", sts.Name, err)
+				}
+				_, restarted := gotSts.Spec.Template.Annotations[certificate.CertificatesRotatedAtAnnotation]
+				// the etcd does not mount any certificate issued by the cluster CA.
+				expectRestarted := tt.expectRestarted && sts == apiserver
+				if restarted != expectRestarted {
+					t.Errorf("StatefulSet %s restarted %v, expect %v", sts.Name, restarted, expectRestarted)
+				}
+			}
+
+			condition := conditions.Get(ncp, controlplanev1.CertificatesUpToDateCondition)
+			if condition == nil {
+				t.Fatalf("%s condition is not set", controlplanev1.CertificatesUpToDateCondition)
+			}
+			if tt.expectReason == "" && condition.Status != corev1.ConditionTrue {
+				t.Errorf("%s condition is %s (%s), expect True", condition.Type, condition.Status, condition.Reason)
+			}
+			if tt.expectReason != "" && (condition.Status != corev1.ConditionFalse || condition.Reason != tt.expectReason) {
+				t.Errorf("%s condition is %s (%s), expect False (%s)", condition.Type, condition.Status, condition.Reason, tt.expectReason)
+			}
+		})
+	}
+}
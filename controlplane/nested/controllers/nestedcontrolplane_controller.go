@@ -58,6 +58,18 @@ type NestedControlPlaneReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// CertificateRenewBefore defines how long before expiry the component
+	// certificates are reissued, defaults to certificate.DefaultCertificateRenewBefore.
+	CertificateRenewBefore time.Duration
+
+	// CARenewBefore defines how long before expiry the CAs are rotated,
+	// defaults to certificate.DefaultCARenewBefore.
+	CARenewBefore time.Duration
+
+	// CAOverlapWindow defines how long the previous CA stays trusted after a
+	// CA rotation, defaults to certificate.DefaultCAOverlapWindow.
+	CAOverlapWindow time.Duration
 }
 
 // SetupWithManager will configure the controller with the manager.
@@ -178,6 +190,7 @@ func patchControlPlane(ctx context.Context, patchHelper *patch.Helper, ncp *cont
 			clusterv1.ReadyCondition,
 			kcpv1.AvailableCondition,
 			kcpv1.CertificatesAvailableCondition,
+			controlplanev1.CertificatesUpToDateCondition,
 		}},
 		patch.WithStatusObservedGeneration{},
 	)
@@ -210,6 +223,12 @@ func (r *NestedControlPlaneReconciler) reconcile(ctx context.Context, log logr.L
 		return result, err
 	}
 
+	certResult, err := r.reconcileCertificates(ctx, cluster, ncp)
+	if err != nil {
+		log.Error(err, "failed to rotate certificates")
+		return certResult, err
+	}
+
 	addOwners := []client.Object{}
 	isReady := []int{}
 	nestedComponents := map[client.Object]*corev1.ObjectReference{
@@ -287,7 +306,7 @@ func (r *NestedControlPlaneReconciler) reconcile(ctx context.Context, log logr.L
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	return ctrl.Result{}, nil
+	return certResult, nil
 }
 
 // reconcileKubeconfig will check if the control plane endpoint has been set
@@ -36,6 +36,7 @@ import (
 
 	infrastructurev1alpha4 "sigs.k8s.io/cluster-api-provider-nested/api/v1alpha4"
 	controlplanev1alpha4 "sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/api/v1alpha4"
+	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/certificate"
 	"sigs.k8s.io/cluster-api-provider-nested/controlplane/nested/controllers"
 	// +kubebuilder:scaffold:imports
 )
@@ -54,6 +55,9 @@ var (
 	syncPeriod                  time.Duration
 	webhookPort                 int
 	healthAddr                  string
+	certificateRenewBefore      time.Duration
+	caRenewBefore               time.Duration
+	caOverlapWindow             time.Duration
 )
 
 func init() {
@@ -93,6 +97,15 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
 
+	fs.DurationVar(&certificateRenewBefore, "certificate-renew-before", certificate.DefaultCertificateRenewBefore,
+		"How long before expiry the nested component certificates are reissued (e.g. 2160h)")
+
+	fs.DurationVar(&caRenewBefore, "ca-renew-before", certificate.DefaultCARenewBefore,
+		"How long before expiry the nested control plane CAs are rotated (e.g. 8760h)")
+
+	fs.DurationVar(&caOverlapWindow, "ca-overlap-window", certificate.DefaultCAOverlapWindow,
+		"How long the previous CA stays trusted after a CA rotation (e.g. 24h)")
+
 	feature.MutableGates.AddFlag(fs)
 }
 
@@ -143,9 +156,12 @@ func main() {
 	}
 
 	if err = (&controllers.NestedControlPlaneReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("controlplane").WithName("NestedControlPlane"),
-		Scheme: mgr.GetScheme(),
+		Client:                 mgr.GetClient(),
+		Log:                    ctrl.Log.WithName("controllers").WithName("controlplane").WithName("NestedControlPlane"),
+		Scheme:                 mgr.GetScheme(),
+		CertificateRenewBefore: certificateRenewBefore,
+		CARenewBefore:          caRenewBefore,
+		CAOverlapWindow:        caOverlapWindow,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NestedControlPlane")
 		os.Exit(1)
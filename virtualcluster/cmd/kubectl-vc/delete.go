@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	tenancyv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+)
+
+const (
+	pollVCPeriod = 2 * time.Second
+
+	deleteExample = `
+	# Delete a VirtualCluster and wait for its control plane to be removed
+	kubectl vc delete -n foo bar
+
+	# Delete the VirtualClusters with a label across all namespaces without waiting
+	kubectl vc delete -A -l team=foo --wait=false`
+)
+
+type DeleteOptions struct {
+	VCFlags
+	vcclient vcclient.Interface
+	names    []string
+	wait     bool
+	timeout  time.Duration
+	out      io.Writer
+}
+
+func NewCmdDelete(f Factory) *cobra.Command {
+	o := &DeleteOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     "delete [VC_NAME...]",
+		Short:   "Delete VirtualClusters",
+		Example: deleteExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f, args))
+			CheckErr(o.Validate(cmd, args, true))
+			CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd, true)
+	cmd.Flags().BoolVar(&o.wait, "wait", true, "If true, wait for the VirtualClusters to be removed, i.e. their control planes are deleted by the vc-manager")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 5*time.Minute, "The length of time to wait for the VirtualClusters to be removed")
+
+	return cmd
+}
+
+func (o *DeleteOptions) Complete(f Factory, args []string) error {
+	var err error
+	o.vcclient, err = f.VirtualClusterClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.names = args
+	return nil
+}
+
+func (o *DeleteOptions) Run() error {
+	vcs, err := o.Select(o.vcclient, o.names)
+	if err != nil {
+		return err
+	}
+
+	deleted := make([]tenancyv1alpha1.VirtualCluster, 0, len(vcs))
+	for i := range vcs {
+		vc := &vcs[i]
+		err := o.vcclient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Delete(vc.Name, &metav1.DeleteOptions{
+			Preconditions: metav1.NewUIDPreconditions(string(vc.UID)),
+		})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("fail to delete VirtualCluster %s/%s: %v", vc.Namespace, vc.Name, err)
+		}
+		if o.output == "" {
+			fmt.Fprintf(o.out, "VirtualCluster %s/%s deleted\n", vc.Namespace, vc.Name)
+		}
+		deleted = append(deleted, *vc)
+	}
+
+	if o.wait {
+		ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+		defer cancel()
+		for i := range deleted {
+			if err := o.waitForRemoval(ctx, &deleted[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if o.output != "" {
+		return printVirtualClusters(o.out, deleted, o.output, len(o.names) == 1)
+	}
+	return nil
+}
+
+// waitForRemoval waits for vc to be removed once its finalizers are done.
+func (o *DeleteOptions) waitForRemoval(ctx context.Context, vc *tenancyv1alpha1.VirtualCluster) error {
+	var finalizers []string
+	err := wait.PollImmediateUntil(pollVCPeriod, func() (bool, error) {
+		current, err := o.vcclient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Get(vc.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && current.UID != vc.UID) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		finalizers = current.Finalizers
+		return false, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out waiting for VirtualCluster %s/%s to be removed, pending finalizers: %v", vc.Namespace, vc.Name, finalizers)
+	}
+	if err != nil {
+		return err
+	}
+	if o.output == "" {
+		fmt.Fprintf(o.out, "VirtualCluster %s/%s removed\n", vc.Namespace, vc.Name)
+	}
+	return nil
+}
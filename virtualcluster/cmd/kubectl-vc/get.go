@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	tenancyv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+const (
+	getExample = `
+	# Get a VirtualCluster
+	kubectl vc get -n foo bar
+
+	# Get a VirtualCluster by namespaced name in json
+	kubectl vc get foo/bar -o json`
+
+	describeExample = `
+	# Describe a VirtualCluster, with the readiness of its control plane
+	kubectl vc describe -n foo bar
+
+	# Describe the VirtualClusters across all namespaces in yaml
+	kubectl vc describe -A -o yaml`
+)
+
+type GetOptions struct {
+	VCFlags
+	vcclient vcclient.Interface
+	client   kubernetes.Interface
+	names    []string
+	describe bool
+	out      io.Writer
+}
+
+func NewCmdGet(f Factory) *cobra.Command {
+	o := &GetOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     "get [VC_NAME...]",
+		Short:   "Display one or many VirtualClusters",
+		Example: getExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f, args))
+			CheckErr(o.Validate(cmd, args, false))
+			CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd, false)
+
+	return cmd
+}
+
+func NewCmdDescribe(f Factory) *cobra.Command {
+	o := &GetOptions{out: os.Stdout, describe: true}
+
+	cmd := &cobra.Command{
+		Use:     "describe [VC_NAME...]",
+		Short:   "Show details of one or many VirtualClusters",
+		Example: describeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f, args))
+			CheckErr(o.Validate(cmd, args, false))
+			CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd, false)
+
+	return cmd
+}
+
+func (o *GetOptions) Complete(f Factory, args []string) error {
+	var err error
+	o.vcclient, err = f.VirtualClusterClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.names = args
+	return nil
+}
+
+func (o *GetOptions) Run() error {
+	vcs, err := o.Select(o.vcclient, o.names)
+	if err != nil {
+		return err
+	}
+	if len(vcs) == 0 && o.output == "" {
+		_, err = io.WriteString(os.Stderr, "No VirtualClusters found.\n")
+		return err
+	}
+
+	if !o.describe {
+		if o.output != "" {
+			return printVirtualClusters(o.out, vcs, o.output, len(o.names) == 1)
+		}
+		return printVirtualClusterTable(o.out, vcs, o.allNamespaces)
+	}
+
+	descriptions := make([]*Description, 0, len(vcs))
+	for i := range vcs {
+		descriptions = append(descriptions, describeVirtualCluster(o.vcclient, o.client, &vcs[i]))
+	}
+	if o.output != "" {
+		if len(o.names) == 1 {
+			return printOutput(o.out, descriptions[0], o.output)
+		}
+		return printOutput(o.out, descriptions, o.output)
+	}
+	for i, d := range descriptions {
+		if i > 0 {
+			fmt.Fprintln(o.out)
+		}
+		if err := d.Print(o.out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Description is the state of a VirtualCluster and of its control plane.
+type Description struct {
+	Name                  string                             `json:"name"`
+	Namespace             string                             `json:"namespace"`
+	Phase                 tenancyv1alpha1.ClusterPhase       `json:"phase"`
+	Reason                string                             `json:"reason,omitempty"`
+	Message               string                             `json:"message,omitempty"`
+	ClusterVersion        string                             `json:"clusterVersion"`
+	ClusterVersionApplied string                             `json:"clusterVersionApplied,omitempty"`
+	ReadyForUpgrade       bool                               `json:"readyForUpgrade"`
+	RootNamespace         string                             `json:"rootNamespace"`
+	Conditions            []tenancyv1alpha1.ClusterCondition `json:"conditions,omitempty"`
+	Components            []ComponentStatus                  `json:"components,omitempty"`
+	// ComponentsError tells why the readiness of the components is unknown.
+	ComponentsError string `json:"componentsError,omitempty"`
+}
+
+// ComponentStatus is the readiness of a control plane component.
+type ComponentStatus struct {
+	Name          string `json:"name"`
+	Ready         bool   `json:"ready"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+	Replicas      int32  `json:"replicas"`
+	Error         string `json:"error,omitempty"`
+}
+
+// describeVirtualCluster returns the description of vc.
+func describeVirtualCluster(vccli vcclient.Interface, cli kubernetes.Interface, vc *tenancyv1alpha1.VirtualCluster) *Description {
+	d := &Description{
+		Name:                  vc.Name,
+		Namespace:             vc.Namespace,
+		Phase:                 vc.Status.Phase,
+		Reason:                vc.Status.Reason,
+		Message:               vc.Status.Message,
+		ClusterVersion:        vc.Spec.ClusterVersionName,
+		ClusterVersionApplied: vc.Labels[constants.LabelClusterVersionApplied],
+		ReadyForUpgrade:       vc.Labels[constants.LabelVCReadyForUpgrade] == "true",
+		RootNamespace:         conversion.ToClusterKey(vc),
+		Conditions:            vc.Status.Conditions,
+	}
+
+	cv, err := vccli.TenancyV1alpha1().ClusterVersions().Get(vc.Spec.ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		d.ComponentsError = fmt.Sprintf("cluster version not found: %v", err)
+		return d
+	}
+	d.Components = componentStatuses(cli, d.RootNamespace, cv)
+	return d
+}
+
+// componentStatuses returns the readiness of the control plane components of cv in the root
+// namespace.
+func componentStatuses(cli kubernetes.Interface, rootNamespace string, cv *tenancyv1alpha1.ClusterVersion) []ComponentStatus {
+	var statuses []ComponentStatus
+	for _, bundle := range []*tenancyv1alpha1.StatefulSetSvcBundle{cv.Spec.ETCD, cv.Spec.APIServer, cv.Spec.ControllerManager} {
+		if bundle == nil {
+			continue
+		}
+		name := bundle.Name
+		if name == "" && bundle.StatefulSet != nil {
+			name = bundle.StatefulSet.Name
+		}
+		status := ComponentStatus{Name: name}
+		sts, err := cli.AppsV1().StatefulSets(rootNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			status.Error = "not created"
+		case err != nil:
+			status.Error = err.Error()
+		default:
+			status.Replicas = 1
+			if sts.Spec.Replicas != nil {
+				status.Replicas = *sts.Spec.Replicas
+			}
+			status.ReadyReplicas = sts.Status.ReadyReplicas
+			status.Ready = status.ReadyReplicas == status.Replicas && sts.Status.ObservedGeneration >= sts.Generation &&
+				sts.Status.UpdatedReplicas == status.Replicas
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Print prints the description in a human readable format.
+func (d *Description) Print(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", d.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", d.Namespace)
+	fmt.Fprintf(w, "Phase:\t%s\n", valueOrNone(string(d.Phase)))
+	fmt.Fprintf(w, "Reason:\t%s\n", valueOrNone(d.Reason))
+	fmt.Fprintf(w, "Message:\t%s\n", valueOrNone(d.Message))
+	fmt.Fprintf(w, "ClusterVersion:\t%s\n", d.ClusterVersion)
+	fmt.Fprintf(w, "ClusterVersion Applied:\t%s\n", valueOrNone(d.ClusterVersionApplied))
+	fmt.Fprintf(w, "Ready For Upgrade:\t%t\n", d.ReadyForUpgrade)
+	fmt.Fprintf(w, "Root Namespace:\t%s\n", d.RootNamespace)
+
+	fmt.Fprintln(w, "Components:")
+	if d.ComponentsError != "" {
+		fmt.Fprintf(w, "  <unknown>: %s\n", d.ComponentsError)
+	} else {
+		fmt.Fprintln(w, "  Name\tReady\tReplicas\tError")
+		fmt.Fprintln(w, "  ----\t-----\t--------\t-----")
+		for _, c := range d.Components {
+			fmt.Fprintf(w, "  %s\t%t\t%d/%d\t%s\n", c.Name, c.Ready, c.ReadyReplicas, c.Replicas, c.Error)
+		}
+	}
+
+	fmt.Fprintln(w, "Conditions:")
+	if len(d.Conditions) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	} else {
+		fmt.Fprintln(w, "  LastTransitionTime\tReason\tMessage")
+		fmt.Fprintln(w, "  ------------------\t------\t-------")
+		for _, c := range d.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", c.LastTransitionTime.UTC().Format("2006-01-02T15:04:05Z"), c.Reason, c.Message)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	tenancyv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+)
+
+const (
+	kubeconfigExample = `
+	# Print the kubeconfig of a VirtualCluster
+	kubectl vc kubeconfig -n foo bar > bar.kubeconfig
+
+	# Merge the kubeconfig of a VirtualCluster into ~/.kube/config as the context foo-bar, and use it
+	kubectl vc kubeconfig foo/bar --merge --context foo-bar --use-context
+
+	# Merge the kubeconfigs of all the VirtualClusters, named vc-<namespace>-<name>
+	kubectl vc kubeconfig -A --all --merge`
+)
+
+type KubeconfigOptions struct {
+	VCFlags
+	client         client.Client
+	vcclient       vcclient.Interface
+	names          []string
+	merge          bool
+	kubeconfigPath string
+	contextName    string
+	useContext     bool
+	out            io.Writer
+}
+
+// MergedContext is a context merged into the kubeconfig of the user.
+type MergedContext struct {
+	VirtualCluster string `json:"virtualCluster"`
+	Context        string `json:"context"`
+	Kubeconfig     string `json:"kubeconfig"`
+}
+
+func NewCmdKubeconfig(f Factory) *cobra.Command {
+	o := &KubeconfigOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     "kubeconfig [VC_NAME...]",
+		Short:   "Print the kubeconfig of a VirtualCluster, or merge it into your kubeconfig",
+		Example: kubeconfigExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f, args))
+			CheckErr(o.Validate(cmd, args))
+			CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd, true)
+	cmd.Flags().BoolVar(&o.merge, "merge", false, "Merge the kubeconfig into the kubeconfig file instead of printing it")
+	cmd.Flags().StringVar(&o.kubeconfigPath, "kubeconfig-path", clientcmd.NewDefaultPathOptions().GetDefaultFilename(), "The kubeconfig file to merge into")
+	cmd.Flags().StringVar(&o.contextName, "context", "", "The name of the merged context, vc-<namespace>-<name> by default")
+	cmd.Flags().BoolVar(&o.useContext, "use-context", false, "Set the merged context as the current context")
+
+	return cmd
+}
+
+func (o *KubeconfigOptions) Complete(f Factory, args []string) error {
+	var err error
+	o.vcclient, err = f.VirtualClusterClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = f.GenericClient()
+	if err != nil {
+		return err
+	}
+
+	o.names = args
+	return nil
+}
+
+func (o *KubeconfigOptions) Validate(cmd *cobra.Command, args []string) error {
+	if err := o.VCFlags.Validate(cmd, args, true); err != nil {
+		return err
+	}
+	if !o.merge && (o.contextName != "" || o.useContext) {
+		return UsageErrorf(cmd, "--context and --use-context require --merge")
+	}
+	if len(args) != 1 && (o.contextName != "" || o.useContext) {
+		return UsageErrorf(cmd, "--context and --use-context require a single VC_NAME")
+	}
+	return nil
+}
+
+func (o *KubeconfigOptions) Run() error {
+	vcs, err := o.Select(o.vcclient, o.names)
+	if err != nil {
+		return err
+	}
+	if len(vcs) == 0 {
+		return errors.New("no VirtualCluster found")
+	}
+
+	if !o.merge {
+		if len(vcs) > 1 {
+			return fmt.Errorf("%d VirtualClusters selected, only a single kubeconfig can be printed, use --merge", len(vcs))
+		}
+		kubecfgBytes, err := o.kubeconfig(&vcs[0])
+		if err != nil {
+			return err
+		}
+		if o.output == outputJSON {
+			if kubecfgBytes, err = yaml.YAMLToJSON(kubecfgBytes); err != nil {
+				return err
+			}
+		}
+		_, err = o.out.Write(kubecfgBytes)
+		return err
+	}
+
+	config, err := clientcmd.LoadFromFile(o.kubeconfigPath)
+	if os.IsNotExist(err) {
+		config = clientcmdapi.NewConfig()
+	} else if err != nil {
+		return errors.Wrapf(err, "load %s", o.kubeconfigPath)
+	}
+
+	merged := make([]MergedContext, 0, len(vcs))
+	for i := range vcs {
+		vc := &vcs[i]
+		kubecfgBytes, err := o.kubeconfig(vc)
+		if err != nil {
+			return err
+		}
+		tenantConfig, err := clientcmd.Load(kubecfgBytes)
+		if err != nil {
+			return errors.Wrapf(err, "load kubeconfig of VirtualCluster %s/%s", vc.Namespace, vc.Name)
+		}
+		contextName := o.contextName
+		if contextName == "" {
+			contextName = fmt.Sprintf("vc-%s-%s", vc.Namespace, vc.Name)
+		}
+		if err := mergeKubeConfig(config, tenantConfig, contextName); err != nil {
+			return errors.Wrapf(err, "merge kubeconfig of VirtualCluster %s/%s", vc.Namespace, vc.Name)
+		}
+		if o.useContext {
+			config.CurrentContext = contextName
+		}
+		merged = append(merged, MergedContext{
+			VirtualCluster: vc.Namespace + "/" + vc.Name,
+			Context:        contextName,
+			Kubeconfig:     o.kubeconfigPath,
+		})
+	}
+
+	if err := clientcmd.WriteToFile(*config, o.kubeconfigPath); err != nil {
+		return err
+	}
+
+	if o.output != "" {
+		return printOutput(o.out, merged, o.output)
+	}
+	for _, m := range merged {
+		fmt.Fprintf(o.out, "VirtualCluster %s merged as context %q into %s\n", m.VirtualCluster, m.Context, m.Kubeconfig)
+	}
+	if o.useContext {
+		fmt.Fprintf(o.out, "Switched to context %q\n", config.CurrentContext)
+	}
+	return nil
+}
+
+// kubeconfig returns the kubeconfig of vc.
+func (o *KubeconfigOptions) kubeconfig(vc *tenancyv1alpha1.VirtualCluster) ([]byte, error) {
+	cv, err := o.vcclient.TenancyV1alpha1().ClusterVersions().Get(vc.Spec.ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cluster version not found")
+	}
+	return genKubeConfig(o.client, vc, cv)
+}
+
+// mergeKubeConfig merges the current context of src into dst as contextName, with its cluster
+// and user also named contextName. Existing entries of the same name are replaced, so that the
+// context is refreshed when merged again.
+func mergeKubeConfig(dst, src *clientcmdapi.Config, contextName string) error {
+	srcContextName := src.CurrentContext
+	if srcContextName == "" && len(src.Contexts) == 1 {
+		for name := range src.Contexts {
+			srcContextName = name
+		}
+	}
+	srcContext, ok := src.Contexts[srcContextName]
+	if !ok {
+		return fmt.Errorf("no current context")
+	}
+	cluster, ok := src.Clusters[srcContext.Cluster]
+	if !ok {
+		return fmt.Errorf("cluster %q of context %q not found", srcContext.Cluster, srcContextName)
+	}
+	authInfo, ok := src.AuthInfos[srcContext.AuthInfo]
+	if !ok {
+		return fmt.Errorf("user %q of context %q not found", srcContext.AuthInfo, srcContextName)
+	}
+
+	dst.Clusters[contextName] = cluster
+	dst.AuthInfos[contextName] = authInfo
+	context := srcContext.DeepCopy()
+	context.Cluster = contextName
+	context.AuthInfo = contextName
+	dst.Contexts[contextName] = context
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newKubeConfig(contextName, server, token string) *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	config.Clusters[contextName] = &clientcmdapi.Cluster{Server: server}
+	config.AuthInfos[contextName] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[contextName] = &clientcmdapi.Context{Cluster: contextName, AuthInfo: contextName}
+	config.CurrentContext = contextName
+	return config
+}
+
+func TestMergeKubeConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		dst         *clientcmdapi.Config
+		src         *clientcmdapi.Config
+		expectError bool
+	}{
+		{
+			name: "into an empty kubeconfig",
+			dst:  clientcmdapi.NewConfig(),
+			src:  newKubeConfig("kubernetes-admin@kubernetes", "https://10.0.0.1:6443", "new"),
+		},
+		{
+			name: "replace the existing context",
+			dst:  newKubeConfig("vc-foo-bar", "https://10.0.0.2:6443", "old"),
+			src:  newKubeConfig("kubernetes-admin@kubernetes", "https://10.0.0.1:6443", "new"),
+		},
+		{
+			name: "keep the other contexts",
+			dst:  newKubeConfig("super", "https://10.0.0.3:6443", "super"),
+			src:  newKubeConfig("kubernetes-admin@kubernetes", "https://10.0.0.1:6443", "new"),
+		},
+		{
+			name: "single context without current context",
+			dst:  clientcmdapi.NewConfig(),
+			src: func() *clientcmdapi.Config {
+				config := newKubeConfig("kubernetes-admin@kubernetes", "https://10.0.0.1:6443", "new")
+				config.CurrentContext = ""
+				return config
+			}(),
+		},
+		{
+			name: "missing cluster",
+			dst:  clientcmdapi.NewConfig(),
+			src: func() *clientcmdapi.Config {
+				config := newKubeConfig("kubernetes-admin@kubernetes", "https://10.0.0.1:6443", "new")
+				config.Clusters = map[string]*clientcmdapi.Cluster{}
+				return config
+			}(),
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			existing := make(map[string]bool)
+			for name := range tc.dst.Contexts {
+				existing[name] = true
+			}
+
+			err := mergeKubeConfig(tc.dst, tc.src, "vc-foo-bar")
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			context, ok := tc.dst.Contexts["vc-foo-bar"]
+			if !ok || context.Cluster != "vc-foo-bar" || context.AuthInfo != "vc-foo-bar" {
+				t.Fatalf("expected context vc-foo-bar, got %+v", tc.dst.Contexts)
+			}
+			if server := tc.dst.Clusters["vc-foo-bar"].Server; server != "https://10.0.0.1:6443" {
+				t.Errorf("expected the merged server, got %s", server)
+			}
+			if token := tc.dst.AuthInfos["vc-foo-bar"].Token; token != "new" {
+				t.Errorf("expected the merged token, got %s", token)
+			}
+			for name := range existing {
+				if _, ok := tc.dst.Contexts[name]; !ok {
+					t.Errorf("expected context %s to be kept", name)
+				}
+			}
+		})
+	}
+}
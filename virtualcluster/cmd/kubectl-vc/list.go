@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+)
+
+const (
+	listExample = `
+	# List the VirtualClusters in the default namespace
+	kubectl vc list
+
+	# List the VirtualClusters across all namespaces
+	kubectl vc list -A
+
+	# List the VirtualClusters with a label in yaml
+	kubectl vc list -l team=foo -o yaml`
+)
+
+type ListOptions struct {
+	VCFlags
+	vcclient vcclient.Interface
+	out      io.Writer
+}
+
+func NewCmdList(f Factory) *cobra.Command {
+	o := &ListOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List VirtualClusters",
+		Example: listExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f))
+			CheckErr(o.Validate(cmd, args))
+			CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd, false)
+
+	return cmd
+}
+
+func (o *ListOptions) Complete(f Factory) error {
+	var err error
+	o.vcclient, err = f.VirtualClusterClientSet()
+	return err
+}
+
+func (o *ListOptions) Validate(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return UsageErrorf(cmd, "list takes no VC_NAME, use get instead")
+	}
+	return o.VCFlags.Validate(cmd, args, false)
+}
+
+func (o *ListOptions) Run() error {
+	vcs, err := o.Select(o.vcclient, nil)
+	if err != nil {
+		return err
+	}
+	if o.output != "" {
+		return printVirtualClusters(o.out, vcs, o.output, false)
+	}
+	if len(vcs) == 0 {
+		_, err = io.WriteString(os.Stderr, "No VirtualClusters found.\n")
+		return err
+	}
+	return printVirtualClusterTable(o.out, vcs, o.allNamespaces)
+}
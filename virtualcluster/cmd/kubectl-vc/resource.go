@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+
+	tenancyv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// VCFlags selects the VirtualClusters a command operates on, and how they are printed.
+type VCFlags struct {
+	namespace     string
+	allNamespaces bool
+	selector      string
+	all           bool
+	output        string
+}
+
+// AddFlags adds the flags to cmd. The --all flag is only added if withAll is set, for the
+// commands which must not operate on every VirtualCluster of a namespace by default.
+func (f *VCFlags) AddFlags(cmd *cobra.Command, withAll bool) {
+	cmd.Flags().StringVarP(&f.namespace, "namespace", "n", metav1.NamespaceDefault, "If present, the namespace scope for this CLI request")
+	cmd.Flags().BoolVarP(&f.allNamespaces, "all-namespaces", "A", false, "If present, select the VirtualClusters across all namespaces. Names are given as NAMESPACE/NAME")
+	cmd.Flags().StringVarP(&f.selector, "selector", "l", "", "Selector (label query) to filter on, e.g. -l key=value")
+	cmd.Flags().StringVarP(&f.output, "output", "o", "", "Output format. One of: json|yaml")
+	if withAll {
+		cmd.Flags().BoolVar(&f.all, "all", false, "Select all the VirtualClusters in the namespace, or across all namespaces with --all-namespaces")
+	}
+}
+
+// Validate checks the flags and the names given in args.
+func (f *VCFlags) Validate(cmd *cobra.Command, args []string, withAll bool) error {
+	if f.output != "" && f.output != outputJSON && f.output != outputYAML {
+		return UsageErrorf(cmd, "unsupported output format %q, one of json|yaml is expected", f.output)
+	}
+	if len(args) > 0 && (f.selector != "" || f.all) {
+		return UsageErrorf(cmd, "VC_NAME cannot be given with --selector or --all")
+	}
+	if withAll && len(args) == 0 && f.selector == "" && !f.all {
+		return UsageErrorf(cmd, "VC_NAME, --selector or --all should be given")
+	}
+	if f.allNamespaces {
+		for _, arg := range args {
+			if !strings.Contains(arg, "/") {
+				return UsageErrorf(cmd, "VC_NAME should be given as NAMESPACE/NAME with --all-namespaces, got %q", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// Select returns the VirtualClusters named by args, given as NAME or NAMESPACE/NAME, or the ones
+// matching the selector if no name is given.
+func (f *VCFlags) Select(vccli vcclient.Interface, args []string) ([]tenancyv1alpha1.VirtualCluster, error) {
+	if len(args) == 0 {
+		namespace := f.namespace
+		if f.allNamespaces {
+			namespace = metav1.NamespaceAll
+		}
+		vcList, err := vccli.TenancyV1alpha1().VirtualClusters(namespace).List(metav1.ListOptions{LabelSelector: f.selector})
+		if err != nil {
+			return nil, err
+		}
+		return vcList.Items, nil
+	}
+
+	vcs := make([]tenancyv1alpha1.VirtualCluster, 0, len(args))
+	for _, arg := range args {
+		namespace, name := splitVCName(arg, f.namespace)
+		vc, err := vccli.TenancyV1alpha1().VirtualClusters(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		vcs = append(vcs, *vc)
+	}
+	return vcs, nil
+}
+
+// splitVCName returns the namespace and the name of arg, given as NAME or NAMESPACE/NAME.
+func splitVCName(arg, defaultNamespace string) (string, string) {
+	if strings.Contains(arg, "/") {
+		namespacedName := strings.SplitN(arg, "/", 2)
+		return namespacedName[0], namespacedName[1]
+	}
+	return defaultNamespace, arg
+}
+
+// printOutput prints v in format, json or yaml.
+func printOutput(out io.Writer, v interface{}, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case outputJSON:
+		data, err = json.MarshalIndent(v, "", "    ")
+		data = append(data, '\n')
+	case outputYAML:
+		data, err = yaml.Marshal(v)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// printVirtualClusters prints vcs in format, as a single object if there is only one and asSingle
+// is set, or as a list.
+func printVirtualClusters(out io.Writer, vcs []tenancyv1alpha1.VirtualCluster, format string, asSingle bool) error {
+	vcList := &tenancyv1alpha1.VirtualClusterList{
+		TypeMeta: metav1.TypeMeta{APIVersion: tenancyv1alpha1.SchemeGroupVersion.String(), Kind: "List"},
+		Items:    vcs,
+	}
+	for i := range vcList.Items {
+		vcList.Items[i].SetGroupVersionKind(tenancyv1alpha1.SchemeGroupVersion.WithKind("VirtualCluster"))
+	}
+	if asSingle && len(vcList.Items) == 1 {
+		return printOutput(out, &vcList.Items[0], format)
+	}
+	return printOutput(out, vcList, format)
+}
+
+// printVirtualClusterTable prints vcs as a table, with their namespace if withNamespace is set.
+func printVirtualClusterTable(out io.Writer, vcs []tenancyv1alpha1.VirtualCluster, withNamespace bool) error {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	if withNamespace {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "NAME\tPHASE\tCLUSTERVERSION\tROOT NAMESPACE\tAGE")
+	for i := range vcs {
+		vc := &vcs[i]
+		if withNamespace {
+			fmt.Fprintf(w, "%s\t", vc.Namespace)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", vc.Name, valueOrNone(string(vc.Status.Phase)), vc.Spec.ClusterVersionName,
+			conversion.ToClusterKey(vc), translateTimestampSince(vc.CreationTimestamp))
+	}
+	return w.Flush()
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+func translateTimestampSince(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
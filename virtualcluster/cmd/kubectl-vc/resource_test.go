@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	tenancyv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned/fake"
+)
+
+func newVC(namespace, name string, labels map[string]string) *tenancyv1alpha1.VirtualCluster {
+	return &tenancyv1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	objects := []runtime.Object{
+		newVC("foo", "a", map[string]string{"team": "x"}),
+		newVC("foo", "b", nil),
+		newVC("bar", "c", map[string]string{"team": "x"}),
+	}
+
+	for _, tc := range []struct {
+		name        string
+		flags       VCFlags
+		args        []string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "names in the namespace",
+			flags:    VCFlags{namespace: "foo"},
+			args:     []string{"a", "b"},
+			expected: []string{"foo/a", "foo/b"},
+		},
+		{
+			name:     "namespaced names",
+			flags:    VCFlags{namespace: "foo", allNamespaces: true},
+			args:     []string{"bar/c"},
+			expected: []string{"bar/c"},
+		},
+		{
+			name:        "missing name",
+			flags:       VCFlags{namespace: "bar"},
+			args:        []string{"a"},
+			expectError: true,
+		},
+		{
+			name:     "all in the namespace",
+			flags:    VCFlags{namespace: "foo", all: true},
+			expected: []string{"foo/a", "foo/b"},
+		},
+		{
+			name:     "selector across all namespaces",
+			flags:    VCFlags{namespace: "foo", allNamespaces: true, selector: "team=x"},
+			expected: []string{"bar/c", "foo/a"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			vcs, err := tc.flags.Select(fake.NewSimpleClientset(objects...), tc.args)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", vcs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, vc := range vcs {
+				got = append(got, vc.Namespace+"/"+vc.Name)
+			}
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Errorf("expected %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+}
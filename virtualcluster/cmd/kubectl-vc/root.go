@@ -42,6 +42,12 @@ func main() {
 
 	rootCmd.AddCommand(NewCmdCreate(f))
 	rootCmd.AddCommand(NewCmdExec(f))
+	rootCmd.AddCommand(NewCmdList(f))
+	rootCmd.AddCommand(NewCmdGet(f))
+	rootCmd.AddCommand(NewCmdDescribe(f))
+	rootCmd.AddCommand(NewCmdDelete(f))
+	rootCmd.AddCommand(NewCmdKubeconfig(f))
+	rootCmd.AddCommand(NewCmdUpgrade(f))
 
 	CheckErr(rootCmd.Execute())
 }
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	tenancyv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+const (
+	upgradeExample = `
+	# Upgrade the control plane of a VirtualCluster to the current spec of its ClusterVersion
+	kubectl vc upgrade -n foo bar
+
+	# Request the upgrade of all the VirtualClusters in a namespace without waiting
+	kubectl vc upgrade -n foo --all --wait=false`
+
+	reasonUpgradeFailed = "TenantControlPlaneUpgradeFailed"
+)
+
+type UpgradeOptions struct {
+	VCFlags
+	vcclient vcclient.Interface
+	client   kubernetes.Interface
+	names    []string
+	wait     bool
+	timeout  time.Duration
+	out      io.Writer
+}
+
+func NewCmdUpgrade(f Factory) *cobra.Command {
+	o := &UpgradeOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:   "upgrade [VC_NAME...]",
+		Short: "Upgrade the control planes of VirtualClusters to their ClusterVersion",
+		Long: `Upgrade the control planes of VirtualClusters to the current spec of their ClusterVersion.
+
+The VirtualClusters are labeled as ready for upgrade, and the vc-manager applies the ClusterVersion
+to their control planes. The vc-manager must run with the ClusterVersionPartialUpgrade feature gate.`,
+		Example: upgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f, args))
+			CheckErr(o.Validate(cmd, args, true))
+			CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd, true)
+	cmd.Flags().BoolVar(&o.wait, "wait", true, "If true, wait for the upgrades to complete and the control planes to be ready")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 10*time.Minute, "The length of time to wait for the upgrades to complete")
+
+	return cmd
+}
+
+func (o *UpgradeOptions) Complete(f Factory, args []string) error {
+	var err error
+	o.vcclient, err = f.VirtualClusterClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.names = args
+	return nil
+}
+
+func (o *UpgradeOptions) Run() error {
+	vcs, err := o.Select(o.vcclient, o.names)
+	if err != nil {
+		return err
+	}
+
+	upgrading := make([]tenancyv1alpha1.VirtualCluster, 0, len(vcs))
+	for i := range vcs {
+		vc := &vcs[i]
+		if vc.Status.Phase != tenancyv1alpha1.ClusterRunning {
+			return fmt.Errorf("VirtualCluster %s/%s is %s, only running VirtualClusters can be upgraded", vc.Namespace, vc.Name, valueOrNone(string(vc.Status.Phase)))
+		}
+		cv, err := o.vcclient.TenancyV1alpha1().ClusterVersions().Get(vc.Spec.ClusterVersionName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("cluster version not found: %v", err)
+		}
+		if vc.Labels[constants.LabelClusterVersionApplied] == cv.ResourceVersion {
+			o.printf("VirtualCluster %s/%s is up to date with ClusterVersion %s\n", vc.Namespace, vc.Name, cv.Name)
+			continue
+		}
+
+		patch := fmt.Sprintf(`{"metadata":{"labels":{%q:"true"}}}`, constants.LabelVCReadyForUpgrade)
+		if _, err := o.vcclient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Patch(vc.Name, types.MergePatchType, []byte(patch)); err != nil {
+			return fmt.Errorf("fail to request the upgrade of VirtualCluster %s/%s: %v", vc.Namespace, vc.Name, err)
+		}
+		o.printf("VirtualCluster %s/%s upgrade to ClusterVersion %s requested\n", vc.Namespace, vc.Name, cv.Name)
+		upgrading = append(upgrading, *vc)
+	}
+
+	if o.wait {
+		ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+		defer cancel()
+		for i := range upgrading {
+			vc, err := o.waitForUpgrade(ctx, &upgrading[i])
+			if err != nil {
+				return err
+			}
+			upgrading[i] = *vc
+		}
+	}
+
+	if o.output != "" {
+		return printVirtualClusters(o.out, upgrading, o.output, len(o.names) == 1)
+	}
+	return nil
+}
+
+// waitForUpgrade waits for the vc-manager to upgrade vc, reporting the progress, then for the
+// control plane components to be ready.
+func (o *UpgradeOptions) waitForUpgrade(ctx context.Context, vc *tenancyv1alpha1.VirtualCluster) (*tenancyv1alpha1.VirtualCluster, error) {
+	current := vc
+	lastStatus := fmt.Sprintf("%s: %s", vc.Status.Reason, vc.Status.Message)
+	err := wait.PollImmediateUntil(pollVCPeriod, func() (bool, error) {
+		var err error
+		current, err = o.vcclient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Get(vc.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if current.UID != vc.UID {
+			return false, fmt.Errorf("VirtualCluster %s/%s was recreated", vc.Namespace, vc.Name)
+		}
+		if status := fmt.Sprintf("%s: %s", current.Status.Reason, current.Status.Message); status != lastStatus {
+			lastStatus = status
+			o.printf("VirtualCluster %s/%s %s\n", vc.Namespace, vc.Name, status)
+		}
+		// The vc-manager removes the label once the upgrade is done.
+		return current.Labels[constants.LabelVCReadyForUpgrade] != "true", nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return nil, fmt.Errorf("timed out waiting for VirtualCluster %s/%s to be upgraded, check that the vc-manager runs with the ClusterVersionPartialUpgrade feature gate", vc.Namespace, vc.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.Status.Reason == reasonUpgradeFailed {
+		return nil, fmt.Errorf("fail to upgrade VirtualCluster %s/%s: %s", vc.Namespace, vc.Name, current.Status.Message)
+	}
+
+	cv, err := o.vcclient.TenancyV1alpha1().ClusterVersions().Get(current.Spec.ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cluster version not found: %v", err)
+	}
+	var statuses []ComponentStatus
+	err = wait.PollImmediateUntil(pollVCPeriod, func() (bool, error) {
+		statuses = componentStatuses(o.client, conversion.ToClusterKey(current), cv)
+		for _, status := range statuses {
+			if !status.Ready {
+				return false, nil
+			}
+		}
+		return true, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return nil, fmt.Errorf("timed out waiting for the control plane of VirtualCluster %s/%s to be ready: %s", vc.Namespace, vc.Name, notReadyComponents(statuses))
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.printf("VirtualCluster %s/%s upgraded\n", vc.Namespace, vc.Name)
+	return current, nil
+}
+
+// printf prints the progress unless a structured output is requested.
+func (o *UpgradeOptions) printf(format string, args ...interface{}) {
+	if o.output == "" {
+		fmt.Fprintf(o.out, format, args...)
+	}
+}
+
+// notReadyComponents returns the components which are not ready and why.
+func notReadyComponents(statuses []ComponentStatus) string {
+	var s string
+	for _, status := range statuses {
+		if status.Ready {
+			continue
+		}
+		if s != "" {
+			s += ", "
+		}
+		if status.Error != "" {
+			s += fmt.Sprintf("%s (%s)", status.Name, status.Error)
+		} else {
+			s += fmt.Sprintf("%s (%d/%d ready)", status.Name, status.ReadyReplicas, status.Replicas)
+		}
+	}
+	return s
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"time"
 
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -39,6 +40,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/controller/constants"
 	logrutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/controller/util/logr"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version/verflag"
 )
@@ -59,6 +61,8 @@ func main() {
 		disableStacktrace                 bool
 		enableWebhook                     bool
 		provisionerTimeout                time.Duration
+		namingSpec                        naming.Spec
+		namingRegistry                    string
 
 		featureGates map[string]bool
 	)
@@ -83,6 +87,14 @@ func main() {
 	flag.BoolVar(&enableWebhook, "enable-webhook", false, "If set, the virtualcluster webhook is enabled")
 	flag.DurationVar(&provisionerTimeout, "provisioner-timeout", 10*time.Minute, "The timeout for provision control-plane statefulsets")
 
+	flag.StringVar(&namingSpec.Strategy, "naming-strategy", naming.StrategyLegacy,
+		"The strategy naming the super cluster namespaces of new virtualclusters [legacy, hash, shortid, template]")
+	flag.StringVar(&namingSpec.ClusterKeyTemplate, "naming-cluster-key-template", naming.DefaultClusterKeyTemplate,
+		"The Go template of the root namespace of the template naming strategy, given the Name, Namespace, UID and ShortID of the virtualcluster")
+	flag.StringVar(&namingSpec.NamespaceTemplate, "naming-namespace-template", naming.DefaultNamespaceTemplate,
+		"The Go template of the tenant namespaces of the template naming strategy, given the ClusterKey, Namespace and its Hash")
+	flag.StringVar(&namingRegistry, "naming-registry", naming.DefaultRegistryName,
+		"The namespace/name of the ConfigMap recording the naming strategy of each virtualcluster, empty to only use the legacy naming")
 	flag.Var(cliflag.NewMapStringBool(&featureGates), "feature-gates", "A set of key=value pairs that describe featuregate gates for various features.")
 
 	flag.Parse()
@@ -164,6 +176,22 @@ func main() {
 		controlPlaneProvisioner = controlPlaneProvisionerDeprecated
 	}
 
+	if _, err := naming.New(namingSpec); err != nil {
+		log.Error(err, "invalid naming strategy")
+		os.Exit(1)
+	}
+	if namingSpec.Strategy != naming.StrategyTemplate {
+		namingSpec.ClusterKeyTemplate, namingSpec.NamespaceTemplate = "", ""
+	}
+	var namingStore *naming.Store
+	if namingRegistry != "" {
+		namingStore, err = naming.NewStore(kubernetes.NewForConfigOrDie(cfg), namingRegistry)
+		if err != nil {
+			log.Error(err, "unable to set up naming registry")
+			os.Exit(1)
+		}
+	}
+
 	// Setup all Controllers
 	log.Info("Setting up controller")
 	if err := (&controller.Controllers{
@@ -172,6 +200,8 @@ func main() {
 		ProvisionerName:         controlPlaneProvisioner,
 		ProvisionerTimeout:      provisionerTimeout,
 		MaxConcurrentReconciles: maxConcurrentReconciles,
+		Naming:                  namingSpec,
+		NamingStore:             namingStore,
 	}).SetupWithManager(mgr); err != nil {
 		log.Error(err, "unable to register controllers to the manager")
 		os.Exit(1)
@@ -32,6 +32,8 @@ import (
 type Config struct {
 	// config is the syncer's configuration object.
 	ComponentConfig syncerconfig.SyncerConfiguration
+	// ConfigFile is the path of the file ComponentConfig is loaded from, if any.
+	ConfigFile string
 
 	// virtual cluster CR client
 	VirtualClusterClient   vcclient.Interface
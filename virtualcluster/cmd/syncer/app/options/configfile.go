@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	syncerconfigscheme "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/scheme"
+)
+
+// LoadConfigFile reads a versioned SyncerConfiguration from path and converts
+// it to the internal configuration with the defaults set.
+func LoadConfigFile(path string) (*syncerconfig.SyncerConfiguration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read syncer configuration file %q: %v", path, err)
+	}
+	return decodeConfig(data)
+}
+
+func decodeConfig(data []byte) (*syncerconfig.SyncerConfiguration, error) {
+	obj, gvk, err := syncerconfigscheme.Codecs.UniversalDecoder().Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode syncer configuration: %v", err)
+	}
+	c, ok := obj.(*syncerconfig.SyncerConfiguration)
+	if !ok {
+		return nil, fmt.Errorf("unexpected syncer configuration type %v", gvk)
+	}
+	return c, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/validation"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		data        string
+		expectError string
+		verify      func(*testing.T, *syncerconfig.SyncerConfiguration)
+	}{
+		{
+			name: "defaults match the flags",
+			data: `
+apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1
+kind: SyncerConfiguration
+`,
+			verify: func(t *testing.T, c *syncerconfig.SyncerConfiguration) {
+				o, _ := NewResourceSyncerOptions()
+				expected := o.ComponentConfig
+				if !c.LeaderElection.LeaderElect || c.LeaderElection.LeaseDuration != expected.LeaderElection.LeaseDuration ||
+					c.LeaderElection.ResourceLock != expected.LeaderElection.ResourceLock || c.LeaderElection.LockObjectName != expected.LeaderElection.LockObjectName {
+					t.Errorf("expected leader election defaults %+v, got %+v", expected.LeaderElection, c.LeaderElection)
+				}
+				if c.DisableServiceAccountToken != expected.DisableServiceAccountToken || c.VNAgentPort != expected.VNAgentPort ||
+					c.VNAgentNamespacedName != expected.VNAgentNamespacedName || c.VNAgentLabelSelector != expected.VNAgentLabelSelector ||
+					c.SuperClusterNodePortRange != expected.SuperClusterNodePortRange || c.TracingSamplingRatio != expected.TracingSamplingRatio ||
+					c.TenantMetricsMaxTenants != expected.TenantMetricsMaxTenants {
+					t.Errorf("expected defaults of the flags, got %+v", c)
+				}
+				if len(c.DefaultOpaqueMetaDomains) != 2 {
+					t.Errorf("expected default opaque meta domains, got %v", c.DefaultOpaqueMetaDomains)
+				}
+				if len(c.DNSOptions) != 1 || c.DNSOptions[0].Name != "ndots" || *c.DNSOptions[0].Value != "5" {
+					t.Errorf("expected default dns options, got %v", c.DNSOptions)
+				}
+				if errs := validation.ValidateSyncerConfiguration(c); len(errs) > 0 {
+					t.Errorf("expected defaults to be valid, got %v", errs)
+				}
+			},
+		},
+		{
+			name: "settings",
+			data: `
+apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1
+kind: SyncerConfiguration
+leaderElection:
+  leaderElect: false
+  leaseDuration: 30s
+disableServiceAccountToken: false
+vnAgentPort: 10551
+extraNodeLabels: ["topology.kubernetes.io/zone"]
+featureGates:
+  TenantAllowDNSPolicy: true
+dnsOptions: []
+`,
+			verify: func(t *testing.T, c *syncerconfig.SyncerConfiguration) {
+				if c.LeaderElection.LeaderElect || c.LeaderElection.LeaseDuration.Duration != 30*time.Second {
+					t.Errorf("expected leader election settings to be kept, got %+v", c.LeaderElection)
+				}
+				if c.DisableServiceAccountToken {
+					t.Errorf("expected disableServiceAccountToken to be kept")
+				}
+				if c.VNAgentPort != 10551 {
+					t.Errorf("expected vn agent port to be kept, got %d", c.VNAgentPort)
+				}
+				if len(c.ExtraNodeLabels) != 1 || !c.FeatureGates["TenantAllowDNSPolicy"] {
+					t.Errorf("expected settings to be kept, got %+v", c)
+				}
+				if c.DNSOptions == nil || len(c.DNSOptions) != 0 {
+					t.Errorf("expected empty dns options to be kept, got %v", c.DNSOptions)
+				}
+			},
+		},
+		{
+			name: "unknown field",
+			data: `
+apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1
+kind: SyncerConfiguration
+vnAgentPorts: 10551
+`,
+			expectError: "unknown field",
+		},
+		{
+			name: "unknown version",
+			data: `
+apiVersion: syncer.config.tenancy.x-k8s.io/v1
+kind: SyncerConfiguration
+`,
+			expectError: "no kind \"SyncerConfiguration\" is registered for version",
+		},
+		{
+			name: "invalid settings",
+			data: `
+apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1
+kind: SyncerConfiguration
+vnAgentPort: 0
+featureGates:
+  UnknownFeature: true
+`,
+			verify: func(t *testing.T, c *syncerconfig.SyncerConfiguration) {
+				errs := validation.ValidateSyncerConfiguration(c)
+				if len(errs) != 2 {
+					t.Errorf("expected invalid vn agent port and feature gate, got %v", errs)
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := decodeConfig([]byte(tc.data))
+			if tc.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectError) {
+					t.Errorf("expected error containing %q, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tc.verify(t, c)
+		})
+	}
+}
@@ -47,6 +47,7 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions"
 	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/validation"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 )
@@ -55,6 +56,9 @@ import (
 type ResourceSyncerOptions struct {
 	// The syncer configuration.
 	ComponentConfig syncerconfig.SyncerConfiguration
+	// ConfigFile is the path of the SyncerConfiguration file. It overrides
+	// ComponentConfig if it is set.
+	ConfigFile string
 
 	MetaClusterAddress string
 	// MetaClusterClientConnection specifies the kubeconfig file and client connection
@@ -95,6 +99,11 @@ func NewResourceSyncerOptions() (*ResourceSyncerOptions, error) {
 			VNAgentPort:                int32(10550),
 			VNAgentNamespacedName:      "vc-manager/vn-agent",
 			VNAgentLabelSelector:       "app=vn-agent",
+			SuperClusterNodePortRange:  "30000-32767",
+			TenantClusterNodePortRange: "30000-32767",
+			NamingRegistry:             "vc-manager/vc-naming-registry",
+			TracingSamplingRatio:       1,
+			TenantMetricsMaxTenants:    100,
 			FeatureGates: map[string]bool{
 				featuregate.SuperClusterPooling:        false,
 				featuregate.SuperClusterServiceNetwork: false,
@@ -116,6 +125,7 @@ func (o *ResourceSyncerOptions) Flags() cliflag.NamedFlagSets {
 	fss := cliflag.NamedFlagSets{}
 
 	fs := fss.FlagSet("server")
+	fs.StringVar(&o.ConfigFile, "config", o.ConfigFile, "Path to a SyncerConfiguration file, e.g. mounted from a ConfigMap. The flags of the settings in the file are ignored. Reloadable settings are applied when the file changes, changes of other settings are rejected until the syncer is restarted.")
 	fs.StringVar(&o.SuperClusterAddress, "super-master", o.SuperClusterAddress, "The address of the super cluster Kubernetes API server (overrides any value in super-master-kubeconfig).")
 	fs.StringVar(&o.ComponentConfig.ClientConnection.Kubeconfig, "super-master-kubeconfig", o.ComponentConfig.ClientConnection.Kubeconfig, "Path to kubeconfig file with authorization and control plane location information.")
 	fs.StringVar(&o.ComponentConfig.Timeout, "super-master-timeout", o.ComponentConfig.Timeout, "Timeout of the super cluster Kubernetes API server, Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'. (overrides any value in super-master-kubeconfig).")
@@ -126,21 +136,33 @@ func (o *ResourceSyncerOptions) Flags() cliflag.NamedFlagSets {
 	fs.BoolVar(&o.ComponentConfig.DisableServiceAccountToken, "disable-service-account-token", o.ComponentConfig.DisableServiceAccountToken, "DisableServiceAccountToken indicates whether to disable super cluster service account tokens being auto generated and mounted in vc pods.")
 	fs.BoolVar(&o.ComponentConfig.DisablePodServiceLinks, "disable-service-links", o.ComponentConfig.DisablePodServiceLinks, "DisablePodServiceLinks indicates whether to disable the `EnableServiceLinks` field in pPod spec.")
 	fs.StringSliceVar(&o.ComponentConfig.DefaultOpaqueMetaDomains, "default-opaque-meta-domains", o.ComponentConfig.DefaultOpaqueMetaDomains, "DefaultOpaqueMetaDomains is the default opaque meta configuration for each Virtual Cluster.")
-	fs.StringSliceVar(&o.ComponentConfig.ExtraSyncingResources, "extra-syncing-resources", o.ComponentConfig.ExtraSyncingResources, "ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster. (priorityclass, ingress, crd)")
+	fs.StringSliceVar(&o.ComponentConfig.ExtraSyncingResources, "extra-syncing-resources", o.ComponentConfig.ExtraSyncingResources, "ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster. (priorityclass, ingress, ingressclass, runtimeclass, crd, poddisruptionbudget)")
 	fs.Var(cliflag.NewMapStringBool(&o.ComponentConfig.FeatureGates), "feature-gates", "A set of key=value pairs that describe feature gates for various features."+
 		"Options are:\n"+strings.Join(featuregate.DefaultFeatureGate.KnownFeatures(), "\n"))
 	fs.StringSliceVar(&o.ComponentConfig.ExtraNodeLabels, "extra-node-labels", o.ComponentConfig.ExtraNodeLabels, "ExtraNodeLabels defines additional node labels that need to be synced for each Virtual Cluster")
 	fs.StringSliceVar(&o.ComponentConfig.OpaqueTaintKeys, "opaque-taint-keys", o.ComponentConfig.OpaqueTaintKeys, "OpaqueTaintKeys defines taint keys that need to be synced for each Virtual Cluster")
 	fs.Int32Var(&o.ComponentConfig.VNAgentPort, "vn-agent-port", 10550, "Port the vn-agent listens on")
 	fs.StringVar(&o.ComponentConfig.VNAgentNamespacedName, "vn-agent-namespace-name", "vc-manager/vn-agent", "Namespace/Name of the vn-agent running in cluster, used for VNodeProviderService")
+	fs.StringVar(&o.ComponentConfig.SuperClusterNodePortRange, "super-cluster-node-port-range", o.ComponentConfig.SuperClusterNodePortRange, "NodePort range of the super cluster, shared out among Virtual Clusters when tenant-node-port-range-size is set")
+	fs.Int32Var(&o.ComponentConfig.TenantNodePortRangeSize, "tenant-node-port-range-size", o.ComponentConfig.TenantNodePortRangeSize, "Number of super cluster NodePorts reserved for each Virtual Cluster. 0 lets the super cluster allocate NodePorts freely")
+	fs.StringVar(&o.ComponentConfig.TenantClusterNodePortRange, "tenant-cluster-node-port-range", o.ComponentConfig.TenantClusterNodePortRange, "NodePort range of the tenant apiservers. Only the tenant NodePorts outside of it are honored as requested when tenant-node-port-range-size is set")
+	fs.StringVar(&o.ComponentConfig.NamingRegistry, "naming-registry", o.ComponentConfig.NamingRegistry, "Namespace/Name of the naming registry ConfigMap in the super cluster, empty to name the namespaces of all Virtual Clusters with the legacy strategy")
 	fs.Var(cliflag.NewMapStringString(&o.DNSOptions), "dns-options", "DNSOptions is the default DNS options attached to each pod")
 	fs.StringVar(&o.ComponentConfig.VNAgentLabelSelector, "vn-agent-label-selector", "app=vn-agent", "Label key=value of the vn-agent running in cluster, used for VNodeProviderPodIP")
 
+	tracingFlags := fss.FlagSet("tracing")
+	tracingFlags.StringVar(&o.ComponentConfig.TracingEndpoint, "tracing-endpoint", o.ComponentConfig.TracingEndpoint, "host:port of the OTLP/HTTP collector to export traces to. Tracing is disabled if empty")
+	tracingFlags.BoolVar(&o.ComponentConfig.TracingInsecure, "tracing-insecure", o.ComponentConfig.TracingInsecure, "Export traces over plain HTTP instead of HTTPS")
+	tracingFlags.Float64Var(&o.ComponentConfig.TracingSamplingRatio, "tracing-sampling-ratio", o.ComponentConfig.TracingSamplingRatio, "Fraction of syncs traced, between 0 and 1")
+
 	serverFlags := fss.FlagSet("metricsServer")
 	serverFlags.StringVar(&o.Address, "address", o.Address, "The server address.")
 	serverFlags.StringVar(&o.Port, "port", o.Port, "The server port.")
 	serverFlags.StringVar(&o.CertFile, "cert-file", o.CertFile, "CertFile is the file containing x509 Certificate for HTTPS.")
 	serverFlags.StringVar(&o.KeyFile, "key-file", o.KeyFile, "KeyFile is the file containing x509 private key matching certFile.")
+	serverFlags.BoolVar(&o.ComponentConfig.TenantMetrics, "tenant-metrics", o.ComponentConfig.TenantMetrics, "Break the syncer metrics down by VirtualCluster in the vc_name label")
+	serverFlags.StringSliceVar(&o.ComponentConfig.TenantMetricsAllowList, "tenant-metrics-allow-list", o.ComponentConfig.TenantMetricsAllowList, "namespace/name of the VirtualClusters always given their own vc_name")
+	serverFlags.IntVar(&o.ComponentConfig.TenantMetricsMaxTenants, "tenant-metrics-max-tenants", o.ComponentConfig.TenantMetricsMaxTenants, "Number of VirtualClusters out of the allow list given their own vc_name, others are reported as \"other\"")
 
 	BindFlags(&o.ComponentConfig.LeaderElection, fss.FlagSet("leader election"))
 
@@ -176,7 +198,20 @@ func BindFlags(l *syncerconfig.SyncerLeaderElectionConfiguration, fs *pflag.Flag
 // Config return a syncer config object
 func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 	c := &syncerappconfig.Config{}
-	c.ComponentConfig = o.ComponentConfig
+	if o.ConfigFile != "" {
+		componentConfig, err := LoadConfigFile(o.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		c.ComponentConfig = *componentConfig
+		c.ConfigFile = o.ConfigFile
+	} else {
+		c.ComponentConfig = o.ComponentConfig
+		c.ComponentConfig.DNSOptions = dnsOptionsConvert(o.DNSOptions)
+	}
+	if errs := validation.ValidateSyncerConfiguration(&c.ComponentConfig); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
 
 	// Prepare kube clients
 	var (
@@ -184,12 +219,12 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 		leaderElectionRestConfig        restclient.Config
 		err                             error
 	)
-	superRestConfig, err = getClientConfig(c.ComponentConfig.ClientConnection, o.SuperClusterAddress, o.ComponentConfig.Timeout, !o.DeployOnMetaCluster)
+	superRestConfig, err = getClientConfig(c.ComponentConfig.ClientConnection, o.SuperClusterAddress, c.ComponentConfig.Timeout, !o.DeployOnMetaCluster)
 	if err != nil {
 		return nil, err
 	}
 	if o.DeployOnMetaCluster || o.MetaClusterClientConnection.Kubeconfig != "" {
-		metaRestConfig, err = getClientConfig(o.MetaClusterClientConnection, o.MetaClusterAddress, o.ComponentConfig.Timeout, o.DeployOnMetaCluster)
+		metaRestConfig, err = getClientConfig(o.MetaClusterClientConnection, o.MetaClusterAddress, c.ComponentConfig.Timeout, o.DeployOnMetaCluster)
 		if err != nil {
 			return nil, err
 		}
@@ -249,7 +284,6 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 		return nil, err
 	}
 	c.ComponentConfig.RestConfig = superRestConfig
-	c.ComponentConfig.DNSOptions = dnsOptionsConvert(o.DNSOptions)
 	c.VirtualClusterClient = virtualClusterClient
 	c.VirtualClusterInformer = vcinformers.NewSharedInformerFactory(virtualClusterClient, 0).Tenancy().V1alpha1().VirtualClusters()
 	c.MetaClusterClient = metaClusterClient
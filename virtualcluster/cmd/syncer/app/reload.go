@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/syncer/app/options"
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/validation"
+)
+
+// configReloadPeriod is how often the configuration file is checked for
+// changes. ConfigMap volumes are updated by the kubelet with a similar delay.
+const configReloadPeriod = 10 * time.Second
+
+// runConfigReloader applies the reloadable settings of the configuration file
+// to the running syncer whenever the file changes.
+func runConfigReloader(path string, current *syncerconfig.SyncerConfiguration, stopCh <-chan struct{}) {
+	loaded, err := ioutil.ReadFile(path)
+	if err != nil {
+		klog.Errorf("failed to read syncer configuration file %q: %v", path, err)
+	}
+	wait.Until(func() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			klog.Errorf("failed to read syncer configuration file %q: %v", path, err)
+			return
+		}
+		if bytes.Equal(data, loaded) {
+			return
+		}
+		loaded = data
+
+		newer, err := options.LoadConfigFile(path)
+		if err != nil {
+			klog.Errorf("failed to reload syncer configuration: %v", err)
+			return
+		}
+		if errs := validation.ValidateSyncerConfiguration(newer); len(errs) > 0 {
+			klog.Errorf("rejected invalid syncer configuration: %v", errs.ToAggregate())
+			return
+		}
+		if err := current.Reload(newer); err != nil {
+			klog.Errorf("rejected syncer configuration: %v", err)
+			return
+		}
+		klog.Infof("reloaded syncer configuration from %s", path)
+	}, configReloadPeriod, stopCh)
+}
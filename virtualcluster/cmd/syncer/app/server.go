@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/syncer/app/options"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer"
 	utilflag "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/flag"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/tracing"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version/verflag"
 )
 
@@ -94,6 +95,18 @@ custom resources on behalf of the tenant users in super cluster.`,
 }
 
 func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
+	shutdownTracing := tracing.Setup(tracing.Options{
+		ServiceName:   "vc-syncer",
+		Endpoint:      cc.ComponentConfig.TracingEndpoint,
+		Insecure:      cc.ComponentConfig.TracingInsecure,
+		SamplingRatio: cc.ComponentConfig.TracingSamplingRatio,
+	})
+	defer func() {
+		if err := shutdownTracing(context.TODO()); err != nil {
+			klog.Errorf("failed to flush traces: %v", err)
+		}
+	}()
+
 	ss, err := syncer.New(&cc.ComponentConfig,
 		cc.VirtualClusterClient,
 		cc.VirtualClusterInformer,
@@ -111,6 +124,10 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 		cc.Broadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: cc.SuperClusterClient.CoreV1().Events("")})
 	}
 
+	if cc.ConfigFile != "" {
+		go runConfigReloader(cc.ConfigFile, &cc.ComponentConfig, stopCh)
+	}
+
 	// Start all informers.
 	go cc.VirtualClusterInformer.Informer().Run(stopCh)
 	cc.SuperClusterInformerFactory.Start(stopCh)
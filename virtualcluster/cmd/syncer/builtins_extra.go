@@ -19,5 +19,8 @@ package main
 import (
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/crd"
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/ingress"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/ingressclass"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/poddisruptionbudget"
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/priorityclass"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/runtimeclass"
 )
@@ -17,7 +17,6 @@ limitations under the License.
 package options
 
 import (
-	"crypto/tls"
 	"fmt"
 	"os"
 
@@ -25,7 +24,10 @@ import (
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/clientca"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/dynamiccert"
 )
 
 // Options holds the config from command line.
@@ -62,6 +64,42 @@ type ServerOption struct {
 
 	// FeatureGates enabled by the user.
 	FeatureGates map[string]bool
+
+	// NamingRegistry is the namespace/name of the naming registry ConfigMap in the super cluster.
+	NamingRegistry string
+
+	// TenantCAConfigMap is the name of the ConfigMap publishing the CA of each virtual cluster in
+	// its root namespace. If set, the client certificate of each tenant must be signed by the CA of
+	// its virtual cluster.
+	TenantCAConfigMap string
+
+	// AuditLogPath is the file the audit records of the exec, attach and port-forward sessions are
+	// written to, empty to disable it.
+	AuditLogPath string
+	// AuditLogMaxSize is the size in megabytes the audit log file is rotated at, 0 to never rotate it.
+	AuditLogMaxSize int
+	// AuditLogMaxBackups is the number of rotated audit log files kept, 0 to keep them all.
+	AuditLogMaxBackups int
+	// AuditWebhookURL is the URL the audit records are posted to, empty to disable it.
+	AuditWebhookURL string
+	// SessionRecordingDir is the directory the streams of the exec and attach sessions are
+	// recorded in, empty to disable the recording.
+	SessionRecordingDir string
+	// SessionRecordingMaxSize is the size in megabytes a recording is cut at, 0 for no limit.
+	SessionRecordingMaxSize int
+	// SessionRecordingMaxFiles is the number of recordings kept for each tenant, 0 to keep them all.
+	SessionRecordingMaxFiles int
+
+	// TenantQPS is the requests per second of each tenant for each action, 0 for unlimited.
+	TenantQPS float64
+	// TenantActionQPS overrides TenantQPS for the actions it holds.
+	TenantActionQPS map[string]string
+	// TenantBurst is the requests of each tenant for each action allowed above TenantQPS.
+	TenantBurst int
+	// TenantMaxSessions is the concurrent streaming sessions of each tenant, 0 for unlimited.
+	TenantMaxSessions int
+	// TenantLogBytesPerSecond is the log bandwidth of each tenant, 0 for unlimited.
+	TenantLogBytesPerSecond int64
 }
 
 // KubeletClientConfig is a subset of the full options exposed in k8s.io/kubernetes/pkg/kubelet/client.KubeletClientConfig
@@ -79,7 +117,8 @@ func NewVnAgentOptions() (*Options, error) {
 	return &Options{
 		KubeletOption: KubeletClientConfig{},
 		ServerOption: ServerOption{
-			FeatureGates: map[string]bool{},
+			FeatureGates:    map[string]bool{},
+			TenantActionQPS: map[string]string{},
 		},
 	}, nil
 }
@@ -97,6 +136,20 @@ func (o *Options) Flags() cliflag.NamedFlagSets {
 	serverFS.UintVar(&o.Port, "port", 10550, "Port is the server listening on")
 	serverFS.StringVar(&o.MetricsAddr, "metrics-addr", ":9100", "Bind address for the metrics server.")
 	serverFS.BoolVar(&o.EnableMetrics, "enable-metrics", true, "Enable metrics server.")
+	serverFS.StringVar(&o.NamingRegistry, "naming-registry", naming.DefaultRegistryName, "The namespace/name of the naming registry ConfigMap in the super cluster, empty to only use the legacy namespace naming.")
+	serverFS.StringVar(&o.TenantCAConfigMap, "tenant-ca-configmap", o.TenantCAConfigMap, "The name of the ConfigMap publishing the CA of each virtual cluster in its root namespace, e.g. "+clientca.DefaultConfigMapName+". If set, the client certificate of each tenant must be signed by the CA of its virtual cluster.")
+	serverFS.StringVar(&o.AuditLogPath, "audit-log-path", o.AuditLogPath, "The file the audit records of the exec, attach and port-forward sessions are written to. Disabled if empty.")
+	serverFS.IntVar(&o.AuditLogMaxSize, "audit-log-maxsize", 100, "The size in megabytes the audit log file is rotated at, 0 to never rotate it.")
+	serverFS.IntVar(&o.AuditLogMaxBackups, "audit-log-maxbackup", 10, "The number of rotated audit log files to keep, 0 to keep them all.")
+	serverFS.StringVar(&o.AuditWebhookURL, "audit-webhook-url", o.AuditWebhookURL, "The URL the audit records of the exec, attach and port-forward sessions are posted to. Disabled if empty.")
+	serverFS.StringVar(&o.SessionRecordingDir, "session-recording-dir", o.SessionRecordingDir, "The directory the streams of the exec and attach sessions are recorded in. Disabled if empty.")
+	serverFS.IntVar(&o.SessionRecordingMaxSize, "session-recording-maxsize", 100, "The size in megabytes a recording is cut at, 0 for no limit.")
+	serverFS.IntVar(&o.SessionRecordingMaxFiles, "session-recording-maxfiles", 100, "The number of recordings kept for each tenant, the oldest are removed, 0 to keep them all.")
+	serverFS.Float64Var(&o.TenantQPS, "tenant-qps", o.TenantQPS, "The requests per second of each tenant for each action, 0 for unlimited.")
+	serverFS.Var(cliflag.NewMapStringString(&o.TenantActionQPS), "tenant-action-qps", "A set of action=qps pairs overriding --tenant-qps for the actions, e.g. exec=1,containerLogs=10.")
+	serverFS.IntVar(&o.TenantBurst, "tenant-burst", 10, "The requests of each tenant for each action allowed above the rate.")
+	serverFS.IntVar(&o.TenantMaxSessions, "tenant-max-sessions", o.TenantMaxSessions, "The concurrent exec, attach, port-forward and followed logs sessions of each tenant, 0 for unlimited.")
+	serverFS.Int64Var(&o.TenantLogBytesPerSecond, "tenant-log-bytes-per-second", o.TenantLogBytesPerSecond, "The bytes per second of the logs of each tenant, 0 for unlimited.")
 	serverFS.Var(cliflag.NewMapStringBool(&o.ServerOption.FeatureGates), "feature-gates", "A set of key=value pairs that describe featuregate gates for various features.")
 
 	kubeletFS := fss.FlagSet("kubelet")
@@ -121,7 +174,7 @@ func (o *Options) Config() (*config.Config, *ServerOption, error) {
 	if fileNotExistOrEmpty(o.KubeletOption.CertFile) || fileNotExistOrEmpty(o.KubeletOption.KeyFile) {
 		return &config.Config{KubeletClientCert: nil}, &o.ServerOption, nil
 	}
-	kubeletClientCertPair, err := tls.LoadX509KeyPair(o.KubeletOption.CertFile, o.KubeletOption.KeyFile)
+	kubeletClientKeyPair, err := dynamiccert.NewKeyPair("kubelet-client", o.KubeletOption.CertFile, o.KubeletOption.KeyFile)
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "failed to load kubelet tls config")
 	}
@@ -132,7 +185,8 @@ func (o *Options) Config() (*config.Config, *ServerOption, error) {
 	}
 
 	return &config.Config{
-		KubeletClientCert: &kubeletClientCertPair,
-		KubeletServerHost: fmt.Sprintf("https://127.0.0.1:%v", o.KubeletOption.Port),
+		KubeletClientCert:    kubeletClientKeyPair.Current(),
+		KubeletClientKeyPair: kubeletClientKeyPair,
+		KubeletServerHost:    fmt.Sprintf("https://127.0.0.1:%v", o.KubeletOption.Port),
 	}, &o.ServerOption, nil
 }
@@ -18,17 +18,19 @@ package app
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"k8s.io/apiserver/pkg/server/healthz"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/cli/globalflag"
 	"k8s.io/component-base/term"
@@ -36,9 +38,14 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/vn-agent/app/options"
 	utilflag "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/flag"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version/verflag"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/audit"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/certificate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/clientca"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/dynamiccert"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/ratelimit"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/server"
 )
 
@@ -93,34 +100,53 @@ func NewVnAgentCommand(stopChan <-chan struct{}) *cobra.Command {
 
 // Run start the vn-agent server.
 func Run(c *config.Config, serverOption *options.ServerOption, stopCh <-chan struct{}) error {
+	if serverOption.TenantCAConfigMap != "" {
+		client, err := superClusterClient(serverOption)
+		if err != nil {
+			return errors.Wrapf(err, "unable to build super cluster client")
+		}
+		tenantCAs := clientca.NewStore(client, serverOption.TenantCAConfigMap)
+		if err := tenantCAs.Start(stopCh); err != nil {
+			return errors.Wrapf(err, "unable to load tenant CA configmaps %s", serverOption.TenantCAConfigMap)
+		}
+		c.TenantCAs = tenantCAs
+	}
+
+	auditor, err := newAuditor(serverOption)
+	if err != nil {
+		return errors.Wrapf(err, "unable to set up auditing")
+	}
+	if auditor != nil {
+		defer auditor.Close()
+		c.Auditor = auditor
+	}
+
+	rateLimiter, err := newRateLimiter(serverOption)
+	if err != nil {
+		return errors.Wrapf(err, "unable to set up rate limiting")
+	}
+	c.RateLimiter = rateLimiter
+
 	handler, err := server.NewServer(c, serverOption)
 	if err != nil {
 		return errors.Wrapf(err, "create server")
 	}
 
-	s := &http.Server{
-		Addr:              fmt.Sprintf(":%d", serverOption.Port),
-		Handler:           handler,
-		ReadHeaderTimeout: time.Minute,
-		TLSConfig: &tls.Config{
-			ClientAuth: tls.RequestClientCert,
-			MinVersion: tls.VersionTLS12,
-		},
+	if err := startNamingRegistry(serverOption, stopCh); err != nil {
+		return errors.Wrapf(err, "unable to load naming registry %s", serverOption.NamingRegistry)
 	}
 
+	baseTLSConfig := &tls.Config{
+		ClientAuth: tls.RequestClientCert,
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
 	if serverOption.ClientCAFile != "" {
-		clientCAs, err := certutil.CertsFromFile(serverOption.ClientCAFile)
-		if err != nil {
-			return errors.Wrapf(err, "unable to load client CA file")
-		}
-
-		certPool := x509.NewCertPool()
-		for _, cert := range clientCAs {
-			certPool.AddCert(cert)
-		}
-
-		s.TLSConfig.ClientCAs = certPool
-		s.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		baseTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	} else if c.TenantCAs != nil {
+		// The client certificates are verified against the CA of each tenant
+		// by the server.
+		baseTLSConfig.ClientAuth = tls.RequireAnyClientCert
 	}
 
 	tlsConfig, err := certificate.InitializeTLS(serverOption.CertDirectory, serverOption.TLSCertFile, serverOption.TLSPrivateKeyFile, "vn")
@@ -128,11 +154,35 @@ func Run(c *config.Config, serverOption *options.ServerOption, stopCh <-chan str
 		return errors.Wrapf(err, "failed to initial tls config")
 	}
 
+	// The serving certificate, the client CA and the kubelet client
+	// certificate are reloaded when their files change, the new connections
+	// use the new certificates while the established ones are left alone.
+	serving, err := dynamiccert.NewServing(baseTLSConfig, tlsConfig.CertFile, tlsConfig.KeyFile, serverOption.ClientCAFile)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load serving certificate and client CA file")
+	}
+	go serving.Run(stopCh)
+	if c.KubeletClientKeyPair != nil {
+		go c.KubeletClientKeyPair.Run(stopCh)
+	}
+
+	s := &http.Server{
+		Addr:              fmt.Sprintf(":%d", serverOption.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: time.Minute,
+		TLSConfig:         serving.TLSConfig(),
+	}
+
 	klog.Infof("server listen on %s", s.Addr)
 
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", s.Addr)
+	}
+
 	errCh := make(chan error)
 	go func() {
-		err := s.ListenAndServeTLS(tlsConfig.CertFile, tlsConfig.KeyFile)
+		err := s.Serve(tls.NewListener(listener, s.TLSConfig))
 		errCh <- err
 	}()
 
@@ -167,3 +217,78 @@ func Run(c *config.Config, serverOption *options.ServerOption, stopCh <-chan str
 
 	return nil
 }
+
+// startNamingRegistry watches the naming registry of the super cluster, which maps the
+// tenant namespaces to the super cluster namespaces. It fails if the registry cannot be
+// read, as falling back to the legacy naming would serve the wrong namespaces.
+func startNamingRegistry(serverOption *options.ServerOption, stopCh <-chan struct{}) error {
+	if serverOption.NamingRegistry == "" {
+		return nil
+	}
+	client, err := superClusterClient(serverOption)
+	if err != nil {
+		return errors.Wrapf(err, "unable to build super cluster client")
+	}
+	store, err := naming.NewStore(client, serverOption.NamingRegistry)
+	if err != nil {
+		return err
+	}
+	return store.Start(naming.DefaultRegistry, stopCh)
+}
+
+// newAuditor returns the auditor of the sessions, or nil if neither an audit
+// sink nor the recording is enabled.
+func newAuditor(serverOption *options.ServerOption) (*audit.Auditor, error) {
+	var sinks []audit.Sink
+	if serverOption.AuditLogPath != "" {
+		sink, err := audit.NewFileSink(serverOption.AuditLogPath, int64(serverOption.AuditLogMaxSize)*1024*1024, serverOption.AuditLogMaxBackups)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open audit log %s", serverOption.AuditLogPath)
+		}
+		sinks = append(sinks, sink)
+	}
+	if serverOption.AuditWebhookURL != "" {
+		sinks = append(sinks, audit.NewWebhookSink(serverOption.AuditWebhookURL))
+	}
+	if len(sinks) == 0 && serverOption.SessionRecordingDir == "" {
+		return nil, nil
+	}
+	return audit.NewAuditor(audit.RecordingConfig{
+		Dir:      serverOption.SessionRecordingDir,
+		MaxSize:  int64(serverOption.SessionRecordingMaxSize) * 1024 * 1024,
+		MaxFiles: serverOption.SessionRecordingMaxFiles,
+	}, sinks...), nil
+}
+
+// newRateLimiter returns the rate limiter of the tenants, or nil if no limit
+// is set.
+func newRateLimiter(serverOption *options.ServerOption) (*ratelimit.Limiter, error) {
+	limits := ratelimit.Config{
+		QPS:               serverOption.TenantQPS,
+		ActionQPS:         map[string]float64{},
+		Burst:             serverOption.TenantBurst,
+		MaxSessions:       serverOption.TenantMaxSessions,
+		LogBytesPerSecond: serverOption.TenantLogBytesPerSecond,
+	}
+	for action, value := range serverOption.TenantActionQPS {
+		qps, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid qps of action %s", action)
+		}
+		limits.ActionQPS[action] = qps
+	}
+	if limits.QPS <= 0 && len(limits.ActionQPS) == 0 && limits.MaxSessions <= 0 && limits.LogBytesPerSecond <= 0 {
+		return nil, nil
+	}
+	return ratelimit.NewLimiter(limits), nil
+}
+
+// superClusterClient returns a client of the super cluster, built from the
+// kubeconfig or the in-cluster config.
+func superClusterClient(serverOption *options.ServerOption) (kubernetes.Interface, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", serverOption.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
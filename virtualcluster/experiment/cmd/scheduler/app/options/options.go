@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	clientgokubescheme "k8s.io/client-go/kubernetes/scheme"
@@ -55,6 +56,9 @@ type SchedulerOptions struct {
 
 	MetaCluster           string
 	MetaClusterKubeconfig string
+
+	// ProfilesConfigFile is the path of a yaml file with the list of scheduler profiles.
+	ProfilesConfigFile string
 }
 
 // NewSchedulerOptions creates new scheduler options with a default config.
@@ -72,6 +76,11 @@ func NewSchedulerOptions() (*SchedulerOptions, error) {
 				LockObjectName: "vc-scheduler-leaderelection-lock",
 			},
 			ClientConnection: componentbaseconfig.ClientConnectionConfiguration{},
+			Rebalance: schedulerconfig.RebalanceConfiguration{
+				ThresholdPercent:  20,
+				MaxMoves:          10,
+				MaxMovesPerTenant: 2,
+			},
 		},
 	}, nil
 }
@@ -82,6 +91,14 @@ func (o *SchedulerOptions) Flags() cliflag.NamedFlagSets {
 	fs := fss.FlagSet("server")
 	fs.StringVar(&o.MetaCluster, "meta-cluster", o.MetaCluster, "The address of the meta cluster Kubernetes APIServer (overrides any value in meta-cluster-kubeconfig).")
 	fs.StringVar(&o.ComponentConfig.ClientConnection.Kubeconfig, "meta-master-kubeconfig", o.ComponentConfig.ClientConnection.Kubeconfig, "Path to kubeconfig file with authorization and meta cluster location information.")
+	fs.StringVar(&o.ProfilesConfigFile, "profiles-config", o.ProfilesConfigFile, "Path to a yaml file with the list of scheduler profiles, each one enabling and weighting score plugins. The first profile is the default one.")
+
+	rs := fss.FlagSet("rebalance")
+	rs.DurationVar(&o.ComponentConfig.Rebalance.Interval.Duration, "rebalance-interval", o.ComponentConfig.Rebalance.Interval.Duration, "The interval between two rounds of moving namespace slices across super clusters. Zero disables the rebalancer.")
+	rs.BoolVar(&o.ComponentConfig.Rebalance.DryRun, "rebalance-dry-run", o.ComponentConfig.Rebalance.DryRun, "Only report the proposed slice moves at /debug/rebalance without applying them.")
+	rs.Int32Var(&o.ComponentConfig.Rebalance.ThresholdPercent, "rebalance-threshold", o.ComponentConfig.Rebalance.ThresholdPercent, "The utilization difference, in percentage points, between the most and the least utilized super clusters below which no slice is moved.")
+	rs.Int32Var(&o.ComponentConfig.Rebalance.MaxMoves, "rebalance-max-moves", o.ComponentConfig.Rebalance.MaxMoves, "The maximum number of slices moved in one rebalance round.")
+	rs.Int32Var(&o.ComponentConfig.Rebalance.MaxMovesPerTenant, "rebalance-max-moves-per-tenant", o.ComponentConfig.Rebalance.MaxMovesPerTenant, "The maximum number of slices of one virtual cluster moved in one rebalance round.")
 
 	BindFlags(&o.ComponentConfig.LeaderElection, fss.FlagSet("leader election"))
 
@@ -119,6 +136,14 @@ func (o *SchedulerOptions) Config() (*schedulerappconfig.Config, error) {
 	c := &schedulerappconfig.Config{}
 	c.ComponentConfig = o.ComponentConfig
 
+	if o.ProfilesConfigFile != "" {
+		profiles, err := loadProfiles(o.ProfilesConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		c.ComponentConfig.Profiles = profiles
+	}
+
 	// Prepare kube clients
 	leaderElectionClient, metaClusterClient, virtualClusterClient, superClusterClient, restConfig, err := createClients(c.ComponentConfig.ClientConnection, o.MetaCluster, c.ComponentConfig.LeaderElection.RenewDeadline.Duration)
 	if err != nil {
@@ -160,6 +185,21 @@ func (o *SchedulerOptions) Config() (*schedulerappconfig.Config, error) {
 	return c, nil
 }
 
+// loadProfiles reads the scheduler profiles from a yaml or json file.
+func loadProfiles(path string) ([]schedulerconfig.SchedulerProfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open profiles config %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var profiles []schedulerconfig.SchedulerProfile
+	if err := utilyaml.NewYAMLOrJSONDecoder(f, 4096).Decode(&profiles); err != nil {
+		return nil, fmt.Errorf("unable to decode profiles config %s: %v", path, err)
+	}
+	return profiles, nil
+}
+
 // makeLeaderElectionConfig builds a leader election configuration. It will
 // create a new resource lock associated with the configuration.
 func makeLeaderElectionConfig(config schedulerconfig.SchedulerLeaderElectionConfiguration, client clientset.Interface, recorder record.EventRecorder) (*leaderelection.LeaderElectionConfig, error) {
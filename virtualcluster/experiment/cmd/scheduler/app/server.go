@@ -18,6 +18,7 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -71,6 +72,7 @@ func NewSchedulerCommand(stopChan <-chan struct{}) *cobra.Command {
 	for _, f := range namedFlagSets.FlagSets {
 		fs.AddFlagSet(f)
 	}
+	cmd.AddCommand(NewSimulateCommand())
 	usageFmt := "Usage:\n  %s\n"
 	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
 	cmd.SetUsageFunc(func(cmd *cobra.Command) error {
@@ -149,6 +151,28 @@ func startScheduler(s *scheduler.Scheduler, stopCh <-chan struct{}) func(context
 			metrics.Register()
 			mux := http.NewServeMux()
 			mux.Handle("/metrics", promhttp.Handler())
+			mux.HandleFunc("/debug/rebalance", func(w http.ResponseWriter, r *http.Request) {
+				report := s.LastRebalanceReport()
+				if report == nil {
+					http.Error(w, "no rebalance round has run", http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(report)
+			})
+			mux.HandleFunc("/debug/simulate", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+					return
+				}
+				result, err := s.Simulate(r.URL.Query().Get("tenant"), r.Body)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(result)
+			})
 			address := net.JoinHostPort("", "80")
 			klog.Fatal(http.ListenAndServe(address, mux))
 		}()
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/engine"
+)
+
+// NewSimulateCommand creates the command asking a running scheduler where a namespace would be scheduled.
+func NewSimulateCommand() *cobra.Command {
+	var server, tenant, filename string
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Show the super clusters a namespace would be scheduled to",
+		Long: `Send a yaml file with a Namespace and its ResourceQuotas to a running scheduler
+and show the super clusters the namespace would be scheduled to. Nothing is created
+and the scheduler state is not changed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = os.Stdin
+			if filename != "-" {
+				f, err := os.Open(filename)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			result, err := simulate(server, tenant, in)
+			if err != nil {
+				return err
+			}
+			printSimulationResult(cmd.OutOrStdout(), result)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&server, "server", "http://localhost:80", "The address of the scheduler debug server.")
+	cmd.Flags().StringVar(&tenant, "tenant", "", "The cluster name of the virtual cluster whose placement constraints apply.")
+	cmd.Flags().StringVarP(&filename, "filename", "f", "-", "The yaml file with a Namespace and its ResourceQuotas, - reads from stdin.")
+	return cmd
+}
+
+func simulate(server, tenant string, manifests io.Reader) (*engine.SimulationResult, error) {
+	u, err := url.Parse(strings.TrimSuffix(server, "/") + "/debug/simulate")
+	if err != nil {
+		return nil, fmt.Errorf("invalid server address %s: %v", server, err)
+	}
+	if tenant != "" {
+		u.RawQuery = url.Values{"tenant": []string{tenant}}.Encode()
+	}
+	body, err := ioutil.ReadAll(manifests)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(u.String(), "application/yaml", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("simulation failed: %s", strings.TrimSpace(string(msg)))
+	}
+	result := &engine.SimulationResult{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to decode simulation result: %v", err)
+	}
+	return result, nil
+}
+
+func printSimulationResult(w io.Writer, result *engine.SimulationResult) {
+	fmt.Fprintf(w, "Namespace:\t%s\n", result.Namespace)
+	fmt.Fprintf(w, "Profile:\t%s\n", result.Profile)
+	if !result.Schedulable {
+		fmt.Fprintf(w, "Schedulable:\tfalse\nReason:\t\t%s\n", result.Reason)
+		return
+	}
+	fmt.Fprintf(w, "Schedulable:\ttrue\nPlacements:\n")
+	for _, cluster := range sortedKeys(result.Placements) {
+		fmt.Fprintf(w, "  %s\t%d slice(s)\n", cluster, result.Placements[cluster])
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
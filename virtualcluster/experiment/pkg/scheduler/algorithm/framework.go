@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algorithm
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	schedulerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/apis/config"
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+const (
+	// MaxClusterScore is the maximum score a ScorePlugin returns.
+	MaxClusterScore int64 = 100
+	// MinClusterScore is the minimum score a ScorePlugin returns.
+	MinClusterScore int64 = 0
+
+	// DefaultProfileName is the name of the profile used when a namespace
+	// does not select one.
+	DefaultProfileName = "default-scheduler"
+)
+
+// Unit is what is being placed in one scheduling attempt, a namespace slice or a pod.
+type Unit struct {
+	// Namespace is the key of the tenant namespace the unit belongs to.
+	Namespace string
+	// Request is the amount of resources the unit needs.
+	Request corev1.ResourceList
+	// Placed counts the slices of the same namespace that are already placed
+	// on each cluster.
+	Placed map[string]int
+	// ClusterSelector selects the clusters the unit can be placed on, nil
+	// selects all clusters.
+	ClusterSelector labels.Selector
+	// Tolerations are the cluster taints the unit tolerates.
+	Tolerations []corev1.Toleration
+}
+
+// FilterPlugin rules out the clusters that cannot host a unit.
+type FilterPlugin interface {
+	Name() string
+	Filter(unit *Unit, cluster string, usage *internalcache.ClusterUsage) error
+}
+
+// ScorePlugin ranks the clusters that passed the filters, a higher score is
+// preferred. Scores are in the range of [MinClusterScore, MaxClusterScore].
+type ScorePlugin interface {
+	Name() string
+	Score(unit *Unit, cluster string, usage *internalcache.ClusterUsage) int64
+}
+
+// ScorePluginFactory creates a ScorePlugin.
+type ScorePluginFactory func() ScorePlugin
+
+var scorePluginRegistry = map[string]ScorePluginFactory{
+	LeastAllocatedName:   func() ScorePlugin { return &leastAllocated{} },
+	MostAllocatedName:    func() ScorePlugin { return &mostAllocated{} },
+	BalancedResourceName: func() ScorePlugin { return &balancedResource{} },
+	SpreadName:           func() ScorePlugin { return &spread{} },
+}
+
+// RegisterScorePlugin makes a ScorePlugin available to the scheduler profiles.
+func RegisterScorePlugin(name string, factory ScorePluginFactory) {
+	scorePluginRegistry[name] = factory
+}
+
+type weightedScorePlugin struct {
+	ScorePlugin
+	weight int64
+}
+
+// Framework runs the filter and score plugins of a scheduler profile.
+type Framework struct {
+	name    string
+	filters []FilterPlugin
+	scorers []weightedScorePlugin
+}
+
+// NewFramework creates the Framework for the given profile.
+func NewFramework(profile schedulerconfig.SchedulerProfile) (*Framework, error) {
+	f := &Framework{
+		name:    profile.SchedulerName,
+		filters: []FilterPlugin{&resourceFit{}, &clusterSelector{}, &taintToleration{}},
+	}
+	for _, each := range profile.Scorers {
+		factory, ok := scorePluginRegistry[each.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown score plugin %s in profile %s", each.Name, profile.SchedulerName)
+		}
+		if each.Weight <= 0 {
+			return nil, fmt.Errorf("score plugin %s in profile %s must have a positive weight", each.Name, profile.SchedulerName)
+		}
+		f.scorers = append(f.scorers, weightedScorePlugin{ScorePlugin: factory(), weight: each.Weight})
+	}
+	return f, nil
+}
+
+// DefaultProfile returns the profile used when none is configured.
+func DefaultProfile() schedulerconfig.SchedulerProfile {
+	return schedulerconfig.SchedulerProfile{
+		SchedulerName: DefaultProfileName,
+		Scorers: []schedulerconfig.ScorerConfig{
+			{Name: LeastAllocatedName, Weight: 1},
+			{Name: SpreadName, Weight: 1},
+		},
+	}
+}
+
+// DefaultFramework returns the Framework of the default profile.
+func DefaultFramework() *Framework {
+	f, _ := NewFramework(DefaultProfile())
+	return f
+}
+
+// Name returns the name of the profile the Framework runs.
+func (f *Framework) Name() string {
+	return f.name
+}
+
+// AddFilterPlugin appends a FilterPlugin to the Framework.
+func (f *Framework) AddFilterPlugin(p FilterPlugin) {
+	f.filters = append(f.filters, p)
+}
+
+// RunFilterPlugins returns nil if the cluster passes all filters.
+func (f *Framework) RunFilterPlugins(unit *Unit, cluster string, usage *internalcache.ClusterUsage) error {
+	for _, p := range f.filters {
+		if err := p.Filter(unit, cluster, usage); err != nil {
+			return fmt.Errorf("%s: %v", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// RunCapacityFilterPlugins returns nil if the cluster passes all filters but
+// the placement constraints, i.e. the cluster selector and the taints. It checks
+// the clusters a unit is already placed on, which are kept when the constraints
+// change.
+func (f *Framework) RunCapacityFilterPlugins(unit *Unit, cluster string, usage *internalcache.ClusterUsage) error {
+	for _, p := range f.filters {
+		if isPlacementConstraint(p) {
+			continue
+		}
+		if err := p.Filter(unit, cluster, usage); err != nil {
+			return fmt.Errorf("%s: %v", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// RunScorePlugins returns the weighted score of the cluster.
+func (f *Framework) RunScorePlugins(unit *Unit, cluster string, usage *internalcache.ClusterUsage) int64 {
+	var score int64
+	for _, p := range f.scorers {
+		score += p.weight * p.Score(unit, cluster, usage)
+	}
+	return score
+}
+
+// Schedule filters and scores all clusters and returns the one with the
+// highest score together with the scores of all feasible clusters. Ties are
+// broken by the cluster name so that the placement is deterministic.
+func (f *Framework) Schedule(unit *Unit, clusters map[string]*internalcache.ClusterUsage) (string, map[string]int64, error) {
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		err    error
+		best   string
+		scores = make(map[string]int64)
+	)
+	for _, name := range names {
+		if ferr := f.RunFilterPlugins(unit, name, clusters[name]); ferr != nil {
+			err = ferr
+			continue
+		}
+		scores[name] = f.RunScorePlugins(unit, name, clusters[name])
+		if best == "" || scores[name] > scores[best] {
+			best = name
+		}
+	}
+	if best == "" {
+		if err == nil {
+			err = fmt.Errorf("no cluster is available")
+		}
+		// return the last error
+		return "", nil, err
+	}
+	return best, scores, nil
+}
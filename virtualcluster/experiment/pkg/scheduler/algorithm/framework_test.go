@@ -0,0 +1,286 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algorithm
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	schedulerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/apis/config"
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+func newSnapshot(t *testing.T, capacities map[string]corev1.ResourceList) *internalcache.NamespaceSchedSnapshot {
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	cache := internalcache.NewSchedulerCache(stop)
+	for name, capacity := range capacities {
+		if err := cache.AddCluster(internalcache.NewCluster(name, nil, capacity)); err != nil {
+			t.Fatalf("failed to add cluster %s: %v", name, err)
+		}
+	}
+	snapshot, err := cache.SnapshotForNamespaceSched()
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	return snapshot
+}
+
+func TestNewFramework(t *testing.T) {
+	testcases := map[string]struct {
+		profile schedulerconfig.SchedulerProfile
+		succeed bool
+	}{
+		"default profile": {
+			profile: DefaultProfile(),
+			succeed: true,
+		},
+		"unknown scorer": {
+			profile: schedulerconfig.SchedulerProfile{
+				SchedulerName: "test",
+				Scorers:       []schedulerconfig.ScorerConfig{{Name: "Unknown", Weight: 1}},
+			},
+			succeed: false,
+		},
+		"non positive weight": {
+			profile: schedulerconfig.SchedulerProfile{
+				SchedulerName: "test",
+				Scorers:       []schedulerconfig.ScorerConfig{{Name: MostAllocatedName, Weight: 0}},
+			},
+			succeed: false,
+		},
+	}
+	for k, tc := range testcases {
+		_, err := NewFramework(tc.profile)
+		if (err == nil) != tc.succeed {
+			t.Errorf("test %s: expect succeed %v, got error %v", k, tc.succeed, err)
+		}
+	}
+}
+
+func TestScheduleNamespaceSlices(t *testing.T) {
+	small := corev1.ResourceList{
+		"cpu":    resource.MustParse("4"),
+		"memory": resource.MustParse("4Gi"),
+	}
+	large := corev1.ResourceList{
+		"cpu":    resource.MustParse("8"),
+		"memory": resource.MustParse("8Gi"),
+	}
+	slice := corev1.ResourceList{
+		"cpu":    resource.MustParse("1"),
+		"memory": resource.MustParse("1Gi"),
+	}
+
+	testcases := map[string]struct {
+		scorers  []schedulerconfig.ScorerConfig
+		slices   int
+		expected map[string]int
+	}{
+		"least allocated prefers the emptier cluster": {
+			scorers:  []schedulerconfig.ScorerConfig{{Name: LeastAllocatedName, Weight: 1}},
+			slices:   1,
+			expected: map[string]int{"large": 1},
+		},
+		"most allocated packs the slices": {
+			scorers:  []schedulerconfig.ScorerConfig{{Name: MostAllocatedName, Weight: 1}},
+			slices:   4,
+			expected: map[string]int{"small": 4},
+		},
+		"spread distributes the slices": {
+			scorers:  []schedulerconfig.ScorerConfig{{Name: SpreadName, Weight: 1}},
+			slices:   4,
+			expected: map[string]int{"large": 2, "small": 2},
+		},
+		"no scorer is deterministic": {
+			slices:   2,
+			expected: map[string]int{"large": 2},
+		},
+		"overflow to the other cluster": {
+			scorers:  []schedulerconfig.ScorerConfig{{Name: MostAllocatedName, Weight: 1}},
+			slices:   6,
+			expected: map[string]int{"small": 4, "large": 2},
+		},
+	}
+
+	for k, tc := range testcases {
+		f, err := NewFramework(schedulerconfig.SchedulerProfile{SchedulerName: "test", Scorers: tc.scorers})
+		if err != nil {
+			t.Fatalf("test %s: unexpected error %v", k, err)
+		}
+		snapshot := newSnapshot(t, map[string]corev1.ResourceList{"small": small, "large": large})
+		slices := SliceInfoArray{}
+		slices.Repeat(tc.slices, "tenant/ns", slice, "", "")
+		result := make(map[string]int)
+		for _, each := range f.ScheduleNamespaceSlices(slices, snapshot) {
+			if each.Err != nil {
+				t.Fatalf("test %s: unexpected error %v", k, each.Err)
+			}
+			if each.Scores == nil {
+				t.Errorf("test %s: scores are not recorded", k)
+			}
+			result[each.Result]++
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("test %s: expect placement %v, got %v", k, tc.expected, result)
+		}
+	}
+}
+
+func TestBalancedResource(t *testing.T) {
+	snapshot := newSnapshot(t, map[string]corev1.ResourceList{
+		"balanced": {
+			"cpu":    resource.MustParse("4"),
+			"memory": resource.MustParse("4Gi"),
+		},
+		"skewed": {
+			"cpu":    resource.MustParse("2"),
+			"memory": resource.MustParse("16Gi"),
+		},
+	})
+	f, err := NewFramework(schedulerconfig.SchedulerProfile{
+		SchedulerName: "test",
+		Scorers:       []schedulerconfig.ScorerConfig{{Name: BalancedResourceName, Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	slice := &SliceInfo{
+		Namespace: "tenant/ns",
+		Request: corev1.ResourceList{
+			"cpu":    resource.MustParse("1"),
+			"memory": resource.MustParse("1Gi"),
+		},
+	}
+	result, err := f.ScheduleOneSlice(slice, snapshot, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result != "balanced" {
+		t.Errorf("expect cluster balanced, got %s with scores %v", result, slice.Scores)
+	}
+}
+
+func TestPlacementConstraints(t *testing.T) {
+	capacity := corev1.ResourceList{
+		"cpu":    resource.MustParse("10"),
+		"memory": resource.MustParse("20Gi"),
+	}
+	request := corev1.ResourceList{
+		"cpu":    resource.MustParse("1"),
+		"memory": resource.MustParse("2Gi"),
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := internalcache.NewSchedulerCache(stop)
+	east := internalcache.NewCluster("east", map[string]string{"region": "east"}, capacity)
+	west := internalcache.NewCluster("west", map[string]string{"region": "west"}, capacity)
+	gpu := internalcache.NewCluster("gpu", map[string]string{"region": "east"}, capacity)
+	gpu.SetTaints([]corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}})
+	for _, each := range []*internalcache.Cluster{east, west, gpu} {
+		if err := cache.AddCluster(each); err != nil {
+			t.Fatalf("failed to add cluster: %v", err)
+		}
+	}
+
+	testcases := map[string]struct {
+		selector    string
+		tolerations []corev1.Toleration
+		mandatory   string
+		expect      []string
+		succeed     bool
+	}{
+		"no constraints avoid the tainted cluster": {
+			expect:  []string{"east", "west"},
+			succeed: true,
+		},
+		"selector": {
+			selector: "region=west",
+			expect:   []string{"west"},
+			succeed:  true,
+		},
+		"selector and toleration": {
+			selector:    "region=east",
+			tolerations: []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+			expect:      []string{"east", "gpu"},
+			succeed:     true,
+		},
+		"no cluster matches": {
+			selector: "region=north",
+			succeed:  false,
+		},
+		"mandatory cluster violating the selector is kept": {
+			selector:  "region=east",
+			mandatory: "west",
+			expect:    []string{"west"},
+			succeed:   true,
+		},
+		"mandatory tainted cluster is kept": {
+			mandatory: "gpu",
+			expect:    []string{"gpu"},
+			succeed:   true,
+		},
+	}
+	for k, tc := range testcases {
+		snapshot, err := cache.SnapshotForNamespaceSched()
+		if err != nil {
+			t.Fatalf("failed to take snapshot: %v", err)
+		}
+		var selector labels.Selector
+		if tc.selector != "" {
+			if selector, err = labels.Parse(tc.selector); err != nil {
+				t.Fatalf("test %s: invalid selector: %v", k, err)
+			}
+		}
+		var slices SliceInfoArray
+		slices.Repeat(4, "tenant/ns", request, tc.mandatory, "")
+		for _, each := range slices {
+			each.ClusterSelector = selector
+			each.Tolerations = tc.tolerations
+		}
+		slices = DefaultFramework().ScheduleNamespaceSlices(slices, snapshot)
+
+		placed := make(map[string]struct{})
+		failed := false
+		for _, each := range slices {
+			if each.Err != nil {
+				failed = true
+				continue
+			}
+			placed[each.Result] = struct{}{}
+		}
+		if failed == tc.succeed {
+			t.Errorf("test %s: expect succeed %v, got %v", k, tc.succeed, !failed)
+			continue
+		}
+		if !tc.succeed {
+			continue
+		}
+		expect := make(map[string]struct{})
+		for _, each := range tc.expect {
+			expect[each] = struct{}{}
+		}
+		if !reflect.DeepEqual(placed, expect) {
+			t.Errorf("test %s: expect placed on %v, got %v", k, expect, placed)
+		}
+	}
+}
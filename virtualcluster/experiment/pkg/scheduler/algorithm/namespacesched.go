@@ -24,30 +24,52 @@ import (
 	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
 )
 
-// ScheduleNamespaceSlices applies ScheduleOneSlice for each slice
+// ScheduleNamespaceSlices applies ScheduleOneSlice for each slice using the default profile.
 func ScheduleNamespaceSlices(slices SliceInfoArray, snapshot *internalcache.NamespaceSchedSnapshot) SliceInfoArray {
+	return DefaultFramework().ScheduleNamespaceSlices(slices, snapshot)
+}
+
+// ScheduleOneSlice checks snapshot and returns cluster than that fits the slice using the default profile.
+func ScheduleOneSlice(slice *SliceInfo, snapshot *internalcache.NamespaceSchedSnapshot) (string, error) {
+	return DefaultFramework().ScheduleOneSlice(slice, snapshot, nil)
+}
+
+// ScheduleNamespaceSlices applies ScheduleOneSlice for each slice
+func (f *Framework) ScheduleNamespaceSlices(slices SliceInfoArray, snapshot *internalcache.NamespaceSchedSnapshot) SliceInfoArray {
+	placed := make(map[string]int)
 	for i, each := range slices {
-		ret, err := ScheduleOneSlice(each, snapshot)
+		ret, err := f.ScheduleOneSlice(each, snapshot, placed)
 		if err != nil {
 			slices[i].Err = err
 		} else {
 			slices[i].Result = ret
+			placed[ret]++
 			_ = snapshot.AddSlices([]*internalcache.Slice{internalcache.NewSlice(each.Namespace, each.Request, ret)})
 		}
 	}
 	return slices
 }
 
-// ScheduleOneSlice checks snapshot and returns cluster than that fits the slice
-func ScheduleOneSlice(slice *SliceInfo, snapshot *internalcache.NamespaceSchedSnapshot) (string, error) {
+// ScheduleOneSlice checks snapshot and returns cluster than that fits the slice,
+// placed counts the slices of the same namespace already placed on each cluster.
+func (f *Framework) ScheduleOneSlice(slice *SliceInfo, snapshot *internalcache.NamespaceSchedSnapshot, placed map[string]int) (string, error) {
 	var err error
+	unit := &Unit{
+		Namespace:       slice.Namespace,
+		Request:         slice.Request,
+		Placed:          placed,
+		ClusterSelector: slice.ClusterSelector,
+		Tolerations:     slice.Tolerations,
+	}
 	if slice.Mandatory != "" {
 		cluster, exists := snapshot.GetClusterUsageMap()[slice.Mandatory]
 		if !exists {
 			return "", fmt.Errorf("mandatory cluster %s cannot be found", slice.Mandatory)
 		}
 
-		if err = fitSlice(slice.Request, cluster); err != nil {
+		// the slice is already placed, it stays on the cluster when the placement
+		// constraints of the namespace change.
+		if err = f.RunCapacityFilterPlugins(unit, slice.Mandatory, cluster); err != nil {
 			return "", fmt.Errorf("mandatory request cannot be satisfied %v ", err)
 		}
 		return slice.Mandatory, nil
@@ -55,21 +77,19 @@ func ScheduleOneSlice(slice *SliceInfo, snapshot *internalcache.NamespaceSchedSn
 
 	if slice.Hint != "" {
 		cluster, exists := snapshot.GetClusterUsageMap()[slice.Hint]
-		if !exists {
-			if err = fitSlice(slice.Request, cluster); err == nil {
+		if exists {
+			if err = f.RunFilterPlugins(unit, slice.Hint, cluster); err == nil {
 				return slice.Hint, nil
 			}
 		}
 	}
 
-	// First fit
-	for n, cluster := range snapshot.GetClusterUsageMap() {
-		if err = fitSlice(slice.Request, cluster); err == nil {
-			return n, nil
-		}
+	result, scores, err := f.Schedule(unit, snapshot.GetClusterUsageMap())
+	if err != nil {
+		return "", err
 	}
-	// return the last error
-	return "", err
+	slice.Scores = scores
+	return result, nil
 }
 
 func fitSlice(request corev1.ResourceList, cluster *internalcache.ClusterUsage) error {
@@ -85,15 +105,20 @@ func fitSlice(request corev1.ResourceList, cluster *internalcache.ClusterUsage)
 	return nil
 }
 
-// SchedulePod checks snapshot and returns cluster name that fits the pod
+// SchedulePod checks snapshot and returns cluster name that fits the pod using the default profile.
 func SchedulePod(pod *internalcache.Pod, snapshot *internalcache.PodSchedSnapshot) (string, error) {
-	var err error
-	// First fit
-	for name, cluster := range snapshot.GetClusterUsageMap() {
-		if err := fitSlice(pod.GetRequest(), cluster); err == nil {
-			return name, nil
-		}
+	result, _, err := DefaultFramework().SchedulePod(pod, nil, snapshot)
+	return result, err
+}
+
+// SchedulePod checks snapshot and returns cluster name that fits the pod
+// together with the scores of the feasible clusters. The placement
+// constraints of the namespace, if not nil, apply to the pod.
+func (f *Framework) SchedulePod(pod *internalcache.Pod, namespace *internalcache.Namespace, snapshot *internalcache.PodSchedSnapshot) (string, map[string]int64, error) {
+	unit := &Unit{Namespace: pod.GetNamespaceKey(), Request: pod.GetRequest()}
+	if namespace != nil {
+		unit.ClusterSelector = namespace.GetClusterSelector()
+		unit.Tolerations = namespace.GetTolerations()
 	}
-	// return the last error
-	return "", err
+	return f.Schedule(unit, snapshot.GetClusterUsageMap())
 }
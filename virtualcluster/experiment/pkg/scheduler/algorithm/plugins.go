@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algorithm
+
+import (
+	"fmt"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+const (
+	// ResourceFitName is the name of the filter checking the cluster capacity.
+	ResourceFitName = "ResourceFit"
+	// ClusterSelectorName is the name of the filter matching the cluster labels.
+	ClusterSelectorName = "ClusterSelector"
+	// TaintTolerationName is the name of the filter matching the cluster taints.
+	TaintTolerationName = "TaintToleration"
+	// LeastAllocatedName is the name of the scorer favoring the least allocated clusters.
+	LeastAllocatedName = "LeastAllocated"
+	// MostAllocatedName is the name of the scorer favoring the most allocated clusters (bin-packing).
+	MostAllocatedName = "MostAllocated"
+	// BalancedResourceName is the name of the scorer favoring clusters with balanced resource usage.
+	BalancedResourceName = "BalancedResource"
+	// SpreadName is the name of the scorer spreading the slices of a namespace across clusters.
+	SpreadName = "Spread"
+)
+
+// resourceFit rejects the clusters whose capacity cannot hold the request.
+type resourceFit struct{}
+
+func (p *resourceFit) Name() string {
+	return ResourceFitName
+}
+
+func (p *resourceFit) Filter(unit *Unit, _ string, usage *internalcache.ClusterUsage) error {
+	return fitSlice(unit.Request, usage)
+}
+
+// clusterSelector rejects the clusters whose labels do not match the selector of the unit.
+type clusterSelector struct{}
+
+func (p *clusterSelector) Name() string {
+	return ClusterSelectorName
+}
+
+func (p *clusterSelector) Filter(unit *Unit, _ string, usage *internalcache.ClusterUsage) error {
+	if unit.ClusterSelector == nil || unit.ClusterSelector.Matches(labels.Set(usage.GetLabels())) {
+		return nil
+	}
+	return fmt.Errorf("cluster labels %v do not match selector %s", usage.GetLabels(), unit.ClusterSelector)
+}
+
+// taintToleration rejects the clusters that have a NoSchedule or NoExecute
+// taint the unit does not tolerate. PreferNoSchedule taints are ignored.
+type taintToleration struct{}
+
+func (p *taintToleration) Name() string {
+	return TaintTolerationName
+}
+
+func (p *taintToleration) Filter(unit *Unit, _ string, usage *internalcache.ClusterUsage) error {
+	taints := usage.GetTaints()
+	for i := range taints {
+		if taints[i].Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !tolerates(unit.Tolerations, &taints[i]) {
+			return fmt.Errorf("cluster has taint %s that is not tolerated", taints[i].ToString())
+		}
+	}
+	return nil
+}
+
+// isPlacementConstraint tells whether p checks the placement constraints of
+// the namespace rather than the capacity of the cluster.
+func isPlacementConstraint(p FilterPlugin) bool {
+	switch p.(type) {
+	case *clusterSelector, *taintToleration:
+		return true
+	}
+	return false
+}
+
+func tolerates(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// allocatedFractions returns, for each resource of the cluster capacity, the
+// fraction that would be allocated once the request is placed.
+func allocatedFractions(unit *Unit, usage *internalcache.ClusterUsage) []float64 {
+	used := usage.GetMaxAlloc()
+	var fractions []float64
+	for res, capacity := range usage.GetCapacity() {
+		if capacity.IsZero() {
+			continue
+		}
+		allocAfter := used[res].DeepCopy()
+		allocAfter.Add(unit.Request[res])
+		fraction := float64(allocAfter.MilliValue()) / float64(capacity.MilliValue())
+		fractions = append(fractions, math.Min(fraction, 1))
+	}
+	return fractions
+}
+
+func average(in []float64) float64 {
+	if len(in) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, each := range in {
+		sum += each
+	}
+	return sum / float64(len(in))
+}
+
+// leastAllocated favors the clusters with the most free capacity.
+type leastAllocated struct{}
+
+func (p *leastAllocated) Name() string {
+	return LeastAllocatedName
+}
+
+func (p *leastAllocated) Score(unit *Unit, _ string, usage *internalcache.ClusterUsage) int64 {
+	return int64((1 - average(allocatedFractions(unit, usage))) * float64(MaxClusterScore))
+}
+
+// mostAllocated favors the clusters with the least free capacity, packing the
+// slices in as few clusters as possible.
+type mostAllocated struct{}
+
+func (p *mostAllocated) Name() string {
+	return MostAllocatedName
+}
+
+func (p *mostAllocated) Score(unit *Unit, _ string, usage *internalcache.ClusterUsage) int64 {
+	return int64(average(allocatedFractions(unit, usage)) * float64(MaxClusterScore))
+}
+
+// balancedResource favors the clusters whose resources are allocated evenly,
+// so that no resource is exhausted while the others are left over.
+type balancedResource struct{}
+
+func (p *balancedResource) Name() string {
+	return BalancedResourceName
+}
+
+func (p *balancedResource) Score(unit *Unit, _ string, usage *internalcache.ClusterUsage) int64 {
+	fractions := allocatedFractions(unit, usage)
+	if len(fractions) == 0 {
+		return MaxClusterScore
+	}
+	min, max := fractions[0], fractions[0]
+	for _, each := range fractions[1:] {
+		min = math.Min(min, each)
+		max = math.Max(max, each)
+	}
+	return int64((1 - (max - min)) * float64(MaxClusterScore))
+}
+
+// spread favors the clusters that host fewer slices of the same namespace.
+type spread struct{}
+
+func (p *spread) Name() string {
+	return SpreadName
+}
+
+func (p *spread) Score(unit *Unit, cluster string, _ *internalcache.ClusterUsage) int64 {
+	return MaxClusterScore / int64(1+unit.Placed[cluster])
+}
@@ -18,6 +18,7 @@ package algorithm
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // SliceInfo is the input to the algorithm.
@@ -27,7 +28,11 @@ type SliceInfo struct {
 	Mandatory string // if not empty, it is the cluster that the slice should go if all checks are passed
 	Hint      string // if not empty, it is the preferred cluster
 
-	Result string // scheduled cluster name
+	ClusterSelector labels.Selector     // if not nil, the labels of the cluster have to match
+	Tolerations     []corev1.Toleration // the cluster taints the slice tolerates
+
+	Result string           // scheduled cluster name
+	Scores map[string]int64 // scores of the feasible clusters, empty if the slice is placed by Mandatory or Hint
 	Err    error
 }
 
@@ -35,6 +35,52 @@ type SchedulerConfiguration struct {
 
 	// Super control plane rest config
 	RestConfig *rest.Config
+
+	// Profiles are the scheduling profiles the scheduler supports. Namespaces
+	// select a profile by its SchedulerName, the first one is used by default.
+	Profiles []SchedulerProfile
+
+	// Rebalance configures the loop moving namespace slices across super clusters.
+	Rebalance RebalanceConfiguration
+}
+
+// RebalanceConfiguration configures the rebalancer that moves the scheduled namespace
+// slices to reduce the utilization difference between super clusters.
+type RebalanceConfiguration struct {
+	// Interval between two rebalance rounds, zero disables the rebalancer.
+	Interval metav1.Duration
+
+	// DryRun only reports the proposed moves without applying them.
+	DryRun bool
+
+	// ThresholdPercent is the utilization difference, in percentage points, between the
+	// most and the least utilized super clusters below which no slice is moved.
+	ThresholdPercent int32
+
+	// MaxMoves is the maximum number of slices moved in one round.
+	MaxMoves int32
+
+	// MaxMovesPerTenant is the maximum number of slices of one tenant moved in one round.
+	MaxMovesPerTenant int32
+}
+
+// SchedulerProfile configures the plugins used to place the namespaces and
+// pods that select it.
+type SchedulerProfile struct {
+	// SchedulerName is the name of the profile.
+	SchedulerName string `json:"schedulerName"`
+
+	// Scorers lists the enabled score plugins and their weights.
+	Scorers []ScorerConfig `json:"scorers,omitempty"`
+}
+
+// ScorerConfig enables a score plugin in a profile.
+type ScorerConfig struct {
+	// Name of the score plugin.
+	Name string `json:"name"`
+
+	// Weight multiplies the score of the plugin, it must be positive.
+	Weight int64 `json:"weight"`
 }
 
 // SchedulerLeaderElectionConfiguration expands LeaderElectionConfiguration
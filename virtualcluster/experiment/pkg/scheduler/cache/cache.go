@@ -422,6 +422,21 @@ func (c *schedulerCache) UpdateClusterCapacity(clustername string, newCapacity c
 	return nil
 }
 
+// UpdateClusterConstraints updates the labels and taints the scheduler matches namespaces against.
+func (c *schedulerCache) UpdateClusterConstraints(clustername string, labels map[string]string, taints []corev1.Taint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clusterState, ok := c.clusters[clustername]
+	if !ok {
+		return fmt.Errorf("cluster %s is not in cache, cannot update the cluster constraints", clustername)
+	}
+	clusterState.labels = copyLabels(labels)
+	clusterState.SetTaints(taints)
+	clusterState.lastUpdateTime = metav1.Now()
+	return nil
+}
+
 func (c *schedulerCache) Dump() string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
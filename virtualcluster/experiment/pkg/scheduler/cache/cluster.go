@@ -28,6 +28,7 @@ import (
 type Cluster struct {
 	name     string
 	labels   map[string]string
+	taints   []corev1.Taint
 	capacity corev1.ResourceList
 	shadow   bool // a shadow cluster has a fake capacity, hence is not involved in scheduling
 
@@ -73,6 +74,7 @@ func (c *Cluster) DeepCopy() *Cluster {
 	}
 
 	out := NewCluster(c.name, labelcopy, c.capacity.DeepCopy())
+	out.SetTaints(c.taints)
 
 	allocItemsCopy := make(map[string][]*Slice)
 	for k, v := range c.allocItems {
@@ -107,6 +109,41 @@ func (c *Cluster) DeepCopy() *Cluster {
 	return out
 }
 
+func (c *Cluster) GetLabels() map[string]string {
+	return c.labels
+}
+
+func (c *Cluster) GetTaints() []corev1.Taint {
+	return c.taints
+}
+
+// SetTaints sets a copy of the taints that keep the namespaces without matching tolerations off the cluster.
+func (c *Cluster) SetTaints(taints []corev1.Taint) {
+	c.taints = copyTaints(taints)
+}
+
+func copyTaints(taints []corev1.Taint) []corev1.Taint {
+	if taints == nil {
+		return nil
+	}
+	out := make([]corev1.Taint, 0, len(taints))
+	for i := range taints {
+		out = append(out, *taints[i].DeepCopy())
+	}
+	return out
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
 func (c *Cluster) addItem(key string, items map[string][]*Slice, alloc corev1.ResourceList, slices []*Slice) (corev1.ResourceList, error) {
 	if _, ok := items[key]; ok {
 		return nil, fmt.Errorf("key %s is already in cluster %s, cannot add twice", key, c.name)
@@ -216,6 +253,7 @@ func (c *Cluster) Dump() string {
 	o := map[string]interface{}{
 		"Name":           c.name,
 		"Labels":         c.labels,
+		"Taints":         c.taints,
 		"Capacity":       c.capacity,
 		"Shadow":         c.shadow,
 		"Alloc":          c.alloc,
@@ -35,7 +35,9 @@ type Cache interface {
 	AddProvision(string, string, []*Slice) error
 	RemoveProvision(string, string) error
 	UpdateClusterCapacity(string, corev1.ResourceList) error
+	UpdateClusterConstraints(string, map[string]string, []corev1.Taint) error
 	SnapshotForNamespaceSched(...*Namespace) (*NamespaceSchedSnapshot, error)
 	SnapshotForPodSched(pod *Pod) (*PodSchedSnapshot, error)
+	SnapshotForRebalance() (*RebalanceSnapshot, error)
 	Dump() string
 }
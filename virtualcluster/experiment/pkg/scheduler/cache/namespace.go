@@ -22,6 +22,7 @@ import (
 	"math"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func Equals(a corev1.ResourceList, b corev1.ResourceList) bool {
@@ -83,6 +84,12 @@ type Namespace struct {
 	quotaSlice corev1.ResourceList
 
 	schedule []*Placement
+
+	// clusterSelector and tolerations restrict the super clusters the namespace can be placed on
+	clusterSelector labels.Selector
+	tolerations     []corev1.Toleration
+
+	scores map[string]int64 // cluster scores of the last scheduling decision
 }
 
 type Slice struct {
@@ -136,13 +143,65 @@ func (n *Namespace) DeepCopy() *Namespace {
 	for k, v := range n.labels {
 		labelCopy[k] = v
 	}
-	return NewNamespace(n.owner, n.name, labelCopy, n.quota.DeepCopy(), n.quotaSlice.DeepCopy(), schedCopy)
+	out := NewNamespace(n.owner, n.name, labelCopy, n.quota.DeepCopy(), n.quotaSlice.DeepCopy(), schedCopy)
+	out.SetPlacementConstraints(n.clusterSelector, n.tolerations)
+	if n.scores != nil {
+		out.scores = make(map[string]int64, len(n.scores))
+		for k, v := range n.scores {
+			out.scores[k] = v
+		}
+	}
+	return out
 }
 
 func (n *Namespace) GetKey() string {
 	return fmt.Sprintf("%s/%s", n.owner, n.name)
 }
 
+// GetOwner returns the tenant cluster name of the namespace.
+func (n *Namespace) GetOwner() string {
+	return n.owner
+}
+
+// GetName returns the name of the namespace in the tenant cluster.
+func (n *Namespace) GetName() string {
+	return n.name
+}
+
+func (n *Namespace) GetLabels() map[string]string {
+	return n.labels
+}
+
+// GetClusterSelector returns the selector the super cluster labels have to match, nil means any cluster.
+func (n *Namespace) GetClusterSelector() labels.Selector {
+	return n.clusterSelector
+}
+
+// GetTolerations returns the super cluster taints the namespace tolerates.
+func (n *Namespace) GetTolerations() []corev1.Toleration {
+	return n.tolerations
+}
+
+// SetPlacementConstraints sets a copy of the cluster selector and the tolerations of the namespace.
+func (n *Namespace) SetPlacementConstraints(selector labels.Selector, tolerations []corev1.Toleration) {
+	n.clusterSelector = nil
+	if selector != nil {
+		n.clusterSelector = selector.DeepCopySelector()
+	}
+	n.tolerations = nil
+	for i := range tolerations {
+		n.tolerations = append(n.tolerations, *tolerations[i].DeepCopy())
+	}
+}
+
+func (n *Namespace) GetScores() map[string]int64 {
+	return n.scores
+}
+
+func (n *Namespace) SetScores(scores map[string]int64) {
+	n.scores = scores
+}
+
 func (n *Namespace) GetPlacementMap() map[string]int {
 	m := make(map[string]int)
 	for _, each := range n.schedule {
@@ -161,6 +220,10 @@ func (n *Namespace) Comparable(in *Namespace) bool {
 	return Equals(n.quotaSlice, in.GetQuotaSlice())
 }
 
+func (n *Namespace) GetQuota() corev1.ResourceList {
+	return n.quota
+}
+
 func (n *Namespace) GetQuotaSlice() corev1.ResourceList {
 	return n.quotaSlice
 }
@@ -210,6 +273,13 @@ func (n *Namespace) Dump() string {
 		"Quota":      n.quota,
 		"QuotaSlice": n.quotaSlice,
 		"Schedule":   n.schedule,
+		"Scores":     n.scores,
+	}
+	if n.clusterSelector != nil {
+		o["ClusterSelector"] = n.clusterSelector.String()
+	}
+	if n.tolerations != nil {
+		o["Tolerations"] = n.tolerations
 	}
 
 	b, err := json.MarshalIndent(o, "", "\t")
@@ -34,11 +34,21 @@ func MaxAlloc(a corev1.ResourceList, b corev1.ResourceList) corev1.ResourceList
 }
 
 type ClusterUsage struct {
+	labels    map[string]string
+	taints    []corev1.Taint
 	capacity  corev1.ResourceList
 	alloc     corev1.ResourceList
 	provision corev1.ResourceList
 }
 
+func (u *ClusterUsage) GetLabels() map[string]string {
+	return u.labels
+}
+
+func (u *ClusterUsage) GetTaints() []corev1.Taint {
+	return u.taints
+}
+
 func (u *ClusterUsage) GetCapacity() corev1.ResourceList {
 	return u.capacity
 }
@@ -97,13 +107,18 @@ func NewNamespaceSchedSnapshot() *NamespaceSchedSnapshot {
 func (c *schedulerCache) SnapshotForNamespaceSched(nsToRemove ...*Namespace) (*NamespaceSchedSnapshot, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	return c.snapshotForNamespaceSchedWithoutLock(nsToRemove...)
+}
 
+func (c *schedulerCache) snapshotForNamespaceSchedWithoutLock(nsToRemove ...*Namespace) (*NamespaceSchedSnapshot, error) {
 	s := NewNamespaceSchedSnapshot()
 	for n, cluster := range c.clusters {
 		if cluster.shadow {
 			continue
 		}
 		s.clusterUsageMap[n] = &ClusterUsage{
+			labels:    copyLabels(cluster.labels),
+			taints:    copyTaints(cluster.taints),
 			capacity:  cluster.capacity.DeepCopy(),
 			alloc:     cluster.alloc.DeepCopy(),
 			provision: cluster.provision.DeepCopy(),
@@ -187,10 +202,15 @@ func (c *schedulerCache) SnapshotForPodSched(pod *Pod) (*PodSchedSnapshot, error
 			val2.Set(0)
 			alloc[k] = val2
 		}
-		s.clusterUsageMap[place.cluster] = &ClusterUsage{
+		usage := &ClusterUsage{
 			capacity: capability,
 			alloc:    alloc,
 		}
+		if cluster, ok := c.clusters[place.cluster]; ok {
+			usage.labels = copyLabels(cluster.labels)
+			usage.taints = copyTaints(cluster.taints)
+		}
+		s.clusterUsageMap[place.cluster] = usage
 	}
 
 	// accumulate allocation for each pod
@@ -225,3 +245,55 @@ func (c *schedulerCache) SnapshotForPodSched(pod *Pod) (*PodSchedSnapshot, error
 
 	return s, nil
 }
+
+// RebalanceSnapshot is the state the rebalancer evaluates: the usage of the
+// clusters, the scheduled namespaces and the resources their pods use in each cluster.
+type RebalanceSnapshot struct {
+	*NamespaceSchedSnapshot
+	namespaces []*Namespace
+	podUsage   map[string]map[string]corev1.ResourceList // ns key -> cluster -> pod requests
+}
+
+func (s *RebalanceSnapshot) GetNamespaces() []*Namespace {
+	return s.namespaces
+}
+
+// GetPodUsage returns the resources requested by the pods of the namespace in the cluster.
+func (s *RebalanceSnapshot) GetPodUsage(key, cluster string) corev1.ResourceList {
+	return s.podUsage[key][cluster]
+}
+
+func (c *schedulerCache) SnapshotForRebalance() (*RebalanceSnapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nsSnapshot, err := c.snapshotForNamespaceSchedWithoutLock()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &RebalanceSnapshot{
+		NamespaceSchedSnapshot: nsSnapshot,
+		podUsage:               make(map[string]map[string]corev1.ResourceList),
+	}
+	for _, ns := range c.namespaces {
+		s.namespaces = append(s.namespaces, ns.DeepCopy())
+	}
+	for _, pod := range c.pods {
+		key := pod.GetNamespaceKey()
+		if _, ok := s.podUsage[key]; !ok {
+			s.podUsage[key] = make(map[string]corev1.ResourceList)
+		}
+		usage, ok := s.podUsage[key][pod.cluster]
+		if !ok {
+			usage = corev1.ResourceList{}
+		}
+		for k, v := range pod.request {
+			val := usage[k].DeepCopy()
+			val.Add(v)
+			usage[k] = val
+		}
+		s.podUsage[key][pod.cluster] = usage
+	}
+	return s, nil
+}
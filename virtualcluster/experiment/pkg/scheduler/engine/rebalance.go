@@ -0,0 +1,316 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/algorithm"
+	schedulerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/apis/config"
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+// Move moves slices of a namespace from one super cluster to another.
+type Move struct {
+	Namespace string `json:"namespace"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Slices    int    `json:"slices"`
+}
+
+// RebalanceReport is the result of a rebalance round.
+type RebalanceReport struct {
+	Time   metav1.Time `json:"time"`
+	DryRun bool        `json:"dryRun"`
+	// ImbalanceBefore and ImbalanceAfter are the utilization differences between the
+	// most and the least utilized super clusters before and after the moves.
+	ImbalanceBefore float64 `json:"imbalanceBefore"`
+	ImbalanceAfter  float64 `json:"imbalanceAfter"`
+	Moves           []Move  `json:"moves"`
+	// Placements are the placements of the namespaces after the moves.
+	Placements map[string]map[string]int `json:"placements,omitempty"`
+	// PlacementsBefore are the placements of the namespaces the moves were planned from.
+	PlacementsBefore map[string]map[string]int `json:"placementsBefore,omitempty"`
+}
+
+// utilization returns the highest allocated fraction among the resources of the cluster.
+func utilization(usage *internalcache.ClusterUsage) float64 {
+	used := usage.GetMaxAlloc()
+	var max float64
+	for res, capacity := range usage.GetCapacity() {
+		if capacity.IsZero() {
+			continue
+		}
+		alloc := used[res]
+		if fraction := float64(alloc.MilliValue()) / float64(capacity.MilliValue()); fraction > max {
+			max = fraction
+		}
+	}
+	return max
+}
+
+// mostAndLeastUtilized returns the most and the least utilized clusters.
+func mostAndLeastUtilized(clusters map[string]*internalcache.ClusterUsage) (string, string) {
+	var most, least string
+	for _, name := range sortedClusterNames(clusters) {
+		u := utilization(clusters[name])
+		if most == "" || u > utilization(clusters[most]) {
+			most = name
+		}
+		if least == "" || u < utilization(clusters[least]) {
+			least = name
+		}
+	}
+	return most, least
+}
+
+func imbalance(clusters map[string]*internalcache.ClusterUsage) float64 {
+	most, least := mostAndLeastUtilized(clusters)
+	if most == "" {
+		return 0
+	}
+	return utilization(clusters[most]) - utilization(clusters[least])
+}
+
+func sortedClusterNames(clusters map[string]*internalcache.ClusterUsage) []string {
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedPlacementClusters(placements map[string]int) []string {
+	names := make([]string, 0, len(placements))
+	for name := range placements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fitsIn returns true if the request fits in num slices.
+func fitsIn(request, slice corev1.ResourceList, num int) bool {
+	for k, v := range request {
+		if v.IsZero() {
+			continue
+		}
+		size, ok := slice[k]
+		if !ok {
+			return false
+		}
+		if v.MilliValue() > size.MilliValue()*int64(num) {
+			return false
+		}
+	}
+	return true
+}
+
+type rebalancer struct {
+	engine   *schedulerEngine
+	config   schedulerconfig.RebalanceConfiguration
+	snapshot *internalcache.RebalanceSnapshot
+
+	placements map[string]map[string]int
+	budget     map[string]int // tenant -> slices that can still be moved in this round
+	moves      map[Move]int   // Move without Slices -> number of slices
+	order      []Move
+	total      int
+}
+
+func (r *rebalancer) canMove(ns *internalcache.Namespace) bool {
+	if int32(r.total) >= r.config.MaxMoves {
+		return false
+	}
+	tenant := ns.GetOwner()
+	if _, ok := r.budget[tenant]; !ok {
+		r.budget[tenant] = int(r.config.MaxMovesPerTenant)
+	}
+	return r.budget[tenant] > 0
+}
+
+// movable returns true if a slice can leave the cluster without evicting the pods of the namespace.
+func (r *rebalancer) movable(ns *internalcache.Namespace, cluster string) bool {
+	if _, exists := r.snapshot.GetClusterUsageMap()[cluster]; !exists {
+		// the pods are gone with the cluster
+		return true
+	}
+	placed := r.placements[ns.GetKey()][cluster]
+	return fitsIn(r.snapshot.GetPodUsage(ns.GetKey(), cluster), ns.GetQuotaSlice(), placed-1)
+}
+
+func (r *rebalancer) move(ns *internalcache.Namespace, from, to string) {
+	slice := ns.GetQuotaSlice()
+	_ = r.snapshot.RemoveSlices([]*internalcache.Slice{internalcache.NewSlice(ns.GetKey(), slice, from)})
+	_ = r.snapshot.AddSlices([]*internalcache.Slice{internalcache.NewSlice(ns.GetKey(), slice, to)})
+
+	placement := r.placements[ns.GetKey()]
+	placement[from]--
+	if placement[from] == 0 {
+		delete(placement, from)
+	}
+	placement[to]++
+
+	key := Move{Namespace: ns.GetKey(), From: from, To: to}
+	if _, ok := r.moves[key]; !ok {
+		r.order = append(r.order, key)
+	}
+	r.moves[key]++
+	r.budget[ns.GetOwner()]--
+	r.total++
+}
+
+func (r *rebalancer) unit(ns *internalcache.Namespace) *algorithm.Unit {
+	return &algorithm.Unit{
+		Namespace:       ns.GetKey(),
+		Request:         ns.GetQuotaSlice(),
+		Placed:          r.placements[ns.GetKey()],
+		ClusterSelector: ns.GetClusterSelector(),
+		Tolerations:     ns.GetTolerations(),
+	}
+}
+
+// evacuate moves the slices placed on clusters that are no longer available.
+func (r *rebalancer) evacuate(namespaces []*internalcache.Namespace) {
+	clusters := r.snapshot.GetClusterUsageMap()
+	for _, ns := range namespaces {
+		framework, err := r.engine.frameworkFor(ns)
+		if err != nil {
+			continue
+		}
+		for _, from := range sortedPlacementClusters(r.placements[ns.GetKey()]) {
+			if _, exists := clusters[from]; exists {
+				continue
+			}
+			for r.placements[ns.GetKey()][from] > 0 && r.canMove(ns) {
+				to, _, err := framework.Schedule(r.unit(ns), clusters)
+				if err != nil {
+					klog.V(4).Infof("cannot evacuate namespace %s from cluster %s: %v", ns.GetKey(), from, err)
+					break
+				}
+				r.move(ns, from, to)
+			}
+		}
+	}
+}
+
+// balance moves slices from the most utilized cluster as long as it reduces the imbalance.
+func (r *rebalancer) balance(namespaces []*internalcache.Namespace) {
+	clusters := r.snapshot.GetClusterUsageMap()
+	threshold := float64(r.config.ThresholdPercent) / 100
+	for {
+		most, least := mostAndLeastUtilized(clusters)
+		if most == "" {
+			return
+		}
+		before := utilization(clusters[most])
+		if before-utilization(clusters[least]) <= threshold && before <= 1 {
+			return
+		}
+		if !r.balanceOnce(namespaces, most, before) {
+			return
+		}
+	}
+}
+
+// balanceOnce moves one slice out of the cluster, it returns false if no slice can be moved.
+func (r *rebalancer) balanceOnce(namespaces []*internalcache.Namespace, from string, before float64) bool {
+	clusters := r.snapshot.GetClusterUsageMap()
+	for _, ns := range namespaces {
+		if r.placements[ns.GetKey()][from] == 0 || !r.canMove(ns) || !r.movable(ns, from) {
+			continue
+		}
+		framework, err := r.engine.frameworkFor(ns)
+		if err != nil {
+			continue
+		}
+		unit := r.unit(ns)
+		var to string
+		var after float64
+		for _, name := range sortedClusterNames(clusters) {
+			if name == from || framework.RunFilterPlugins(unit, name, clusters[name]) != nil {
+				continue
+			}
+			_ = r.snapshot.AddSlices([]*internalcache.Slice{internalcache.NewSlice(ns.GetKey(), ns.GetQuotaSlice(), name)})
+			u := utilization(clusters[name])
+			_ = r.snapshot.RemoveSlices([]*internalcache.Slice{internalcache.NewSlice(ns.GetKey(), ns.GetQuotaSlice(), name)})
+			if to == "" || u < after {
+				to, after = name, u
+			}
+		}
+		// the move has to make the target cluster less utilized than the source cluster was
+		if to != "" && after < before {
+			r.move(ns, from, to)
+			return true
+		}
+	}
+	return false
+}
+
+func (e *schedulerEngine) PlanRebalance(config schedulerconfig.RebalanceConfiguration) (*RebalanceReport, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	snapshot, err := e.cache.SnapshotForRebalance()
+	if err != nil {
+		return nil, err
+	}
+	r := &rebalancer{
+		engine:     e,
+		config:     config,
+		snapshot:   snapshot,
+		placements: make(map[string]map[string]int),
+		budget:     make(map[string]int),
+		moves:      make(map[Move]int),
+	}
+
+	namespaces := snapshot.GetNamespaces()
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].GetKey() < namespaces[j].GetKey()
+	})
+	before := make(map[string]map[string]int)
+	for _, ns := range namespaces {
+		r.placements[ns.GetKey()] = ns.GetPlacementMap()
+		before[ns.GetKey()] = ns.GetPlacementMap()
+	}
+
+	report := &RebalanceReport{
+		Time:            metav1.Now(),
+		DryRun:          config.DryRun,
+		ImbalanceBefore: imbalance(snapshot.GetClusterUsageMap()),
+	}
+	r.evacuate(namespaces)
+	r.balance(namespaces)
+	report.ImbalanceAfter = imbalance(snapshot.GetClusterUsageMap())
+
+	for _, each := range r.order {
+		each.Slices = r.moves[each]
+		report.Moves = append(report.Moves, each)
+		if report.Placements == nil {
+			report.Placements = make(map[string]map[string]int)
+			report.PlacementsBefore = make(map[string]map[string]int)
+		}
+		report.Placements[each.Namespace] = r.placements[each.Namespace]
+		report.PlacementsBefore[each.Namespace] = before[each.Namespace]
+	}
+	return report, nil
+}
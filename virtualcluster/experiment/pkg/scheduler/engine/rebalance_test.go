@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	schedulerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/apis/config"
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+func TestPlanRebalance(t *testing.T) {
+	capacity := corev1.ResourceList{
+		"cpu":    resource.MustParse("8"),
+		"memory": resource.MustParse("16Gi"),
+	}
+	slice := corev1.ResourceList{
+		"cpu":    resource.MustParse("1"),
+		"memory": resource.MustParse("2Gi"),
+	}
+	quota := corev1.ResourceList{
+		"cpu":    resource.MustParse("4"),
+		"memory": resource.MustParse("8Gi"),
+	}
+	podRequest := corev1.ResourceList{
+		"cpu":    resource.MustParse("3"),
+		"memory": resource.MustParse("1Gi"),
+	}
+	config := schedulerconfig.RebalanceConfiguration{
+		ThresholdPercent:  20,
+		MaxMoves:          10,
+		MaxMovesPerTenant: 10,
+	}
+
+	testcases := map[string]struct {
+		clusters   []string
+		placements map[string]int
+		withPods   bool
+		config     func(schedulerconfig.RebalanceConfiguration) schedulerconfig.RebalanceConfiguration
+		expect     map[string]int
+	}{
+		"balanced": {
+			clusters:   []string{"a", "b"},
+			placements: map[string]int{"a": 2, "b": 2},
+			expect:     nil,
+		},
+		"move to the new cluster": {
+			clusters:   []string{"a", "b"},
+			placements: map[string]int{"a": 4},
+			expect:     map[string]int{"a": 2, "b": 2},
+		},
+		"tenant budget": {
+			clusters:   []string{"a", "b"},
+			placements: map[string]int{"a": 4},
+			config: func(c schedulerconfig.RebalanceConfiguration) schedulerconfig.RebalanceConfiguration {
+				c.MaxMovesPerTenant = 1
+				return c
+			},
+			expect: map[string]int{"a": 3, "b": 1},
+		},
+		"slices used by pods are not moved": {
+			clusters:   []string{"a", "b"},
+			placements: map[string]int{"a": 4},
+			withPods:   true,
+			expect:     map[string]int{"a": 3, "b": 1},
+		},
+		"evacuate the removed cluster": {
+			clusters:   []string{"a", "b"},
+			placements: map[string]int{"a": 2, "c": 2},
+			expect:     map[string]int{"a": 2, "b": 2},
+		},
+	}
+	for k, tc := range testcases {
+		stop := make(chan struct{})
+		cache := internalcache.NewSchedulerCache(stop)
+		for _, name := range tc.clusters {
+			if err := cache.AddCluster(internalcache.NewCluster(name, nil, capacity)); err != nil {
+				t.Fatalf("test %s: failed to add cluster: %v", k, err)
+			}
+		}
+		cache.AddTenant("tenant")
+		var schedule []*internalcache.Placement
+		for cluster, num := range tc.placements {
+			schedule = append(schedule, internalcache.NewPlacement(cluster, num))
+		}
+		if err := cache.AddNamespace(internalcache.NewNamespace("tenant", "ns", nil, quota, slice, schedule)); err != nil {
+			t.Fatalf("test %s: failed to add namespace: %v", k, err)
+		}
+		if tc.withPods {
+			if err := cache.AddPod(internalcache.NewPod("tenant", "ns", "pod", "a", podRequest)); err != nil {
+				t.Fatalf("test %s: failed to add pod: %v", k, err)
+			}
+		}
+
+		c := config
+		if tc.config != nil {
+			c = tc.config(c)
+		}
+		report, err := NewSchedulerEngine(cache).PlanRebalance(c)
+		close(stop)
+		if err != nil {
+			t.Errorf("test %s: unexpected error %v", k, err)
+			continue
+		}
+		if !reflect.DeepEqual(report.Placements["tenant/ns"], tc.expect) {
+			t.Errorf("test %s: expect placements %v, got %v, moves %v", k, tc.expect, report.Placements["tenant/ns"], report.Moves)
+		}
+		if tc.expect != nil && !reflect.DeepEqual(report.PlacementsBefore["tenant/ns"], tc.placements) {
+			t.Errorf("test %s: expect the moves to be planned from %v, got %v", k, tc.placements, report.PlacementsBefore["tenant/ns"])
+		}
+		if tc.expect != nil && report.ImbalanceAfter > report.ImbalanceBefore {
+			t.Errorf("test %s: imbalance increases from %v to %v", k, report.ImbalanceBefore, report.ImbalanceAfter)
+		}
+	}
+}
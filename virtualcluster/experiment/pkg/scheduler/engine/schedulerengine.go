@@ -23,8 +23,10 @@ import (
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/algorithm"
+	schedulerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/apis/config"
 	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/util"
+	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 )
 
 // Engine is an interface for scheduler handler
@@ -34,6 +36,8 @@ type Engine interface {
 	DeScheduleNamespace(key string) error
 	SchedulePod(pod *internalcache.Pod) (*internalcache.Pod, error)
 	DeSchedulePod(key string) error
+	PlanRebalance(schedulerconfig.RebalanceConfiguration) (*RebalanceReport, error)
+	Simulate(*internalcache.Namespace) (*SimulationResult, error)
 }
 
 var _ Engine = &schedulerEngine{}
@@ -42,11 +46,54 @@ type schedulerEngine struct {
 	mu sync.RWMutex
 
 	cache internalcache.Cache
+
+	frameworks       map[string]*algorithm.Framework
+	defaultFramework *algorithm.Framework
 }
 
-// NewSchedulerEngine creates new instance of Engine with cache
+// NewSchedulerEngine creates new instance of Engine with cache, using the default profile
 func NewSchedulerEngine(schedulerCache internalcache.Cache) Engine {
-	return &schedulerEngine{cache: schedulerCache}
+	e, _ := NewSchedulerEngineWithProfiles(schedulerCache, nil)
+	return e
+}
+
+// NewSchedulerEngineWithProfiles creates new instance of Engine with cache. Namespaces select
+// one of the profiles with the scheduler profile label, the first profile is used by default.
+func NewSchedulerEngineWithProfiles(schedulerCache internalcache.Cache, profiles []schedulerconfig.SchedulerProfile) (Engine, error) {
+	if len(profiles) == 0 {
+		profiles = []schedulerconfig.SchedulerProfile{algorithm.DefaultProfile()}
+	}
+	e := &schedulerEngine{
+		cache:      schedulerCache,
+		frameworks: make(map[string]*algorithm.Framework),
+	}
+	for _, profile := range profiles {
+		if _, exists := e.frameworks[profile.SchedulerName]; exists {
+			return nil, fmt.Errorf("duplicate scheduler profile %s", profile.SchedulerName)
+		}
+		f, err := algorithm.NewFramework(profile)
+		if err != nil {
+			return nil, err
+		}
+		e.frameworks[profile.SchedulerName] = f
+		if e.defaultFramework == nil {
+			e.defaultFramework = f
+		}
+	}
+	return e, nil
+}
+
+// frameworkFor returns the framework of the profile selected by the namespace.
+func (e *schedulerEngine) frameworkFor(namespace *internalcache.Namespace) (*algorithm.Framework, error) {
+	name, ok := namespace.GetLabels()[utilconst.LabelSchedulerProfile]
+	if !ok {
+		return e.defaultFramework, nil
+	}
+	f, ok := e.frameworks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheduler profile %s", name)
+	}
+	return f, nil
 }
 
 // GetSlicesToSchedule retrieve all slices and return unscheduled
@@ -83,9 +130,26 @@ func GetSlicesToSchedule(namespace *internalcache.Namespace, oldPlacements map[s
 		remainingToSchedule -= hinted
 	}
 	slicesToSchedule.Repeat(remainingToSchedule, key, size, "", "")
+
+	// the placement constraints of the namespace apply to the hinted and the
+	// regular slices, the mandatory ones keep their clusters.
+	for _, each := range slicesToSchedule {
+		each.ClusterSelector = namespace.GetClusterSelector()
+		each.Tolerations = namespace.GetTolerations()
+	}
 	return slicesToSchedule
 }
 
+// GetLastScores returns the cluster scores of the last slice placed by scoring.
+func GetLastScores(slices algorithm.SliceInfoArray) map[string]int64 {
+	for i := len(slices) - 1; i >= 0; i-- {
+		if slices[i].Scores != nil {
+			return slices[i].Scores
+		}
+	}
+	return nil
+}
+
 // GetNewPlacement finds the placement for slices
 func GetNewPlacement(slices algorithm.SliceInfoArray) (map[string]int, error) {
 	newPlacement := make(map[string]int)
@@ -118,21 +182,26 @@ func (e *schedulerEngine) ScheduleNamespace(namespace *internalcache.Namespace)
 		oldPlacements = curState.GetPlacementMap()
 	}
 
+	framework, err := e.frameworkFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	var newPlacement map[string]int
 	var snapshot *internalcache.NamespaceSchedSnapshot
-	var err error
 	slicesToSchedule := GetSlicesToSchedule(namespace, oldPlacements)
 	snapshot, err = e.cache.SnapshotForNamespaceSched(curState)
 	if err != nil {
 		return nil, err
 	}
-	slicesToSchedule = algorithm.ScheduleNamespaceSlices(slicesToSchedule, snapshot)
+	slicesToSchedule = framework.ScheduleNamespaceSlices(slicesToSchedule, snapshot)
 	newPlacement, err = GetNewPlacement(slicesToSchedule)
 	if err != nil {
 		return nil, err
 	}
 	ret := namespace.DeepCopy()
 	ret.SetNewPlacements(newPlacement)
+	ret.SetScores(GetLastScores(slicesToSchedule))
 
 	// update the cache
 	if curState != nil {
@@ -176,12 +245,17 @@ func (e *schedulerEngine) SchedulePod(pod *internalcache.Pod) (*internalcache.Po
 		return nil, fmt.Errorf("namespace %s has not been schduled", nsKey)
 	}
 
+	framework, err := e.frameworkFor(ns)
+	if err != nil {
+		return nil, err
+	}
+
 	snapshot, err := e.cache.SnapshotForPodSched(pod)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := algorithm.SchedulePod(pod, snapshot)
+	result, _, err := framework.SchedulePod(pod, ns, snapshot)
 	if err != nil {
 		return nil, err
 	}
@@ -22,6 +22,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/algorithm"
 	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
@@ -188,3 +189,68 @@ func TestGetSlicesToSchedule(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleNamespaceConstraintsChange(t *testing.T) {
+	capacity := corev1.ResourceList{
+		"cpu":    resource.MustParse("10"),
+		"memory": resource.MustParse("10Gi"),
+	}
+	slice := corev1.ResourceList{
+		"cpu":    resource.MustParse("1"),
+		"memory": resource.MustParse("1Gi"),
+	}
+	quota := func(cpu string) corev1.ResourceList {
+		return corev1.ResourceList{
+			"cpu":    resource.MustParse(cpu),
+			"memory": resource.MustParse(cpu + "Gi"),
+		}
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := internalcache.NewSchedulerCache(stop)
+	for _, region := range []string{"east", "west"} {
+		if err := cache.AddCluster(internalcache.NewCluster(region, map[string]string{"region": region}, capacity)); err != nil {
+			t.Fatalf("failed to add cluster: %v", err)
+		}
+	}
+	e := NewSchedulerEngine(cache)
+
+	namespace := internalcache.NewNamespace("tenant", "ns", nil, quota("2"), slice, nil)
+	namespace.SetPlacementConstraints(labels.SelectorFromSet(labels.Set{"region": "east"}), nil)
+	placed, err := e.ScheduleNamespace(namespace)
+	if err != nil {
+		t.Fatalf("failed to schedule namespace: %v", err)
+	}
+	if expect := map[string]int{"east": 2}; !reflect.DeepEqual(placed.GetPlacementMap(), expect) {
+		t.Fatalf("expect placements %v, got %v", expect, placed.GetPlacementMap())
+	}
+
+	testcases := map[string]struct {
+		cpu    string
+		expect map[string]int
+	}{
+		"existing placements are kept": {
+			cpu:    "2",
+			expect: map[string]int{"east": 2},
+		},
+		"added slices follow the new selector": {
+			cpu:    "3",
+			expect: map[string]int{"east": 2, "west": 1},
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			updated := internalcache.NewNamespace("tenant", "ns", nil, quota(tc.cpu), slice, nil)
+			updated.SetNewPlacements(placed.GetPlacementMap())
+			updated.SetPlacementConstraints(labels.SelectorFromSet(labels.Set{"region": "west"}), nil)
+			ret, err := e.ScheduleNamespace(updated)
+			if err != nil {
+				t.Fatalf("failed to reschedule namespace: %v", err)
+			}
+			if !reflect.DeepEqual(ret.GetPlacementMap(), tc.expect) {
+				t.Errorf("expect placements %v, got %v", tc.expect, ret.GetPlacementMap())
+			}
+		})
+	}
+}
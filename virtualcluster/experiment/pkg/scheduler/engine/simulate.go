@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+// SimulationResult tells where a namespace would be scheduled.
+type SimulationResult struct {
+	Namespace   string `json:"namespace"`
+	Profile     string `json:"profile"`
+	Schedulable bool   `json:"schedulable"`
+	// Placements are the number of slices placed on each super cluster.
+	Placements map[string]int   `json:"placements,omitempty"`
+	Scores     map[string]int64 `json:"scores,omitempty"`
+	// Reason explains why the namespace cannot be scheduled.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Simulate schedules the namespace against a snapshot of the cache without changing the cache.
+// If the namespace is already scheduled, its current placements are released in the snapshot
+// as if it was rescheduled.
+func (e *schedulerEngine) Simulate(namespace *internalcache.Namespace) (*SimulationResult, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	framework, err := e.frameworkFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	ret := &SimulationResult{
+		Namespace: namespace.GetKey(),
+		Profile:   framework.Name(),
+	}
+	if _, err := internalcache.GetLeastFitSliceNum(namespace.GetQuota(), namespace.GetQuotaSlice()); err != nil {
+		ret.Reason = err.Error()
+		return ret, nil
+	}
+
+	var oldPlacements map[string]int
+	curState := e.cache.GetNamespace(namespace.GetKey())
+	if curState != nil {
+		oldPlacements = curState.GetPlacementMap()
+	}
+	snapshot, err := e.cache.SnapshotForNamespaceSched(curState)
+	if err != nil {
+		return nil, err
+	}
+	slices := framework.ScheduleNamespaceSlices(GetSlicesToSchedule(namespace, oldPlacements), snapshot)
+	placements, err := GetNewPlacement(slices)
+	if err != nil {
+		ret.Reason = err.Error()
+		return ret, nil
+	}
+	ret.Schedulable = true
+	ret.Placements = placements
+	ret.Scores = GetLastScores(slices)
+	return ret, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+)
+
+func TestSimulate(t *testing.T) {
+	capacity := corev1.ResourceList{
+		"cpu":    resource.MustParse("4"),
+		"memory": resource.MustParse("8Gi"),
+	}
+	slice := corev1.ResourceList{
+		"cpu":    resource.MustParse("1"),
+		"memory": resource.MustParse("2Gi"),
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := internalcache.NewSchedulerCache(stop)
+	for _, name := range []string{"a", "b"} {
+		if err := cache.AddCluster(internalcache.NewCluster(name, nil, capacity)); err != nil {
+			t.Fatalf("failed to add cluster: %v", err)
+		}
+	}
+	e := NewSchedulerEngine(cache)
+
+	testcases := map[string]struct {
+		cpu         string
+		schedulable bool
+		slices      int
+	}{
+		"fits": {
+			cpu:         "6",
+			schedulable: true,
+			slices:      6,
+		},
+		"exceeds the pool capacity": {
+			cpu:         "9",
+			schedulable: false,
+		},
+	}
+	for k, tc := range testcases {
+		quota := corev1.ResourceList{
+			"cpu":    resource.MustParse(tc.cpu),
+			"memory": resource.MustParse("2Gi"),
+		}
+		result, err := e.Simulate(internalcache.NewNamespace("tenant", "ns", nil, quota, slice, nil))
+		if err != nil {
+			t.Errorf("test %s: unexpected error %v", k, err)
+			continue
+		}
+		if result.Schedulable != tc.schedulable {
+			t.Errorf("test %s: expect schedulable %v, got %v: %s", k, tc.schedulable, result.Schedulable, result.Reason)
+			continue
+		}
+		total := 0
+		for _, num := range result.Placements {
+			total += num
+		}
+		if total != tc.slices {
+			t.Errorf("test %s: expect %d slices, got %v", k, tc.slices, result.Placements)
+		}
+		if ns := cache.GetNamespace("tenant/ns"); ns != nil {
+			t.Errorf("test %s: the cache is changed by the simulation", k)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/engine"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/util"
+	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// rebalance plans the slice moves that reduce the utilization difference between super clusters
+// and, unless in dry run mode, applies them by updating the placements of the tenant namespaces.
+// The namespace controller then reconciles the scheduler cache with the updated placements.
+func (s *Scheduler) rebalance() {
+	report, err := s.schedulerEngine.PlanRebalance(s.config.Rebalance)
+	if err != nil {
+		klog.Errorf("failed to plan rebalance: %v", err)
+		return
+	}
+	s.rebalanceLock.Lock()
+	s.lastRebalanceReport = report
+	s.rebalanceLock.Unlock()
+
+	if len(report.Moves) == 0 {
+		klog.V(4).Infof("rebalance: no slice needs to move, imbalance %.2f", report.ImbalanceBefore)
+		return
+	}
+	for _, each := range report.Moves {
+		klog.Infof("rebalance (dry run: %v): move %d slice(s) of namespace %s from cluster %s to %s", report.DryRun, each.Slices, each.Namespace, each.From, each.To)
+	}
+	if report.DryRun {
+		return
+	}
+
+	// the moves of a namespace are applied at once
+	for key, placements := range report.Placements {
+		if err := s.applyMoves(key, report.PlacementsBefore[key], placements); err != nil {
+			klog.Errorf("rebalance: failed to move the slices of namespace %s: %v", key, err)
+		}
+	}
+}
+
+// applyMoves updates the placements of the tenant namespace with the given key from the
+// placements the moves were planned from to the planned ones. The moves are skipped if the
+// namespace was rescheduled in the meantime, the next round plans them again if needed.
+func (s *Scheduler) applyMoves(key string, before, placements map[string]int) error {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return fmt.Errorf("invalid namespace key %s", key)
+	}
+	clusterName, name := key[:i], key[i+1:]
+
+	var tenant mc.ClusterInterface
+	s.virtualClusterLock.Lock()
+	for _, each := range s.virtualClusterSet {
+		if each.GetClusterName() == clusterName {
+			tenant = each
+			break
+		}
+	}
+	s.virtualClusterLock.Unlock()
+	if tenant == nil {
+		return fmt.Errorf("virtual cluster %s is not found", clusterName)
+	}
+	cs, err := tenant.GetClientSet()
+	if err != nil {
+		return err
+	}
+
+	updated, _ := json.Marshal(placements)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		namespace, err := cs.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		current, _, err := util.GetSchedulingInfo(namespace)
+		if err != nil {
+			return err
+		}
+		if samePlacements(current, placements) {
+			return nil
+		}
+		if !samePlacements(current, before) {
+			return fmt.Errorf("placements changed from %v to %v since the moves were planned, skip them", before, current)
+		}
+		if namespace.Annotations == nil {
+			namespace.Annotations = make(map[string]string)
+		}
+		namespace.Annotations[utilconst.LabelScheduledPlacements] = string(updated)
+		_, err = cs.CoreV1().Namespaces().Update(context.TODO(), namespace, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// samePlacements returns whether the placements put the same number of slices on each cluster.
+func samePlacements(a, b map[string]int) bool {
+	for cluster, num := range a {
+		if b[cluster] != num {
+			return false
+		}
+	}
+	for cluster, num := range b {
+		if a[cluster] != num {
+			return false
+		}
+	}
+	return true
+}
+
+// LastRebalanceReport returns the report of the last rebalance round, nil if none has run.
+func (s *Scheduler) LastRebalanceReport() *engine.RebalanceReport {
+	s.rebalanceLock.Lock()
+	defer s.rebalanceLock.Unlock()
+	return s.lastRebalanceReport
+}
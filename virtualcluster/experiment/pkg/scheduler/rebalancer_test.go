@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+func TestApplyMoves(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-1", Name: "test", UID: "12345"}}
+	key := conversion.ToClusterKey(vc) + "/ns"
+	before := map[string]int{"a": 4}
+	planned := map[string]int{"a": 2, "b": 2}
+
+	testcases := map[string]struct {
+		current   string
+		expectErr bool
+		expect    string
+	}{
+		"planned from the current placements": {
+			current: `{"a":4}`,
+			expect:  `{"a":2,"b":2}`,
+		},
+		"already moved": {
+			current: `{"a":2,"b":2}`,
+			expect:  `{"a":2,"b":2}`,
+		},
+		"slice scheduled since planned": {
+			current:   `{"a":4,"c":1}`,
+			expectErr: true,
+			expect:    `{"a":4,"c":1}`,
+		},
+		"slices moved since planned": {
+			current:   `{"a":3,"c":1}`,
+			expectErr: true,
+			expect:    `{"a":3,"c":1}`,
+		},
+	}
+	for k, tc := range testcases {
+		client := fake.NewSimpleClientset(&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ns",
+				Annotations: map[string]string{utilconst.LabelScheduledPlacements: tc.current},
+			},
+		})
+		tenant := cluster.NewFakeTenantCluster(vc, client, nil)
+		s := &Scheduler{virtualClusterSet: map[string]mc.ClusterInterface{tenant.GetClusterName(): tenant}}
+
+		err := s.applyMoves(key, before, planned)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("test %s: expect error %v, got %v", k, tc.expectErr, err)
+		}
+		namespace, err := client.CoreV1().Namespaces().Get(context.TODO(), "ns", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("test %s: failed to get namespace: %v", k, err)
+		}
+		if got := namespace.Annotations[utilconst.LabelScheduledPlacements]; got != tc.expect {
+			t.Errorf("test %s: expect placements %s, got %s", k, tc.expect, got)
+		}
+	}
+}
@@ -304,9 +304,14 @@ func (s *Scheduler) addSuperCluster(key string, super *v1alpha4.Cluster) error {
 	klog.Infof("add supercluster %s", key)
 
 	s.superClusterLock.Lock()
-	if _, exist := s.superClusterSet[key]; exist {
+	if existing, exist := s.superClusterSet[key]; exist {
 		s.superClusterLock.Unlock()
-		return nil
+		// the labels and taints may have changed, they only affect the future scheduling decisions
+		taints, err := util.GetClusterTaints(super)
+		if err != nil {
+			return fmt.Errorf("failed to get taints of super cluster %s: %v", key, err)
+		}
+		return s.schedulerCache.UpdateClusterConstraints(existing.GetClusterName(), super.GetLabels(), taints)
 	}
 	s.superClusterLock.Unlock()
 
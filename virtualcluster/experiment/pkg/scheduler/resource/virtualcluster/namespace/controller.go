@@ -138,7 +138,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	expect, _ := internalcache.GetLeastFitSliceNum(quota, quotaSlice)
 	if expect == 0 {
 		// the quota is gone. we should delete the ns scheduling placements and update the scheduler cache
-		if err := c.updateSchedulingResult(request.ClusterName, namespace, nil); err != nil {
+		if err := c.updateSchedulingResult(request.ClusterName, namespace, nil, nil); err != nil {
 			return reconciler.Result{}, fmt.Errorf("failed to remove scheduing placements from namespace %s in %s: %v", request.Name, request.ClusterName, err)
 		}
 		if err := c.SchedulerEngine.DeScheduleNamespace(fmt.Sprintf("%s/%s", request.ClusterName, request.Name)); err != nil {
@@ -153,7 +153,23 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 		schedule = append(schedule, internalcache.NewPlacement(k, v))
 	}
 
+	vc, err := c.MultiClusterController.GetClusterObject(request.ClusterName)
+	if err != nil {
+		return reconciler.Result{}, fmt.Errorf("failed to get the virtual cluster of %s: %v", request.ClusterName, err)
+	}
+	selector, tolerations, err := util.GetPlacementConstraints(vc, namespace)
+	if err != nil {
+		c.MultiClusterController.Eventf(request.ClusterName, &corev1.ObjectReference{
+			Kind:      "Namespace",
+			Name:      namespace.Name,
+			Namespace: namespace.Name,
+			UID:       namespace.UID,
+		}, corev1.EventTypeWarning, "Failed", "Invalid placement constraints of namespace %s: %v", request.Name, err)
+		return reconciler.Result{}, fmt.Errorf("failed to get placement constraints of namespace %s in %s: %v", request.Name, request.ClusterName, err)
+	}
+
 	candidate := internalcache.NewNamespace(request.ClusterName, request.Name, namespace.GetLabels(), quota, quotaSlice, schedule)
+	candidate.SetPlacementConstraints(selector, tolerations)
 	// ensure the cache is consistent with the scheduled placements
 	if numSched == expect {
 		if err := c.SchedulerEngine.EnsureNamespacePlacements(candidate); err != nil {
@@ -175,7 +191,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	}
 	// update virtualcluster namespace with the scheduling result.
 	placementMap := ret.GetPlacementMap()
-	err = c.updateSchedulingResult(request.ClusterName, namespace, placementMap)
+	err = c.updateSchedulingResult(request.ClusterName, namespace, placementMap, ret.GetScores())
 	if err == nil {
 		updatedPlacement, _ := json.Marshal(placementMap)
 		klog.Infof("Successfully schedule namespace %s/%s with placement %s", request.ClusterName, request.Name, string(updatedPlacement))
@@ -189,7 +205,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	return reconciler.Result{}, err
 }
 
-func (c *controller) updateSchedulingResult(clusterName string, namespace *corev1.Namespace, placementMap map[string]int) error {
+func (c *controller) updateSchedulingResult(clusterName string, namespace *corev1.Namespace, placementMap map[string]int, scores map[string]int64) error {
 	vcClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 	if err != nil {
 		return fmt.Errorf("failed to get vc %s's client: %v", clusterName, err)
@@ -201,9 +217,18 @@ func (c *controller) updateSchedulingResult(clusterName string, namespace *corev
 		}
 		if placementMap == nil {
 			delete(clone.Annotations, utilconst.LabelScheduledPlacements)
+			delete(clone.Annotations, utilconst.LabelScheduledScores)
 		} else {
 			updatedPlacement, _ := json.Marshal(placementMap)
 			clone.Annotations[utilconst.LabelScheduledPlacements] = string(updatedPlacement)
+			// scores are only known when at least one slice was placed by scoring,
+			// the scores of a previous scheduling must not be left behind otherwise.
+			if scores != nil {
+				updatedScores, _ := json.Marshal(scores)
+				clone.Annotations[utilconst.LabelScheduledScores] = string(updatedScores)
+			} else {
+				delete(clone.Annotations, utilconst.LabelScheduledScores)
+			}
 		}
 		_, updateErr := vcClient.CoreV1().Namespaces().Update(context.TODO(), clone, metav1.UpdateOptions{})
 		if updateErr == nil {
@@ -82,6 +82,9 @@ type Scheduler struct {
 
 	schedulerCache  internalcache.Cache
 	schedulerEngine engine.Engine
+
+	rebalanceLock       sync.Mutex
+	lastRebalanceReport *engine.RebalanceReport
 }
 
 // New creates new Scheduler
@@ -146,7 +149,11 @@ func New(
 	scheduler.superClusterSynced = superInformer.Informer().HasSynced
 
 	scheduler.schedulerCache = internalcache.NewSchedulerCache(stopCh)
-	scheduler.schedulerEngine = engine.NewSchedulerEngine(scheduler.schedulerCache)
+	schedulerEngine, err := engine.NewSchedulerEngineWithProfiles(scheduler.schedulerCache, config.Profiles)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.schedulerEngine = schedulerEngine
 
 	vcWatcher := manager.New()
 	scheduler.virtualClusterWatcher = vcWatcher
@@ -294,6 +301,9 @@ func (s *Scheduler) Run(stopChan <-chan struct{}) {
 	go wait.Until(s.Dump, 1*time.Minute, stopChan)
 	go wait.Until(s.superClusterHealthPatrol, 1*time.Minute, stopChan)
 	go wait.Until(s.virtualClusterHealthPatrol, 1*time.Minute, stopChan)
+	if s.config.Rebalance.Interval.Duration > 0 {
+		go wait.Until(s.rebalance, s.config.Rebalance.Interval.Duration, stopChan)
+	}
 }
 
 // Dump scheduler cache.
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	internalcache "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/cache"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/engine"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/experiment/pkg/scheduler/util"
+)
+
+// SimulationTenant is the tenant cluster name used when a simulation does not specify one.
+const SimulationTenant = "simulation"
+
+// Simulate returns the super clusters the Namespace and ResourceQuotas in the manifests would be
+// scheduled to, without changing the scheduler cache. If tenant is set, it must be the name of a
+// known virtual cluster, whose placement constraints apply, and an existing namespace with the same
+// name is simulated as rescheduled.
+func (s *Scheduler) Simulate(tenant string, manifests io.Reader) (*engine.SimulationResult, error) {
+	namespace, quotas, err := util.ParseSimulationManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	placements, quotaSlice, err := util.GetSchedulingInfo(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var vc client.Object
+	if tenant == "" {
+		tenant = SimulationTenant
+	} else {
+		found := false
+		s.virtualClusterLock.Lock()
+		for _, each := range s.virtualClusterSet {
+			if each.GetClusterName() == tenant {
+				vc, err = each.GetObject()
+				found = true
+				break
+			}
+		}
+		s.virtualClusterLock.Unlock()
+		if !found {
+			return nil, fmt.Errorf("virtual cluster %s is not found", tenant)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get virtual cluster %s: %v", tenant, err)
+		}
+	}
+
+	selector, tolerations, err := util.GetPlacementConstraints(vc, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var schedule []*internalcache.Placement
+	for k, v := range placements {
+		schedule = append(schedule, internalcache.NewPlacement(k, v))
+	}
+	candidate := internalcache.NewNamespace(tenant, namespace.Name, namespace.GetLabels(), util.GetMaxQuota(quotas), quotaSlice, schedule)
+	candidate.SetPlacementConstraints(selector, tolerations)
+	return s.schedulerEngine.Simulate(candidate)
+}
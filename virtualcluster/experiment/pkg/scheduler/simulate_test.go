@@ -0,0 +1,37 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+func TestSimulateUnknownTenant(t *testing.T) {
+	s := &Scheduler{virtualClusterSet: make(map[string]mc.ClusterInterface)}
+	manifests := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+`
+	_, err := s.Simulate("unknown", strings.NewReader(manifests))
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("expect an error naming the tenant unknown, got %v", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -161,7 +162,12 @@ func SyncSuperClusterState(metaClient clientset.Interface, super *v1alpha4.Clust
 			labels[k] = v
 		}
 	}
+	taints, err := GetClusterTaints(super)
+	if err != nil {
+		return fmt.Errorf("failed to get taints of super cluster %s/%s: %v", super.Namespace, super.Name, err)
+	}
 	clusterInstance := internalcache.NewCluster(id, labels, capacity)
+	clusterInstance.SetTaints(taints)
 	nslist, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get namespaces from super cluster %s/%s: %v", super.Namespace, super.Name, err)
@@ -188,6 +194,57 @@ func SyncSuperClusterState(metaClient clientset.Interface, super *v1alpha4.Clust
 	return nil
 }
 
+// GetClusterTaints returns the taints set on the super cluster object.
+func GetClusterTaints(super metav1.Object) ([]corev1.Taint, error) {
+	val, ok := super.GetAnnotations()[utilconst.LabelClusterTaints]
+	if !ok {
+		return nil, nil
+	}
+	var taints []corev1.Taint
+	if err := json.Unmarshal([]byte(val), &taints); err != nil {
+		return nil, fmt.Errorf("unknown format %s of key %s: %v", val, utilconst.LabelClusterTaints, err)
+	}
+	for _, each := range taints {
+		if each.Key == "" {
+			return nil, fmt.Errorf("taint %v of key %s has an empty key", each, utilconst.LabelClusterTaints)
+		}
+	}
+	return taints, nil
+}
+
+// GetPlacementConstraints returns the cluster selector and the tolerations set on the given objects, usually
+// the VirtualCluster and the tenant namespace. The selectors are ANDed and the tolerations are merged. A nil
+// selector is returned if none of the objects has a cluster selector.
+func GetPlacementConstraints(objs ...metav1.Object) (labels.Selector, []corev1.Toleration, error) {
+	var selector labels.Selector
+	var tolerations []corev1.Toleration
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		annotations := obj.GetAnnotations()
+		if val, ok := annotations[utilconst.LabelClusterSelector]; ok {
+			s, err := labels.Parse(val)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unknown format %s of key %s in %s: %v", val, utilconst.LabelClusterSelector, obj.GetName(), err)
+			}
+			if selector == nil {
+				selector = labels.NewSelector()
+			}
+			requirements, _ := s.Requirements()
+			selector = selector.Add(requirements...)
+		}
+		if val, ok := annotations[utilconst.LabelClusterTolerations]; ok {
+			var each []corev1.Toleration
+			if err := json.Unmarshal([]byte(val), &each); err != nil {
+				return nil, nil, fmt.Errorf("unknown format %s of key %s in %s: %v", val, utilconst.LabelClusterTolerations, obj.GetName(), err)
+			}
+			tolerations = append(tolerations, each...)
+		}
+	}
+	return selector, tolerations, nil
+}
+
 func GetMaxQuota(quotalist *corev1.ResourceQuotaList) corev1.ResourceList {
 	quota := corev1.ResourceList{
 		corev1.ResourceCPU:    resource.MustParse("0"),
@@ -353,7 +410,12 @@ func SyncVirtualClusterState(metaClient clientset.Interface, vc *v1alpha1.Virtua
 				labels[k] = v
 			}
 		}
+		selector, tolerations, err := GetPlacementConstraints(vc, &nslist.Items[nsIndex])
+		if err != nil {
+			return fmt.Errorf("failed to get placement constraints in %s/%s: %v", vc.Namespace, vc.Name, err)
+		}
 		cNamespace := internalcache.NewNamespace(clustername, each.Name, labels, quota, quotaSlice, schedule)
+		cNamespace.SetPlacementConstraints(selector, tolerations)
 		// If the namespace already exists, AddNamespace will update the cache with latest labels and schedule.
 		if err := cache.AddNamespace(cNamespace); err != nil {
 			return fmt.Errorf("failed to add namespace to cache: %s/%s with error %v", clustername, each.Name, err)
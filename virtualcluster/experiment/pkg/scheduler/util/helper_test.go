@@ -17,10 +17,14 @@ limitations under the License.
 package util
 
 import (
+	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 )
 
 func Equals(a corev1.ResourceList, b corev1.ResourceList) bool {
@@ -331,3 +335,66 @@ func TestGetPodRequirements(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPlacementConstraints(t *testing.T) {
+	withAnnotations := func(annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: annotations}}
+	}
+	testcases := map[string]struct {
+		objs              []metav1.Object
+		expectSelector    string
+		expectTolerations []corev1.Toleration
+		succeed           bool
+	}{
+		"no constraints": {
+			objs:    []metav1.Object{withAnnotations(nil), withAnnotations(nil)},
+			succeed: true,
+		},
+		"selectors are ANDed and tolerations are merged": {
+			objs: []metav1.Object{
+				withAnnotations(map[string]string{
+					utilconst.LabelClusterSelector:    "region=east",
+					utilconst.LabelClusterTolerations: `[{"key":"gpu","operator":"Exists"}]`,
+				}),
+				withAnnotations(map[string]string{
+					utilconst.LabelClusterSelector:    "tier in (gold)",
+					utilconst.LabelClusterTolerations: `[{"key":"spot","operator":"Equal","value":"true","effect":"NoSchedule"}]`,
+				}),
+			},
+			expectSelector: "region=east,tier in (gold)",
+			expectTolerations: []corev1.Toleration{
+				{Key: "gpu", Operator: corev1.TolerationOpExists},
+				{Key: "spot", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule},
+			},
+			succeed: true,
+		},
+		"invalid selector": {
+			objs:    []metav1.Object{withAnnotations(map[string]string{utilconst.LabelClusterSelector: "region in (east"})},
+			succeed: false,
+		},
+		"invalid tolerations": {
+			objs:    []metav1.Object{withAnnotations(map[string]string{utilconst.LabelClusterTolerations: "gpu"})},
+			succeed: false,
+		},
+	}
+	for k, tc := range testcases {
+		selector, tolerations, err := GetPlacementConstraints(tc.objs...)
+		if (err == nil) != tc.succeed {
+			t.Errorf("test %s: expect succeed %v, got error %v", k, tc.succeed, err)
+			continue
+		}
+		if !tc.succeed {
+			continue
+		}
+		if tc.expectSelector == "" {
+			if selector != nil {
+				t.Errorf("test %s: expect no selector, got %s", k, selector)
+			}
+		} else if selector == nil || selector.String() != tc.expectSelector {
+			t.Errorf("test %s: expect selector %s, got %v", k, tc.expectSelector, selector)
+		}
+		if !reflect.DeepEqual(tolerations, tc.expectTolerations) {
+			t.Errorf("test %s: expect tolerations %v, got %v", k, tc.expectTolerations, tolerations)
+		}
+	}
+}
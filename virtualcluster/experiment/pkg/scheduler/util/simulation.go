@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ParseSimulationManifests reads a yaml or json stream with one Namespace and its ResourceQuotas.
+func ParseSimulationManifests(r io.Reader) (*corev1.Namespace, *corev1.ResourceQuotaList, error) {
+	var namespace *corev1.Namespace
+	quotas := &corev1.ResourceQuotaList{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, fmt.Errorf("failed to decode manifests: %v", err)
+		}
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw.Raw, &typeMeta); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode manifest kind: %v", err)
+		}
+		switch typeMeta.Kind {
+		case "Namespace":
+			if namespace != nil {
+				return nil, nil, fmt.Errorf("only one Namespace can be simulated at a time")
+			}
+			namespace = &corev1.Namespace{}
+			if err := json.Unmarshal(raw.Raw, namespace); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode Namespace: %v", err)
+			}
+		case "ResourceQuota":
+			quota := corev1.ResourceQuota{}
+			if err := json.Unmarshal(raw.Raw, &quota); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode ResourceQuota: %v", err)
+			}
+			quotas.Items = append(quotas.Items, quota)
+		default:
+			return nil, nil, fmt.Errorf("unsupported kind %q, only Namespace and ResourceQuota are accepted", typeMeta.Kind)
+		}
+	}
+	if namespace == nil {
+		return nil, nil, fmt.Errorf("no Namespace is found in manifests")
+	}
+	if namespace.Name == "" {
+		return nil, nil, fmt.Errorf("the Namespace has no name")
+	}
+	return namespace, quotas, nil
+}
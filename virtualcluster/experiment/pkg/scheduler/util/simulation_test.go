@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSimulationManifests(t *testing.T) {
+	testcases := map[string]struct {
+		manifests string
+		quotas    int
+		succeed   bool
+	}{
+		"namespace and quotas": {
+			manifests: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: q1
+  namespace: test
+spec:
+  hard:
+    cpu: "4"
+    memory: 8Gi
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: q2
+  namespace: test
+spec:
+  hard:
+    cpu: "2"
+`,
+			quotas:  2,
+			succeed: true,
+		},
+		"json namespace": {
+			manifests: `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "test"}}`,
+			succeed:   true,
+		},
+		"no namespace": {
+			manifests: `apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: q1
+`,
+			succeed: false,
+		},
+		"two namespaces": {
+			manifests: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: b
+`,
+			succeed: false,
+		},
+		"unsupported kind": {
+			manifests: `apiVersion: v1
+kind: Pod
+metadata:
+  name: a
+`,
+			succeed: false,
+		},
+	}
+	for k, tc := range testcases {
+		ns, quotas, err := ParseSimulationManifests(strings.NewReader(tc.manifests))
+		if (err == nil) != tc.succeed {
+			t.Errorf("test %s: expect succeed %v, got error %v", k, tc.succeed, err)
+			continue
+		}
+		if !tc.succeed {
+			continue
+		}
+		if ns.Name != "test" {
+			t.Errorf("test %s: expect namespace test, got %s", k, ns.Name)
+		}
+		if len(quotas.Items) != tc.quotas {
+			t.Errorf("test %s: expect %d quotas, got %d", k, tc.quotas, len(quotas.Items))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ServiceExport declares that the Service with the same name and namespace
+// is exported to the other virtual clusters allowed by the ServiceExportPolicy
+// of the virtual cluster.
+type ServiceExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Status ServiceExportStatus `json:"status,omitempty"`
+}
+
+// ServiceExportStatus contains the current status of an export.
+type ServiceExportStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []ServiceExportCondition `json:"conditions,omitempty"`
+}
+
+// ServiceExportConditionType identifies a specific condition.
+type ServiceExportConditionType string
+
+const (
+	// ServiceExportValid means that the service referenced by this
+	// service export has been recognized as valid by the syncer.
+	// This will be false if the service is found to be unexportable
+	// (ExternalName, not found).
+	ServiceExportValid ServiceExportConditionType = "Valid"
+	// ServiceExportConflict means that there is a conflict between two
+	// exports for the same Service.
+	ServiceExportConflict ServiceExportConditionType = "Conflict"
+)
+
+// ServiceExportCondition contains details for the current condition of this
+// service export.
+type ServiceExportCondition struct {
+	Type ServiceExportConditionType `json:"type"`
+	// Status is one of {"True", "False", "Unknown"}
+	Status corev1.ConditionStatus `json:"status"`
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// +optional
+	Reason *string `json:"reason,omitempty"`
+	// +optional
+	Message *string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServiceExportList represents a list of ServiceExports.
+type ServiceExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServiceExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceExport{}, &ServiceExportList{})
+}
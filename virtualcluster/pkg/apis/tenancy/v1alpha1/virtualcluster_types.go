@@ -51,6 +51,126 @@ type VirtualClusterSpec struct {
 	// Service CIDRs used by VirtualCluster
 	// +optional
 	ServiceCidr string `json:"serviceCidr,omitempty"`
+
+	// Quota caps the aggregate resources the virtual cluster can consume in the
+	// super control plane. Supported resources are cpu, memory, pods, services
+	// and requests.storage; cpu and memory are accounted by pod requests.
+	// +optional
+	Quota corev1.ResourceList `json:"quota,omitempty"`
+
+	// IngressPolicy controls how tenant Ingresses are synced to the super
+	// control plane. If not set, tenant Ingresses are synced as they are.
+	// +optional
+	IngressPolicy *IngressPolicy `json:"ingressPolicy,omitempty"`
+
+	// PodSecurity sets the Pod Security Standard enforced on the super control
+	// plane namespaces of the virtual cluster. If not set, tenant pods are
+	// admitted under the default of the super control plane.
+	// +optional
+	PodSecurity *PodSecurity `json:"podSecurity,omitempty"`
+
+	// ServiceExportPolicy controls which virtual clusters can import the
+	// Services exported by ServiceExports of the virtual cluster. If not set,
+	// the ServiceExports of the virtual cluster are ignored.
+	// +optional
+	ServiceExportPolicy *ServiceExportPolicy `json:"serviceExportPolicy,omitempty"`
+
+	// ConflictPolicy controls how the syncer handles super control plane
+	// objects which already exist but are not delegated by the tenant object
+	// they are synced from, e.g. leftovers of a deleted tenant object. If not
+	// set, conflicts fail.
+	// +optional
+	ConflictPolicy *ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// IngressPolicy defines the constraints applied to tenant Ingresses.
+type IngressPolicy struct {
+	// ClassMappings maps tenant IngressClass names to the super control plane
+	// IngressClass names they are allowed to use. If not empty, Ingresses using
+	// a class that is not listed are rejected.
+	// +optional
+	ClassMappings map[string]string `json:"classMappings,omitempty"`
+
+	// DefaultClass is the super control plane IngressClass assigned to tenant
+	// Ingresses that do not specify one.
+	// +optional
+	DefaultClass string `json:"defaultClass,omitempty"`
+
+	// HostSuffix, if set, requires every host of tenant Ingresses to be a
+	// subdomain of the suffix, e.g. "tenant-a.example.com". The rules without
+	// host and the default backends, which serve any host, are rejected.
+	// +optional
+	HostSuffix string `json:"hostSuffix,omitempty"`
+}
+
+// PodSecurityLevel is a Pod Security Standard level.
+type PodSecurityLevel string
+
+const (
+	PodSecurityLevelPrivileged PodSecurityLevel = "privileged"
+	PodSecurityLevelBaseline   PodSecurityLevel = "baseline"
+	PodSecurityLevelRestricted PodSecurityLevel = "restricted"
+)
+
+// PodSecurity defines the Pod Security Admission policy applied to tenant pods.
+type PodSecurity struct {
+	// Level is the Pod Security Standard enforced on every namespace of the
+	// virtual cluster.
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	Level PodSecurityLevel `json:"level"`
+
+	// Version pins the version of the Pod Security Standard, e.g. "v1.24".
+	// Defaults to "latest".
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Exceptions maps tenant namespace names to the level enforced on them
+	// instead of Level.
+	// +optional
+	Exceptions map[string]PodSecurityLevel `json:"exceptions,omitempty"`
+}
+
+// ServiceExportPolicy defines the virtual clusters which can consume the
+// exported Services of a virtual cluster.
+type ServiceExportPolicy struct {
+	// ImporterSelector selects the VirtualClusters, by their labels, which get
+	// a ServiceImport for each exported Service. An empty selector selects all
+	// VirtualClusters of the super control plane.
+	ImporterSelector *metav1.LabelSelector `json:"importerSelector"`
+
+	// Namespaces are the tenant namespaces whose Services can be exported. All
+	// namespaces can if empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ConflictAction is the action taken on a super control plane object which
+// conflicts with a tenant object.
+type ConflictAction string
+
+const (
+	// ConflictActionFail leaves the object alone and reports the conflict on
+	// the tenant object.
+	ConflictActionFail ConflictAction = "Fail"
+	// ConflictActionAdopt makes the tenant object delegate the object.
+	ConflictActionAdopt ConflictAction = "Adopt"
+	// ConflictActionRecreate deletes the object once the conflict lasted for
+	// the grace period, so that it is created again from the tenant object.
+	ConflictActionRecreate ConflictAction = "Recreate"
+)
+
+// ConflictPolicy defines how the conflicts between tenant objects and super
+// control plane objects are resolved. Objects delegated by another virtual
+// cluster always fail.
+type ConflictPolicy struct {
+	// Action is the action taken on conflicting objects.
+	// +kubebuilder:validation:Enum=Fail;Adopt;Recreate
+	Action ConflictAction `json:"action"`
+
+	// GracePeriodSeconds is how long a conflict is reported before the object
+	// is recreated. Only used by the Recreate action. Defaults to 0.
+	// +optional
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds,omitempty"`
 }
 
 // VirtualClusterStatus defines the observed state of VirtualCluster
@@ -76,6 +196,16 @@ type VirtualClusterStatus struct {
 
 	// Cluster Conditions
 	Conditions []ClusterCondition `json:"conditions,omitempty"`
+
+	// QuotaUsed is the observed aggregate usage of the resources listed in
+	// Spec.Quota by all tenant objects in the super control plane.
+	// +optional
+	QuotaUsed corev1.ResourceList `json:"quotaUsed,omitempty"`
+
+	// NodePortRange is the slice of the super cluster NodePort range reserved
+	// for the services of this cluster, e.g. "30100-30199".
+	// +optional
+	NodePortRange string `json:"nodePortRange,omitempty"`
 }
 
 type ClusterPhase string
@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/controller/controllers"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 )
 
 // Controllers defines all the shared information between all
@@ -34,6 +35,10 @@ type Controllers struct {
 	MaxConcurrentReconciles int
 	ProvisionerName         string
 	ProvisionerTimeout      time.Duration
+	// Naming is the namespace naming strategy of new VirtualClusters.
+	Naming naming.Spec
+	// NamingStore records the naming of new VirtualClusters, nil to use the legacy naming.
+	NamingStore *naming.Store
 }
 
 // SetupWithManager adds all Controllers to the Manager
@@ -69,6 +74,8 @@ func (c *Controllers) SetupWithManager(mgr ctrl.Manager) error {
 		Log:                c.Log.WithName("virtualcluster"),
 		ProvisionerName:    c.ProvisionerName,
 		ProvisionerTimeout: c.ProvisionerTimeout,
+		Naming:             c.Naming,
+		NamingStore:        c.NamingStore,
 	}).SetupWithManager(mgr, opts); err != nil {
 		return err
 	}
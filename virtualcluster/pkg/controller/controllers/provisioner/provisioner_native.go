@@ -293,7 +293,8 @@ func (mpn *Native) deployComponent(ctx context.Context, vc *tenancyv1alpha1.Virt
 }
 
 // createOrUpdatePKISecrets creates secrets to store crt/key pairs and kubeconfigs
-// for control plane components of the virtual cluster
+// for control plane components of the virtual cluster, and a configmap to publish
+// the root crt
 func (mpn *Native) createOrUpdatePKISecrets(ctx context.Context, caGroup *vcpki.ClusterCAGroup, namespace string) error {
 	// create secret for root crt/key pair
 	rootSrt := secret.CrtKeyPairToSecret(secret.RootCASecretName, namespace, caGroup.RootCA)
@@ -332,7 +333,12 @@ func (mpn *Native) createOrUpdatePKISecrets(ctx context.Context, caGroup *vcpki.
 		}
 	}
 
-	return nil
+	// publish the root crt without its key, for the vn-agents to verify the
+	// client certificates of the tenant apiserver
+	rootCrtCm := secret.CrtToConfigMap(constants.VCRootCACertConfigMapName, namespace, caGroup.RootCA)
+	mpn.Log.Info("applying configmap", "name",
+		rootCrtCm.Name, "namespace", rootCrtCm.Namespace)
+	return mpn.Patch(ctx, rootCrtCm, client.Apply, patchOptions)
 }
 
 // createAndApplyPKI constructs the PKI (all crt/key pair and kubeconfig) for the
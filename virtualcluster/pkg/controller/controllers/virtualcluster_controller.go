@@ -39,6 +39,7 @@ import (
 	strutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/controller/util/strings"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 )
 
 // GetProvisioner returns a new provisioner.Provisioner by ProvisionerName
@@ -61,6 +62,8 @@ type ReconcileVirtualCluster struct {
 	ProvisionerName    string
 	ProvisionerTimeout time.Duration
 	Provisioner        provisioner.Provisioner
+	Naming             naming.Spec
+	NamingStore        *naming.Store
 }
 
 // SetupWithManager will configure the VirtualCluster reconciler
@@ -133,6 +136,12 @@ func (r *ReconcileVirtualCluster) Reconcile(ctx context.Context, request reconci
 				r.Log.Error(err, "fail to delete virtualcluster", "vc-name", vc.Name)
 				return
 			}
+			if r.NamingStore != nil && vc.Status.ClusterNamespace != "" {
+				if err = r.NamingStore.Unregister(ctx, vc.Status.ClusterNamespace, vc.Namespace+"/"+vc.Name); err != nil {
+					r.Log.Error(err, "fail to unregister the namespace naming", "vc-name", vc.Name)
+					return
+				}
+			}
 			// remove finalizer from the list and update it.
 			vc.ObjectMeta.Finalizers = strutil.RemoveString(vc.ObjectMeta.Finalizers, vcFinalizerName)
 			err = kubeutil.RetryUpdateVCStatusOnConflict(ctx, r, vc, r.Log)
@@ -147,6 +156,10 @@ func (r *ReconcileVirtualCluster) Reconcile(ctx context.Context, request reconci
 	case "":
 		// set vc status as ClusterPending if no status is set
 		r.Log.Info("will create a VirtualCluster", "vc", vc.Name)
+		if err = r.registerNaming(ctx, vc); err != nil {
+			r.Log.Error(err, "fail to register the namespace naming", "vc", vc.Name)
+			return
+		}
 		// will retry three times
 		kubeutil.SetVCStatus(vc, tenancyv1alpha1.ClusterPending,
 			"retry: 3", "ClusterCreating")
@@ -222,3 +235,29 @@ func (r *ReconcileVirtualCluster) Reconcile(ctx context.Context, request reconci
 		return
 	}
 }
+
+// registerNaming names the root namespace of a new VirtualCluster with the naming strategy
+// and records it in the naming registry, which the syncers and vn-agents use to name the
+// tenant namespaces. VirtualClusters named by the legacy strategy, or whose ClusterNamespace
+// is already set, are not recorded.
+func (r *ReconcileVirtualCluster) registerNaming(ctx context.Context, vc *tenancyv1alpha1.VirtualCluster) error {
+	if r.NamingStore == nil || vc.Status.ClusterNamespace != "" {
+		return nil
+	}
+	strategy, err := naming.New(r.Naming)
+	if err != nil {
+		return err
+	}
+	if strategy.Name() == naming.StrategyLegacy {
+		return nil
+	}
+	clusterKey := strategy.ClusterKey(vc)
+	if err := r.NamingStore.Register(ctx, clusterKey, naming.Entry{
+		Spec:           r.Naming,
+		VirtualCluster: vc.Namespace + "/" + vc.Name,
+	}); err != nil {
+		return err
+	}
+	vc.Status.ClusterNamespace = clusterKey
+	return nil
+}
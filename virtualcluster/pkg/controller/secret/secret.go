@@ -95,6 +95,24 @@ func CrtKeyPairToSecret(name, namespace string, ckp *vcpki.CrtKeyPair) *corev1.S
 	}
 }
 
+// CrtToConfigMap encapsulates the certificate of ca/key pair ckp into a config map
+// object, so that the certificate can be read without access to the key
+func CrtToConfigMap(name, namespace string, ckp *vcpki.CrtKeyPair) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			corev1.ServiceAccountRootCAKey: string(pkiutil.EncodeCertPEM(ckp.Crt)),
+		},
+	}
+}
+
 // KubeconfigToSecret encapsulates kubeconfig cfgContent into a secret object
 func KubeconfigToSecret(name, namespace string, cfgContent string) *corev1.Secret {
 	return &corev1.Secret{
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+)
+
+// reloadableFields are the fields of SyncerConfiguration which can be changed
+// at runtime. They must be read through their getters. The reloadable feature
+// gates are defined by the featuregate package.
+var reloadableFields = sets.NewString(
+	"DefaultOpaqueMetaDomains",
+	"ExtraNodeLabels",
+	"OpaqueTaintKeys",
+	"DNSOptions",
+)
+
+// ignoredFields are not read from the configuration file, or compared
+// separately as for FeatureGates.
+var ignoredFields = sets.NewString(
+	"TypeMeta",
+	"RestConfig",
+	"FeatureGates",
+)
+
+// reloadLock guards the reloadable fields of all SyncerConfigurations.
+var reloadLock sync.RWMutex
+
+// GetDefaultOpaqueMetaDomains returns DefaultOpaqueMetaDomains, which is reloadable.
+func (c *SyncerConfiguration) GetDefaultOpaqueMetaDomains() []string {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return c.DefaultOpaqueMetaDomains
+}
+
+// GetExtraNodeLabels returns ExtraNodeLabels, which is reloadable.
+func (c *SyncerConfiguration) GetExtraNodeLabels() []string {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return c.ExtraNodeLabels
+}
+
+// GetOpaqueTaintKeys returns OpaqueTaintKeys, which is reloadable.
+func (c *SyncerConfiguration) GetOpaqueTaintKeys() []string {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return c.OpaqueTaintKeys
+}
+
+// GetDNSOptions returns DNSOptions, which is reloadable.
+func (c *SyncerConfiguration) GetDNSOptions() []corev1.PodDNSConfigOption {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return c.DNSOptions
+}
+
+// Reload applies the reloadable settings of newer to c and to the default
+// feature gate. newer is rejected as a whole if it changes any other setting,
+// as that requires restarting the syncer.
+func (c *SyncerConfiguration) Reload(newer *SyncerConfiguration) error {
+	if changed := c.unreloadableChanges(newer); len(changed) > 0 {
+		return fmt.Errorf("changing %s requires restarting the syncer", strings.Join(changed, ", "))
+	}
+
+	for _, name := range changedFeatureGates(c.FeatureGates, newer.FeatureGates) {
+		if err := featuregate.DefaultFeatureGate.Set(featuregate.Feature(name), newer.FeatureGates[name]); err != nil {
+			return err
+		}
+	}
+
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	c.DefaultOpaqueMetaDomains = newer.DefaultOpaqueMetaDomains
+	c.ExtraNodeLabels = newer.ExtraNodeLabels
+	c.OpaqueTaintKeys = newer.OpaqueTaintKeys
+	c.DNSOptions = newer.DNSOptions
+	c.FeatureGates = newer.FeatureGates
+	return nil
+}
+
+// unreloadableChanges returns the settings which differ between c and newer
+// but cannot be reloaded.
+func (c *SyncerConfiguration) unreloadableChanges(newer *SyncerConfiguration) []string {
+	var changed []string
+	current, next := reflect.ValueOf(c).Elem(), reflect.ValueOf(newer).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		name := current.Type().Field(i).Name
+		if reloadableFields.Has(name) || ignoredFields.Has(name) {
+			continue
+		}
+		if !equality.Semantic.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range changedFeatureGates(c.FeatureGates, newer.FeatureGates) {
+		if !featuregate.IsReloadable(featuregate.Feature(name)) {
+			changed = append(changed, "feature gate "+name)
+		}
+	}
+	return changed
+}
+
+// changedFeatureGates returns the feature gates whose values differ between
+// current and newer. Missing feature gates are disabled.
+func changedFeatureGates(current, newer map[string]bool) []string {
+	var names []string
+	for name := range sets.StringKeySet(current).Union(sets.StringKeySet(newer)) {
+		if current[name] != newer[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+)
+
+func TestReload(t *testing.T) {
+	defer func() {
+		featuregate.DefaultFeatureGate, _ = featuregate.NewFeatureGate(nil)
+	}()
+
+	newConfig := func() *SyncerConfiguration {
+		return &SyncerConfiguration{
+			DefaultOpaqueMetaDomains: []string{"kubernetes.io"},
+			VNAgentPort:              10550,
+			FeatureGates:             map[string]bool{featuregate.SuperClusterPooling: true},
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		mutate      func(*SyncerConfiguration)
+		expectError string
+		verify      func(*testing.T, *SyncerConfiguration)
+	}{
+		{
+			name: "reloadable settings",
+			mutate: func(c *SyncerConfiguration) {
+				c.DefaultOpaqueMetaDomains = []string{"k8s.io"}
+				c.ExtraNodeLabels = []string{"zone"}
+				c.OpaqueTaintKeys = []string{"dedicated"}
+				c.DNSOptions = []corev1.PodDNSConfigOption{{Name: "single-request"}}
+			},
+			verify: func(t *testing.T, c *SyncerConfiguration) {
+				if domains := c.GetDefaultOpaqueMetaDomains(); len(domains) != 1 || domains[0] != "k8s.io" {
+					t.Errorf("expected opaque meta domains to be reloaded, got %v", domains)
+				}
+				if labels := c.GetExtraNodeLabels(); len(labels) != 1 || labels[0] != "zone" {
+					t.Errorf("expected extra node labels to be reloaded, got %v", labels)
+				}
+				if keys := c.GetOpaqueTaintKeys(); len(keys) != 1 || keys[0] != "dedicated" {
+					t.Errorf("expected opaque taint keys to be reloaded, got %v", keys)
+				}
+				if options := c.GetDNSOptions(); len(options) != 1 || options[0].Name != "single-request" {
+					t.Errorf("expected dns options to be reloaded, got %v", options)
+				}
+			},
+		},
+		{
+			name: "reloadable feature gate",
+			mutate: func(c *SyncerConfiguration) {
+				c.FeatureGates[featuregate.TenantAllowDNSPolicy] = true
+			},
+			verify: func(t *testing.T, c *SyncerConfiguration) {
+				if !featuregate.DefaultFeatureGate.Enabled(featuregate.TenantAllowDNSPolicy) {
+					t.Errorf("expected feature gate %s to be enabled", featuregate.TenantAllowDNSPolicy)
+				}
+			},
+		},
+		{
+			name: "unreloadable setting",
+			mutate: func(c *SyncerConfiguration) {
+				c.VNAgentPort = 10551
+				c.ExtraNodeLabels = []string{"zone"}
+			},
+			expectError: "changing VNAgentPort requires restarting the syncer",
+			verify: func(t *testing.T, c *SyncerConfiguration) {
+				if c.VNAgentPort != 10550 {
+					t.Errorf("expected vn agent port to be kept, got %d", c.VNAgentPort)
+				}
+				if labels := c.GetExtraNodeLabels(); len(labels) != 0 {
+					t.Errorf("expected rejected configuration not to be applied, got extra node labels %v", labels)
+				}
+			},
+		},
+		{
+			name: "unreloadable feature gate",
+			mutate: func(c *SyncerConfiguration) {
+				delete(c.FeatureGates, featuregate.SuperClusterPooling)
+			},
+			expectError: "feature gate " + featuregate.SuperClusterPooling,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			featuregate.DefaultFeatureGate, _ = featuregate.NewFeatureGate(map[string]bool{featuregate.SuperClusterPooling: true})
+			current, newer := newConfig(), newConfig()
+			tc.mutate(newer)
+
+			err := current.Reload(newer)
+			if tc.expectError == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.expectError != "" && (err == nil || !strings.Contains(err.Error(), tc.expectError)) {
+				t.Errorf("expected error containing %q, got %v", tc.expectError, err)
+			}
+			if tc.verify != nil {
+				tc.verify(t, current)
+			}
+		})
+	}
+}
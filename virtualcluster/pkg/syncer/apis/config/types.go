@@ -23,7 +23,8 @@ import (
 	componentbaseconfig "k8s.io/component-base/config"
 )
 
-// SyncerConfiguration configures a syncer. It is read only during syncer life cycle.
+// SyncerConfiguration configures a syncer. It is read only during syncer life cycle,
+// except for the settings listed in reload.go, which are read through their getters.
 type SyncerConfiguration struct {
 	metav1.TypeMeta
 
@@ -76,6 +77,48 @@ type SyncerConfiguration struct {
 	// is used for the feature VNodeProviderPodIP
 	VNAgentLabelSelector string
 
+	// SuperClusterNodePortRange is the NodePort range of the super cluster, e.g. "30000-32767".
+	SuperClusterNodePortRange string
+
+	// TenantNodePortRangeSize is the number of NodePorts in the slice of SuperClusterNodePortRange
+	// each Virtual Cluster is given. Tenant NodePorts are kept in the super cluster only if they fall
+	// into the slice of their Virtual Cluster. Defaults to 0, which disables per tenant NodePort
+	// ranges and lets the super cluster allocate NodePorts freely.
+	TenantNodePortRangeSize int32
+
+	// TenantClusterNodePortRange is the --service-node-port-range of the tenant apiservers, which
+	// allocate the NodePorts of tenant services left empty from it. With per tenant NodePort ranges,
+	// only the tenant NodePorts outside of it are honored as explicitly requested, the others are
+	// reassigned from the slice of the Virtual Cluster. Defaults to "30000-32767".
+	TenantClusterNodePortRange string
+
+	// NamingRegistry is the namespace/name of the naming registry ConfigMap of the super cluster,
+	// which records the namespace naming strategy of each VirtualCluster. If it is empty, the
+	// namespaces of all VirtualClusters are named with the legacy strategy.
+	NamingRegistry string
+
+	// TracingEndpoint is the host:port of the OTLP/HTTP collector the syncer exports the traces of
+	// downward and upward syncs to. Defaults to "", which disables tracing.
+	TracingEndpoint string
+
+	// TracingInsecure indicates whether to export traces to TracingEndpoint over plain HTTP.
+	TracingInsecure bool
+
+	// TracingSamplingRatio is the fraction of syncs traced, between 0 and 1.
+	TracingSamplingRatio float64
+
+	// TenantMetrics breaks the syncer metrics down by VirtualCluster in the vc_name label.
+	TenantMetrics bool
+
+	// TenantMetricsAllowList is the namespace/name of the VirtualClusters which always get their
+	// own vc_name when TenantMetrics is enabled.
+	TenantMetricsAllowList []string
+
+	// TenantMetricsMaxTenants is the number of other VirtualClusters getting their own vc_name, in
+	// the order they are added to the syncer. Further VirtualClusters are reported as "other", so
+	// that the number of series stays bounded.
+	TenantMetricsMaxTenants int
+
 	// FeatureGates enabled by the user.
 	FeatureGates map[string]bool
 
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	if err := scheme.AddConversionFunc((*SyncerConfiguration)(nil), (*config.SyncerConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SyncerConfiguration_To_config_SyncerConfiguration(a.(*SyncerConfiguration), b.(*config.SyncerConfiguration), scope)
+	}); err != nil {
+		return err
+	}
+	return scheme.AddConversionFunc((*config.SyncerConfiguration)(nil), (*SyncerConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_SyncerConfiguration_To_v1alpha1_SyncerConfiguration(a.(*config.SyncerConfiguration), b.(*SyncerConfiguration), scope)
+	})
+}
+
+// Convert_v1alpha1_SyncerConfiguration_To_config_SyncerConfiguration converts
+// the versioned configuration to the internal one used by the syncer.
+func Convert_v1alpha1_SyncerConfiguration_To_config_SyncerConfiguration(in *SyncerConfiguration, out *config.SyncerConfiguration, s conversion.Scope) error {
+	if err := componentbaseconfigv1alpha1.Convert_v1alpha1_LeaderElectionConfiguration_To_config_LeaderElectionConfiguration(&in.LeaderElection.LeaderElectionConfiguration, &out.LeaderElection.LeaderElectionConfiguration, s); err != nil {
+		return err
+	}
+	out.LeaderElection.LockObjectNamespace = in.LeaderElection.LockObjectNamespace
+	out.LeaderElection.LockObjectName = in.LeaderElection.LockObjectName
+	if err := componentbaseconfigv1alpha1.Convert_v1alpha1_ClientConnectionConfiguration_To_config_ClientConnectionConfiguration(&in.ClientConnection, &out.ClientConnection, s); err != nil {
+		return err
+	}
+	out.DefaultOpaqueMetaDomains = in.DefaultOpaqueMetaDomains
+	out.ExtraSyncingResources = in.ExtraSyncingResources
+	if in.DisableServiceAccountToken != nil {
+		out.DisableServiceAccountToken = *in.DisableServiceAccountToken
+	}
+	out.DisablePodServiceLinks = in.DisablePodServiceLinks
+	out.ExtraNodeLabels = in.ExtraNodeLabels
+	out.OpaqueTaintKeys = in.OpaqueTaintKeys
+	if in.VNAgentPort != nil {
+		out.VNAgentPort = *in.VNAgentPort
+	}
+	out.VNAgentNamespacedName = in.VNAgentNamespacedName
+	out.VNAgentLabelSelector = in.VNAgentLabelSelector
+	out.SuperClusterNodePortRange = in.SuperClusterNodePortRange
+	out.TenantNodePortRangeSize = in.TenantNodePortRangeSize
+	out.TenantClusterNodePortRange = in.TenantClusterNodePortRange
+	out.NamingRegistry = in.NamingRegistry
+	out.TracingEndpoint = in.TracingEndpoint
+	out.TracingInsecure = in.TracingInsecure
+	if in.TracingSamplingRatio != nil {
+		out.TracingSamplingRatio = *in.TracingSamplingRatio
+	}
+	out.TenantMetrics = in.TenantMetrics
+	out.TenantMetricsAllowList = in.TenantMetricsAllowList
+	if in.TenantMetricsMaxTenants != nil {
+		out.TenantMetricsMaxTenants = int(*in.TenantMetricsMaxTenants)
+	}
+	out.FeatureGates = in.FeatureGates
+	out.Timeout = in.Timeout
+	out.DNSOptions = in.DNSOptions
+	return nil
+}
+
+// Convert_config_SyncerConfiguration_To_v1alpha1_SyncerConfiguration converts
+// the internal configuration to the versioned one.
+func Convert_config_SyncerConfiguration_To_v1alpha1_SyncerConfiguration(in *config.SyncerConfiguration, out *SyncerConfiguration, s conversion.Scope) error {
+	if err := componentbaseconfigv1alpha1.Convert_config_LeaderElectionConfiguration_To_v1alpha1_LeaderElectionConfiguration(&in.LeaderElection.LeaderElectionConfiguration, &out.LeaderElection.LeaderElectionConfiguration, s); err != nil {
+		return err
+	}
+	out.LeaderElection.LockObjectNamespace = in.LeaderElection.LockObjectNamespace
+	out.LeaderElection.LockObjectName = in.LeaderElection.LockObjectName
+	if err := componentbaseconfigv1alpha1.Convert_config_ClientConnectionConfiguration_To_v1alpha1_ClientConnectionConfiguration(&in.ClientConnection, &out.ClientConnection, s); err != nil {
+		return err
+	}
+	out.DefaultOpaqueMetaDomains = in.DefaultOpaqueMetaDomains
+	out.ExtraSyncingResources = in.ExtraSyncingResources
+	out.DisableServiceAccountToken = pointer.BoolPtr(in.DisableServiceAccountToken)
+	out.DisablePodServiceLinks = in.DisablePodServiceLinks
+	out.ExtraNodeLabels = in.ExtraNodeLabels
+	out.OpaqueTaintKeys = in.OpaqueTaintKeys
+	out.VNAgentPort = pointer.Int32Ptr(in.VNAgentPort)
+	out.VNAgentNamespacedName = in.VNAgentNamespacedName
+	out.VNAgentLabelSelector = in.VNAgentLabelSelector
+	out.SuperClusterNodePortRange = in.SuperClusterNodePortRange
+	out.TenantNodePortRangeSize = in.TenantNodePortRangeSize
+	out.TenantClusterNodePortRange = in.TenantClusterNodePortRange
+	out.NamingRegistry = in.NamingRegistry
+	out.TracingEndpoint = in.TracingEndpoint
+	out.TracingInsecure = in.TracingInsecure
+	ratio := in.TracingSamplingRatio
+	out.TracingSamplingRatio = &ratio
+	out.TenantMetrics = in.TenantMetrics
+	out.TenantMetricsAllowList = in.TenantMetricsAllowList
+	out.TenantMetricsMaxTenants = pointer.Int32Ptr(int32(in.TenantMetricsMaxTenants))
+	out.FeatureGates = in.FeatureGates
+	out.Timeout = in.Timeout
+	out.DNSOptions = in.DNSOptions
+	return nil
+}
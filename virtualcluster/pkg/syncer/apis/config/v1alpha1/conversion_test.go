@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+// fill sets every field reachable from v to a non-zero value, except the
+// fields named in skip.
+func fill(v reflect.Value, skip map[string]bool) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		v.SetInt(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(0.5)
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), skip)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), skip)
+	case reflect.Map:
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(key, skip)
+		fill(elem, skip)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !skip[v.Type().Field(i).Name] {
+				fill(v.Field(i), skip)
+			}
+		}
+	}
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := config.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add internal types: %v", err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 types: %v", err)
+	}
+	// the type meta is set by the codecs and the rest config is only built at runtime.
+	skip := map[string]bool{"TypeMeta": true, "RestConfig": true}
+
+	internal := &config.SyncerConfiguration{}
+	fill(reflect.ValueOf(internal).Elem(), skip)
+	versioned := &SyncerConfiguration{}
+	if err := scheme.Convert(internal, versioned, nil); err != nil {
+		t.Fatalf("failed to convert to v1alpha1: %v", err)
+	}
+	roundTrip := &config.SyncerConfiguration{}
+	if err := scheme.Convert(versioned, roundTrip, nil); err != nil {
+		t.Fatalf("failed to convert from v1alpha1: %v", err)
+	}
+	if !reflect.DeepEqual(internal, roundTrip) {
+		t.Errorf("internal configuration changed by the round trip:\nexpected %+v\ngot      %+v", internal, roundTrip)
+	}
+
+	versioned = &SyncerConfiguration{}
+	fill(reflect.ValueOf(versioned).Elem(), skip)
+	internal = &config.SyncerConfiguration{}
+	if err := scheme.Convert(versioned, internal, nil); err != nil {
+		t.Fatalf("failed to convert from v1alpha1: %v", err)
+	}
+	versionedRoundTrip := &SyncerConfiguration{}
+	if err := scheme.Convert(internal, versionedRoundTrip, nil); err != nil {
+		t.Fatalf("failed to convert to v1alpha1: %v", err)
+	}
+	if !reflect.DeepEqual(versioned, versionedRoundTrip) {
+		t.Errorf("v1alpha1 configuration changed by the round trip:\nexpected %+v\ngot      %+v", versioned, versionedRoundTrip)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+	"k8s.io/utils/pointer"
+)
+
+// SetDefaults_SyncerConfiguration sets the same defaults as the flags of the syncer.
+func SetDefaults_SyncerConfiguration(obj *SyncerConfiguration) {
+	if obj.LeaderElection.ResourceLock == "" {
+		obj.LeaderElection.ResourceLock = resourcelock.ConfigMapsResourceLock
+	}
+	componentbaseconfigv1alpha1.RecommendedDefaultLeaderElectionConfiguration(&obj.LeaderElection.LeaderElectionConfiguration)
+	if obj.LeaderElection.LockObjectName == "" {
+		obj.LeaderElection.LockObjectName = "syncer-leaderelection-lock"
+	}
+	if obj.DisableServiceAccountToken == nil {
+		obj.DisableServiceAccountToken = pointer.BoolPtr(true)
+	}
+	if obj.DefaultOpaqueMetaDomains == nil {
+		obj.DefaultOpaqueMetaDomains = []string{"kubernetes.io", "k8s.io"}
+	}
+	if obj.VNAgentPort == nil {
+		obj.VNAgentPort = pointer.Int32Ptr(10550)
+	}
+	if obj.VNAgentNamespacedName == "" {
+		obj.VNAgentNamespacedName = "vc-manager/vn-agent"
+	}
+	if obj.VNAgentLabelSelector == "" {
+		obj.VNAgentLabelSelector = "app=vn-agent"
+	}
+	if obj.SuperClusterNodePortRange == "" {
+		obj.SuperClusterNodePortRange = "30000-32767"
+	}
+	if obj.TenantClusterNodePortRange == "" {
+		obj.TenantClusterNodePortRange = "30000-32767"
+	}
+	if obj.NamingRegistry == "" {
+		obj.NamingRegistry = "vc-manager/vc-naming-registry"
+	}
+	if obj.TracingSamplingRatio == nil {
+		ratio := float64(1)
+		obj.TracingSamplingRatio = &ratio
+	}
+	if obj.TenantMetricsMaxTenants == nil {
+		obj.TenantMetricsMaxTenants = pointer.Int32Ptr(100)
+	}
+	if obj.DNSOptions == nil {
+		obj.DNSOptions = []corev1.PodDNSConfigOption{{Name: "ndots", Value: pointer.StringPtr("5")}}
+	}
+}
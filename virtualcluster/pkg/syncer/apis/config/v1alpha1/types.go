@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+)
+
+// SyncerConfiguration configures a syncer. Settings which are safe to change
+// at runtime are reloaded when the configuration file changes, changes of any
+// other setting are rejected until the syncer is restarted.
+type SyncerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// LeaderElection defines the configuration of leader election client.
+	LeaderElection SyncerLeaderElectionConfiguration `json:"leaderElection"`
+
+	// ClientConnection specifies the kubeconfig file and client connection
+	// settings for the proxy server to use when communicating with the apiserver.
+	ClientConnection componentbaseconfigv1alpha1.ClientConnectionConfiguration `json:"clientConnection"`
+
+	// DefaultOpaqueMetaDomains is the default configuration for each Virtual Cluster.
+	// The key prefix of labels or annotations match this domain would be invisible to Virtual Cluster but
+	// are kept in super cluster. Defaults to ["kubernetes.io", "k8s.io"]. Reloadable.
+	DefaultOpaqueMetaDomains []string `json:"defaultOpaqueMetaDomains,omitempty"`
+
+	// ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster.
+	ExtraSyncingResources []string `json:"extraSyncingResources,omitempty"`
+
+	// DisableServiceAccountToken indicates whether to disable super cluster service account tokens being auto generated
+	// and mounted in vc pods. Defaults to true.
+	DisableServiceAccountToken *bool `json:"disableServiceAccountToken,omitempty"`
+
+	// DisablePodServiceLinks indicates whether to disable the `EnableServiceLinks` field in pPod spec.
+	DisablePodServiceLinks bool `json:"disablePodServiceLinks,omitempty"`
+
+	// ExtraNodeLabels is the list of extra labels to be synced to vNode from the super cluster. Reloadable.
+	ExtraNodeLabels []string `json:"extraNodeLabels,omitempty"`
+
+	// OpaqueTaintKeys is the list of taint keys to be synced to vNode from the super cluster. Reloadable.
+	OpaqueTaintKeys []string `json:"opaqueTaintKeys,omitempty"`
+
+	// VNAgentPort defines the port that the VN Agent is running on per host. Defaults to 10550.
+	VNAgentPort *int32 `json:"vnAgentPort,omitempty"`
+
+	// VNAgentNamespacedName defines the namespace/name of the VN Agent Kubernetes
+	// service, this is used for feature VNodeProviderService. Defaults to "vc-manager/vn-agent".
+	VNAgentNamespacedName string `json:"vnAgentNamespacedName,omitempty"`
+
+	// VNAgentLabelSelector defines the label of the VN Agent Kubernetes pods, this
+	// is used for the feature VNodeProviderPodIP. Defaults to "app=vn-agent".
+	VNAgentLabelSelector string `json:"vnAgentLabelSelector,omitempty"`
+
+	// SuperClusterNodePortRange is the NodePort range of the super cluster. Defaults to "30000-32767".
+	SuperClusterNodePortRange string `json:"superClusterNodePortRange,omitempty"`
+
+	// TenantNodePortRangeSize is the number of NodePorts in the slice of SuperClusterNodePortRange
+	// each Virtual Cluster is given. 0 lets the super cluster allocate NodePorts freely.
+	TenantNodePortRangeSize int32 `json:"tenantNodePortRangeSize,omitempty"`
+
+	// TenantClusterNodePortRange is the --service-node-port-range of the tenant apiservers. Only the
+	// tenant NodePorts outside of it are honored as requested. Defaults to "30000-32767".
+	TenantClusterNodePortRange string `json:"tenantClusterNodePortRange,omitempty"`
+
+	// NamingRegistry is the namespace/name of the naming registry ConfigMap of the super cluster.
+	// Defaults to "vc-manager/vc-naming-registry".
+	NamingRegistry string `json:"namingRegistry,omitempty"`
+
+	// TracingEndpoint is the host:port of the OTLP/HTTP collector the syncer exports traces to.
+	// Tracing is disabled if it is empty.
+	TracingEndpoint string `json:"tracingEndpoint,omitempty"`
+
+	// TracingInsecure indicates whether to export traces to TracingEndpoint over plain HTTP.
+	TracingInsecure bool `json:"tracingInsecure,omitempty"`
+
+	// TracingSamplingRatio is the fraction of syncs traced, between 0 and 1. Defaults to 1.
+	TracingSamplingRatio *float64 `json:"tracingSamplingRatio,omitempty"`
+
+	// TenantMetrics breaks the syncer metrics down by VirtualCluster in the vc_name label.
+	TenantMetrics bool `json:"tenantMetrics,omitempty"`
+
+	// TenantMetricsAllowList is the namespace/name of the VirtualClusters which always get their
+	// own vc_name when TenantMetrics is enabled.
+	TenantMetricsAllowList []string `json:"tenantMetricsAllowList,omitempty"`
+
+	// TenantMetricsMaxTenants is the number of other VirtualClusters getting their own vc_name.
+	// Defaults to 100.
+	TenantMetricsMaxTenants *int32 `json:"tenantMetricsMaxTenants,omitempty"`
+
+	// FeatureGates enabled by the user. Only the feature gates checked on each sync are reloadable.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// Timeout is the maximum length of time to wait before giving up on a super cluster request.
+	// A value of "" means use default.
+	Timeout string `json:"timeout,omitempty"`
+
+	// DNSOptions are the DNS options in resolv.conf that are attached to pods. Defaults to ndots:5. Reloadable.
+	DNSOptions []corev1.PodDNSConfigOption `json:"dnsOptions,omitempty"`
+}
+
+// SyncerLeaderElectionConfiguration expands LeaderElectionConfiguration
+// to include syncer specific configuration.
+type SyncerLeaderElectionConfiguration struct {
+	componentbaseconfigv1alpha1.LeaderElectionConfiguration `json:",inline"`
+	// LockObjectNamespace defines the namespace of the lock object
+	LockObjectNamespace string `json:"lockObjectNamespace,omitempty"`
+	// LockObjectName defines the lock object name
+	LockObjectName string `json:"lockObjectName,omitempty"`
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strings"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	componentbasevalidation "k8s.io/component-base/config/validation"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+)
+
+// ValidateSyncerConfiguration validates the syncer configuration, whether it
+// is loaded from a file or set by flags.
+func ValidateSyncerConfiguration(c *config.SyncerConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateLeaderElection(&c.LeaderElection, field.NewPath("leaderElection"))...)
+	allErrs = append(allErrs, componentbasevalidation.ValidateClientConnectionConfiguration(&c.ClientConnection, field.NewPath("clientConnection"))...)
+
+	if c.VNAgentPort < 1 || c.VNAgentPort > 65535 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("vnAgentPort"), c.VNAgentPort, "must be between 1 and 65535"))
+	}
+	if parts := strings.Split(c.VNAgentNamespacedName, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("vnAgentNamespacedName"), c.VNAgentNamespacedName, "must be in the form namespace/name"))
+	}
+	if _, err := utilnet.ParsePortRange(c.SuperClusterNodePortRange); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("superClusterNodePortRange"), c.SuperClusterNodePortRange, err.Error()))
+	}
+	if _, err := utilnet.ParsePortRange(c.TenantClusterNodePortRange); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("tenantClusterNodePortRange"), c.TenantClusterNodePortRange, err.Error()))
+	}
+	if c.TenantNodePortRangeSize < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("tenantNodePortRangeSize"), c.TenantNodePortRangeSize, "must be non-negative"))
+	}
+	if c.NamingRegistry != "" {
+		if parts := strings.Split(c.NamingRegistry, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("namingRegistry"), c.NamingRegistry, "must be in the form namespace/name"))
+		}
+	}
+	if c.TracingSamplingRatio < 0 || c.TracingSamplingRatio > 1 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("tracingSamplingRatio"), c.TracingSamplingRatio, "must be between 0 and 1"))
+	}
+	if c.TenantMetricsMaxTenants < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("tenantMetricsMaxTenants"), c.TenantMetricsMaxTenants, "must be non-negative"))
+	}
+	if _, err := featuregate.NewFeatureGate(c.FeatureGates); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("featureGates"), c.FeatureGates, err.Error()))
+	}
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("timeout"), c.Timeout, err.Error()))
+		}
+	}
+	for i, option := range c.DNSOptions {
+		if option.Name == "" {
+			allErrs = append(allErrs, field.Required(field.NewPath("dnsOptions").Index(i).Child("name"), ""))
+		}
+	}
+
+	return allErrs
+}
+
+// validateLeaderElection validates the leader election settings. The lock
+// namespace is not required as it defaults to the namespace of the syncer pod.
+func validateLeaderElection(l *config.SyncerLeaderElectionConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if !l.LeaderElect {
+		return allErrs
+	}
+	if l.LeaseDuration.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("leaseDuration"), l.LeaseDuration, "must be greater than zero"))
+	}
+	if l.RenewDeadline.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("renewDeadline"), l.RenewDeadline, "must be greater than zero"))
+	}
+	if l.RetryPeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retryPeriod"), l.RetryPeriod, "must be greater than zero"))
+	}
+	if l.LeaseDuration.Duration < l.RenewDeadline.Duration {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("leaseDuration"), l.LeaseDuration, "must be greater than renewDeadline"))
+	}
+	if l.ResourceLock == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("resourceLock"), ""))
+	}
+	return allErrs
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conflict resolves the conflicts between tenant objects and the
+// super control plane objects they are synced to.
+package conflict
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+const (
+	// ReasonOwnershipConflict is the reason of the events reporting a conflict.
+	ReasonOwnershipConflict = "OwnershipConflict"
+	// ReasonAdopted is the reason of the events reporting an adopted object.
+	ReasonAdopted = "Adopted"
+	// ReasonRecreated is the reason of the events reporting a recreated object.
+	ReasonRecreated = "Recreated"
+
+	// conflictTTL is how long a conflict which is not checked anymore is
+	// remembered, e.g. once the object or its tenant object is deleted. The
+	// retries and the patrol check the pending conflicts much more often.
+	conflictTTL = time.Hour
+)
+
+// Funcs patches and deletes the super control plane objects of a resource.
+type Funcs struct {
+	// Patch applies a JSON merge patch to an object.
+	Patch func(namespace, name string, data []byte) (runtime.Object, error)
+	// Delete deletes an object.
+	Delete func(namespace, name string, opts metav1.DeleteOptions) error
+}
+
+// Resolver applies the ConflictPolicy of the virtual clusters to the super
+// control plane objects of a downward syncer which are not delegated by the
+// tenant object they are synced from.
+type Resolver struct {
+	mc    *mc.MultiClusterController
+	funcs Funcs
+	clock clock.Clock
+
+	mu sync.Mutex
+	// conflicts records when the conflicts of the objects to recreate were
+	// seen first and last.
+	conflicts map[types.UID]*conflict
+}
+
+type conflict struct {
+	since    time.Time
+	lastSeen time.Time
+}
+
+// NewResolver returns the resolver of the objects synced by mcc.
+func NewResolver(mcc *mc.MultiClusterController, funcs Funcs) *Resolver {
+	return &Resolver{
+		mc:        mcc,
+		funcs:     funcs,
+		clock:     clock.RealClock{},
+		conflicts: make(map[types.UID]*conflict),
+	}
+}
+
+// Check returns the super control plane object to update from vObj. pObj is
+// returned if it is delegated by vObj. Otherwise the conflict policy of the
+// virtual cluster is applied, returning the adopted object or an error until
+// the conflict is resolved.
+func (r *Resolver) Check(clusterName, requestUID string, vObj, pObj client.Object) (client.Object, error) {
+	delegatedUID := pObj.GetAnnotations()[constants.LabelUID]
+	if delegatedUID == requestUID || delegatedUID == string(vObj.GetUID()) {
+		r.forget(pObj.GetUID())
+		return pObj, r.setStatus(clusterName, vObj, "")
+	}
+
+	kind := r.mc.GetObjectKind()
+	conflictErr := fmt.Errorf("p%s %s delegated UID is different from updated object", kind, objectKey(pObj))
+
+	vc, err := util.GetVirtualClusterObject(r.mc, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	action, gracePeriod := v1alpha1.ConflictActionFail, time.Duration(0)
+	if policy := vc.Spec.ConflictPolicy; policy != nil {
+		action, gracePeriod = policy.Action, time.Duration(policy.GracePeriodSeconds)*time.Second
+	}
+	message := fmt.Sprintf("%s %s in super control plane is not delegated by this object", kind, objectKey(pObj))
+	if owner := pObj.GetAnnotations()[constants.LabelCluster]; owner != "" && owner != clusterName {
+		// Never touch the objects of another tenant.
+		action = v1alpha1.ConflictActionFail
+		message = fmt.Sprintf("%s %s in super control plane belongs to another virtual cluster", kind, objectKey(pObj))
+	}
+
+	switch action {
+	case v1alpha1.ConflictActionAdopt:
+		return r.adopt(clusterName, vObj, pObj)
+	case v1alpha1.ConflictActionRecreate:
+		if pObj.GetDeletionTimestamp() != nil {
+			return nil, fmt.Errorf("%v, waiting for its deletion", conflictErr)
+		}
+		deadline := r.firstSeen(pObj.GetUID()).Add(gracePeriod)
+		if r.clock.Now().Before(deadline) {
+			if _, err := r.report(clusterName, vObj, fmt.Sprintf("%s, it is recreated after %s", message, deadline.UTC().Format(time.RFC3339))); err != nil {
+				return nil, err
+			}
+			return nil, conflictErr
+		}
+		if err := r.recreate(clusterName, vObj, pObj); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%v, deleted it to be recreated", conflictErr)
+	default:
+		reported, err := r.report(clusterName, vObj, message)
+		if err != nil {
+			return nil, err
+		}
+		if reported {
+			metrics.RecordOwnershipConflict(kind, clusterName, string(v1alpha1.ConflictActionFail))
+		}
+		return nil, conflictErr
+	}
+}
+
+// adopt makes vObj the delegator of pObj.
+func (r *Resolver) adopt(clusterName string, vObj, pObj client.Object) (client.Object, error) {
+	tenantNamespace := vObj.GetNamespace()
+	if tenantNamespace == "" {
+		// The super control plane namespaces are delegated by tenant namespaces.
+		tenantNamespace = vObj.GetName()
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				constants.LabelCluster:   clusterName,
+				constants.LabelUID:       string(vObj.GetUID()),
+				constants.LabelNamespace: tenantNamespace,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj, err := r.funcs.Patch(pObj.GetNamespace(), pObj.GetName(), data)
+	if err != nil {
+		return nil, err
+	}
+	adopted, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+
+	kind := r.mc.GetObjectKind()
+	klog.Infof("adopted %s %s of cluster %s in super control plane", kind, objectKey(pObj), clusterName)
+	metrics.RecordOwnershipConflict(kind, clusterName, string(v1alpha1.ConflictActionAdopt))
+	if err := r.mc.Eventf(clusterName, objectReference(kind, vObj), corev1.EventTypeNormal, ReasonAdopted,
+		"Adopted %s %s in super control plane", kind, objectKey(pObj)); err != nil {
+		klog.Warningf("failed to record adoption of %s %s: %v", kind, objectKey(pObj), err)
+	}
+	return adopted, r.setStatus(clusterName, vObj, "")
+}
+
+// recreate deletes pObj, so that it is created again from vObj.
+func (r *Resolver) recreate(clusterName string, vObj, pObj client.Object) error {
+	err := r.funcs.Delete(pObj.GetNamespace(), pObj.GetName(), metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(pObj.GetUID())),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	r.forget(pObj.GetUID())
+	if err != nil {
+		return nil
+	}
+
+	kind := r.mc.GetObjectKind()
+	klog.Infof("deleted conflicting %s %s of cluster %s in super control plane", kind, objectKey(pObj), clusterName)
+	metrics.RecordOwnershipConflict(kind, clusterName, string(v1alpha1.ConflictActionRecreate))
+	if err := r.mc.Eventf(clusterName, objectReference(kind, vObj), corev1.EventTypeNormal, ReasonRecreated,
+		"Deleted conflicting %s %s in super control plane to recreate it", kind, objectKey(pObj)); err != nil {
+		klog.Warningf("failed to record recreation of %s %s: %v", kind, objectKey(pObj), err)
+	}
+	return nil
+}
+
+// report records message in an event and in the sync conflict annotation of
+// vObj, unless it is already recorded. It returns whether it is recorded.
+func (r *Resolver) report(clusterName string, vObj client.Object, message string) (bool, error) {
+	if vObj.GetAnnotations()[constants.LabelSyncConflict] == message {
+		return false, nil
+	}
+	kind := r.mc.GetObjectKind()
+	klog.Warningf("%s %s of cluster %s conflicts: %s", kind, objectKey(vObj), clusterName, message)
+	if err := r.mc.Eventf(clusterName, objectReference(kind, vObj), corev1.EventTypeWarning, ReasonOwnershipConflict, "%s", message); err != nil {
+		return false, err
+	}
+	return true, r.setStatus(clusterName, vObj, message)
+}
+
+// setStatus records message in the sync conflict annotation of vObj. An empty
+// message removes the annotation.
+func (r *Resolver) setStatus(clusterName string, vObj client.Object, message string) error {
+	if vObj.GetAnnotations()[constants.LabelSyncConflict] == message {
+		return nil
+	}
+	cluster := r.mc.GetCluster(clusterName)
+	if cluster == nil {
+		return fmt.Errorf("cluster %s not found", clusterName)
+	}
+	tenantClient, err := cluster.GetDelegatingClient()
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	var value interface{}
+	if message != "" {
+		value = message
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{constants.LabelSyncConflict: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return tenantClient.Patch(context.TODO(), vObj.DeepCopyObject().(client.Object), client.RawPatch(types.MergePatchType, data))
+}
+
+// firstSeen returns when the conflict of the object uid was seen first. The
+// conflicts not seen for conflictTTL are forgotten.
+func (r *Resolver) firstSeen(uid types.UID) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	for other, c := range r.conflicts {
+		if now.Sub(c.lastSeen) > conflictTTL {
+			delete(r.conflicts, other)
+		}
+	}
+	c, ok := r.conflicts[uid]
+	if !ok {
+		c = &conflict{since: now}
+		r.conflicts[uid] = c
+	}
+	c.lastSeen = now
+	return c.since
+}
+
+// forget forgets the conflict of the object uid.
+func (r *Resolver) forget(uid types.UID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conflicts, uid)
+}
+
+func objectKey(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func objectReference(kind string, obj client.Object) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind:      kind,
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		UID:       obj.GetUID(),
+	}
+}
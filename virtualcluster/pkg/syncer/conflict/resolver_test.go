@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conflict
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type fakeReconciler struct{}
+
+func (r *fakeReconciler) Reconcile(reconciler.Request) (reconciler.Result, error) {
+	return reconciler.Result{}, nil
+}
+
+func testTenant(policy *v1alpha1.ConflictPolicy) *v1alpha1.VirtualCluster {
+	return &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			ConflictPolicy: policy,
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+}
+
+func tenantConfigMap(uid, conflict string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cm",
+			Namespace: "default",
+			UID:       types.UID(uid),
+		},
+	}
+	if conflict != "" {
+		cm.Annotations = map[string]string{constants.LabelSyncConflict: conflict}
+	}
+	return cm
+}
+
+func superConfigMap(namespace, uid, clusterKey string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cm",
+			Namespace: namespace,
+			UID:       "super-uid",
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+// newTestResolver returns a resolver of the configmaps of vc, and the clients
+// of the super and tenant control planes.
+func newTestResolver(t *testing.T, vc *v1alpha1.VirtualCluster, vObj, pObj runtime.Object) (*Resolver, *fake.Clientset, client.Client) {
+	mcc, err := mc.NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeReconciler{})
+	if err != nil {
+		t.Fatalf("failed to create mccontroller: %v", err)
+	}
+	tenantClient := fakeclient.NewClientBuilder().WithRuntimeObjects(vObj).Build()
+	tenantCluster := cluster.NewFakeTenantCluster(vc, fake.NewSimpleClientset(vObj), tenantClient)
+	if err := mcc.RegisterClusterResource(tenantCluster, mc.WatchOptions{}); err != nil {
+		t.Fatalf("failed to register cluster: %v", err)
+	}
+
+	superClient := fake.NewSimpleClientset(pObj)
+	r := NewResolver(mcc, Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return superClient.CoreV1().ConfigMaps(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return superClient.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
+	superClient.ClearActions()
+	return r, superClient, tenantClient
+}
+
+func syncConflict(t *testing.T, tenantClient client.Client) string {
+	cm := &corev1.ConfigMap{}
+	if err := tenantClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "cm"}, cm); err != nil {
+		t.Fatalf("failed to get tenant configmap: %v", err)
+	}
+	return cm.Annotations[constants.LabelSyncConflict]
+}
+
+func TestCheck(t *testing.T) {
+	clusterKey := conversion.ToClusterKey(testTenant(nil))
+	superNamespace := conversion.ToSuperClusterNamespace(clusterKey, "default")
+	adopt := &v1alpha1.ConflictPolicy{Action: v1alpha1.ConflictActionAdopt}
+
+	testcases := map[string]struct {
+		policy              *v1alpha1.ConflictPolicy
+		vObj                *corev1.ConfigMap
+		pObj                *corev1.ConfigMap
+		expectedError       string
+		expectedDelegated   string
+		expectedConflict    string
+		expectedSuperAction string
+	}{
+		"delegated": {
+			vObj:              tenantConfigMap("12345", ""),
+			pObj:              superConfigMap(superNamespace, "12345", clusterKey),
+			expectedDelegated: "12345",
+		},
+		"delegated clears conflict": {
+			vObj:              tenantConfigMap("12345", "conflict"),
+			pObj:              superConfigMap(superNamespace, "12345", clusterKey),
+			expectedDelegated: "12345",
+		},
+		"fail by default": {
+			vObj:             tenantConfigMap("12345", ""),
+			pObj:             superConfigMap(superNamespace, "123456", clusterKey),
+			expectedError:    "delegated UID is different",
+			expectedConflict: "is not delegated by this object",
+		},
+		"fail": {
+			policy:           &v1alpha1.ConflictPolicy{Action: v1alpha1.ConflictActionFail},
+			vObj:             tenantConfigMap("12345", ""),
+			pObj:             superConfigMap(superNamespace, "123456", clusterKey),
+			expectedError:    "delegated UID is different",
+			expectedConflict: "is not delegated by this object",
+		},
+		"adopt": {
+			policy:              adopt,
+			vObj:                tenantConfigMap("12345", "conflict"),
+			pObj:                superConfigMap(superNamespace, "123456", clusterKey),
+			expectedDelegated:   "12345",
+			expectedSuperAction: "patch",
+		},
+		"adopt object without cluster": {
+			policy:              adopt,
+			vObj:                tenantConfigMap("12345", ""),
+			pObj:                superConfigMap(superNamespace, "123456", ""),
+			expectedDelegated:   "12345",
+			expectedSuperAction: "patch",
+		},
+		"never adopt object of another cluster": {
+			policy:           adopt,
+			vObj:             tenantConfigMap("12345", ""),
+			pObj:             superConfigMap(superNamespace, "123456", "another-cluster"),
+			expectedError:    "delegated UID is different",
+			expectedConflict: "belongs to another virtual cluster",
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			r, superClient, tenantClient := newTestResolver(t, testTenant(tc.policy), tc.vObj, tc.pObj)
+
+			obj, err := r.Check(clusterKey, string(tc.vObj.UID), tc.vObj, tc.pObj)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			} else if delegated := obj.GetAnnotations()[constants.LabelUID]; delegated != tc.expectedDelegated {
+				t.Errorf("expected object delegated by %q, got %q", tc.expectedDelegated, delegated)
+			}
+
+			if conflict := syncConflict(t, tenantClient); !strings.Contains(conflict, tc.expectedConflict) || (tc.expectedConflict == "") != (conflict == "") {
+				t.Errorf("expected sync conflict %q, got %q", tc.expectedConflict, conflict)
+			}
+
+			actions := superClient.Actions()
+			if tc.expectedSuperAction == "" {
+				if len(actions) != 0 {
+					t.Errorf("expected no super action, got %v", actions)
+				}
+			} else if len(actions) != 1 || actions[0].GetVerb() != tc.expectedSuperAction {
+				t.Errorf("expected %s super action, got %v", tc.expectedSuperAction, actions)
+			}
+		})
+	}
+}
+
+func TestCheckRecreate(t *testing.T) {
+	vc := testTenant(&v1alpha1.ConflictPolicy{Action: v1alpha1.ConflictActionRecreate, GracePeriodSeconds: 60})
+	clusterKey := conversion.ToClusterKey(vc)
+	vObj := tenantConfigMap("12345", "")
+	pObj := superConfigMap(conversion.ToSuperClusterNamespace(clusterKey, "default"), "123456", clusterKey)
+
+	r, superClient, tenantClient := newTestResolver(t, vc, vObj, pObj)
+	fakeClock := clock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+	var deleteOptions metav1.DeleteOptions
+	deleteFunc := r.funcs.Delete
+	r.funcs.Delete = func(namespace, name string, opts metav1.DeleteOptions) error {
+		deleteOptions = opts
+		return deleteFunc(namespace, name, opts)
+	}
+
+	if _, err := r.Check(clusterKey, string(vObj.UID), vObj, pObj); err == nil || !strings.Contains(err.Error(), "delegated UID is different") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+	if conflict := syncConflict(t, tenantClient); !strings.Contains(conflict, "it is recreated after") {
+		t.Errorf("expected sync conflict with recreation time, got %q", conflict)
+	}
+	if actions := superClient.Actions(); len(actions) != 0 {
+		t.Errorf("expected no super action during the grace period, got %v", actions)
+	}
+
+	fakeClock.Step(61 * time.Second)
+	if _, err := r.Check(clusterKey, string(vObj.UID), vObj, pObj); err == nil || !strings.Contains(err.Error(), "deleted it to be recreated") {
+		t.Errorf("expected recreation error, got %v", err)
+	}
+	actions := superClient.Actions()
+	if len(actions) != 1 || !actions[0].Matches("delete", "configmaps") {
+		t.Fatalf("expected delete super action, got %v", actions)
+	}
+	if preconditions := deleteOptions.Preconditions; preconditions == nil || preconditions.UID == nil || *preconditions.UID != pObj.UID {
+		t.Errorf("expected delete precondition on uid %s, got %v", pObj.UID, deleteOptions.Preconditions)
+	}
+	if len(r.conflicts) != 0 {
+		t.Errorf("expected the conflict to be forgotten, got %v", r.conflicts)
+	}
+}
+
+func TestCheckRecreateForgotten(t *testing.T) {
+	vc := testTenant(&v1alpha1.ConflictPolicy{Action: v1alpha1.ConflictActionRecreate, GracePeriodSeconds: 60})
+	clusterKey := conversion.ToClusterKey(vc)
+	vObj := tenantConfigMap("12345", "")
+	pObj := superConfigMap(conversion.ToSuperClusterNamespace(clusterKey, "default"), "123456", clusterKey)
+
+	r, _, _ := newTestResolver(t, vc, vObj, pObj)
+	fakeClock := clock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+
+	if _, err := r.Check(clusterKey, string(vObj.UID), vObj, pObj); err == nil {
+		t.Errorf("expected conflict error")
+	}
+	// The conflicting object is deleted by someone else, its conflict is
+	// never checked again.
+	fakeClock.Step(conflictTTL + time.Second)
+	other := superConfigMap(pObj.Namespace, "1234567", clusterKey)
+	other.UID = "other"
+	if _, err := r.Check(clusterKey, string(vObj.UID), vObj, other); err == nil {
+		t.Errorf("expected conflict error")
+	}
+	if _, ok := r.conflicts[pObj.UID]; ok || len(r.conflicts) != 1 {
+		t.Errorf("expected the conflict of the deleted object to be forgotten, got %v", r.conflicts)
+	}
+}
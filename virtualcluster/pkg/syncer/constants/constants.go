@@ -67,6 +67,31 @@ const (
 	// LabelTenantIgnoreSync is used by resources that do not need to be synced.
 	LabelTenantIgnoreSync = "tenancy.x-k8s.io/ignore-sync"
 
+	// LabelEvicted marks a tenant pod whose deletion was initiated by the Eviction API,
+	// so that the syncer evicts the super pod instead of deleting it. The syncer does not set it:
+	// the tenant control planes older than 1.26, which do not add the DisruptionTarget condition
+	// to the evicted pods, need it to be set by whatever evicts the pods.
+	LabelEvicted = "tenancy.x-k8s.io/evicted"
+
+	// LabelPodSecurityLabels records the Pod Security Admission labels the syncer stamped on a super
+	// namespace, comma separated, so that only those are removed once the VirtualCluster has no
+	// pod security policy anymore.
+	LabelPodSecurityLabels = "tenancy.x-k8s.io/pod-security-labels"
+
+	// LabelProjectedToken marks a super secret holding a projected service account token issued
+	// by the tenant control plane for the pod owning the secret.
+	LabelProjectedToken = "tenancy.x-k8s.io/projected-token"
+	// LabelProjectedTokenAudience is the audience of the token in a projected token secret.
+	LabelProjectedTokenAudience = "tenancy.x-k8s.io/projected-token.audience"
+	// LabelProjectedTokenExpirationSeconds is the requested lifetime of the token in a projected token secret.
+	LabelProjectedTokenExpirationSeconds = "tenancy.x-k8s.io/projected-token.expiration-seconds"
+	// LabelProjectedTokenExpiration is the expiration time of the token in a projected token secret.
+	LabelProjectedTokenExpiration = "tenancy.x-k8s.io/projected-token.expiration"
+
+	// LabelTraceContext is the W3C traceparent of the downward sync that created a super object,
+	// so that the traces of the upward syncs of the object link to it.
+	LabelTraceContext = "tenancy.x-k8s.io/trace-context"
+
 	// UwsControllerWorkerHigh is the quantity of the worker routine for a resource that generates high number of uws requests.
 	UwsControllerWorkerHigh = 10
 	// UwsControllerWorkerLow is the quantity of the worker routine for a resource that generates low number of uws requests.
@@ -85,6 +110,9 @@ const (
 
 	// PublicObjectKey is a label key which marks the super control plane object that should be populated to every tenant control plane.
 	PublicObjectKey = "tenancy.x-k8s.io/super.public"
+	// PublicObjectSelectorKey is an annotation key of a public super control plane object whose value is a label selector
+	// of VirtualClusters. The object is only populated to the tenant control planes of the matching VirtualClusters.
+	PublicObjectSelectorKey = "tenancy.x-k8s.io/super.public.selector"
 
 	LabelVirtualNode = "tenancy.x-k8s.io/virtualnode"
 	// LabelSuperClusterID is a label key added to the vNode object in tenant when SuperClusterPooling feature is enabled.
@@ -99,6 +127,21 @@ const (
 	// LabelSuperClusterIP is used to inform the tenant service about the cluster IP used in super control plane.
 	LabelSuperClusterIP = "transparency.tenancy.x-k8s.io/clusterIP"
 
+	// LabelIngressSyncStatus is used to inform the tenant ingress why it is not synced to super control plane.
+	LabelIngressSyncStatus = "transparency.tenancy.x-k8s.io/ingress-sync-status"
+
+	// LabelSuperPDBStatus is used to inform the tenant PodDisruptionBudget about the status of the
+	// super control plane PodDisruptionBudget, in JSON. The status of the tenant object is owned by
+	// the disruption controller of the tenant control plane.
+	LabelSuperPDBStatus = "transparency.tenancy.x-k8s.io/pdb-status"
+
+	// LabelSyncConflict is used to inform the tenant object why it conflicts with the super control plane object.
+	LabelSyncConflict = "transparency.tenancy.x-k8s.io/sync-conflict"
+
+	// LabelServiceImport marks the tenant objects synthesized for a ServiceImport, whose name is the value.
+	// These objects are not synced to super control plane.
+	LabelServiceImport = "tenancy.x-k8s.io/service-import"
+
 	KubeconfigAdminSecretName = "admin-kubeconfig" // #nosec G101 -- This is a secret name
 
 	// RootCACertConfigMapName is name of the configmap which stores certificates
@@ -108,6 +151,10 @@ const (
 	// TenantRootCACertConfigMapName is name of the configmap which stores certificates
 	// to access api-server
 	TenantRootCACertConfigMapName = "tenant-kube-root-ca.crt"
+
+	// VCRootCACertConfigMapName is name of the configmap which publishes the root CA
+	// certificate of a virtual cluster, without its private key, in its root namespace
+	VCRootCACertConfigMapName = "root-ca.crt"
 )
 
 const (
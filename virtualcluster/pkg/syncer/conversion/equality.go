@@ -17,16 +17,20 @@ limitations under the License.
 package conversion
 
 import (
+	"encoding/json"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	v1networking "k8s.io/api/networking/v1"
+	v1node "k8s.io/api/node/v1"
+	v1policy "k8s.io/api/policy/v1"
 	v1scheduling "k8s.io/api/scheduling/v1"
 	v1storage "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/pointer"
 
@@ -69,6 +73,23 @@ func (e vcEquality) CheckPodEquality(pPod, vPod *v1.Pod) *v1.Pod {
 	return updatedPod
 }
 
+// CheckPodEphemeralContainersEquality returns the ephemeral containers of vPod
+// which have not been added to pPod yet. Ephemeral containers can only be added
+// to a pod, so existing ones are never compared.
+func CheckPodEphemeralContainersEquality(pPod, vPod *v1.Pod) []v1.EphemeralContainer {
+	pNames := sets.NewString()
+	for _, c := range pPod.Spec.EphemeralContainers {
+		pNames.Insert(c.Name)
+	}
+	var added []v1.EphemeralContainer
+	for _, c := range vPod.Spec.EphemeralContainers {
+		if !pNames.Has(c.Name) {
+			added = append(added, *c.DeepCopy())
+		}
+	}
+	return added
+}
+
 // CheckDWPodConditionEquality check whether super control plane Pod Status and virtual Pod Status
 // are logically equal.
 // In most cases, the source of truth is super pod status, because super control plane actually
@@ -300,7 +321,7 @@ func isOpaquedKey(config *config.SyncerConfiguration, key string) bool {
 	if len(tokens) < 1 {
 		return false
 	}
-	for _, domain := range config.DefaultOpaqueMetaDomains {
+	for _, domain := range config.GetDefaultOpaqueMetaDomains() {
 		if strings.HasSuffix(tokens[0], domain) {
 			return true
 		}
@@ -645,17 +666,48 @@ func (e vcEquality) CheckCRDEquality(pObj, vObj *apiextensionsv1.CustomResourceD
 	}
 }
 
-func (e vcEquality) CheckIngressEquality(pObj, vObj *v1networking.Ingress) *v1networking.Ingress {
+func (e vcEquality) CheckIngressClassEquality(pObj, vObj *v1networking.IngressClass) *v1networking.IngressClass {
 	pObjCopy := pObj.DeepCopy()
 	pObjCopy.ObjectMeta = vObj.ObjectMeta
-	// pObj.TypeMeta is empty
 	pObjCopy.TypeMeta = vObj.TypeMeta
 
 	if !equality.Semantic.DeepEqual(vObj, pObjCopy) {
 		return pObjCopy
-	} else {
-		return nil
 	}
+	return nil
+}
+
+func (e vcEquality) CheckRuntimeClassEquality(pObj, vObj *v1node.RuntimeClass) *v1node.RuntimeClass {
+	pObjCopy := pObj.DeepCopy()
+	pObjCopy.ObjectMeta = vObj.ObjectMeta
+	pObjCopy.TypeMeta = vObj.TypeMeta
+
+	if !equality.Semantic.DeepEqual(vObj, pObjCopy) {
+		return pObjCopy
+	}
+	return nil
+}
+
+// CheckIngressEquality checks whether super control plane Ingress and virtual Ingress
+// are logically equal. The source of truth is virtual object. Ingress status is
+// managed by super control plane and is not checked.
+func (e vcEquality) CheckIngressEquality(pObj, vObj *v1networking.Ingress) *v1networking.Ingress {
+	var updated *v1networking.Ingress
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec, vObj.Spec) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec = *vObj.Spec.DeepCopy()
+	}
+	return updated
 }
 
 func filterNodePort(svc *v1.Service) *v1.ServiceSpec {
@@ -667,6 +719,26 @@ func filterNodePort(svc *v1.Service) *v1.ServiceSpec {
 	return specClone
 }
 
+// keepTenantNodePorts restores in vSpec and pSpec the NodePorts vObj explicitly
+// requests, i.e. those outside of tenantRange, so that they are compared. The
+// NodePorts allocated by the tenant apiserver from tenantRange are reassigned
+// by the syncer and stay ignored.
+func keepTenantNodePorts(tenantRange *utilnet.PortRange, pObj, vObj *v1.Service, pSpec, vSpec *v1.ServiceSpec) {
+	requested := func(port int32) bool {
+		return port != 0 && !tenantRange.Contains(int(port))
+	}
+	for i := range vObj.Spec.Ports {
+		if i < len(pObj.Spec.Ports) && requested(vObj.Spec.Ports[i].NodePort) {
+			vSpec.Ports[i].NodePort = vObj.Spec.Ports[i].NodePort
+			pSpec.Ports[i].NodePort = pObj.Spec.Ports[i].NodePort
+		}
+	}
+	if requested(vObj.Spec.HealthCheckNodePort) {
+		vSpec.HealthCheckNodePort = vObj.Spec.HealthCheckNodePort
+		pSpec.HealthCheckNodePort = pObj.Spec.HealthCheckNodePort
+	}
+}
+
 func (e vcEquality) CheckServiceEquality(pObj, vObj *v1.Service) *v1.Service {
 	var updated *v1.Service
 	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
@@ -680,6 +752,13 @@ func (e vcEquality) CheckServiceEquality(pObj, vObj *v1.Service) *v1.Service {
 	// Super/tenant service ClusterIP may not be the same
 	vSpec := filterNodePort(vObj)
 	pSpec := filterNodePort(pObj)
+	if e.config != nil && e.config.TenantNodePortRangeSize > 0 {
+		// The NodePorts requested by the tenant are honored with per tenant
+		// NodePort ranges, so changing them must be synced.
+		if tenantRange, err := utilnet.ParsePortRange(e.config.TenantClusterNodePortRange); err == nil {
+			keepTenantNodePorts(tenantRange, pObj, vObj, pSpec, vSpec)
+		}
+	}
 	vSpec.ClusterIP = pSpec.ClusterIP
 	vSpec.ClusterIPs = pSpec.ClusterIPs
 	vSpec.IPFamilies = pSpec.IPFamilies
@@ -744,6 +823,43 @@ func (e vcEquality) CheckUWPVCStatusEquality(pObj, vObj *v1.PersistentVolumeClai
 	return updated
 }
 
+func (e vcEquality) CheckPDBEquality(pObj, vObj *v1policy.PodDisruptionBudget) *v1policy.PodDisruptionBudget {
+	var updated *v1policy.PodDisruptionBudget
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec, vObj.Spec) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec = *vObj.Spec.DeepCopy()
+	}
+	return updated
+}
+
+// CheckUWPDBStatusEquality returns the LabelSuperPDBStatus annotation to be back populated to the
+// tenant PodDisruptionBudget, or nil if it is up to date. The status of the tenant object is left to
+// the disruption controller of the tenant control plane. ObservedGeneration is dropped since the
+// generations of the super control plane and tenant objects are unrelated.
+func (e vcEquality) CheckUWPDBStatusEquality(pObj, vObj *v1policy.PodDisruptionBudget) *string {
+	pStatus := pObj.Status.DeepCopy()
+	pStatus.ObservedGeneration = 0
+	data, err := json.Marshal(pStatus)
+	if err != nil {
+		return nil
+	}
+	status := string(data)
+	if vObj.Annotations[constants.LabelSuperPDBStatus] != status {
+		return &status
+	}
+	return nil
+}
+
 func (e vcEquality) CheckPVSpecEquality(pObj, vObj *v1.PersistentVolumeSpec) *v1.PersistentVolumeSpec {
 	var updatedPVSpec *v1.PersistentVolumeSpec
 	pCopy := pObj.DeepCopy()
@@ -979,3 +979,45 @@ func TestCheckDWPodConditionEquality(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckPodEphemeralContainersEquality(t *testing.T) {
+	debugger := func(name string) v1.EphemeralContainer {
+		return v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: name, Image: "busybox"},
+		}
+	}
+	for _, tt := range []struct {
+		name     string
+		pPod     *v1.Pod
+		vPod     *v1.Pod
+		expected []v1.EphemeralContainer
+	}{
+		{
+			name:     "no ephemeral containers",
+			pPod:     &v1.Pod{},
+			vPod:     &v1.Pod{},
+			expected: nil,
+		},
+		{
+			name: "ephemeral container added",
+			pPod: &v1.Pod{Spec: v1.PodSpec{EphemeralContainers: []v1.EphemeralContainer{debugger("debugger-1")}}},
+			vPod: &v1.Pod{Spec: v1.PodSpec{EphemeralContainers: []v1.EphemeralContainer{debugger("debugger-1"), debugger("debugger-2")}}},
+			expected: []v1.EphemeralContainer{
+				debugger("debugger-2"),
+			},
+		},
+		{
+			name:     "ephemeral containers already added",
+			pPod:     &v1.Pod{Spec: v1.PodSpec{EphemeralContainers: []v1.EphemeralContainer{debugger("debugger-1")}}},
+			vPod:     &v1.Pod{Spec: v1.PodSpec{EphemeralContainers: []v1.EphemeralContainer{debugger("debugger-1")}}},
+			expected: nil,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckPodEphemeralContainersEquality(tt.pPod, tt.vPod)
+			if !equality.Semantic.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -18,22 +18,21 @@ package conversion
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	v1networking "k8s.io/api/networking/v1"
+	v1node "k8s.io/api/node/v1"
 	v1scheduling "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/apimachinery/pkg/util/validation"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	listersv1 "k8s.io/client-go/listers/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,33 +43,35 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 )
 
 // ToClusterKey makes a unique key which is used to create the root namespace in super control plane for a virtual cluster.
-// To avoid name conflict, the key uses the format <namespace>-<hash>-<name>
+// The vc-manager records the key of its naming strategy in the ClusterNamespace status. VirtualClusters without
+// ClusterNamespace use the legacy format <namespace>-<hash>-<name> to avoid name conflict.
 func ToClusterKey(vc *v1alpha1.VirtualCluster) string {
 	// If the ClusterNamespace is set then this will automatically return that prefix allowing us to override
 	// any other hooks for the ClusterNamespace.
 	if vc.Status.ClusterNamespace != "" {
 		return vc.Status.ClusterNamespace
 	}
-	digest := sha256.Sum256([]byte(vc.GetUID()))
-	return vc.GetNamespace() + "-" + hex.EncodeToString(digest[0:])[0:6] + "-" + vc.GetName()
+	return naming.Legacy.ClusterKey(vc)
 }
 
+// ToSuperClusterNamespace returns the namespace in super control plane of the namespace ns of a virtual cluster,
+// using the naming strategy registered for the cluster.
 func ToSuperClusterNamespace(cluster, ns string) string {
-	targetNamespace := strings.Join([]string{cluster, ns}, "-")
-	if len(targetNamespace) > validation.DNS1123LabelMaxLength {
-		digest := sha256.Sum256([]byte(targetNamespace))
-		return targetNamespace[0:57] + "-" + hex.EncodeToString(digest[0:])[0:5]
-	}
-	return targetNamespace
+	return naming.DefaultRegistry.SuperNamespace(cluster, ns)
 }
 
 // GetVirtualNamespace is used to find the corresponding namespace in tenant control plane for objects created in super control plane originally, e.g., events.
+// The namespace annotations are used if the namespace is known, the naming registry otherwise.
 func GetVirtualNamespace(nsLister listersv1.NamespaceLister, pNamespace string) (cluster, namespace string, err error) {
 	vcInfo, err := nsLister.Get(pNamespace)
 	if err != nil {
+		if key, ns, ok := naming.DefaultRegistry.TenantNamespace(pNamespace); ok {
+			return key, ns, nil
+		}
 		return
 	}
 
@@ -325,6 +326,18 @@ func BuildVirtualCRD(cluster string, pCRD *apiextensionsv1.CustomResourceDefinit
 	return vCRD
 }
 
+func BuildVirtualIngressClass(cluster string, pIngressClass *v1networking.IngressClass) *v1networking.IngressClass {
+	vIngressClass := pIngressClass.DeepCopy()
+	ResetMetadata(vIngressClass)
+	return vIngressClass
+}
+
+func BuildVirtualRuntimeClass(cluster string, pRuntimeClass *v1node.RuntimeClass) *v1node.RuntimeClass {
+	vRuntimeClass := pRuntimeClass.DeepCopy()
+	ResetMetadata(vRuntimeClass)
+	return vRuntimeClass
+}
+
 func BuildVirtualPersistentVolume(pPV *v1.PersistentVolume, vPVC *v1.PersistentVolumeClaim) *v1.PersistentVolume {
 	vPV := pPV.DeepCopy()
 	ResetMetadata(vPV)
@@ -184,6 +184,24 @@ func PodMutateDefault(vPod *v1.Pod, saSecretMap map[string]string, services []*v
 	}
 }
 
+// MutateEphemeralContainers applies the env var and service account secret
+// mutations PodMutateDefault applies to regular containers to ephemeral
+// containers added to an existing pPod. DNS settings are shared with the pod
+// and were mutated when the pPod was created.
+func MutateEphemeralContainers(containers []v1.EphemeralContainer, pPod, vPod *v1.Pod, clusterName string, saSecretMap map[string]string, services []*v1.Service) {
+	_, serviceEnv := getServiceEnvVarMap(pPod.Namespace, clusterName, pPod.Spec.EnableServiceLinks, services)
+	for i := range containers {
+		c := v1.Container{
+			Env:          containers[i].Env,
+			VolumeMounts: containers[i].VolumeMounts,
+		}
+		mutateContainerEnv(&c, vPod, serviceEnv)
+		mutateContainerSecret(&c, saSecretMap, vPod)
+		containers[i].Env = c.Env
+		containers[i].VolumeMounts = c.VolumeMounts
+	}
+}
+
 func mutateContainerEnv(c *v1.Container, vPod *v1.Pod, serviceEnvMap map[string]string) {
 	// Inject env var from service
 	// 1. Do nothing if it conflicts with user-defined one.
@@ -36,6 +36,9 @@ const (
 	UWSOperationCounterKey   = "uws_operations_total"
 	UWSOperationDurationKey  = "uws_operations_duration_seconds"
 	ClusterHealthKey         = "virtual_cluster_health"
+	DWSQueueDepthKey         = "dws_queue_depth"
+	SyncedObjectsKey         = "synced_objects"
+	OwnershipConflictsKey    = "ownership_conflicts_total"
 )
 
 var (
@@ -45,7 +48,7 @@ var (
 			Name:      PodOperationsKey,
 			Help:      "Cumulative number of pod operations by operation type.",
 		},
-		[]string{"operation_type", "code"},
+		[]string{"operation_type", "code", "vc_name"},
 	)
 	PodOperationsDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -62,7 +65,7 @@ var (
 			Name:      CheckerMissMatchKey,
 			Help:      "Last checker scan results for mismatched resources.",
 		},
-		[]string{"counter_name"},
+		[]string{"counter_name", "vc_name"},
 	)
 	CheckerRemedyStats = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -111,7 +114,7 @@ var (
 			Name:      UWSOperationCounterKey,
 			Help:      "Cumulative number of upward resource operations.",
 		},
-		[]string{"resource", "code"})
+		[]string{"resource", "vc_name", "code"})
 	ClusterHealthStats = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: ResourceSyncerSubsystem,
@@ -120,6 +123,30 @@ var (
 		},
 		[]string{"status"},
 	)
+	DWSQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      DWSQueueDepthKey,
+			Help:      "Number of downward requests waiting in the queue of each resource syncer, including backoff.",
+		},
+		[]string{"resource", "vc_name"},
+	)
+	SyncedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SyncedObjectsKey,
+			Help:      "Number of tenant objects watched by each resource syncer.",
+		},
+		[]string{"resource", "vc_name"},
+	)
+	OwnershipConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      OwnershipConflictsKey,
+			Help:      "Cumulative number of super control plane objects conflicting with tenant objects by conflict action.",
+		},
+		[]string{"resource", "action", "vc_name"},
+	)
 )
 
 var registerMetrics sync.Once
@@ -137,6 +164,9 @@ func Register() {
 		prometheus.MustRegister(UWSOperationDuration)
 		prometheus.MustRegister(UWSOperationCounter)
 		prometheus.MustRegister(ClusterHealthStats)
+		prometheus.MustRegister(DWSQueueDepth)
+		prometheus.MustRegister(SyncedObjects)
+		prometheus.MustRegister(OwnershipConflicts)
 	})
 }
 
@@ -158,14 +188,22 @@ func RecordUWSOperationDuration(resource string, start time.Time) {
 	UWSOperationDuration.With(prometheus.Labels{"resource": resource}).Observe(SinceInSeconds(start))
 }
 
-func RecordUWSOperationStatus(resource, code string) {
-	UWSOperationCounter.With(prometheus.Labels{"resource": resource, "code": code}).Inc()
+func RecordUWSOperationStatus(resource, superNamespace, code string) {
+	UWSOperationCounter.With(prometheus.Labels{"resource": resource, "vc_name": SuperNamespaceTenantLabel(superNamespace), "code": code}).Inc()
 }
 
 func RecordDWSOperationDuration(resource, cluster string, start time.Time) {
-	DWSOperationDuration.With(prometheus.Labels{"resource": resource, "vc_name": cluster}).Observe(SinceInSeconds(start))
+	DWSOperationDuration.With(prometheus.Labels{"resource": resource, "vc_name": dwsTenantLabel(cluster)}).Observe(SinceInSeconds(start))
 }
 
 func RecordDWSOperationStatus(resource, cluster, code string) {
-	DWSOperationCounter.With(prometheus.Labels{"resource": resource, "vc_name": cluster, "code": code}).Inc()
+	DWSOperationCounter.With(prometheus.Labels{"resource": resource, "vc_name": dwsTenantLabel(cluster), "code": code}).Inc()
+}
+
+func RecordOwnershipConflict(resource, cluster, action string) {
+	OwnershipConflicts.With(prometheus.Labels{"resource": resource, "action": action, "vc_name": dwsTenantLabel(cluster)}).Inc()
+}
+
+func RecordPodOperationStatus(operation, cluster, code string) {
+	PodOperations.With(prometheus.Labels{"operation_type": operation, "code": code, "vc_name": TenantLabel(cluster)}).Inc()
 }
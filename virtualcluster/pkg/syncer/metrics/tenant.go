@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// OtherTenants is the vc_name of the tenants which are not given their own
+// label value, so that the number of series stays bounded.
+const OtherTenants = "other"
+
+// TenantLabelOptions configures the vc_name label of the syncer metrics.
+type TenantLabelOptions struct {
+	// Enabled breaks the syncer metrics down by tenant.
+	Enabled bool
+	// AllowList is the namespace/name of the VirtualClusters which always
+	// get their own vc_name.
+	AllowList []string
+	// MaxTenants is the number of VirtualClusters out of the allow list which
+	// get their own vc_name, in the order they are added to the syncer.
+	MaxTenants int
+}
+
+type tenantLabeler struct {
+	sync.RWMutex
+	TenantLabelOptions
+
+	allowList sets.String
+	// labels maps the cluster key of the added tenants to their vc_name.
+	labels map[string]string
+	// admitted are the tenants labeled out of the allow list.
+	admitted sets.String
+}
+
+var tenants = &tenantLabeler{
+	allowList: sets.NewString(),
+	labels:    make(map[string]string),
+	admitted:  sets.NewString(),
+}
+
+// ConfigureTenantLabels sets the options of the vc_name label. It must be
+// called before any tenant is added.
+func ConfigureTenantLabels(opts TenantLabelOptions) {
+	tenants.Lock()
+	defer tenants.Unlock()
+	tenants.TenantLabelOptions = opts
+	tenants.allowList = sets.NewString(opts.AllowList...)
+}
+
+// TenantLabelsEnabled checks whether the syncer metrics are broken down by tenant.
+func TenantLabelsEnabled() bool {
+	tenants.RLock()
+	defer tenants.RUnlock()
+	return tenants.Enabled
+}
+
+// AddTenant assigns the vc_name of the tenant cluster owned by the
+// VirtualCluster owner, given as namespace/name.
+func AddTenant(cluster, owner string) {
+	tenants.Lock()
+	defer tenants.Unlock()
+	if _, exists := tenants.labels[cluster]; exists {
+		return
+	}
+	switch {
+	case tenants.allowList.Has(owner):
+		tenants.labels[cluster] = cluster
+	case tenants.admitted.Len() < tenants.MaxTenants:
+		tenants.admitted.Insert(cluster)
+		tenants.labels[cluster] = cluster
+	default:
+		tenants.labels[cluster] = OtherTenants
+	}
+}
+
+// RemoveTenant releases the vc_name of the tenant cluster and deletes its series.
+func RemoveTenant(cluster string) {
+	tenants.Lock()
+	delete(tenants.labels, cluster)
+	tenants.admitted.Delete(cluster)
+	tenants.Unlock()
+
+	for _, vec := range []*prometheus.MetricVec{
+		PodOperations.MetricVec,
+		CheckerMissMatchStats.MetricVec,
+		DWSOperationDuration.MetricVec,
+		DWSOperationCounter.MetricVec,
+		UWSOperationCounter.MetricVec,
+		SyncedObjects.MetricVec,
+		OwnershipConflicts.MetricVec,
+	} {
+		deleteSeries(vec, "vc_name", cluster)
+	}
+}
+
+// TenantLabel returns the vc_name of the tenant cluster, which is empty if
+// tenant labels are disabled.
+func TenantLabel(cluster string) string {
+	tenants.RLock()
+	defer tenants.RUnlock()
+	if !tenants.Enabled {
+		return ""
+	}
+	if label, ok := tenants.labels[cluster]; ok {
+		return label
+	}
+	return OtherTenants
+}
+
+// SuperNamespaceTenantLabel returns the vc_name of the tenant owning the super
+// control plane namespace, whose name is prefixed by the cluster key. The
+// cluster key itself is accepted for cluster scoped objects.
+func SuperNamespaceTenantLabel(namespace string) string {
+	tenants.RLock()
+	defer tenants.RUnlock()
+	if !tenants.Enabled {
+		return ""
+	}
+	if label, ok := tenants.labels[namespace]; ok {
+		return label
+	}
+	for i := range namespace {
+		if namespace[i] != '-' {
+			continue
+		}
+		if label, ok := tenants.labels[namespace[:i]]; ok {
+			return label
+		}
+	}
+	return OtherTenants
+}
+
+// dwsTenantLabel keeps the cluster key as the vc_name of the dws metrics if
+// tenant labels are disabled, as these have always been broken down by tenant.
+func dwsTenantLabel(cluster string) string {
+	if !TenantLabelsEnabled() {
+		return cluster
+	}
+	return TenantLabel(cluster)
+}
+
+// deleteSeries deletes the series of vec having value for label.
+func deleteSeries(vec *prometheus.MetricVec, label, value string) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+
+	var matched []prometheus.Labels
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			continue
+		}
+		labels := prometheus.Labels{}
+		for _, pair := range pb.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		if labels[label] == value {
+			matched = append(matched, labels)
+		}
+	}
+	// series can't be deleted while collected.
+	for _, labels := range matched {
+		vec.Delete(labels)
+	}
+}
+
+// MissMatchCounter counts the mismatched objects found by a checker scan and
+// records them in CheckerMissMatchStats by tenant.
+type MissMatchCounter struct {
+	name string
+
+	mu       sync.Mutex
+	counts   map[string]uint64
+	recorded sets.String
+}
+
+func NewMissMatchCounter(name string) *MissMatchCounter {
+	return &MissMatchCounter{
+		name:     name,
+		counts:   make(map[string]uint64),
+		recorded: sets.NewString(),
+	}
+}
+
+// Inc counts a mismatched object of the tenant cluster.
+func (c *MissMatchCounter) Inc(cluster string) {
+	label := TenantLabel(cluster)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[label]++
+}
+
+// Reset drops the counts of an unfinished scan.
+func (c *MissMatchCounter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = make(map[string]uint64)
+}
+
+// Record sets the counts of the finished scan and resets them for the next
+// one. Tenants without mismatch are removed, unless tenant labels are disabled.
+func (c *MissMatchCounter) Record() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !TenantLabelsEnabled() {
+		c.counts[""] += 0
+	}
+	current := sets.NewString()
+	for label, count := range c.counts {
+		current.Insert(label)
+		CheckerMissMatchStats.WithLabelValues(c.name, label).Set(float64(count))
+	}
+	for _, label := range c.recorded.Difference(current).UnsortedList() {
+		CheckerMissMatchStats.DeleteLabelValues(c.name, label)
+	}
+	c.recorded = current
+	c.counts = make(map[string]uint64)
+}
+
+var syncedObjectLabels = struct {
+	sync.Mutex
+	byResource map[string]sets.String
+}{byResource: make(map[string]sets.String)}
+
+// RecordSyncedObjects sets the number of objects of resource watched in each
+// tenant cluster, summed by vc_name.
+func RecordSyncedObjects(resource string, counts map[string]int) {
+	byLabel := make(map[string]int)
+	for cluster, count := range counts {
+		byLabel[TenantLabel(cluster)] += count
+	}
+
+	syncedObjectLabels.Lock()
+	defer syncedObjectLabels.Unlock()
+	current := sets.NewString()
+	for label, count := range byLabel {
+		current.Insert(label)
+		SyncedObjects.WithLabelValues(resource, label).Set(float64(count))
+	}
+	for _, label := range syncedObjectLabels.byResource[resource].Difference(current).UnsortedList() {
+		SyncedObjects.DeleteLabelValues(resource, label)
+	}
+	syncedObjectLabels.byResource[resource] = current
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func setupTenants(opts TenantLabelOptions) {
+	tenants = &tenantLabeler{
+		allowList: sets.NewString(),
+		labels:    make(map[string]string),
+		admitted:  sets.NewString(),
+	}
+	ConfigureTenantLabels(opts)
+}
+
+func TestTenantLabel(t *testing.T) {
+	defer setupTenants(TenantLabelOptions{})
+
+	setupTenants(TenantLabelOptions{})
+	AddTenant("ns-a-vc", "ns/a")
+	if label := TenantLabel("ns-a-vc"); label != "" {
+		t.Errorf("expected no label if disabled, got %q", label)
+	}
+	if label := dwsTenantLabel("ns-a-vc"); label != "ns-a-vc" {
+		t.Errorf("expected dws label to be the cluster if disabled, got %q", label)
+	}
+
+	setupTenants(TenantLabelOptions{Enabled: true, AllowList: []string{"ns/allowed"}, MaxTenants: 1})
+	AddTenant("ns-a-first", "ns/first")
+	AddTenant("ns-b-second", "ns/second")
+	AddTenant("ns-c-allowed", "ns/allowed")
+
+	for cluster, expected := range map[string]string{
+		"ns-a-first":   "ns-a-first",
+		"ns-b-second":  OtherTenants,
+		"ns-c-allowed": "ns-c-allowed",
+		"unknown":      OtherTenants,
+	} {
+		if label := TenantLabel(cluster); label != expected {
+			t.Errorf("expected label %q for %s, got %q", expected, cluster, label)
+		}
+	}
+
+	RemoveTenant("ns-a-first")
+	AddTenant("ns-d-third", "ns/third")
+	if label := TenantLabel("ns-d-third"); label != "ns-d-third" {
+		t.Errorf("expected removed tenant to release its label, got %q", label)
+	}
+
+	for namespace, expected := range map[string]string{
+		"ns-c-allowed-default": "ns-c-allowed",
+		"ns-c-allowed":         "ns-c-allowed",
+		"ns-b-second-default":  OtherTenants,
+		"kube-system":          OtherTenants,
+	} {
+		if label := SuperNamespaceTenantLabel(namespace); label != expected {
+			t.Errorf("expected label %q for namespace %s, got %q", expected, namespace, label)
+		}
+	}
+}
+
+func TestRemoveTenantSeries(t *testing.T) {
+	defer setupTenants(TenantLabelOptions{})
+	setupTenants(TenantLabelOptions{Enabled: true, MaxTenants: 10})
+	AddTenant("ns-a-vc", "ns/a")
+	AddTenant("ns-b-vc", "ns/b")
+
+	DWSOperationCounter.Reset()
+	RecordDWSOperationStatus("Pod", "ns-a-vc", "OK")
+	RecordDWSOperationStatus("Pod", "ns-a-vc", "Error")
+	RecordDWSOperationStatus("Pod", "ns-b-vc", "OK")
+
+	RemoveTenant("ns-a-vc")
+	if n := testutil.CollectAndCount(DWSOperationCounter); n != 1 {
+		t.Errorf("expected series of removed tenant to be deleted, got %d series", n)
+	}
+	if v := testutil.ToFloat64(DWSOperationCounter.WithLabelValues("Pod", "ns-b-vc", "OK")); v != 1 {
+		t.Errorf("expected series of other tenants to be kept, got %v", v)
+	}
+}
+
+func TestMissMatchCounter(t *testing.T) {
+	defer setupTenants(TenantLabelOptions{})
+	setupTenants(TenantLabelOptions{Enabled: true, MaxTenants: 1})
+	AddTenant("ns-a-vc", "ns/a")
+	AddTenant("ns-b-vc", "ns/b")
+
+	CheckerMissMatchStats.Reset()
+	counter := NewMissMatchCounter("SpecMissMatchedPods")
+	counter.Inc("ns-a-vc")
+	counter.Inc("ns-a-vc")
+	counter.Inc("ns-b-vc")
+	counter.Record()
+
+	if v := testutil.ToFloat64(CheckerMissMatchStats.WithLabelValues("SpecMissMatchedPods", "ns-a-vc")); v != 2 {
+		t.Errorf("expected 2 mismatches of ns-a-vc, got %v", v)
+	}
+	if v := testutil.ToFloat64(CheckerMissMatchStats.WithLabelValues("SpecMissMatchedPods", OtherTenants)); v != 1 {
+		t.Errorf("expected 1 mismatch of other tenants, got %v", v)
+	}
+
+	counter.Inc("ns-b-vc")
+	counter.Record()
+	if n := testutil.CollectAndCount(CheckerMissMatchStats); n != 1 {
+		t.Errorf("expected tenants without mismatch to be removed, got %d series", n)
+	}
+}
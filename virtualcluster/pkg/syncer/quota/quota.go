@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// SupportedResources are the resources that can be capped by VirtualCluster.Spec.Quota.
+var SupportedResources = []corev1.ResourceName{
+	corev1.ResourceCPU,
+	corev1.ResourceMemory,
+	corev1.ResourcePods,
+	corev1.ResourceServices,
+	corev1.ResourceRequestsStorage,
+}
+
+// ExceededError is returned when admitting an object would exceed the
+// VirtualCluster quota.
+type ExceededError struct {
+	Requested corev1.ResourceList
+	Used      corev1.ResourceList
+	Limited   corev1.ResourceList
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("exceeded virtual cluster quota: requested: %s, used: %s, limited: %s",
+		format(e.Requested), format(e.Used), format(e.Limited))
+}
+
+// IsExceeded returns true if the error indicates the quota is exceeded.
+func IsExceeded(err error) bool {
+	_, ok := err.(*ExceededError)
+	return ok
+}
+
+// PodUsage returns the quota usage of a pod. Terminated pods do not consume quota.
+func PodUsage(pod *corev1.Pod) corev1.ResourceList {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return corev1.ResourceList{}
+	}
+
+	requests := corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		requests = Add(requests, c.Resources.Requests)
+	}
+	// init containers run sequentially, so the pod needs the larger one of
+	// the biggest init container and the sum of regular containers.
+	for _, c := range pod.Spec.InitContainers {
+		for name, q := range c.Resources.Requests {
+			if cur, ok := requests[name]; !ok || q.Cmp(cur) > 0 {
+				requests[name] = q.DeepCopy()
+			}
+		}
+	}
+	requests = Add(requests, pod.Spec.Overhead)
+
+	usage := corev1.ResourceList{
+		corev1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := requests[name]; ok {
+			usage[name] = q
+		}
+	}
+	return usage
+}
+
+// PVCUsage returns the quota usage of a persistent volume claim.
+func PVCUsage(pvc *corev1.PersistentVolumeClaim) corev1.ResourceList {
+	usage := corev1.ResourceList{}
+	if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		usage[corev1.ResourceRequestsStorage] = q.DeepCopy()
+	}
+	return usage
+}
+
+// ServiceUsage returns the quota usage of a service.
+func ServiceUsage(*corev1.Service) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceServices: *resource.NewQuantity(1, resource.DecimalSI),
+	}
+}
+
+// Add returns the sum of a and b.
+func Add(a, b corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for name, q := range a {
+		result[name] = q.DeepCopy()
+	}
+	for name, q := range b {
+		sum := q.DeepCopy()
+		if cur, ok := result[name]; ok {
+			sum.Add(cur)
+		}
+		result[name] = sum
+	}
+	return result
+}
+
+// Mask returns the subset of list whose names are in names.
+func Mask(list, names corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for name := range names {
+		if q, ok := list[name]; ok {
+			result[name] = q.DeepCopy()
+		} else {
+			result[name] = resource.Quantity{}
+		}
+	}
+	return result
+}
+
+// Check returns an ExceededError if adding requested to used exceeds any of
+// the limits in hard. Resources absent from hard are not limited.
+func Check(hard, used, requested corev1.ResourceList) error {
+	total := Add(used, requested)
+	exceeded := corev1.ResourceList{}
+	for name, req := range requested {
+		limit, ok := hard[name]
+		if !ok || req.IsZero() {
+			continue
+		}
+		if q := total[name]; q.Cmp(limit) > 0 {
+			exceeded[name] = limit
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return &ExceededError{
+		Requested: Mask(requested, exceeded),
+		Used:      Mask(used, exceeded),
+		Limited:   exceeded,
+	}
+}
+
+func format(list corev1.ResourceList) string {
+	parts := make([]string, 0, len(list))
+	for name, q := range list {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
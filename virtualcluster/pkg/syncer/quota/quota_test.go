@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func requests(cpu, memory string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		},
+	}
+}
+
+func TestPodUsage(t *testing.T) {
+	testcases := map[string]struct {
+		pod      *corev1.Pod
+		expected corev1.ResourceList
+	}{
+		"sum of containers": {
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Resources: requests("100m", "64Mi")},
+						{Resources: requests("200m", "64Mi")},
+					},
+				},
+			},
+			expected: corev1.ResourceList{
+				corev1.ResourcePods:   resource.MustParse("1"),
+				corev1.ResourceCPU:    resource.MustParse("300m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
+		"init container is larger": {
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{
+						{Resources: requests("1", "32Mi")},
+					},
+					Containers: []corev1.Container{
+						{Resources: requests("100m", "64Mi")},
+					},
+				},
+			},
+			expected: corev1.ResourceList{
+				corev1.ResourcePods:   resource.MustParse("1"),
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+		"terminated pod": {
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Resources: requests("100m", "64Mi")},
+					},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+			},
+			expected: corev1.ResourceList{},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got := PodUsage(tc.pod)
+			if !equality.Semantic.DeepEqual(got, tc.expected) {
+				t.Errorf("expected usage %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	hard := corev1.ResourceList{
+		corev1.ResourceCPU:  resource.MustParse("1"),
+		corev1.ResourcePods: resource.MustParse("2"),
+	}
+
+	testcases := map[string]struct {
+		used      corev1.ResourceList
+		requested corev1.ResourceList
+		exceeded  []corev1.ResourceName
+	}{
+		"within quota": {
+			used: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("500m"),
+				corev1.ResourcePods: resource.MustParse("1"),
+			},
+			requested: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("500m"),
+				corev1.ResourcePods: resource.MustParse("1"),
+			},
+		},
+		"cpu exceeded": {
+			used: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("800m"),
+				corev1.ResourcePods: resource.MustParse("1"),
+			},
+			requested: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("500m"),
+				corev1.ResourcePods: resource.MustParse("1"),
+			},
+			exceeded: []corev1.ResourceName{corev1.ResourceCPU},
+		},
+		"unlimited resource": {
+			used: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+			requested: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			err := Check(hard, tc.used, tc.requested)
+			if len(tc.exceeded) == 0 {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !IsExceeded(err) {
+				t.Fatalf("expected quota exceeded error, got %v", err)
+			}
+			limited := err.(*ExceededError).Limited
+			if len(limited) != len(tc.exceeded) {
+				t.Errorf("expected exceeded resources %v, got %v", tc.exceeded, limited)
+			}
+			for _, name := range tc.exceeded {
+				if _, ok := limited[name]; !ok {
+					t.Errorf("expected %s to be exceeded, got %v", name, limited)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	vclisters "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/listers/tenancy/v1alpha1"
+)
+
+// Reporter periodically writes the aggregate usage of every VirtualCluster
+// with a quota to its status.
+type Reporter struct {
+	tracker  *Tracker
+	vcClient vcclient.Interface
+	vcLister vclisters.VirtualClusterLister
+	synced   []cache.InformerSynced
+}
+
+// NewReporter creates a Reporter.
+func NewReporter(vcClient vcclient.Interface, vcInformer vcinformers.VirtualClusterInformer, informer informers.SharedInformerFactory) *Reporter {
+	return &Reporter{
+		tracker:  NewTracker(informer),
+		vcClient: vcClient,
+		vcLister: vcInformer.Lister(),
+		synced: []cache.InformerSynced{
+			vcInformer.Informer().HasSynced,
+			informer.Core().V1().Pods().Informer().HasSynced,
+			informer.Core().V1().Services().Informer().HasSynced,
+			informer.Core().V1().PersistentVolumeClaims().Informer().HasSynced,
+		},
+	}
+}
+
+// Report updates Status.QuotaUsed of all VirtualClusters.
+func (r *Reporter) Report() {
+	for _, synced := range r.synced {
+		if !synced() {
+			klog.V(4).Infof("caches are not synced yet, skip reporting virtual cluster quota usage")
+			return
+		}
+	}
+
+	vcs, err := r.vcLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list virtual clusters: %v", err)
+		return
+	}
+	for _, vc := range vcs {
+		if len(vc.Spec.Quota) == 0 {
+			r.tracker.Forget(vc)
+			if vc.Status.QuotaUsed == nil {
+				continue
+			}
+			updated := vc.DeepCopy()
+			updated.Status.QuotaUsed = nil
+			if _, err := r.vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).UpdateStatus(updated); err != nil {
+				klog.Errorf("failed to clear quota usage of virtual cluster %s/%s: %v", vc.Namespace, vc.Name, err)
+			}
+			continue
+		}
+
+		used, err := r.tracker.Usage(vc)
+		if err != nil {
+			klog.Errorf("failed to compute quota usage of virtual cluster %s/%s: %v", vc.Namespace, vc.Name, err)
+			continue
+		}
+		if equality.Semantic.DeepEqual(used, vc.Status.QuotaUsed) {
+			continue
+		}
+		updated := vc.DeepCopy()
+		updated.Status.QuotaUsed = used
+		if _, err := r.vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).UpdateStatus(updated); err != nil {
+			klog.Errorf("failed to update quota usage of virtual cluster %s/%s: %v", vc.Namespace, vc.Name, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+// assumedTTL bounds how long a created object is accounted for before the
+// informer cache observes it.
+const assumedTTL = time.Minute
+
+// RetryPeriod is how long an object exceeding the quota waits before being
+// admitted again, the objects freeing the quota do not requeue it.
+const RetryPeriod = 30 * time.Second
+
+// Tracker computes the aggregate usage of a VirtualCluster from the super
+// control plane informer caches and admits new objects against Spec.Quota.
+type Tracker struct {
+	podLister     listersv1.PodLister
+	serviceLister listersv1.ServiceLister
+	pvcLister     listersv1.PersistentVolumeClaimLister
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+}
+
+type tenant struct {
+	sync.Mutex
+	// assumed holds the usage of objects created in the super control plane
+	// which are not observed by the informer caches yet, keyed by namespace/name.
+	assumed map[string]assumedUsage
+}
+
+type assumedUsage struct {
+	usage   corev1.ResourceList
+	expires time.Time
+}
+
+// NewTracker creates a Tracker using the super control plane informers.
+func NewTracker(informer informers.SharedInformerFactory) *Tracker {
+	return &Tracker{
+		podLister:     informer.Core().V1().Pods().Lister(),
+		serviceLister: informer.Core().V1().Services().Lister(),
+		pvcLister:     informer.Core().V1().PersistentVolumeClaims().Lister(),
+		tenants:       make(map[string]*tenant),
+	}
+}
+
+func (t *Tracker) getTenant(vc *v1alpha1.VirtualCluster) *tenant {
+	key := vc.Namespace + "/" + vc.Name
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tn, ok := t.tenants[key]
+	if !ok {
+		tn = &tenant{assumed: make(map[string]assumedUsage)}
+		t.tenants[key] = tn
+	}
+	return tn
+}
+
+// Forget drops the tracking state of a VirtualCluster.
+func (t *Tracker) Forget(vc *v1alpha1.VirtualCluster) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.tenants, vc.Namespace+"/"+vc.Name)
+}
+
+// Usage returns the aggregate usage of the resources in vc.Spec.Quota.
+func (t *Tracker) Usage(vc *v1alpha1.VirtualCluster) (corev1.ResourceList, error) {
+	tn := t.getTenant(vc)
+	tn.Lock()
+	defer tn.Unlock()
+	used, err := t.usage(vc, tn)
+	if err != nil {
+		return nil, err
+	}
+	return Mask(used, vc.Spec.Quota), nil
+}
+
+func (t *Tracker) usage(vc *v1alpha1.VirtualCluster, tn *tenant) (corev1.ResourceList, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		constants.LabelVCName:      vc.Name,
+		constants.LabelVCNamespace: vc.Namespace,
+	})
+	observed := make(map[string]struct{})
+	used := corev1.ResourceList{}
+
+	if hasAny(vc.Spec.Quota, corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourcePods) {
+		pods, err := t.podLister.List(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %v", err)
+		}
+		for _, pod := range pods {
+			observed[pod.Namespace+"/"+pod.Name] = struct{}{}
+			used = Add(used, PodUsage(pod))
+		}
+	}
+	if hasAny(vc.Spec.Quota, corev1.ResourceServices) {
+		services, err := t.serviceLister.List(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %v", err)
+		}
+		for _, svc := range services {
+			observed[svc.Namespace+"/"+svc.Name] = struct{}{}
+			used = Add(used, ServiceUsage(svc))
+		}
+	}
+	if hasAny(vc.Spec.Quota, corev1.ResourceRequestsStorage) {
+		pvcs, err := t.pvcLister.List(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list persistentvolumeclaims: %v", err)
+		}
+		for _, pvc := range pvcs {
+			observed[pvc.Namespace+"/"+pvc.Name] = struct{}{}
+			used = Add(used, PVCUsage(pvc))
+		}
+	}
+
+	now := time.Now()
+	for key, a := range tn.assumed {
+		if _, ok := observed[key]; ok || now.After(a.expires) {
+			delete(tn.assumed, key)
+			continue
+		}
+		used = Add(used, a.usage)
+	}
+	return used, nil
+}
+
+// Admit checks whether creating obj in the super control plane keeps vc within
+// its quota. The usage of an admitted object is accounted for right away, so
+// that the concurrent admissions see it: on success the caller must invoke the
+// returned function with the result of the create request once it is done,
+// which releases the usage of objects that were not created. An ExceededError
+// is returned if the quota does not allow the object.
+func (t *Tracker) Admit(vc *v1alpha1.VirtualCluster, obj client.Object) (func(created bool), error) {
+	requested := Mask(objectUsage(obj), vc.Spec.Quota)
+	if isZero(requested) {
+		return func(bool) {}, nil
+	}
+
+	tn := t.getTenant(vc)
+	tn.Lock()
+	defer tn.Unlock()
+	used, err := t.usage(vc, tn)
+	if err != nil {
+		return nil, err
+	}
+	if err := Check(vc.Spec.Quota, used, requested); err != nil {
+		return nil, err
+	}
+	key := obj.GetNamespace() + "/" + obj.GetName()
+	tn.assumed[key] = assumedUsage{usage: requested, expires: time.Now().Add(assumedTTL)}
+	return func(created bool) {
+		tn.Lock()
+		defer tn.Unlock()
+		if created {
+			// The informer cache observes the object within assumedTTL from now.
+			tn.assumed[key] = assumedUsage{usage: requested, expires: time.Now().Add(assumedTTL)}
+			return
+		}
+		delete(tn.assumed, key)
+	}, nil
+}
+
+func objectUsage(obj client.Object) corev1.ResourceList {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return PodUsage(o)
+	case *corev1.PersistentVolumeClaim:
+		return PVCUsage(o)
+	case *corev1.Service:
+		return ServiceUsage(o)
+	default:
+		return corev1.ResourceList{}
+	}
+}
+
+func isZero(list corev1.ResourceList) bool {
+	for _, q := range list {
+		if !q.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAny(list corev1.ResourceList, names ...corev1.ResourceName) bool {
+	for _, name := range names {
+		if _, ok := list[name]; ok {
+			return true
+		}
+	}
+	return false
+}
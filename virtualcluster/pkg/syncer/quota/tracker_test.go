@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+func superPod(name string, vc *v1alpha1.VirtualCluster, cpu string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "tenant-1-test-default",
+			Labels: map[string]string{
+				constants.LabelVCName:      vc.Name,
+				constants.LabelVCNamespace: vc.Namespace,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Resources: requests(cpu, "64Mi")},
+			},
+		},
+	}
+}
+
+func TestTrackerAdmit(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			Quota: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("1"),
+				corev1.ResourcePods: resource.MustParse("3"),
+			},
+		},
+	}
+
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	tracker := NewTracker(informer)
+	if err := informer.Core().V1().Pods().Informer().GetStore().Add(superPod("existing", vc, "500m")); err != nil {
+		t.Fatalf("failed to add pod to informer: %v", err)
+	}
+
+	admitted, err := tracker.Admit(vc, superPod("pod-1", vc, "300m"))
+	if err != nil {
+		t.Fatalf("expected pod-1 to be admitted, got %v", err)
+	}
+	admitted(true)
+
+	// pod-1 is not observed by the informer yet but must be accounted.
+	if _, err := tracker.Admit(vc, superPod("pod-2", vc, "300m")); !IsExceeded(err) {
+		t.Errorf("expected pod-2 to exceed the quota, got %v", err)
+	}
+
+	used, err := tracker.Usage(vc)
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+	if cpu := used[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("800m")) != 0 {
+		t.Errorf("expected cpu usage 800m, got %s", cpu.String())
+	}
+	if pods := used[corev1.ResourcePods]; pods.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected pods usage 2, got %s", pods.String())
+	}
+	if _, ok := used[corev1.ResourceMemory]; ok {
+		t.Errorf("expected memory not to be reported since it is not limited")
+	}
+
+	// The usage of pod-4 is accounted for while it is being created, without
+	// blocking the other requests, and released if it is not created.
+	admitted, err = tracker.Admit(vc, superPod("pod-4", vc, "100m"))
+	if err != nil {
+		t.Fatalf("expected pod-4 to be admitted, got %v", err)
+	}
+	if _, err := tracker.Admit(vc, superPod("pod-5", vc, "100m")); !IsExceeded(err) {
+		t.Errorf("expected pod-5 to exceed the pods quota, got %v", err)
+	}
+	used, err = tracker.Usage(vc)
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+	if cpu := used[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("900m")) != 0 {
+		t.Errorf("expected cpu usage 900m while pod-4 is created, got %s", cpu.String())
+	}
+	admitted(false)
+	used, err = tracker.Usage(vc)
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+	if cpu := used[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("800m")) != 0 {
+		t.Errorf("expected the usage of pod-4 to be released, got cpu usage %s", cpu.String())
+	}
+
+	vc.Spec.Quota = nil
+	admitted, err = tracker.Admit(vc, superPod("pod-3", vc, "2"))
+	if err != nil {
+		t.Errorf("expected pod-3 to be admitted without quota, got %v", err)
+	}
+	admitted(false)
+}
@@ -19,7 +19,6 @@ package configmap
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,7 +35,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 )
 
-var numMissMatchedConfigMaps uint64
+var numMissMatchedConfigMaps = metrics.NewMissMatchCounter("MissMatchedConfigMaps")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
@@ -121,7 +120,7 @@ func (c *controller) PatrollerDo() {
 		}
 		updated := conversion.Equality(c.Config, vc).CheckConfigMapEquality(pCM, vCM)
 		if updated != nil {
-			atomic.AddUint64(&numMissMatchedConfigMaps, 1)
+			numMissMatchedConfigMaps.Inc(vObj.GetOwnerCluster())
 			klog.Warningf("ConfigMap %s diff in super&tenant control plane", pObj.Key)
 		}
 	}
@@ -141,5 +140,5 @@ func (c *controller) PatrollerDo() {
 		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
 	})
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedConfigMaps").Set(float64(numMissMatchedConfigMaps))
+	numMissMatchedConfigMaps.Record()
 }
@@ -109,9 +109,11 @@ func (c *controller) reconcileConfigMapCreate(clusterName, targetName, targetNam
 }
 
 func (c *controller) reconcileConfigMapUpdate(clusterName, targetNamespace, requestUID string, pConfigMap, vConfigMap *corev1.ConfigMap) error {
-	if pConfigMap.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pConfigMap %s/%s delegated UID is different from updated object", targetNamespace, pConfigMap.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vConfigMap, pConfigMap)
+	if err != nil {
+		return err
 	}
+	pConfigMap = pObj.(*corev1.ConfigMap)
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
 		return err
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -35,9 +34,10 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numMissMatchedCRD uint64
+var numMissMatchedCRD = metrics.NewMissMatchCounter("MissMatchedCRD")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
@@ -56,7 +56,7 @@ func (c *controller) PatrollerDo() {
 		return
 	}
 	wg := sync.WaitGroup{}
-	numMissMatchedCRD = 0
+	numMissMatchedCRD.Reset()
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -78,6 +78,14 @@ func (c *controller) PatrollerDo() {
 			continue
 		}
 		for _, clusterName := range clusterNames {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, &pCRDList.Items[i])
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of crd %s selects cluster %s: %v", pCRD.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				continue
+			}
 			if err := c.MultiClusterController.Get(clusterName, "", pCRD.Name, &apiextensionsv1.CustomResourceDefinition{}); err != nil {
 				if apierrors.IsNotFound(err) {
 					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperControlPlaneCRD").Inc()
@@ -87,7 +95,7 @@ func (c *controller) PatrollerDo() {
 			}
 		}
 	}
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedCRD").Set(float64(numMissMatchedCRD))
+	numMissMatchedCRD.Record()
 }
 
 func (c *controller) checkCRDOfTenantCluster(clusterName string) {
@@ -143,9 +151,21 @@ func (c *controller) checkCRDOfTenantCluster(clusterName string) {
 			klog.Errorf("failed to get CRD  %s from super control plane cache: %v", vCRD.Name, err)
 			continue
 		}
+		selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pCRD)
+		if err != nil {
+			klog.Errorf("failed to check whether the selector of crd %s selects cluster %s: %v", pCRD.Name, clusterName, err)
+			continue
+		}
+		if !selected {
+			// the tenant no longer matches the selector of pCRD, retract it.
+			metrics.CheckerRemedyStats.WithLabelValues("RetractedTenantCRD").Inc()
+			c.UpwardController.AddToQueue(clusterName + "/" + pCRD.Name)
+			continue
+		}
+
 		updatedCRD := conversion.Equality(nil, nil).CheckCRDEquality(pCRD, &crdList.Items[i])
 		if updatedCRD != nil {
-			atomic.AddUint64(&numMissMatchedCRD, 1)
+			numMissMatchedCRD.Inc(clusterName)
 			if publicCRD(pCRD) {
 				klog.Infof("patroller update CRD %v in tenant cluster %v", vCRD.Name, clusterName)
 				c.UpwardController.AddToQueue(clusterName + "/" + pCRD.Name)
@@ -31,6 +31,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
@@ -54,6 +55,7 @@ func (c *controller) BackPopulate(key string) error {
 	// The key format is clustername/pcName.
 	clusterName, crdName, _ := cache.SplitMetaNamespaceKey(key)
 	op := reconciler.AddEvent
+	retracted := false
 	pCRD := &apiextensionsv1.CustomResourceDefinition{}
 	err := c.superClient.Get(context.TODO(), client.ObjectKey{
 		Name: crdName,
@@ -63,6 +65,15 @@ func (c *controller) BackPopulate(key string) error {
 			return err
 		}
 		op = reconciler.DeleteEvent
+	} else {
+		selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pCRD)
+		if err != nil {
+			return err
+		}
+		if !selected {
+			op = reconciler.DeleteEvent
+			retracted = true
+		}
 	}
 
 	cluster := c.MultiClusterController.GetCluster(clusterName)
@@ -100,6 +111,10 @@ func (c *controller) BackPopulate(key string) error {
 	}
 
 	if op == reconciler.DeleteEvent {
+		if retracted && !util.IsPublicObject(vCRD) {
+			// the crd is created by the tenant, which is not retracted.
+			return nil
+		}
 		opts := &metav1.DeleteOptions{
 			PropagationPolicy: &constants.DefaultDeletionPolicy,
 		}
@@ -18,7 +18,6 @@ package endpoints
 
 import (
 	"fmt"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -32,8 +31,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numMissingEndPoints uint64
-var numMissMatchedEndPoints uint64
+var numMissingEndPoints = metrics.NewMissMatchCounter("MissingEndPoints")
+var numMissMatchedEndPoints = metrics.NewMissMatchCounter("MissMatchedEndPoints")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
@@ -55,8 +54,8 @@ func (c *controller) PatrollerDo() {
 		return
 	}
 
-	numMissingEndPoints = 0
-	numMissMatchedEndPoints = 0
+	numMissingEndPoints.Reset()
+	numMissMatchedEndPoints.Reset()
 
 	pList, err := c.endpointsLister.List(util.GetSuperClusterListerLabelsSelector())
 	if err != nil {
@@ -79,6 +78,9 @@ func (c *controller) PatrollerDo() {
 		}
 
 		for i := range vList.Items {
+			if util.IsImportedObject(&vList.Items[i]) {
+				continue
+			}
 			vSet.Insert(differ.ClusterObject{
 				Object:       &vList.Items[i],
 				OwnerCluster: cluster,
@@ -89,7 +91,7 @@ func (c *controller) PatrollerDo() {
 
 	d := differ.HandlerFuncs{}
 	d.AddFunc = func(vObj differ.ClusterObject) {
-		atomic.AddUint64(&numMissingEndPoints, 1)
+		numMissingEndPoints.Inc(vObj.OwnerCluster)
 		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj); err != nil {
 			klog.Errorf("error requeue vEndpoints %s: %v", vObj.Key, err)
 		} else {
@@ -101,7 +103,7 @@ func (c *controller) PatrollerDo() {
 		p := pObj.Object.(*corev1.Endpoints)
 		updated := conversion.Equality(c.Config, nil).CheckEndpointsEquality(p, v)
 		if updated != nil {
-			atomic.AddUint64(&numMissMatchedEndPoints, 1)
+			numMissMatchedEndPoints.Inc(vObj.OwnerCluster)
 			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj); err != nil {
 				klog.Errorf("error requeue vEndpoints %s: %v", vObj.Key, err)
 			} else {
@@ -115,6 +117,6 @@ func (c *controller) PatrollerDo() {
 		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
 	})
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissingEndPoints").Set(float64(numMissingEndPoints))
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedEndPoints").Set(float64(numMissMatchedEndPoints))
+	numMissingEndPoints.Record()
+	numMissMatchedEndPoints.Record()
 }
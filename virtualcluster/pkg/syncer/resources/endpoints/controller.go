@@ -17,7 +17,12 @@ limitations under the License.
 package endpoints
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -27,6 +32,7 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
@@ -46,6 +52,8 @@ type controller struct {
 	manager.BaseResourceSyncer
 	// super control plane endpoints client
 	endpointClient v1core.EndpointsGetter
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
 	// super control plane endpoints informer lister/synced function
 	endpointsLister listersv1.EndpointsLister
 	endpointsSynced cache.InformerSynced
@@ -69,6 +77,14 @@ func NewEndpointsController(config *config.SyncerConfiguration,
 	if err != nil {
 		return nil, err
 	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return c.endpointClient.Endpoints(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return c.endpointClient.Endpoints(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
 
 	c.endpointsLister = informer.Core().V1().Endpoints().Lister()
 	if options.IsFake {
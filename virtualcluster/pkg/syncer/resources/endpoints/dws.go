@@ -51,6 +51,10 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			// Supercontrol plane ep controller handles the service ep lifecycle, quit.
 			return reconciler.Result{}, nil
 		}
+		if util.IsImportedObject(vService) {
+			// imported services only live in the tenant control plane.
+			return reconciler.Result{}, nil
+		}
 	}
 	klog.V(4).Infof("reconcile endpoints %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
@@ -116,9 +120,11 @@ func (c *controller) reconcileEndpointsCreate(clusterName, targetNamespace, requ
 }
 
 func (c *controller) reconcileEndpointsUpdate(clusterName, targetNamespace, requestUID string, pEP, vEP *corev1.Endpoints) error {
-	if pEP.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pEndpoints %s/%s delegated UID is different from updated object", targetNamespace, pEP.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vEP, pEP)
+	if err != nil {
+		return err
 	}
+	pEP = pObj.(*corev1.Endpoints)
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
 		return err
@@ -92,6 +92,13 @@ func applySelectorToService(svc *corev1.Service, key, value string) *corev1.Serv
 	return svc
 }
 
+func applyImportToService(svc *corev1.Service, name string) *corev1.Service {
+	svc.Labels = map[string]string{
+		constants.LabelServiceImport: name,
+	}
+	return svc
+}
+
 func TestDWEndpointsCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -154,6 +161,13 @@ func TestDWEndpointsCreation(t *testing.T) {
 			},
 			ExpectedNoOperation: true,
 		},
+		"new ep related to imported service": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantEndpoints("imported-svc", "default", "12345"),
+				applyImportToService(tenantService("imported-svc", "default", "123456"), "svc"),
+			},
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
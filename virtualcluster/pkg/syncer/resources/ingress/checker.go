@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -35,9 +34,9 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numSpecMissMatchedIngresses uint64
-var numStatusMissMatchedIngresses uint64
-var numUWMetaMissMatchedIngresses uint64
+var numSpecMissMatchedIngresses = metrics.NewMissMatchCounter("SpecMissMatchedIngresses")
+var numStatusMissMatchedIngresses = metrics.NewMissMatchCounter("StatusMissMatchedIngresses")
+var numUWMetaMissMatchedIngresses = metrics.NewMissMatchCounter("UWMetaMissMatchedIngresses")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.ingressSynced) {
@@ -57,9 +56,9 @@ func (c *controller) PatrollerDo() {
 	}
 
 	wg := sync.WaitGroup{}
-	numSpecMissMatchedIngresses = 0
-	numStatusMissMatchedIngresses = 0
-	numUWMetaMissMatchedIngresses = 0
+	numSpecMissMatchedIngresses.Reset()
+	numStatusMissMatchedIngresses.Reset()
+	numUWMetaMissMatchedIngresses.Reset()
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -103,9 +102,9 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 
-	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedIngresses").Set(float64(numSpecMissMatchedIngresses))
-	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedIngresses").Set(float64(numStatusMissMatchedIngresses))
-	metrics.CheckerMissMatchStats.WithLabelValues("UWMetaMissMatchedIngresses").Set(float64(numUWMetaMissMatchedIngresses))
+	numSpecMissMatchedIngresses.Record()
+	numStatusMissMatchedIngresses.Record()
+	numUWMetaMissMatchedIngresses.Record()
 }
 
 func (c *controller) checkIngressesOfTenantCluster(clusterName string) {
@@ -143,9 +142,9 @@ func (c *controller) checkIngressesOfTenantCluster(clusterName string) {
 			klog.Errorf("fail to get cluster spec : %s", clusterName)
 			continue
 		}
-		updatedIngress := conversion.Equality(c.Config, vc).CheckIngressEquality(pIngress, &ingList.Items[i])
-		if updatedIngress != nil {
-			atomic.AddUint64(&numSpecMissMatchedIngresses, 1)
+		desired, err := desiredIngress(vc, &ingList.Items[i])
+		if err != nil || conversion.Equality(c.Config, vc).CheckIngressEquality(pIngress, desired) != nil {
+			numSpecMissMatchedIngresses.Inc(clusterName)
 			klog.Warningf("spec of ingress %v/%v diff in super&tenant control plane", vIngress.Namespace, vIngress.Name)
 			if err := c.MultiClusterController.RequeueObject(clusterName, &ingList.Items[i]); err != nil {
 				klog.Errorf("error requeue vingress %v/%v in cluster %s: %v", vIngress.Namespace, vIngress.Name, clusterName, err)
@@ -157,13 +156,13 @@ func (c *controller) checkIngressesOfTenantCluster(clusterName string) {
 		enqueue := false
 		updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pIngress.ObjectMeta, &ingList.Items[i].ObjectMeta)
 		if updatedMeta != nil {
-			atomic.AddUint64(&numUWMetaMissMatchedIngresses, 1)
+			numUWMetaMissMatchedIngresses.Inc(clusterName)
 			enqueue = true
 			klog.Warningf("UWObjectMeta of vIngress %v/%v diff in super&tenant control plane", vIngress.Namespace, vIngress.Name)
 		}
 		if !equality.Semantic.DeepEqual(vIngress.Status, pIngress.Status) {
 			enqueue = true
-			atomic.AddUint64(&numStatusMissMatchedIngresses, 1)
+			numStatusMissMatchedIngresses.Inc(clusterName)
 			klog.Warningf("Status of vIngress %v/%v diff in super&tenant control plane", vIngress.Namespace, vIngress.Name)
 		}
 		if enqueue {
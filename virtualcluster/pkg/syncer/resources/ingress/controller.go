@@ -17,9 +17,13 @@ limitations under the License.
 package ingress
 
 import (
+	"context"
 	"fmt"
 
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -30,6 +34,7 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
@@ -52,6 +57,8 @@ type controller struct {
 	manager.BaseResourceSyncer
 	// super control plane ingress client
 	ingressClient v1networking.IngressesGetter
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
 	// super control plane informer/listers/synced functions
 	ingressLister listersnetworkingv1.IngressLister
 	ingressSynced cache.InformerSynced
@@ -75,6 +82,14 @@ func NewIngressController(config *config.SyncerConfiguration,
 	if err != nil {
 		return nil, err
 	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return c.ingressClient.Ingresses(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return c.ingressClient.Ingresses(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
 
 	c.ingressLister = informer.Networking().V1().Ingresses().Lister()
 	if options.IsFake {
@@ -85,7 +85,22 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 }
 
 func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, requestUID string, ingress *networkingv1.Ingress) error {
-	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, ingress)
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	desired, err := desiredIngress(vc, ingress)
+	if err == nil {
+		err = c.checkHostConflict(clusterName, desired)
+	}
+	if err != nil {
+		if rejected, ok := err.(*rejectedError); ok {
+			return c.rejectIngress(clusterName, ingress, rejected)
+		}
+		return err
+	}
+
+	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, desired)
 	if err != nil {
 		return err
 	}
@@ -100,26 +115,42 @@ func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, reques
 		}
 		return fmt.Errorf("pIngress %s/%s exists but its delegated object UID is different", targetNamespace, pIngress.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	return c.setSyncStatus(clusterName, ingress, "")
 }
 
 func (c *controller) reconcileIngressUpdate(clusterName, targetNamespace, requestUID string, pIngress, vIngress *networkingv1.Ingress) error {
-	if pIngress.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pIngress %s/%s delegated UID is different from updated object", targetNamespace, pIngress.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vIngress, pIngress)
+	if err != nil {
+		return err
 	}
+	pIngress = pObj.(*networkingv1.Ingress)
 
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
 		return err
 	}
-	updated := conversion.Equality(c.Config, vc).CheckIngressEquality(pIngress, vIngress)
+	desired, err := desiredIngress(vc, vIngress)
+	if err == nil {
+		err = c.checkHostConflict(clusterName, desired)
+	}
+	if err != nil {
+		if rejected, ok := err.(*rejectedError); ok {
+			return c.rejectIngress(clusterName, vIngress, rejected)
+		}
+		return err
+	}
+
+	updated := conversion.Equality(c.Config, vc).CheckIngressEquality(pIngress, desired)
 	if updated != nil {
 		_, err = c.ingressClient.Ingresses(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return c.setSyncStatus(clusterName, vIngress, "")
 }
 
 func (c *controller) reconcileIngressRemove(targetNamespace, requestUID, name string, pIngress *networkingv1.Ingress) error {
@@ -151,6 +151,153 @@ func TestDWIngressCreation(t *testing.T) {
 	}
 }
 
+func applyRuleToIngress(ing *networkingv1.Ingress, host, path string) *networkingv1.Ingress {
+	ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{
+		Host: host,
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{Path: path}},
+			},
+		},
+	})
+	return ing
+}
+
+func applyDefaultBackendToIngress(ing *networkingv1.Ingress) *networkingv1.Ingress {
+	ing.Spec.DefaultBackend = &networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: "default", Port: networkingv1.ServiceBackendPort{Number: 80}},
+	}
+	return ing
+}
+
+func applyClassToIngress(ing *networkingv1.Ingress, class string) *networkingv1.Ingress {
+	ing.Spec.IngressClassName = &class
+	return ing
+}
+
+func TestDWIngressPolicy(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			IngressPolicy: &v1alpha1.IngressPolicy{
+				ClassMappings: map[string]string{"nginx": "tenant-1-nginx"},
+				DefaultClass:  "tenant-1-nginx",
+				HostSuffix:    "tenant-1.example.com",
+			},
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant *networkingv1.Ingress
+
+		ExpectedCreatedClass string
+		ExpectedNoCreation   bool
+	}{
+		"mapped class": {
+			ExistingObjectInTenant: applyClassToIngress(applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/"), "nginx"),
+			ExpectedCreatedClass:   "tenant-1-nginx",
+		},
+		"default class": {
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/"),
+			ExpectedCreatedClass:   "tenant-1-nginx",
+		},
+		"class not allowed": {
+			ExistingObjectInTenant: applyClassToIngress(applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/"), "haproxy"),
+			ExpectedNoCreation:     true,
+		},
+		"host not allowed": {
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-2.example.com", "/"),
+			ExpectedNoCreation:     true,
+		},
+		"host conflicts with another tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyRuleToIngress(superIngress("ing-2", "tenant-2-default", "23456", "tenant-2"), "a.tenant-1.example.com", "/api"),
+			},
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/api"),
+			ExpectedNoCreation:     true,
+		},
+		"different path of a host of another tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyRuleToIngress(superIngress("ing-2", "tenant-2-default", "23456", "tenant-2"), "a.tenant-1.example.com", "/"),
+			},
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/api"),
+			ExpectedNoCreation:     true,
+		},
+		"host matching a wildcard host of another tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyRuleToIngress(superIngress("ing-2", "tenant-2-default", "23456", "tenant-2"), "*.tenant-1.example.com", "/"),
+			},
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/"),
+			ExpectedNoCreation:     true,
+		},
+		"different host of another tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyRuleToIngress(superIngress("ing-2", "tenant-2-default", "23456", "tenant-2"), "b.tenant-1.example.com", "/"),
+			},
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/"),
+			ExpectedCreatedClass:   "tenant-1-nginx",
+		},
+		"default backend of another tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyDefaultBackendToIngress(superIngress("ing-2", "tenant-2-default", "23456", "tenant-2")),
+			},
+			ExistingObjectInTenant: applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/"),
+			ExpectedCreatedClass:   "tenant-1-nginx",
+		},
+		"default backend not allowed": {
+			ExistingObjectInTenant: applyDefaultBackendToIngress(applyRuleToIngress(tenantIngress("ing-1", "default", "12345"), "a.tenant-1.example.com", "/")),
+			ExpectedNoCreation:     true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewIngressController,
+				testTenant,
+				tc.ExistingObjectInSuper,
+				[]runtime.Object{tc.ExistingObjectInTenant},
+				tc.ExistingObjectInTenant,
+				nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+			}
+
+			if tc.ExpectedNoCreation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expected no ingress to be created. Actual actions were: %#v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("create", "ingresses") {
+				t.Errorf("%s: Expected to create ingress. Actual actions were: %#v", k, actions)
+				return
+			}
+			created := actions[0].(core.CreateAction).GetObject().(*networkingv1.Ingress)
+			if created.Namespace != superDefaultNSName {
+				t.Errorf("%s: Expected ingress to be created in %s, got %s", k, superDefaultNSName, created.Namespace)
+			}
+			if created.Spec.IngressClassName == nil || *created.Spec.IngressClassName != tc.ExpectedCreatedClass {
+				t.Errorf("%s: Expected ingress class %s, got %v", k, tc.ExpectedCreatedClass, created.Spec.IngressClassName)
+			}
+		})
+	}
+}
+
 func TestDWIngressDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
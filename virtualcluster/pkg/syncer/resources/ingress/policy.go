@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+const (
+	// legacyIngressClassAnnotation is the deprecated annotation used to select an IngressClass.
+	legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+	reasonHostConflict           = "HostConflict"
+	reasonHostNotAllowed         = "HostNotAllowed"
+	reasonIngressClassNotAllowed = "IngressClassNotAllowed"
+)
+
+// rejectedError is returned when a tenant Ingress cannot be synced because it
+// violates the VirtualCluster ingress policy or conflicts with another tenant.
+type rejectedError struct {
+	reason  string
+	message string
+}
+
+func (e *rejectedError) Error() string {
+	return e.message
+}
+
+// desiredIngress returns the super control plane view of vIngress after the
+// IngressClass mapping of the VirtualCluster ingress policy is applied.
+func desiredIngress(vc *v1alpha1.VirtualCluster, vIngress *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+	desired := vIngress.DeepCopy()
+	policy := vc.Spec.IngressPolicy
+	if policy == nil {
+		return desired, nil
+	}
+
+	class, fromAnnotation := "", false
+	if desired.Spec.IngressClassName != nil {
+		class = *desired.Spec.IngressClassName
+	} else if v, ok := desired.Annotations[legacyIngressClassAnnotation]; ok {
+		class, fromAnnotation = v, true
+	}
+
+	switch {
+	case class == "":
+		if policy.DefaultClass != "" {
+			defaultClass := policy.DefaultClass
+			desired.Spec.IngressClassName = &defaultClass
+		}
+	case len(policy.ClassMappings) != 0:
+		mapped, ok := policy.ClassMappings[class]
+		if !ok {
+			return nil, &rejectedError{
+				reason:  reasonIngressClassNotAllowed,
+				message: fmt.Sprintf("ingress class %q is not allowed", class),
+			}
+		}
+		if fromAnnotation {
+			desired.Annotations[legacyIngressClassAnnotation] = mapped
+		} else {
+			desired.Spec.IngressClassName = &mapped
+		}
+	}
+
+	if policy.HostSuffix != "" {
+		if desired.Spec.DefaultBackend != nil {
+			return nil, &rejectedError{
+				reason:  reasonHostNotAllowed,
+				message: fmt.Sprintf("the default backend serves hosts which are not subdomains of %q", policy.HostSuffix),
+			}
+		}
+		for _, host := range ingressHosts(desired) {
+			if !strings.HasSuffix(host, "."+policy.HostSuffix) && host != policy.HostSuffix {
+				return nil, &rejectedError{
+					reason:  reasonHostNotAllowed,
+					message: fmt.Sprintf("host %q is not a subdomain of %q", host, policy.HostSuffix),
+				}
+			}
+		}
+	}
+	return desired, nil
+}
+
+// ingressHosts returns all hosts referenced by the rules and TLS section of ingress.
+func ingressHosts(ingress *networkingv1.Ingress) []string {
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		hosts = append(hosts, rule.Host)
+	}
+	for _, tls := range ingress.Spec.TLS {
+		hosts = append(hosts, tls.Hosts...)
+	}
+	return hosts
+}
+
+// ingressClaims returns the hosts served by ingress. A rule without host and a
+// default backend serve any host, which is claimed as "".
+func ingressClaims(ingress *networkingv1.Ingress) []string {
+	var claims []string
+	if ingress.Spec.DefaultBackend != nil {
+		claims = append(claims, "")
+	}
+	for _, rule := range ingress.Spec.Rules {
+		claims = append(claims, rule.Host)
+	}
+	return claims
+}
+
+// hostsOverlap returns whether a request can match both hosts. A wildcard host
+// matches a single DNS label, as defined by the Ingress API.
+func hostsOverlap(a, b string) bool {
+	return a == b || wildcardMatches(a, b) || wildcardMatches(b, a)
+}
+
+func wildcardMatches(wildcard, host string) bool {
+	if !strings.HasPrefix(wildcard, "*.") {
+		return false
+	}
+	i := strings.Index(host, ".")
+	return i > 0 && host[i:] == wildcard[1:]
+}
+
+// describeHost returns the host in messages, "" being any host.
+func describeHost(host string) string {
+	if host == "" {
+		return "any host"
+	}
+	return fmt.Sprintf("host %q", host)
+}
+
+// checkHostConflict returns a rejectedError if any host served by pIngress is
+// already served by an Ingress of another tenant. The ownership is arbitrated
+// per host rather than per path, since the ingress controllers merge the paths
+// of all the Ingresses of a host and a longer path of a tenant would take over
+// the traffic of a shorter one of another tenant.
+func (c *controller) checkHostConflict(clusterName string, pIngress *networkingv1.Ingress) error {
+	claims := ingressClaims(pIngress)
+	if len(claims) == 0 {
+		return nil
+	}
+
+	pIngresses, err := c.ingressLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, other := range pIngresses {
+		otherCluster, otherNamespace := conversion.GetVirtualOwner(other)
+		if otherCluster == "" || otherCluster == clusterName {
+			continue
+		}
+		for _, otherClaim := range ingressClaims(other) {
+			for _, claim := range claims {
+				if !hostsOverlap(claim, otherClaim) {
+					continue
+				}
+				klog.V(4).Infof("ingress %s/%s of cluster %s conflicts with ingress %s/%s of cluster %s on %s",
+					pIngress.Namespace, pIngress.Name, clusterName, otherNamespace, other.Name, otherCluster, describeHost(otherClaim))
+				return &rejectedError{
+					reason:  reasonHostConflict,
+					message: fmt.Sprintf("%s is already served by an ingress of another tenant", describeHost(otherClaim)),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rejectIngress surfaces why vIngress is not synced with an event and the
+// sync status annotation on the tenant object.
+func (c *controller) rejectIngress(clusterName string, vIngress *networkingv1.Ingress, rejected *rejectedError) error {
+	klog.Warningf("ingress %s/%s of cluster %s is rejected: %s", vIngress.Namespace, vIngress.Name, clusterName, rejected.message)
+	if err := c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:      "Ingress",
+		Name:      vIngress.Name,
+		Namespace: vIngress.Namespace,
+		UID:       vIngress.UID,
+	}, corev1.EventTypeWarning, rejected.reason, "Ingress is not synced: %s", rejected.message); err != nil {
+		return err
+	}
+	return c.setSyncStatus(clusterName, vIngress, fmt.Sprintf("%s: %s", rejected.reason, rejected.message))
+}
+
+// setSyncStatus records status in the sync status annotation of vIngress. An
+// empty status removes the annotation.
+func (c *controller) setSyncStatus(clusterName string, vIngress *networkingv1.Ingress, status string) error {
+	if vIngress.Annotations[constants.LabelIngressSyncStatus] == status {
+		return nil
+	}
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	updated := vIngress.DeepCopy()
+	if status == "" {
+		delete(updated.Annotations, constants.LabelIngressSyncStatus)
+	} else {
+		if updated.Annotations == nil {
+			updated.Annotations = make(map[string]string)
+		}
+		updated.Annotations[constants.LabelIngressSyncStatus] = status
+	}
+	_, err = tenantClient.NetworkingV1().Ingresses(vIngress.Namespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+var numMissMatchedIngressClasses = metrics.NewMissMatchCounter("MissMatchedIngressClasses")
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.ingressclassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting Service checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if IngressClass keeps consistency between super control plane and tenant control planes.
+func (c *controller) PatrollerDo() {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.V(5).Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "ingressclass")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	numMissMatchedIngressClasses.Reset()
+
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(clusterName string) {
+			defer wg.Done()
+			c.checkIngressClassOfTenantCluster(clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	pIngressClassList, err := c.ingressclassLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing ingressclass from super control plane informer cache: %v", err)
+		return
+	}
+
+	for _, pIngressClass := range pIngressClassList {
+		if !publicIngressClass(pIngressClass) {
+			continue
+		}
+		for _, clusterName := range clusterNames {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pIngressClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of ingressclass %s selects cluster %s: %v", pIngressClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				continue
+			}
+			if err := c.MultiClusterController.Get(clusterName, "", pIngressClass.Name, &networkingv1.IngressClass{}); err != nil {
+				if apierrors.IsNotFound(err) {
+					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperControlPlaneIngressClasses").Inc()
+					c.UpwardController.AddToQueue(clusterName + "/" + pIngressClass.Name)
+				}
+				klog.Errorf("fail to get ingressclass from cluster %s: %v", clusterName, err)
+			}
+		}
+	}
+
+	numMissMatchedIngressClasses.Record()
+}
+
+func (c *controller) checkIngressClassOfTenantCluster(clusterName string) {
+	icList := &networkingv1.IngressClassList{}
+	if err := c.MultiClusterController.List(clusterName, icList); err != nil {
+		klog.Errorf("error listing ingressclass from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+
+	for i, vIngressClass := range icList.Items {
+		if !publicIngressClass(&icList.Items[i]) {
+			continue
+		}
+		pIngressClass, err := c.ingressclassLister.Get(vIngressClass.Name)
+		if apierrors.IsNotFound(err) {
+			// super control plane is the source of the truth for ingressclass object, delete tenant control plane obj
+			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+			if err != nil {
+				klog.Errorf("error getting cluster %s clientset: %v", clusterName, err)
+				continue
+			}
+			opts := &metav1.DeleteOptions{
+				PropagationPolicy: &constants.DefaultDeletionPolicy,
+			}
+			if err := tenantClient.NetworkingV1().IngressClasses().Delete(context.TODO(), vIngressClass.Name, *opts); err != nil {
+				klog.Errorf("error deleting ingressclass %v in cluster %s: %v", vIngressClass.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantIngressClasses").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pIngressClass %s from super control plane cache: %v", vIngressClass.Name, err)
+			continue
+		}
+
+		if util.IsPublicObject(&icList.Items[i]) {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pIngressClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of ingressclass %s selects cluster %s: %v", pIngressClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				// the tenant no longer matches the selector of pIngressClass, retract it.
+				metrics.CheckerRemedyStats.WithLabelValues("RetractedTenantIngressClasses").Inc()
+				c.UpwardController.AddToQueue(clusterName + "/" + pIngressClass.Name)
+				continue
+			}
+		}
+
+		updatedIngressClass := conversion.Equality(nil, nil).CheckIngressClassEquality(pIngressClass, &icList.Items[i])
+		if updatedIngressClass != nil {
+			numMissMatchedIngressClasses.Inc(clusterName)
+			klog.Warningf("spec of ingressClass %v diff in super&tenant control plane", vIngressClass.Name)
+			if publicIngressClass(pIngressClass) {
+				c.UpwardController.AddToQueue(clusterName + "/" + pIngressClass.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/networking/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	ingressclassinformers "k8s.io/client-go/informers/networking/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	v1ingressclass "k8s.io/client-go/kubernetes/typed/networking/v1"
+	listersv1 "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "ingressclass",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewIngressClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// super control plane ingressclasses client
+	client v1ingressclass.IngressClassesGetter
+	// super control plane ingressclasses informer/lister/synced functions
+	informer           ingressclassinformers.Interface
+	ingressclassLister listersv1.IngressClassLister
+	ingressclassSynced cache.InformerSynced
+}
+
+func NewIngressClassController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		client:   client.NetworkingV1(),
+		informer: informer.Networking().V1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.IngressClass{}, &v1.IngressClassList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.ingressclassLister = informer.Networking().V1().IngressClasses().Lister()
+	if options.IsFake {
+		c.ingressclassSynced = func() bool { return true }
+	} else {
+		c.ingressclassSynced = informer.Networking().V1().IngressClasses().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.IngressClass{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&v1.IngressClass{}, c, pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.informer.IngressClasses().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.IngressClass:
+					return publicIngressClass(t)
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*v1.IngressClass); ok {
+						return publicIngressClass(e)
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.IngressClass", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super control plane ingressclass controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueIngressClass,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newIngressClass := newObj.(*v1.IngressClass)
+					oldIngressClass := oldObj.(*v1.IngressClass)
+					if newIngressClass.ResourceVersion != oldIngressClass.ResourceVersion {
+						c.enqueueIngressClass(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueIngressClass,
+			},
+		})
+	return c, nil
+}
+
+func publicIngressClass(e *v1.IngressClass) bool {
+	// We only backpopulate specific ingressclass to tenant control planes
+	return e.Labels[constants.PublicObjectKey] == "true"
+}
+
+func (c *controller) enqueueIngressClass(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("No tenant control planes, stop backpopulate ingressclass %v", key)
+		return
+	}
+
+	for _, clusterName := range clusterNames {
+		c.UpwardController.AddToQueue(clusterName + "/" + key)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.ingressclassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync ingressclass")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	// The key format is clustername/icName.
+	clusterName, icName, _ := cache.SplitMetaNamespaceKey(key)
+
+	op := reconciler.AddEvent
+	pIngressClass, err := c.ingressclassLister.Get(icName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		op = reconciler.DeleteEvent
+	} else {
+		selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pIngressClass)
+		if err != nil {
+			return err
+		}
+		if !selected {
+			op = reconciler.DeleteEvent
+		}
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+
+	vIngressClass := &networkingv1.IngressClass{}
+	if err := c.MultiClusterController.Get(clusterName, "", icName, vIngressClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			if op == reconciler.AddEvent {
+				// Available in super, hence create a new in tenant control plane
+				vIngressClass := conversion.BuildVirtualIngressClass(clusterName, pIngressClass)
+				_, err := tenantClient.NetworkingV1().IngressClasses().Create(context.TODO(), vIngressClass, metav1.CreateOptions{})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return err
+	}
+
+	if op == reconciler.DeleteEvent {
+		if pIngressClass != nil && !util.IsPublicObject(vIngressClass) {
+			// the ingressclass is created by the tenant, which is not retracted.
+			return nil
+		}
+		opts := &metav1.DeleteOptions{
+			PropagationPolicy: &constants.DefaultDeletionPolicy,
+		}
+		err := tenantClient.NetworkingV1().IngressClasses().Delete(context.TODO(), icName, *opts)
+		if err != nil {
+			return err
+		}
+	} else {
+		updatedIngressClass := conversion.Equality(c.Config, nil).CheckIngressClassEquality(pIngressClass, vIngressClass)
+		if updatedIngressClass != nil {
+			_, err := tenantClient.NetworkingV1().IngressClasses().Update(context.TODO(), updatedIngressClass, metav1.UpdateOptions{})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func makeIngressClass(name, uid string, mFuncs ...func(*v1.IngressClass)) *v1.IngressClass {
+	ic := &v1.IngressClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "IngressClass",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		Spec: v1.IngressClassSpec{
+			Controller: "example.com/ingress-controller",
+		},
+	}
+
+	for _, f := range mFuncs {
+		f(ic)
+	}
+	return ic
+}
+
+func public(selector string) func(*v1.IngressClass) {
+	return func(class *v1.IngressClass) {
+		class.Labels = map[string]string{
+			constants.PublicObjectKey: "true",
+		}
+		if selector != "" {
+			class.Annotations = map[string]string{
+				constants.PublicObjectSelectorKey: selector,
+			}
+		}
+	}
+}
+
+func TestUWIngressClass(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+			Labels:    map[string]string{"tier": "gold"},
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedAction         string
+		ExpectedError          string
+	}{
+		"pIC exists, vIC not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("")),
+			},
+			ExpectedAction: "create",
+		},
+		"pIC selects the tenant, vIC not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("tier in (gold, platinum)")),
+			},
+			ExpectedAction: "create",
+		},
+		"pIC does not select the tenant, vIC not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("tier=silver")),
+			},
+		},
+		"pIC with invalid selector": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("tier in gold")),
+			},
+			ExpectedError: "invalid " + constants.PublicObjectSelectorKey,
+		},
+		"pIC exists, vIC exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("ic", "123456", public(""), func(class *v1.IngressClass) {
+					class.Spec.Controller = "example.com/other"
+				}),
+			},
+			ExpectedAction: "update",
+		},
+		"pIC not found, vIC exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("ic", "123456", public("")),
+			},
+			ExpectedAction: "delete",
+		},
+		"pIC no longer selects the tenant, vIC exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("tier=silver")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("ic", "123456", public("")),
+			},
+			ExpectedAction: "delete",
+		},
+		"pIC does not select the tenant, vIC created by tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("ic", "12345", public("tier=silver")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("ic", "123456"),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(NewIngressClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, defaultClusterKey+"/ic", nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedError != "" {
+				if reconcileErr == nil || !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+			}
+
+			if tc.ExpectedAction == "" {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches(tc.ExpectedAction, "ingressclasses") {
+				t.Errorf("%s: Expect %s ingressclass, got %v", k, tc.ExpectedAction, actions)
+				return
+			}
+			if update, ok := actions[0].(core.UpdateAction); ok {
+				if updated := update.GetObject().(*v1.IngressClass); updated.Spec.Controller != "example.com/ingress-controller" {
+					t.Errorf("%s: Expect updated controller of super ingressclass, got %s", k, updated.Spec.Controller)
+				}
+			}
+		})
+	}
+}
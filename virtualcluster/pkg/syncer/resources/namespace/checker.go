@@ -146,6 +146,7 @@ func (c *controller) PatrollerDo() {
 			return
 		}
 		updatedNamespace := conversion.Equality(c.Config, vc).CheckNamespaceEquality(p, v)
+		updatedNamespace = withPodSecurity(vc, v, p, updatedNamespace)
 		if updatedNamespace != nil {
 			klog.Warningf("metadata of namespace %s diff in super&tenant cluster", pObj.Key)
 			d.OnAdd(vObj)
@@ -17,7 +17,12 @@ limitations under the License.
 package namespace
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -28,6 +33,7 @@ import (
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	vclisters "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/listers/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
@@ -47,6 +53,8 @@ type controller struct {
 	manager.BaseResourceSyncer
 	// super control plane namespace client
 	namespaceClient v1core.NamespacesGetter
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
 	// super control plane namespace lister
 	nsLister listersv1.NamespaceLister
 	nsSynced cache.InformerSynced
@@ -75,6 +83,14 @@ func NewNamespaceController(config *config.SyncerConfiguration,
 	if err != nil {
 		return nil, err
 	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(_, name string, data []byte) (runtime.Object, error) {
+			return c.namespaceClient.Namespaces().Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(_, name string, opts metav1.DeleteOptions) error {
+			return c.namespaceClient.Namespaces().Delete(context.TODO(), name, opts)
+		},
+	})
 
 	c.nsLister = informer.Core().V1().Namespaces().Lister()
 	c.vcLister = vcInformer.Lister()
@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -86,12 +87,25 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 }
 
 func (c *controller) reconcileNamespaceCreate(clusterName, targetNamespace string, vNamespace *corev1.Namespace) error {
+	// Record the namespace first, so that the objects created in it by the
+	// super control plane can be mapped back even if the namespace is gone.
+	if err := naming.DefaultRegistry.RecordNamespace(context.TODO(), clusterName, vNamespace.Name); err != nil {
+		return err
+	}
+
 	newObj, err := c.Conversion().BuildSuperClusterNamespace(clusterName, vNamespace)
 	if err != nil {
 		return err
 	}
 
-	_, err = c.namespaceClient.Namespaces().Create(context.TODO(), newObj.(*corev1.Namespace), metav1.CreateOptions{})
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	pNamespace := newObj.(*corev1.Namespace)
+	applyPodSecurity(vc, vNamespace.Name, pNamespace)
+
+	_, err = c.namespaceClient.Namespaces().Create(context.TODO(), pNamespace, metav1.CreateOptions{})
 	if apierrors.IsAlreadyExists(err) {
 		klog.Infof("namespace %s of cluster %s already exist in super control plane", targetNamespace, clusterName)
 		return nil
@@ -100,23 +114,32 @@ func (c *controller) reconcileNamespaceCreate(clusterName, targetNamespace strin
 }
 
 func (c *controller) reconcileNamespaceUpdate(clusterName, targetNamespace, requestUID string, pNamespace, vNamespace *corev1.Namespace) error {
-	if pNamespace.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pNamespace %s exists but its delegated UID is different", targetNamespace)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vNamespace, pNamespace)
+	if err != nil {
+		return err
 	}
+	pNamespace = pObj.(*corev1.Namespace)
 
+	// The namespaces created before the reverse mappings were persisted are recorded here.
+	if err := naming.DefaultRegistry.RecordNamespace(context.TODO(), clusterName, vNamespace.Name); err != nil {
+		return err
+	}
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	var updatedNamespace *corev1.Namespace
 	// update namespace meta is a generic operation, guarded by SuperClusterPooling for now
 	if featuregate.DefaultFeatureGate.Enabled(featuregate.SuperClusterPooling) {
-		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+		updatedNamespace = conversion.Equality(c.Config, vc).CheckNamespaceEquality(pNamespace, vNamespace)
+	}
+	updatedNamespace = withPodSecurity(vc, vNamespace, pNamespace, updatedNamespace)
+	if updatedNamespace != nil {
+		_, err = c.namespaceClient.Namespaces().Update(context.TODO(), updatedNamespace, metav1.UpdateOptions{})
 		if err != nil {
 			return err
 		}
-		updatedNamespace := conversion.Equality(c.Config, vc).CheckNamespaceEquality(pNamespace, vNamespace)
-		if updatedNamespace != nil {
-			_, err = c.namespaceClient.Namespaces().Update(context.TODO(), updatedNamespace, metav1.UpdateOptions{})
-			if err != nil {
-				return err
-			}
-		}
 	}
 	return nil
 }
@@ -133,7 +156,10 @@ func (c *controller) reconcileNamespaceRemove(clusterName, targetNamespace, requ
 	err := c.namespaceClient.Namespaces().Delete(context.TODO(), targetNamespace, *opts)
 	if apierrors.IsNotFound(err) {
 		klog.Warningf("namespace %s of cluster %s not found in super control plane", targetNamespace, clusterName)
-		return nil
+		err = nil
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	return naming.DefaultRegistry.ForgetNamespace(context.TODO(), clusterName, pNamespace.Annotations[constants.LabelNamespace])
 }
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+const (
+	podSecurityLabelPrefix         = "pod-security.kubernetes.io/"
+	podSecurityEnforceLabel        = podSecurityLabelPrefix + "enforce"
+	podSecurityEnforceVersionLabel = podSecurityLabelPrefix + "enforce-version"
+	podSecurityWarnLabel           = podSecurityLabelPrefix + "warn"
+	podSecurityWarnVersionLabel    = podSecurityLabelPrefix + "warn-version"
+
+	defaultPodSecurityVersion = "latest"
+)
+
+// applyPodSecurity stamps the Pod Security Admission labels of vc onto the
+// super namespace of the tenant namespace vNamespace. Pod security labels
+// copied from the tenant namespace are dropped so that tenants cannot weaken
+// the policy of the virtual cluster.
+func applyPodSecurity(vc *v1alpha1.VirtualCluster, vNamespace string, pNamespace *corev1.Namespace) {
+	if vc == nil || vc.Spec.PodSecurity == nil {
+		return
+	}
+	level := vc.Spec.PodSecurity.Level
+	if exception, ok := vc.Spec.PodSecurity.Exceptions[vNamespace]; ok {
+		level = exception
+	}
+	version := vc.Spec.PodSecurity.Version
+	if version == "" {
+		version = defaultPodSecurityVersion
+	}
+
+	labels := make(map[string]string)
+	for k, v := range pNamespace.Labels {
+		if !strings.HasPrefix(k, podSecurityLabelPrefix) {
+			labels[k] = v
+		}
+	}
+	labels[podSecurityEnforceLabel] = string(level)
+	labels[podSecurityEnforceVersionLabel] = version
+	labels[podSecurityWarnLabel] = string(level)
+	labels[podSecurityWarnVersionLabel] = version
+	pNamespace.Labels = labels
+
+	if pNamespace.Annotations == nil {
+		pNamespace.Annotations = make(map[string]string)
+	}
+	pNamespace.Annotations[constants.LabelPodSecurityLabels] = strings.Join(stampedPodSecurityLabels, ",")
+}
+
+// stampedPodSecurityLabels are the labels set by applyPodSecurity, sorted.
+var stampedPodSecurityLabels = []string{
+	podSecurityEnforceLabel,
+	podSecurityEnforceVersionLabel,
+	podSecurityWarnLabel,
+	podSecurityWarnVersionLabel,
+}
+
+// resetPodSecurity replaces the Pod Security Admission labels stamped by
+// applyPodSecurity on pNamespace by the ones of the tenant namespace
+// vNamespace. The labels set on pNamespace by the super control plane admins
+// are left alone.
+func resetPodSecurity(vNamespace, pNamespace *corev1.Namespace) {
+	stamped, ok := pNamespace.Annotations[constants.LabelPodSecurityLabels]
+	if !ok {
+		return
+	}
+	labels := make(map[string]string)
+	for k, v := range pNamespace.Labels {
+		labels[k] = v
+	}
+	for _, k := range strings.Split(stamped, ",") {
+		if v, ok := vNamespace.Labels[k]; ok {
+			labels[k] = v
+		} else {
+			delete(labels, k)
+		}
+	}
+	pNamespace.Labels = labels
+
+	annotations := make(map[string]string)
+	for k, v := range pNamespace.Annotations {
+		if k != constants.LabelPodSecurityLabels {
+			annotations[k] = v
+		}
+	}
+	pNamespace.Annotations = annotations
+}
+
+// withPodSecurity amends updated, the pNamespace computed by the namespace
+// equality check, with the Pod Security Admission labels of vc. The labels
+// stamped by a policy that has been removed from vc are reset to the ones of
+// the tenant namespace. It returns nil if pNamespace is already up to date.
+func withPodSecurity(vc *v1alpha1.VirtualCluster, vNamespace, pNamespace, updated *corev1.Namespace) *corev1.Namespace {
+	if vc == nil {
+		return updated
+	}
+	if updated == nil {
+		updated = pNamespace.DeepCopy()
+	}
+	if vc.Spec.PodSecurity == nil {
+		resetPodSecurity(vNamespace, updated)
+	} else {
+		applyPodSecurity(vc, vNamespace.Name, updated)
+	}
+	if equality.Semantic.DeepEqual(updated.ObjectMeta, pNamespace.ObjectMeta) {
+		return nil
+	}
+	return updated
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func podSecurityTenant() *v1alpha1.VirtualCluster {
+	return &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			PodSecurity: &v1alpha1.PodSecurity{
+				Level: v1alpha1.PodSecurityLevelRestricted,
+				Exceptions: map[string]v1alpha1.PodSecurityLevel{
+					"kube-system": v1alpha1.PodSecurityLevelPrivileged,
+				},
+			},
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+}
+
+func TestDWNamespacePodSecurity(t *testing.T) {
+	testTenant := podSecurityTenant()
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	for name, tc := range map[string]struct {
+		namespace     string
+		tenantLabels  map[string]string
+		expectedLevel string
+	}{
+		"namespace gets cluster level": {
+			namespace:     "default",
+			expectedLevel: "restricted",
+		},
+		"tenant labels are overridden": {
+			namespace:     "default",
+			tenantLabels:  map[string]string{"pod-security.kubernetes.io/enforce": "privileged", "pod-security.kubernetes.io/audit": "privileged"},
+			expectedLevel: "restricted",
+		},
+		"namespace exception": {
+			namespace:     "kube-system",
+			expectedLevel: "privileged",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			vNamespace := tenantNamespace(tc.namespace, "12345")
+			vNamespace.Labels = tc.tenantLabels
+			actions, reconcileErr, err := util.RunDownwardSync(NewNamespaceController, testTenant, nil,
+				[]runtime.Object{vNamespace}, vNamespace, nil)
+			if err != nil {
+				t.Fatalf("error running downward sync: %v", err)
+			}
+			if reconcileErr != nil {
+				t.Fatalf("expected no error, but got \"%v\"", reconcileErr)
+			}
+			if len(actions) != 1 || !actions[0].Matches("create", "namespaces") {
+				t.Fatalf("expected namespace to be created, got %#v", actions)
+			}
+			created := actions[0].(core.CreateAction).GetObject().(*corev1.Namespace)
+			if created.Name != conversion.ToSuperClusterNamespace(defaultClusterKey, tc.namespace) {
+				t.Errorf("unexpected namespace %s created", created.Name)
+			}
+			expected := map[string]string{
+				"pod-security.kubernetes.io/enforce":         tc.expectedLevel,
+				"pod-security.kubernetes.io/enforce-version": "latest",
+				"pod-security.kubernetes.io/warn":            tc.expectedLevel,
+				"pod-security.kubernetes.io/warn-version":    "latest",
+			}
+			for k, v := range expected {
+				if created.Labels[k] != v {
+					t.Errorf("expected label %s=%s, got %q", k, v, created.Labels[k])
+				}
+			}
+			if _, ok := created.Labels["pod-security.kubernetes.io/audit"]; ok {
+				t.Errorf("expected tenant pod security label to be dropped, got %v", created.Labels)
+			}
+			if created.Annotations[constants.LabelPodSecurityLabels] == "" {
+				t.Errorf("expected the stamped labels to be recorded, got %v", created.Annotations)
+			}
+		})
+	}
+}
+
+func TestDWNamespacePodSecurityUpdate(t *testing.T) {
+	testTenant := podSecurityTenant()
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	stale := superNamespace(superNSName, "12345", defaultClusterKey)
+	stale.Labels = map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}
+	upToDate := superNamespace(superNSName, "12345", defaultClusterKey)
+	upToDate.Labels = map[string]string{
+		"pod-security.kubernetes.io/enforce":         "restricted",
+		"pod-security.kubernetes.io/enforce-version": "latest",
+		"pod-security.kubernetes.io/warn":            "restricted",
+		"pod-security.kubernetes.io/warn-version":    "latest",
+	}
+	upToDate.Annotations[constants.LabelPodSecurityLabels] = strings.Join(stampedPodSecurityLabels, ",")
+	unrecorded := upToDate.DeepCopy()
+	delete(unrecorded.Annotations, constants.LabelPodSecurityLabels)
+
+	for name, tc := range map[string]struct {
+		existing       *corev1.Namespace
+		expectedUpdate bool
+	}{
+		"stamped labels are recorded": {existing: unrecorded, expectedUpdate: true},
+		"stale policy is updated":     {existing: stale, expectedUpdate: true},
+		"up to date policy is kept":   {existing: upToDate, expectedUpdate: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			vNamespace := tenantNamespace("default", "12345")
+			actions, reconcileErr, err := util.RunDownwardSync(NewNamespaceController, testTenant,
+				[]runtime.Object{tc.existing}, []runtime.Object{vNamespace}, vNamespace, nil)
+			if err != nil {
+				t.Fatalf("error running downward sync: %v", err)
+			}
+			if reconcileErr != nil {
+				t.Fatalf("expected no error, but got \"%v\"", reconcileErr)
+			}
+			if !tc.expectedUpdate {
+				if len(actions) != 0 {
+					t.Errorf("expected no action, got %#v", actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("update", "namespaces") {
+				t.Fatalf("expected namespace to be updated, got %#v", actions)
+			}
+			updated := actions[0].(core.UpdateAction).GetObject().(*corev1.Namespace)
+			if updated.Labels["pod-security.kubernetes.io/enforce"] != "restricted" {
+				t.Errorf("expected restricted level to be enforced, got %v", updated.Labels)
+			}
+			if updated.Annotations[constants.LabelPodSecurityLabels] != upToDate.Annotations[constants.LabelPodSecurityLabels] {
+				t.Errorf("expected the stamped labels to be recorded, got %v", updated.Annotations)
+			}
+		})
+	}
+}
+
+func TestDWNamespacePodSecurityRemoved(t *testing.T) {
+	testTenant := podSecurityTenant()
+	testTenant.Spec.PodSecurity = nil
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	enforced := superNamespace(superNSName, "12345", defaultClusterKey)
+	enforced.Labels = map[string]string{
+		"pod-security.kubernetes.io/enforce":         "restricted",
+		"pod-security.kubernetes.io/enforce-version": "latest",
+		"pod-security.kubernetes.io/warn":            "restricted",
+		"pod-security.kubernetes.io/warn-version":    "latest",
+		"pod-security.kubernetes.io/audit":           "restricted",
+	}
+	enforced.Annotations[constants.LabelPodSecurityLabels] = strings.Join(stampedPodSecurityLabels, ",")
+	fromTenant := superNamespace(superNSName, "12345", defaultClusterKey)
+	fromTenant.Labels = map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}
+	// the labels set by the super control plane admins, not stamped by the syncer.
+	fromAdmins := superNamespace(superNSName, "12345", defaultClusterKey)
+	fromAdmins.Labels = map[string]string{
+		"pod-security.kubernetes.io/enforce": "restricted",
+		"pod-security.kubernetes.io/audit":   "restricted",
+	}
+
+	for name, tc := range map[string]struct {
+		existing       *corev1.Namespace
+		tenantLabels   map[string]string
+		expectedLabels map[string]string
+		expectedUpdate bool
+	}{
+		"labels of the removed policy are dropped": {
+			existing:       enforced,
+			expectedLabels: map[string]string{"pod-security.kubernetes.io/audit": "restricted"},
+			expectedUpdate: true,
+		},
+		"labels of the removed policy are reset to the tenant ones": {
+			existing:     enforced,
+			tenantLabels: map[string]string{"pod-security.kubernetes.io/enforce": "baseline"},
+			expectedLabels: map[string]string{
+				"pod-security.kubernetes.io/enforce": "baseline",
+				"pod-security.kubernetes.io/audit":   "restricted",
+			},
+			expectedUpdate: true,
+		},
+		"labels of the super control plane admins are kept": {
+			existing:       fromAdmins,
+			expectedUpdate: false,
+		},
+		"tenant labels are kept": {
+			existing:       fromTenant,
+			tenantLabels:   map[string]string{"pod-security.kubernetes.io/enforce": "baseline"},
+			expectedUpdate: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			vNamespace := tenantNamespace("default", "12345")
+			vNamespace.Labels = tc.tenantLabels
+			actions, reconcileErr, err := util.RunDownwardSync(NewNamespaceController, testTenant,
+				[]runtime.Object{tc.existing}, []runtime.Object{vNamespace}, vNamespace, nil)
+			if err != nil {
+				t.Fatalf("error running downward sync: %v", err)
+			}
+			if reconcileErr != nil {
+				t.Fatalf("expected no error, but got \"%v\"", reconcileErr)
+			}
+			if !tc.expectedUpdate {
+				if len(actions) != 0 {
+					t.Errorf("expected no action, got %#v", actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("update", "namespaces") {
+				t.Fatalf("expected namespace to be updated, got %#v", actions)
+			}
+			updated := actions[0].(core.UpdateAction).GetObject().(*corev1.Namespace)
+			if len(updated.Labels) != len(tc.expectedLabels) {
+				t.Errorf("expected labels %v, got %v", tc.expectedLabels, updated.Labels)
+			}
+			for k, v := range tc.expectedLabels {
+				if updated.Labels[k] != v {
+					t.Errorf("expected label %s=%s, got %v", k, v, updated.Labels)
+				}
+			}
+			if _, ok := updated.Annotations[constants.LabelPodSecurityLabels]; ok {
+				t.Errorf("expected the stamped labels record to be removed, got %v", updated.Annotations)
+			}
+		})
+	}
+}
@@ -19,7 +19,6 @@ package persistentvolume
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -34,8 +33,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 )
 
-var numClaimMissMatchedPVs uint64
-var numSpecMissMatchedPVs uint64
+var numClaimMissMatchedPVs = metrics.NewMissMatchCounter("ClaimMissMatchedPVs")
+var numSpecMissMatchedPVs = metrics.NewMissMatchCounter("SpecMissMatchedPVs")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.pvSynced, c.pvcSynced) {
@@ -53,8 +52,8 @@ func (c *controller) PatrollerDo() {
 		return
 	}
 
-	numClaimMissMatchedPVs = 0
-	numSpecMissMatchedPVs = 0
+	numClaimMissMatchedPVs.Reset()
+	numSpecMissMatchedPVs.Reset()
 
 	pList, err := c.pvLister.List(labels.Everything())
 	if err != nil {
@@ -105,7 +104,7 @@ func (c *controller) PatrollerDo() {
 		// Double check if the vPV is bound to the correct PVC.
 		if vPV.Spec.ClaimRef == nil || vPV.Spec.ClaimRef.Name != pPVC.Name || vPV.Spec.ClaimRef.Namespace != vNamespace {
 			klog.Errorf("vPV %v from cluster %s is not bound to the correct pvc", vPV.GetName(), clusterName)
-			atomic.AddUint64(&numClaimMissMatchedPVs, 1)
+			numClaimMissMatchedPVs.Inc(clusterName)
 		}
 
 		if vPV.Annotations[constants.LabelUID] != string(pPV.UID) {
@@ -115,7 +114,7 @@ func (c *controller) PatrollerDo() {
 
 		updatedPVSpec := conversion.Equality(c.Config, nil).CheckPVSpecEquality(&pPV.Spec, &vPV.Spec)
 		if updatedPVSpec != nil {
-			atomic.AddUint64(&numSpecMissMatchedPVs, 1)
+			numSpecMissMatchedPVs.Inc(clusterName)
 			klog.Warningf("spec of pv %v diff in super&tenant control plane %s", vPV.Name, clusterName)
 			if boundPersistentVolume(pPV) {
 				c.enqueuePersistentVolume(pPV)
@@ -175,6 +174,6 @@ func (c *controller) PatrollerDo() {
 		},
 	})
 
-	metrics.CheckerMissMatchStats.WithLabelValues("ClaimMissMatchedPVs").Set(float64(numClaimMissMatchedPVs))
-	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedPVs").Set(float64(numSpecMissMatchedPVs))
+	numClaimMissMatchedPVs.Record()
+	numSpecMissMatchedPVs.Record()
 }
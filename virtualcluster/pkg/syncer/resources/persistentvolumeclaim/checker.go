@@ -19,7 +19,6 @@ package persistentvolumeclaim
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,7 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numMissMatchedPVCs uint64
+var numMissMatchedPVCs = metrics.NewMissMatchCounter("MissMatchedPVCs")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.pvcSynced) {
@@ -53,7 +52,7 @@ func (c *controller) PatrollerDo() {
 		return
 	}
 
-	numMissMatchedPVCs = 0
+	numMissMatchedPVCs.Reset()
 
 	pList, err := c.pvcLister.List(util.GetSuperClusterListerLabelsSelector())
 	if err != nil {
@@ -108,7 +107,7 @@ func (c *controller) PatrollerDo() {
 		}
 		updatedPVC := conversion.Equality(c.Config, vc).CheckPVCEquality(p, v)
 		if updatedPVC != nil {
-			atomic.AddUint64(&numMissMatchedPVCs, 1)
+			numMissMatchedPVCs.Inc(vObj.GetOwnerCluster())
 			klog.Warningf("spec of pvc %s diff in super&tenant control plane", pObj.Key)
 		}
 
@@ -132,5 +131,5 @@ func (c *controller) PatrollerDo() {
 		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
 	})
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedPVCs").Set(float64(numMissMatchedPVCs))
+	numMissMatchedPVCs.Record()
 }
@@ -17,9 +17,13 @@ limitations under the License.
 package persistentvolumeclaim
 
 import (
+	"context"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -34,8 +38,10 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -53,10 +59,14 @@ type controller struct {
 	manager.BaseResourceSyncer
 	// super control plane pvc client
 	pvcClient v1core.PersistentVolumeClaimsGetter
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
 	// super control plane pvc lister
 	pvcLister listersv1.PersistentVolumeClaimLister
 	pvcSynced cache.InformerSynced
 	informer  coreinformers.Interface
+	// quotaTracker admits pvcs against the virtual cluster quota.
+	quotaTracker *quota.Tracker
 }
 
 func NewPVCController(config *config.SyncerConfiguration,
@@ -69,8 +79,9 @@ func NewPVCController(config *config.SyncerConfiguration,
 		BaseResourceSyncer: manager.BaseResourceSyncer{
 			Config: config,
 		},
-		pvcClient: client.CoreV1(),
-		informer:  informer.Core().V1(),
+		pvcClient:    client.CoreV1(),
+		informer:     informer.Core().V1(),
+		quotaTracker: quota.NewTracker(informer),
 	}
 
 	var err error
@@ -78,6 +89,14 @@ func NewPVCController(config *config.SyncerConfiguration,
 	if err != nil {
 		return nil, err
 	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return c.pvcClient.PersistentVolumeClaims(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return c.pvcClient.PersistentVolumeClaims(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
 
 	c.pvcLister = informer.Core().V1().PersistentVolumeClaims().Lister()
 	if options.IsFake {
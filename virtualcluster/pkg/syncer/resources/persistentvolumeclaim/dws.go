@@ -28,6 +28,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
@@ -65,6 +66,10 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	case vExists && !pExists:
 		err := c.reconcilePVCCreate(request.ClusterName, targetNamespace, request.UID, vPVC)
 		if err != nil {
+			if quota.IsExceeded(err) {
+				// Retry until the quota frees up, the event is recorded already.
+				return reconciler.Result{RequeueAfter: quota.RetryPeriod}, nil
+			}
 			klog.Errorf("failed reconcile pvc %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
@@ -94,7 +99,27 @@ func (c *controller) reconcilePVCCreate(clusterName, targetNamespace, requestUID
 
 	pPVC := newObj.(*corev1.PersistentVolumeClaim)
 
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	admitted, err := c.quotaTracker.Admit(vc, pPVC)
+	if err != nil {
+		if !quota.IsExceeded(err) {
+			return err
+		}
+		klog.Warningf("persistentvolumeclaim %s/%s of cluster %s is not created: %v", pvc.Namespace, pvc.Name, clusterName, err)
+		c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+			Kind:      "PersistentVolumeClaim",
+			Name:      pvc.Name,
+			Namespace: pvc.Namespace,
+			UID:       pvc.UID,
+		}, corev1.EventTypeWarning, "ExceededQuota", "Error creating: %v", err)
+		return err
+	}
+
 	pPVC, err = c.pvcClient.PersistentVolumeClaims(targetNamespace).Create(context.TODO(), pPVC, metav1.CreateOptions{})
+	admitted(err == nil)
 	if apierrors.IsAlreadyExists(err) {
 		if pPVC.Annotations[constants.LabelUID] == requestUID {
 			klog.Infof("pvc %s/%s of cluster %s already exist in super control plane", targetNamespace, pPVC.Name, clusterName)
@@ -106,9 +131,11 @@ func (c *controller) reconcilePVCCreate(clusterName, targetNamespace, requestUID
 }
 
 func (c *controller) reconcilePVCUpdate(clusterName, targetNamespace, requestUID string, pPVC, vPVC *corev1.PersistentVolumeClaim) error {
-	if pPVC.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pPVC %s/%s delegated UID is different from updated object", targetNamespace, pPVC.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vPVC, pPVC)
+	if err != nil {
+		return err
 	}
+	pPVC = pObj.(*corev1.PersistentVolumeClaim)
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
 		return err
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -48,9 +47,9 @@ const (
 	minimumGracePeriodInSeconds = 30
 )
 
-var numStatusMissMatchedPods uint64
-var numSpecMissMatchedPods uint64
-var numUWMetaMissMatchedPods uint64
+var numStatusMissMatchedPods = metrics.NewMissMatchCounter("StatusMissMatchedPods")
+var numSpecMissMatchedPods = metrics.NewMissMatchCounter("SpecMissMatchedPods")
+var numUWMetaMissMatchedPods = metrics.NewMissMatchCounter("UWMetaMissMatchedPods")
 
 // StartPatrol starts the period checker for data consistency check. Checker is
 // blocking so should be called via a goroutine.
@@ -142,9 +141,9 @@ func (c *controller) PatrollerDo() {
 
 	wg := sync.WaitGroup{}
 
-	numStatusMissMatchedPods = 0
-	numSpecMissMatchedPods = 0
-	numUWMetaMissMatchedPods = 0
+	numStatusMissMatchedPods.Reset()
+	numSpecMissMatchedPods.Reset()
+	numUWMetaMissMatchedPods.Reset()
 
 	pList, err := c.podLister.List(util.GetSuperClusterListerLabelsSelector())
 	if err != nil {
@@ -215,9 +214,9 @@ func (c *controller) PatrollerDo() {
 		},
 	})
 
-	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedPods").Set(float64(numStatusMissMatchedPods))
-	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedPods").Set(float64(numSpecMissMatchedPods))
-	metrics.CheckerMissMatchStats.WithLabelValues("UWMetaMissMatchedPods").Set(float64(numUWMetaMissMatchedPods))
+	numStatusMissMatchedPods.Record()
+	numSpecMissMatchedPods.Record()
+	numUWMetaMissMatchedPods.Record()
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -274,7 +273,7 @@ func (c *controller) differUpdateFunc(vObj differ.ClusterObject, pObj differ.Clu
 	}
 
 	if conversion.Equality(c.Config, vc).CheckPodEquality(pPod, vPod) != nil {
-		atomic.AddUint64(&numSpecMissMatchedPods, 1)
+		numSpecMissMatchedPods.Inc(clusterName)
 		klog.Warningf("spec of pod %s diff in super&tenant control plane", pObj.Key)
 		if err := c.MultiClusterController.RequeueObject(clusterName, vPod); err != nil {
 			klog.Errorf("error requeue vPod %s: %v", vObj.Key, err)
@@ -284,7 +283,7 @@ func (c *controller) differUpdateFunc(vObj differ.ClusterObject, pObj differ.Clu
 	}
 
 	if conversion.CheckDWPodConditionEquality(pPod, vPod) != nil {
-		atomic.AddUint64(&numSpecMissMatchedPods, 1)
+		numSpecMissMatchedPods.Inc(clusterName)
 		klog.Warningf("DWStatus of pod %s diff in super&tenant control plane", pObj.Key)
 		if err := c.MultiClusterController.RequeueObject(clusterName, vPod); err != nil {
 			klog.Errorf("error requeue vpod %v/%v in cluster %s: %v", vPod.Namespace, vPod.Name, clusterName, err)
@@ -294,7 +293,7 @@ func (c *controller) differUpdateFunc(vObj differ.ClusterObject, pObj differ.Clu
 	}
 
 	if conversion.Equality(c.Config, nil).CheckUWPodStatusEquality(pPod, vPod) != nil {
-		atomic.AddUint64(&numStatusMissMatchedPods, 1)
+		numStatusMissMatchedPods.Inc(clusterName)
 		klog.Warningf("status of pod %v/%v diff in super&tenant control plane", pPod.Namespace, pPod.Name)
 		if assignedPod(pPod) {
 			c.enqueuePod(pPod)
@@ -302,7 +301,7 @@ func (c *controller) differUpdateFunc(vObj differ.ClusterObject, pObj differ.Clu
 	}
 
 	if conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pPod.ObjectMeta, &vPod.ObjectMeta) != nil {
-		atomic.AddUint64(&numUWMetaMissMatchedPods, 1)
+		numUWMetaMissMatchedPods.Inc(clusterName)
 		klog.Warningf("UWObjectMeta of pod %v/%v diff in super&tenant control plane", vPod.Namespace, vPod.Name)
 		if assignedPod(pPod) {
 			c.enqueuePod(pPod)
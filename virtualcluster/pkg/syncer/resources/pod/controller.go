@@ -24,6 +24,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -36,10 +38,12 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/pod/mutatorplugin"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/pod/validationplugin"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
@@ -63,6 +67,8 @@ type controller struct {
 	manager.BaseResourceSyncer
 	// super control plane pod client
 	client v1core.CoreV1Interface
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
 	// super control plane informer/listers/synced functions
 	informer      coreinformers.Interface
 	podLister     listersv1.PodLister
@@ -80,6 +86,8 @@ type controller struct {
 	vnodeProvider provider.VirtualNodeProvider
 	plugin        validationplugin.Interface
 	podMutators   []conversion.PodMutator
+	// quotaTracker admits pods against the virtual cluster quota.
+	quotaTracker *quota.Tracker
 }
 
 type VirtulNodeDeletionPhase string
@@ -110,6 +118,7 @@ func NewPodController(config *config.SyncerConfiguration,
 		clusterVNodeGCMap:  make(map[string]map[string]VNodeGCStatus),
 		vNodeGCGracePeriod: constants.DefaultvNodeGCGracePeriod,
 		vnodeProvider:      vnode.GetNodeProvider(config, client),
+		quotaTracker:       quota.NewTracker(informer),
 	}
 
 	var err error
@@ -118,6 +127,14 @@ func NewPodController(config *config.SyncerConfiguration,
 	if err != nil {
 		return nil, err
 	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return c.client.Pods(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return c.client.Pods(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
 
 	// check registered validation plugin
 	rs := validationplugin.ValidationRegister.List()
@@ -23,12 +23,12 @@ import (
 	"time"
 
 	pkgerr "github.com/pkg/errors"
-	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
@@ -37,20 +37,30 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/tracing"
 )
 
 func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.podSynced, c.serviceSynced, c.secretSynced) {
 		return fmt.Errorf("failed to wait for caches to sync before starting Pod dws")
 	}
+	// KubeApiAccessSupport is reloadable, the tokens are refreshed whatever the
+	// gate so that the pods created while it is enabled keep valid tokens.
+	go wait.Until(c.refreshProjectedTokens, time.Minute, stopCh)
 	return c.MultiClusterController.Start(stopCh)
 }
 
-func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Result, retErr error) {
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	return c.ReconcileContext(context.TODO(), request)
+}
+
+// ReconcileContext reconciles the pod of request within the trace carried by ctx.
+func (c *controller) ReconcileContext(ctx context.Context, request reconciler.Request) (res reconciler.Result, retErr error) {
 	klog.V(4).Infof("reconcile pod %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	reconcilestart := time.Now()
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
@@ -75,14 +85,18 @@ func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Resul
 	var operation string
 	defer func() {
 		recordOperationDuration(operation, reconcilestart)
-		recordOperationStatus(operation, retErr)
+		recordOperationStatus(request.ClusterName, operation, retErr)
 	}()
 
 	switch {
 	case !reflect.DeepEqual(vPod, &corev1.Pod{}) && pPod == nil:
 		operation = "pod_add"
-		err := c.reconcilePodCreate(request.ClusterName, targetNamespace, request.UID, vPod)
+		err := c.reconcilePodCreate(ctx, request.ClusterName, targetNamespace, request.UID, vPod)
 		if err != nil {
+			if quota.IsExceeded(err) {
+				// Retry until the quota frees up, the event is recorded already.
+				return reconciler.Result{RequeueAfter: quota.RetryPeriod}, nil
+			}
 			klog.Errorf("failed reconcile Pod %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 
 			if parentRef := getParentRefFromPod(vPod); parentRef != nil {
@@ -109,7 +123,7 @@ func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Resul
 		}
 	case vPod != nil && pPod != nil:
 		operation = "pod_update"
-		err := c.reconcilePodUpdate(request.ClusterName, targetNamespace, request.UID, pPod, vPod)
+		err := c.reconcilePodUpdate(ctx, request.ClusterName, targetNamespace, request.UID, pPod, vPod)
 		if err != nil {
 			klog.Errorf("failed reconcile Pod %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
@@ -165,7 +179,7 @@ func getParentRefFromPod(vPod *corev1.Pod) *corev1.ObjectReference {
 	}
 }
 
-func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID string, vPod *corev1.Pod) error {
+func (c *controller) reconcilePodCreate(ctx context.Context, clusterName, targetNamespace, requestUID string, vPod *corev1.Pod) error {
 	// load deleting pod, don't create any pod on super control plane.
 	if vPod.DeletionTimestamp != nil {
 		return nil
@@ -182,37 +196,12 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 		return err
 	}
 
-	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, vPod)
+	newObj, err := c.buildSuperPod(ctx, clusterName, vPod)
 	if err != nil {
 		return err
 	}
-
 	pPod := newObj.(*corev1.Pod)
 
-	pSecretMap, err := c.findPodServiceAccountSecret(clusterName, pPod, vPod)
-	if err != nil {
-		return fmt.Errorf("failed to get service account secret from cluster %s cache: %v", clusterName, err)
-	}
-
-	services, err := c.getPodRelatedServices(clusterName, pPod)
-	if err != nil {
-		return fmt.Errorf("failed to list services from cluster %s cache: %v", clusterName, err)
-	}
-
-	nameServer, err := c.getClusterNameServer(clusterName)
-	if err != nil {
-		return fmt.Errorf("failed to find nameserver: %v", err)
-	}
-
-	// TODO: Convert PodMutateDefault to a plugin
-	// It is not an easy task as it uses a lot of controller methods now, but could be nice to be generalised.
-	var ms = append(c.podMutators, conversion.PodMutateDefault(vPod, pSecretMap, services, nameServer, c.Config.DNSOptions))
-
-	err = conversion.VC(c.MultiClusterController, clusterName).Pod(pPod, vPod).Mutate(ms...)
-	if err != nil {
-		return fmt.Errorf("failed to mutate pod: %v", err)
-	}
-
 	// Validation plugin processing
 	if c.plugin != nil {
 		pluginstart := time.Now()
@@ -235,7 +224,38 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 		recordOperationDuration("validation_plugin", pluginstart)
 	}
 
-	pPod, err = c.client.Pods(targetNamespace).Create(context.TODO(), pPod, metav1.CreateOptions{})
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	admitted, err := c.quotaTracker.Admit(vc, pPod)
+	if err != nil {
+		if !quota.IsExceeded(err) {
+			return err
+		}
+		klog.Warningf("pod %s/%s of cluster %s is not created: %v", vPod.Namespace, vPod.Name, clusterName, err)
+		c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      vPod.Name,
+			Namespace: vPod.Namespace,
+			UID:       vPod.UID,
+		}, corev1.EventTypeWarning, "ExceededQuota", "Error creating: %v", err)
+		return err
+	}
+
+	if pPod.Annotations == nil {
+		pPod.Annotations = make(map[string]string)
+	}
+	tracing.Inject(ctx, constants.LabelTraceContext, pPod.Annotations)
+	pPod, err = c.createSuperPod(ctx, targetNamespace, pPod)
+	admitted(err == nil)
+	if isPodSecurityViolation(err) {
+		return c.rejectPodSecurity(clusterName, vPod, err)
+	}
+	if err == nil && featuregate.DefaultFeatureGate.Enabled(featuregate.KubeApiAccessSupport) {
+		// The kubelet waits for the projected token secrets to show up.
+		return c.ensureProjectedTokens(clusterName, pPod, vPod)
+	}
 	if apierrors.IsAlreadyExists(err) {
 		if pPod.Annotations[constants.LabelUID] == requestUID {
 			klog.Infof("pod %s/%s of cluster %s already exist in super control plane", targetNamespace, pPod.Name, clusterName)
@@ -247,6 +267,56 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 	return err
 }
 
+// buildSuperPod converts vPod to the pPod to be created in the super control plane.
+func (c *controller) buildSuperPod(ctx context.Context, clusterName string, vPod *corev1.Pod) (obj client.Object, err error) {
+	_, span := tracing.Tracer().Start(ctx, "mutate")
+	defer func() { tracing.End(span, err) }()
+
+	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, vPod)
+	if err != nil {
+		return nil, err
+	}
+	pPod := newObj.(*corev1.Pod)
+
+	pSecretMap, err := c.findPodServiceAccountSecret(clusterName, pPod, vPod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service account secret from cluster %s cache: %v", clusterName, err)
+	}
+
+	services, err := c.getPodRelatedServices(clusterName, pPod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services from cluster %s cache: %v", clusterName, err)
+	}
+
+	nameServer, err := c.getClusterNameServer(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nameserver: %v", err)
+	}
+
+	// TODO: Convert PodMutateDefault to a plugin
+	// It is not an easy task as it uses a lot of controller methods now, but could be nice to be generalised.
+	var ms = append(c.podMutators, conversion.PodMutateDefault(vPod, pSecretMap, services, nameServer, c.Config.GetDNSOptions()))
+
+	err = conversion.VC(c.MultiClusterController, clusterName).Pod(pPod, vPod).Mutate(ms...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mutate pod: %v", err)
+	}
+	if featuregate.DefaultFeatureGate.Enabled(featuregate.KubeApiAccessSupport) {
+		mutateProjectedTokens(pPod)
+		if err := c.checkProjectedTokens(pPod, vPod); err != nil {
+			return nil, err
+		}
+	}
+
+	return newObj, nil
+}
+
+func (c *controller) createSuperPod(ctx context.Context, targetNamespace string, pPod *corev1.Pod) (created *corev1.Pod, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "create super pod")
+	defer func() { tracing.End(span, err) }()
+	return c.client.Pods(targetNamespace).Create(ctx, pPod, metav1.CreateOptions{})
+}
+
 func (c *controller) findPodServiceAccountSecret(clusterName string, pPod, vPod *corev1.Pod) (map[string]string, error) {
 	mountSecretSet := sets.NewString()
 	for _, volume := range vPod.Spec.Volumes {
@@ -337,16 +407,21 @@ func (c *controller) getPodRelatedServices(cluster string, pPod *corev1.Pod) ([]
 	return services, nil
 }
 
-func (c *controller) reconcilePodUpdate(clusterName, targetNamespace, requestUID string, pPod, vPod *corev1.Pod) error {
-	if pPod.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pPod %s/%s delegated UID is different from updated object", targetNamespace, pPod.Name)
+func (c *controller) reconcilePodUpdate(ctx context.Context, clusterName, targetNamespace, requestUID string, pPod, vPod *corev1.Pod) error {
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vPod, pPod)
+	if err != nil {
+		return err
 	}
+	pPod = pObj.(*corev1.Pod)
 
 	if vPod.DeletionTimestamp != nil {
 		if pPod.DeletionTimestamp != nil {
 			// pPod is under deletion, waiting for UWS bock populate the pod status.
 			return nil
 		}
+		if isEvicted(vPod) {
+			return c.evictPod(clusterName, targetNamespace, pPod, vPod)
+		}
 		deleteOptions := metav1.NewDeleteOptions(*vPod.DeletionGracePeriodSeconds)
 		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pPod.UID))
 		err := c.client.Pods(targetNamespace).Delete(context.TODO(), pPod.Name, *deleteOptions)
@@ -355,13 +430,26 @@ func (c *controller) reconcilePodUpdate(clusterName, targetNamespace, requestUID
 		}
 		return err
 	}
+	if featuregate.DefaultFeatureGate.Enabled(featuregate.KubeApiAccessSupport) {
+		if err := c.ensureProjectedTokens(clusterName, pPod, vPod); err != nil {
+			return err
+		}
+	}
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
 		return err
 	}
 	updatedPod := conversion.Equality(c.Config, vc).CheckPodEquality(pPod, vPod)
 	if updatedPod != nil {
-		pPod, err = c.client.Pods(targetNamespace).Update(context.TODO(), updatedPod, metav1.UpdateOptions{})
+		spanCtx, span := tracing.Tracer().Start(ctx, "update super pod")
+		pPod, err = c.client.Pods(targetNamespace).Update(spanCtx, updatedPod, metav1.UpdateOptions{})
+		tracing.End(span, err)
+		if err != nil {
+			return err
+		}
+	}
+	if added := conversion.CheckPodEphemeralContainersEquality(pPod, vPod); len(added) != 0 {
+		pPod, err = c.reconcilePodEphemeralContainers(clusterName, targetNamespace, pPod, vPod, added)
 		if err != nil {
 			return err
 		}
@@ -370,7 +458,9 @@ func (c *controller) reconcilePodUpdate(clusterName, targetNamespace, requestUID
 	if updatedPodStatus != nil {
 		updatedPod = pPod.DeepCopy()
 		updatedPod.Status = *updatedPodStatus
-		_, err = c.client.Pods(targetNamespace).UpdateStatus(context.TODO(), updatedPod, metav1.UpdateOptions{})
+		spanCtx, span := tracing.Tracer().Start(ctx, "update super pod status")
+		_, err = c.client.Pods(targetNamespace).UpdateStatus(spanCtx, updatedPod, metav1.UpdateOptions{})
+		tracing.End(span, err)
 		if err != nil {
 			return err
 		}
@@ -378,6 +468,33 @@ func (c *controller) reconcilePodUpdate(clusterName, targetNamespace, requestUID
 	return nil
 }
 
+// reconcilePodEphemeralContainers adds the ephemeral containers added to vPod,
+// e.g. by kubectl debug, to pPod through the ephemeralcontainers subresource.
+func (c *controller) reconcilePodEphemeralContainers(clusterName, targetNamespace string, pPod, vPod *corev1.Pod, added []corev1.EphemeralContainer) (*corev1.Pod, error) {
+	pSecretMap, err := c.findPodServiceAccountSecret(clusterName, pPod, vPod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service account secret from cluster %s cache: %v", clusterName, err)
+	}
+	services, err := c.getPodRelatedServices(clusterName, pPod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services from cluster %s cache: %v", clusterName, err)
+	}
+	conversion.MutateEphemeralContainers(added, pPod, vPod, clusterName, pSecretMap, services)
+
+	ephemeralContainers := &corev1.EphemeralContainers{
+		ObjectMeta:          *pPod.ObjectMeta.DeepCopy(),
+		EphemeralContainers: append(pPod.DeepCopy().Spec.EphemeralContainers, added...),
+	}
+	result, err := c.client.Pods(targetNamespace).UpdateEphemeralContainers(context.TODO(), pPod.Name, ephemeralContainers, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	updated := pPod.DeepCopy()
+	updated.ObjectMeta = result.ObjectMeta
+	updated.Spec.EphemeralContainers = result.EphemeralContainers
+	return updated, nil
+}
+
 func (c *controller) reconcilePodRemove(clusterName, targetNamespace, requestUID, name string, pPod *corev1.Pod) error {
 	if pPod.Annotations[constants.LabelUID] != requestUID {
 		return fmt.Errorf("to be deleted pPod %s/%s delegated UID is different from deleted object", targetNamespace, name)
@@ -399,10 +516,10 @@ func recordOperationDuration(operation string, start time.Time) {
 	metrics.PodOperationsDuration.WithLabelValues(operation).Observe(metrics.SinceInSeconds(start))
 }
 
-func recordOperationStatus(operation string, err error) {
+func recordOperationStatus(clusterName, operation string, err error) {
 	if err != nil {
-		metrics.PodOperations.With(prometheus.Labels{"operation_type": operation, "code": utilconstants.StatusCodeError}).Inc()
+		metrics.RecordPodOperationStatus(operation, clusterName, utilconstants.StatusCodeError)
 		return
 	}
-	metrics.PodOperations.With(prometheus.Labels{"operation_type": operation, "code": utilconstants.StatusCodeOK}).Inc()
+	metrics.RecordPodOperationStatus(operation, clusterName, utilconstants.StatusCodeOK)
 }
@@ -26,11 +26,14 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 
@@ -528,6 +531,49 @@ func TestDWPodCreation(t *testing.T) {
 	}
 }
 
+func TestDWPodCreationExceedingQuota(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			Quota: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("1"),
+			},
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	existingObjectInTenant := []runtime.Object{
+		tenantPod("pod-1", "default", "12345"),
+		tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+		tenantServiceAccount("default", "default", "12345"),
+	}
+	actions, reconcileErr, err := util.RunDownwardSync(NewPodController, testTenant,
+		[]runtime.Object{
+			superSecret("default-token-12345", superDefaultNSName, "s12345"),
+			superService("kubernetes", superDefaultNSName, "12345", ""),
+			superPod(defaultClusterKey, testTenant.Name, testTenant.Namespace, "pod-0", "default", "12344"),
+		},
+		existingObjectInTenant, existingObjectInTenant[0], nil)
+	if err != nil {
+		t.Fatalf("error running downward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+	}
+	if len(actions) != 0 {
+		t.Errorf("expected no pod to be created, got %#v", actions)
+	}
+}
+
 func TestDWPodDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -642,6 +688,104 @@ func TestDWPodDeletion(t *testing.T) {
 	}
 }
 
+func applyAnnotationToPod(pod *corev1.Pod, key, value string) *corev1.Pod {
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[key] = value
+	return pod
+}
+
+func applyEvictionToPod(vPod *corev1.Pod) *corev1.Pod {
+	vPod.Status.Conditions = append(vPod.Status.Conditions, corev1.PodCondition{
+		Type:   "DisruptionTarget",
+		Status: corev1.ConditionTrue,
+		Reason: "EvictionByEvictionAPI",
+	})
+	return vPod
+}
+
+func TestDWPodEviction(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	defaultVCName, defaultVCNamespace := testTenant.Name, testTenant.Namespace
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	blockedByPDB := func(tenantClientset, superClientset *fake.Clientset) {
+		superClientset.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		})
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInTenant *corev1.Pod
+		ClientSetMutator       util.FakeClientSetMutator
+		ExpectedEviction       bool
+		ExpectedError          string
+	}{
+		"vPod evicted with annotation": {
+			ExistingObjectInTenant: applyAnnotationToPod(applyDeletionTimestampToPod(tenantPod("pod-1", "default", "12345"), time.Now(), 30), constants.LabelEvicted, "true"),
+			ExpectedEviction:       true,
+		},
+		"vPod evicted with disruption condition": {
+			ExistingObjectInTenant: applyEvictionToPod(applyDeletionTimestampToPod(tenantPod("pod-1", "default", "12345"), time.Now(), 30)),
+			ExpectedEviction:       true,
+		},
+		"vPod eviction blocked by super PDB": {
+			ExistingObjectInTenant: applyEvictionToPod(applyDeletionTimestampToPod(tenantPod("pod-1", "default", "12345"), time.Now(), 30)),
+			ClientSetMutator:       blockedByPDB,
+			ExpectedEviction:       true,
+			ExpectedError:          "eviction of pPod " + superDefaultNSName + "/pod-1 is blocked",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPodController, testTenant,
+				[]runtime.Object{superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345")},
+				[]runtime.Object{tc.ExistingObjectInTenant},
+				tc.ExistingObjectInTenant,
+				tc.ClientSetMutator)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			if len(actions) != 1 {
+				t.Errorf("%s: Expected to evict pod. Actual actions were: %#v", k, actions)
+				return
+			}
+			if !actions[0].Matches("create", "pods") || actions[0].GetSubresource() != "eviction" {
+				t.Errorf("%s: Unexpected action %s", k, actions[0])
+			}
+		})
+	}
+}
+
 func applySpecToPod(pod *corev1.Pod, spec *corev1.PodSpec) *corev1.Pod {
 	pod.Spec = *spec.DeepCopy()
 	return pod
@@ -788,3 +932,110 @@ func TestDWPodUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestDWPodEphemeralContainers(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	defaultVCName, defaultVCNamespace := testTenant.Name, testTenant.Namespace
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+	spec := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Image: "ngnix",
+				Name:  "c-1",
+			},
+		},
+		NodeName: "i-xxx",
+	}
+	debugger := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:  "debugger",
+			Image: "busybox",
+			Env: []corev1.EnvVar{
+				{
+					Name: "NAMESPACE",
+					ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+					},
+				},
+			},
+		},
+		TargetContainerName: "c-1",
+	}
+	debugSpec := spec.DeepCopy()
+	debugSpec.EphemeralContainers = []corev1.EphemeralContainer{debugger}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedContainers     []string
+	}{
+		"ephemeral container added": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), spec),
+				superService("kubernetes", superDefaultNSName, "12345", "192.168.0.1"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySpecToPod(tenantPod("pod-1", "default", "12345"), debugSpec),
+			},
+			ExpectedContainers: []string{"debugger"},
+		},
+		"ephemeral container already added": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), debugSpec),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySpecToPod(tenantPod("pod-1", "default", "12345"), debugSpec),
+			},
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPodController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				return
+			}
+
+			if len(tc.ExpectedContainers) == 0 {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("update", "pods") || actions[0].GetSubresource() != "ephemeralcontainers" {
+				t.Errorf("%s: Expected ephemeral containers to be updated, got actions %#v", k, actions)
+				return
+			}
+			updated := actions[0].(core.UpdateAction).GetObject().(*corev1.EphemeralContainers)
+			if len(updated.EphemeralContainers) != len(tc.ExpectedContainers) {
+				t.Errorf("%s: Expected ephemeral containers %v, got %v", k, tc.ExpectedContainers, updated.EphemeralContainers)
+				return
+			}
+			for i, name := range tc.ExpectedContainers {
+				c := updated.EphemeralContainers[i]
+				if c.Name != name {
+					t.Errorf("%s: Expected ephemeral container %s, got %s", k, name, c.Name)
+				}
+				if c.Env[0].ValueFrom != nil || c.Env[0].Value != "default" {
+					t.Errorf("%s: Expected downward API env to be mutated, got %+v", k, c.Env[0])
+				}
+			}
+		})
+	}
+}
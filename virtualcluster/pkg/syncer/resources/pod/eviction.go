@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+const (
+	// podDisruptionTarget is the condition the Eviction API adds to a pod
+	// before deleting it, see the PodDisruptionConditions feature.
+	podDisruptionTarget corev1.PodConditionType = "DisruptionTarget"
+	// evictionByEvictionAPI is the reason of the podDisruptionTarget condition
+	// added by the Eviction API.
+	evictionByEvictionAPI = "EvictionByEvictionAPI"
+
+	reasonEvictionBlocked = "EvictionBlocked"
+)
+
+// isEvicted checks whether the deletion of vPod was initiated by the Eviction API.
+// The syncer only sees the deleted pod, the Eviction API calls are not synced:
+// the tenant control plane must add the DisruptionTarget condition, which
+// it does from Kubernetes 1.26 on, or the pod must carry the LabelEvicted
+// annotation. Other evicted pods are deleted from the super control plane.
+func isEvicted(vPod *corev1.Pod) bool {
+	if vPod.Annotations[constants.LabelEvicted] == "true" {
+		return true
+	}
+	_, cond := getPodCondition(&vPod.Status, podDisruptionTarget)
+	return cond != nil && cond.Status == corev1.ConditionTrue && cond.Reason == evictionByEvictionAPI
+}
+
+// evictPod evicts pPod so that the super control plane PodDisruptionBudgets
+// are honored. The policy/v1beta1 Eviction is used as client-go v0.21 has no
+// policy/v1 Evict. If the eviction is rejected, the tenant is informed with an
+// event and an error is returned to retry later.
+func (c *controller) evictPod(clusterName, targetNamespace string, pPod, vPod *corev1.Pod) error {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pPod.Name,
+			Namespace: targetNamespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: vPod.DeletionGracePeriodSeconds,
+			Preconditions:      metav1.NewUIDPreconditions(string(pPod.UID)),
+		},
+	}
+	err := c.client.Pods(targetNamespace).Evict(context.TODO(), eviction)
+	switch {
+	case err == nil, apierrors.IsNotFound(err):
+		return nil
+	case apierrors.IsTooManyRequests(err):
+		klog.Warningf("eviction of pod %s/%s of cluster %s is blocked: %v", vPod.Namespace, vPod.Name, clusterName, err)
+		if eventErr := c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      vPod.Name,
+			Namespace: vPod.Namespace,
+			UID:       vPod.UID,
+		}, corev1.EventTypeWarning, reasonEvictionBlocked, "Cannot evict pod from the super control plane: %v", err); eventErr != nil {
+			klog.Errorf("failed to send event to pod %s/%s of cluster %s: %v", vPod.Namespace, vPod.Name, clusterName, eventErr)
+		}
+		return fmt.Errorf("eviction of pPod %s/%s is blocked: %v", targetNamespace, pPod.Name, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const reasonPodSecurityViolation = "PodSecurityViolation"
+
+// isPodSecurityViolation checks whether err is the rejection of a pod by the
+// Pod Security Admission of the super control plane.
+func isPodSecurityViolation(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), "violates PodSecurity")
+}
+
+// rejectPodSecurity reports the admission message on vPod with an event and
+// an unschedulable PodScheduled condition. The pod is retried by the patroller
+// only, so that relaxing the policy eventually creates the pod.
+func (c *controller) rejectPodSecurity(clusterName string, vPod *corev1.Pod, rejection error) error {
+	message := rejection.Error()
+	if status, ok := rejection.(apierrors.APIStatus); ok {
+		message = status.Status().Message
+	}
+	klog.Warningf("pod %s/%s of cluster %s is rejected by pod security admission: %s", vPod.Namespace, vPod.Name, clusterName, message)
+	if err := c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:      "Pod",
+		Name:      vPod.Name,
+		Namespace: vPod.Namespace,
+		UID:       vPod.UID,
+	}, corev1.EventTypeWarning, reasonPodSecurityViolation, "Error creating: %s", message); err != nil {
+		return err
+	}
+
+	if _, cond := getPodCondition(&vPod.Status, corev1.PodScheduled); cond != nil &&
+		cond.Status == corev1.ConditionFalse && cond.Reason == reasonPodSecurityViolation && cond.Message == message {
+		return nil
+	}
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	updated := vPod.DeepCopy()
+	condition := corev1.PodCondition{
+		Type:               corev1.PodScheduled,
+		Status:             corev1.ConditionFalse,
+		Reason:             reasonPodSecurityViolation,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	if i, _ := getPodCondition(&updated.Status, corev1.PodScheduled); i >= 0 {
+		updated.Status.Conditions[i] = condition
+	} else {
+		updated.Status.Conditions = append(updated.Status.Conditions, condition)
+	}
+	_, err = tenantClient.CoreV1().Pods(vPod.Namespace).UpdateStatus(context.TODO(), updated, metav1.UpdateOptions{})
+	return err
+}
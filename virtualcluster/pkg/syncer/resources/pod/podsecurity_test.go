@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestDWPodSecurityViolation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	violation := `violates PodSecurity "restricted:latest": allowPrivilegeEscalation != false`
+	var updatedStatus *corev1.Pod
+	rejectByPSA := func(tenantClientset, superClientset *fake.Clientset) {
+		superClientset.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "pod-1", fmt.Errorf(violation))
+		})
+		tenantClientset.PrependReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() == "status" {
+				updatedStatus = action.(core.UpdateAction).GetObject().(*corev1.Pod)
+			}
+			return false, nil, nil
+		})
+	}
+
+	existingObjectInTenant := []runtime.Object{
+		tenantPod("pod-1", "default", "12345"),
+		tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+		tenantServiceAccount("default", "default", "12345"),
+	}
+	actions, reconcileErr, err := util.RunDownwardSync(NewPodController, testTenant,
+		[]runtime.Object{
+			superSecret("default-token-12345", superDefaultNSName, "s12345"),
+			superService("kubernetes", superDefaultNSName, "12345", ""),
+		},
+		existingObjectInTenant, existingObjectInTenant[0], rejectByPSA)
+	if err != nil {
+		t.Fatalf("error running downward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Fatalf("expected rejected pod not to be retried, but got \"%v\"", reconcileErr)
+	}
+	if len(actions) != 1 || !actions[0].Matches("create", "pods") {
+		t.Fatalf("expected pod creation to be attempted once, got %#v", actions)
+	}
+
+	if updatedStatus == nil {
+		t.Fatalf("expected vPod status to be updated")
+	}
+	_, cond := getPodCondition(&updatedStatus.Status, corev1.PodScheduled)
+	if cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != reasonPodSecurityViolation {
+		t.Fatalf("expected PodScheduled condition with reason %s, got %+v", reasonPodSecurityViolation, cond)
+	}
+	if cond.Message != `pods "pod-1" is forbidden: `+violation {
+		t.Errorf("expected admission message on vPod, got %q", cond.Message)
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+const (
+	// projectedTokenKey is the key of the token in a projected token secret.
+	projectedTokenKey = "token"
+	// defaultProjectedTokenExpirationSeconds is the expiration the apiserver
+	// defaults serviceAccountToken projections to.
+	defaultProjectedTokenExpirationSeconds = 3600
+	// projectedTokenSecretPrefix prefixes the names of the projected token secrets.
+	projectedTokenSecretPrefix = "vc-projected-token-"
+)
+
+// projectedTokenSecretName returns the name of the super secret holding the
+// token of the serviceAccountToken projection at index source of volume of
+// pPod. The name is a hash of the pod and volume names and of the index, so
+// that it stays a valid name whatever their length and each projection of a
+// volume gets its own audience and expiration.
+func projectedTokenSecretName(pPod *corev1.Pod, volume string, source int) string {
+	digest := sha256.Sum256([]byte(pPod.Name + "/" + volume + "/" + strconv.Itoa(source)))
+	return projectedTokenSecretPrefix + hex.EncodeToString(digest[:])[:32]
+}
+
+// projectedTokenOwner returns the pod controlling secret if it is a projected
+// token secret, nil otherwise. The secrets synced from the tenants have no
+// owner, so that they cannot pass for the token of a pod.
+func projectedTokenOwner(secret *corev1.Secret) *metav1.OwnerReference {
+	if secret.Labels[constants.LabelProjectedToken] != "true" {
+		return nil
+	}
+	owner := metav1.GetControllerOf(secret)
+	if owner == nil || owner.Kind != "Pod" || owner.APIVersion != corev1.SchemeGroupVersion.String() {
+		return nil
+	}
+	return owner
+}
+
+// checkProjectedTokens checks that the secrets the serviceAccountToken
+// projections of vPod are read from in pPod, which is not created yet, do not
+// exist or are projected token secrets of a pod of the same name, which are
+// replaced once pPod is created.
+func (c *controller) checkProjectedTokens(pPod, vPod *corev1.Pod) error {
+	for _, volume := range vPod.Spec.Volumes {
+		if volume.Projected == nil {
+			continue
+		}
+		for j, source := range volume.Projected.Sources {
+			if source.ServiceAccountToken == nil {
+				continue
+			}
+			name := projectedTokenSecretName(pPod, volume.Name, j)
+			secret, err := c.secretLister.Secrets(pPod.Namespace).Get(name)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if owner := projectedTokenOwner(secret); owner == nil || owner.Name != pPod.Name {
+				return fmt.Errorf("secret %s/%s conflicts with the projected token of volume %s", pPod.Namespace, name, volume.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// mutateProjectedTokens replaces the serviceAccountToken projections of pPod
+// with projections of the super secrets which hold the tokens issued by the
+// tenant control plane.
+func mutateProjectedTokens(pPod *corev1.Pod) {
+	for i, volume := range pPod.Spec.Volumes {
+		if volume.Projected == nil {
+			continue
+		}
+		for j, source := range volume.Projected.Sources {
+			if source.ServiceAccountToken == nil {
+				continue
+			}
+			pPod.Spec.Volumes[i].Projected.Sources[j] = corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: projectedTokenSecretName(pPod, volume.Name, j)},
+					Items: []corev1.KeyToPath{
+						{Key: projectedTokenKey, Path: source.ServiceAccountToken.Path},
+					},
+				},
+			}
+		}
+	}
+}
+
+// ensureProjectedTokens creates the secrets holding the tokens of the
+// serviceAccountToken projections of vPod in the namespace of pPod. The
+// secrets are owned by pPod so that they are deleted with it. The secrets
+// left by a previous pod of the same name are replaced.
+func (c *controller) ensureProjectedTokens(clusterName string, pPod, vPod *corev1.Pod) error {
+	for _, volume := range vPod.Spec.Volumes {
+		if volume.Projected == nil {
+			continue
+		}
+		for j, source := range volume.Projected.Sources {
+			if source.ServiceAccountToken == nil {
+				continue
+			}
+			name := projectedTokenSecretName(pPod, volume.Name, j)
+			existing, err := c.secretLister.Secrets(pPod.Namespace).Get(name)
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil:
+				return err
+			default:
+				owner := projectedTokenOwner(existing)
+				if owner == nil || owner.Name != pPod.Name {
+					return fmt.Errorf("secret %s/%s conflicts with the projected token of volume %s", pPod.Namespace, name, volume.Name)
+				}
+				if owner.UID == pPod.UID {
+					continue
+				}
+				err = c.client.Secrets(pPod.Namespace).Delete(context.TODO(), name, *metav1.NewPreconditionDeleteOptions(string(existing.UID)))
+				if err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+			}
+
+			expirationSeconds := pointer.Int64Deref(source.ServiceAccountToken.ExpirationSeconds, defaultProjectedTokenExpirationSeconds)
+			token, err := c.requestProjectedToken(clusterName, vPod, source.ServiceAccountToken.Audience, expirationSeconds)
+			if err != nil {
+				return err
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: pPod.Namespace,
+					Labels: map[string]string{
+						constants.LabelProjectedToken: "true",
+					},
+					Annotations: map[string]string{
+						constants.LabelProjectedTokenAudience:          source.ServiceAccountToken.Audience,
+						constants.LabelProjectedTokenExpirationSeconds: strconv.FormatInt(expirationSeconds, 10),
+						constants.LabelProjectedTokenExpiration:        token.Status.ExpirationTimestamp.Format(time.RFC3339),
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(pPod, corev1.SchemeGroupVersion.WithKind("Pod")),
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					projectedTokenKey: []byte(token.Status.Token),
+				},
+			}
+			_, err = c.client.Secrets(pPod.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// requestProjectedToken requests a token bound to vPod from the tenant control plane.
+func (c *controller) requestProjectedToken(clusterName string, vPod *corev1.Pod, audience string, expirationSeconds int64) (*authenticationv1.TokenRequest, error) {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: pointer.Int64Ptr(expirationSeconds),
+			BoundObjectRef: &authenticationv1.BoundObjectReference{
+				Kind:       "Pod",
+				APIVersion: "v1",
+				Name:       vPod.Name,
+				UID:        vPod.UID,
+			},
+		},
+	}
+	if audience != "" {
+		tokenRequest.Spec.Audiences = []string{audience}
+	}
+	tokenRequest, err = tenantClient.CoreV1().ServiceAccounts(vPod.Namespace).CreateToken(context.TODO(), vPod.Spec.ServiceAccountName, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token of service account %s/%s from cluster %s: %v", vPod.Namespace, vPod.Spec.ServiceAccountName, clusterName, err)
+	}
+	return tokenRequest, nil
+}
+
+// needsRefresh checks whether the token in secret has passed 80% of its
+// lifetime, the same threshold the kubelet uses for projected tokens.
+func needsRefresh(secret *corev1.Secret, now time.Time) bool {
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[constants.LabelProjectedTokenExpiration])
+	if err != nil {
+		return true
+	}
+	expirationSeconds, err := strconv.ParseInt(secret.Annotations[constants.LabelProjectedTokenExpirationSeconds], 10, 64)
+	if err != nil {
+		return true
+	}
+	return now.After(expiration.Add(-time.Duration(expirationSeconds) * time.Second / 5))
+}
+
+// refreshProjectedTokens renews the projected token secrets which are close to expiry.
+func (c *controller) refreshProjectedTokens() {
+	secrets, err := c.secretLister.List(labels.SelectorFromSet(map[string]string{
+		constants.LabelProjectedToken: "true",
+	}))
+	if err != nil {
+		klog.Errorf("failed to list projected token secrets: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, secret := range secrets {
+		if !needsRefresh(secret, now) {
+			continue
+		}
+		if err := c.refreshProjectedToken(secret); err != nil {
+			klog.Errorf("failed to refresh projected token secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+	}
+}
+
+func (c *controller) refreshProjectedToken(secret *corev1.Secret) error {
+	owner := projectedTokenOwner(secret)
+	if owner == nil {
+		return nil
+	}
+	pPod, err := c.podLister.Pods(secret.Namespace).Get(owner.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// pPod is gone, the secret is garbage collected.
+			return nil
+		}
+		return err
+	}
+	if pPod.UID != owner.UID {
+		// the secret of a previous pod, replaced once the pod is synced.
+		return nil
+	}
+	clusterName, vNamespace := conversion.GetVirtualOwner(pPod)
+	if clusterName == "" || vNamespace == "" {
+		return nil
+	}
+	vPod := &corev1.Pod{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pPod.Name, vPod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pPod.Annotations[constants.LabelUID] != string(vPod.UID) {
+		return nil
+	}
+
+	expirationSeconds, err := strconv.ParseInt(secret.Annotations[constants.LabelProjectedTokenExpirationSeconds], 10, 64)
+	if err != nil {
+		expirationSeconds = defaultProjectedTokenExpirationSeconds
+	}
+	token, err := c.requestProjectedToken(clusterName, vPod, secret.Annotations[constants.LabelProjectedTokenAudience], expirationSeconds)
+	if err != nil {
+		return err
+	}
+	updated := secret.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = make(map[string][]byte)
+	}
+	updated.Data[projectedTokenKey] = []byte(token.Status.Token)
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string)
+	}
+	updated.Annotations[constants.LabelProjectedTokenExpiration] = token.Status.ExpirationTimestamp.Format(time.RFC3339)
+	_, err = c.client.Secrets(secret.Namespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+	return err
+}
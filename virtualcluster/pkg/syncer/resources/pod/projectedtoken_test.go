@@ -0,0 +1,348 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func projectedTokenPod(name, namespace, uid string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "default",
+			Containers: []corev1.Container{
+				{
+					Image: "busybox",
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "kube-api-access-abcde",
+							MountPath: "/var/run/secrets/kubernetes.io/serviceaccount",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "kube-api-access-abcde",
+					VolumeSource: corev1.VolumeSource{
+						Projected: &corev1.ProjectedVolumeSource{
+							Sources: []corev1.VolumeProjection{
+								{
+									ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+										Audience:          "vault",
+										ExpirationSeconds: pointer.Int64Ptr(3607),
+										Path:              "token",
+									},
+								},
+								{
+									ConfigMap: &corev1.ConfigMapProjection{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "kube-root-ca.crt"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMutateProjectedTokens(t *testing.T) {
+	pPod := projectedTokenPod("pod-1", "default", "12345")
+	pPod.Spec.Volumes[0].Projected.Sources = append(pPod.Spec.Volumes[0].Projected.Sources, corev1.VolumeProjection{
+		ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Audience: "other", Path: "other-token"},
+	})
+	mutateProjectedTokens(pPod)
+
+	sources := pPod.Spec.Volumes[0].Projected.Sources
+	if sources[0].ServiceAccountToken != nil || sources[0].Secret == nil {
+		t.Fatalf("expected serviceAccountToken projection to be replaced by a secret projection, got %+v", sources[0])
+	}
+	if name := projectedTokenSecretName(pPod, "kube-api-access-abcde", 0); sources[0].Secret.Name != name {
+		t.Errorf("expected secret %s, got %s", name, sources[0].Secret.Name)
+	}
+	if len(sources[0].Secret.Items) != 1 || sources[0].Secret.Items[0].Key != projectedTokenKey || sources[0].Secret.Items[0].Path != "token" {
+		t.Errorf("unexpected secret projection items %+v", sources[0].Secret.Items)
+	}
+	if sources[1].ConfigMap == nil {
+		t.Errorf("expected configmap projection to be kept, got %+v", sources[1])
+	}
+	if sources[2].Secret == nil || sources[2].Secret.Name == sources[0].Secret.Name {
+		t.Errorf("expected each projected token to be read from its own secret, got %+v", sources[2])
+	}
+}
+
+func TestProjectedTokenSecretName(t *testing.T) {
+	long := projectedTokenPod(strings.Repeat("p", validation.DNS1123SubdomainMaxLength), "default", "12345")
+	name := projectedTokenSecretName(long, strings.Repeat("v", validation.DNS1123LabelMaxLength), 0)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		t.Errorf("expected a valid secret name, got %s: %v", name, errs)
+	}
+	pPod := projectedTokenPod("pod-1", "default", "12345")
+	if projectedTokenSecretName(pPod, "a-b", 0) == projectedTokenSecretName(projectedTokenPod("pod-1-a", "default", "12345"), "b", 0) {
+		t.Errorf("expected distinct secret names for distinct pod and volume names")
+	}
+	if projectedTokenSecretName(pPod, "a", 0) == projectedTokenSecretName(pPod, "a", 1) {
+		t.Errorf("expected distinct secret names for distinct projections of a volume")
+	}
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	now := time.Now()
+	secret := func(expiration time.Time, expirationSeconds string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.LabelProjectedTokenExpiration:        expiration.Format(time.RFC3339),
+					constants.LabelProjectedTokenExpirationSeconds: expirationSeconds,
+				},
+			},
+		}
+	}
+	for name, tc := range map[string]struct {
+		secret   *corev1.Secret
+		expected bool
+	}{
+		"fresh token":             {secret: secret(now.Add(time.Hour), "3600"), expected: false},
+		"token close to expiry":   {secret: secret(now.Add(10*time.Minute), "3600"), expected: true},
+		"expired token":           {secret: secret(now.Add(-time.Minute), "3600"), expected: true},
+		"missing expiration info": {secret: &corev1.Secret{}, expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := needsRefresh(tc.secret, now); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDWPodProjectedToken(t *testing.T) {
+	if err := featuregate.DefaultFeatureGate.Set(featuregate.KubeApiAccessSupport, true); err != nil {
+		t.Fatalf("failed to enable feature gate: %v", err)
+	}
+	defer featuregate.DefaultFeatureGate.Set(featuregate.KubeApiAccessSupport, false)
+
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	var tokenRequest *authenticationv1.TokenRequest
+	issueToken := func(tenantClientset, superClientset *fake.Clientset) {
+		tenantClientset.PrependReactor("create", "serviceaccounts", func(action core.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "token" {
+				return false, nil, nil
+			}
+			tokenRequest = action.(core.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+			return true, &authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{
+					Token:               "t0ken",
+					ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+				},
+			}, nil
+		})
+	}
+
+	vPod := projectedTokenPod("pod-1", "default", "12345")
+	actions, reconcileErr, err := util.RunDownwardSync(NewPodController, testTenant,
+		[]runtime.Object{superService("kubernetes", superDefaultNSName, "12345", "")},
+		[]runtime.Object{vPod, tenantServiceAccount("default", "default", "12345")},
+		vPod,
+		issueToken)
+	if err != nil {
+		t.Fatalf("error running downward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Fatalf("expected no error, but got \"%v\"", reconcileErr)
+	}
+
+	if len(actions) != 2 || !actions[0].Matches("create", "pods") || !actions[1].Matches("create", "secrets") {
+		t.Fatalf("expected pod and token secret to be created, got %#v", actions)
+	}
+	pPod := actions[0].(core.CreateAction).GetObject().(*corev1.Pod)
+	secretName := projectedTokenSecretName(pPod, "kube-api-access-abcde", 0)
+	if source := pPod.Spec.Volumes[0].Projected.Sources[0]; source.Secret == nil || source.Secret.Name != secretName {
+		t.Errorf("expected projected token to be read from secret, got %+v", source)
+	}
+	secret := actions[1].(core.CreateAction).GetObject().(*corev1.Secret)
+	if secret.Name != secretName || string(secret.Data[projectedTokenKey]) != "t0ken" {
+		t.Errorf("unexpected token secret %+v", secret)
+	}
+	if owner := metav1.GetControllerOf(secret); owner == nil || owner.Kind != "Pod" || owner.Name != "pod-1" {
+		t.Errorf("expected token secret to be owned by pPod, got %+v", owner)
+	}
+
+	if tokenRequest == nil {
+		t.Fatalf("expected a token to be requested from tenant")
+	}
+	if len(tokenRequest.Spec.Audiences) != 1 || tokenRequest.Spec.Audiences[0] != "vault" {
+		t.Errorf("expected token for audience vault, got %v", tokenRequest.Spec.Audiences)
+	}
+	if *tokenRequest.Spec.ExpirationSeconds != 3607 {
+		t.Errorf("expected token to expire in 3607 seconds, got %d", *tokenRequest.Spec.ExpirationSeconds)
+	}
+	if ref := tokenRequest.Spec.BoundObjectRef; ref == nil || ref.Kind != "Pod" || ref.UID != vPod.UID {
+		t.Errorf("expected token to be bound to vPod, got %+v", ref)
+	}
+}
+
+func TestDWPodProjectedTokenConflict(t *testing.T) {
+	if err := featuregate.DefaultFeatureGate.Set(featuregate.KubeApiAccessSupport, true); err != nil {
+		t.Fatalf("failed to enable feature gate: %v", err)
+	}
+	defer featuregate.DefaultFeatureGate.Set(featuregate.KubeApiAccessSupport, false)
+
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+	secretName := projectedTokenSecretName(projectedTokenPod("pod-1", superDefaultNSName, ""), "kube-api-access-abcde", 0)
+
+	issueToken := func(tenantClientset, superClientset *fake.Clientset) {
+		tenantClientset.PrependReactor("create", "serviceaccounts", func(action core.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "token" {
+				return false, nil, nil
+			}
+			return true, &authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{
+					Token:               "t0ken",
+					ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+				},
+			}, nil
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		secret      *corev1.Secret
+		expectedErr bool
+		expected    []string
+	}{
+		"secret synced from the tenant": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        secretName,
+					Namespace:   superDefaultNSName,
+					Labels:      map[string]string{constants.LabelProjectedToken: "true"},
+					Annotations: map[string]string{constants.LabelCluster: defaultClusterKey},
+				},
+				Data: map[string][]byte{projectedTokenKey: []byte("forged")},
+			},
+			expectedErr: true,
+		},
+		"secret of another pod": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: superDefaultNSName,
+					Labels:    map[string]string{constants.LabelProjectedToken: "true"},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(projectedTokenPod("pod-2", superDefaultNSName, "67890"), corev1.SchemeGroupVersion.WithKind("Pod")),
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		"secret of a previous pod of the same name": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: superDefaultNSName,
+					Labels:    map[string]string{constants.LabelProjectedToken: "true"},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(projectedTokenPod("pod-1", superDefaultNSName, "67890"), corev1.SchemeGroupVersion.WithKind("Pod")),
+					},
+				},
+			},
+			expected: []string{"create/pods", "delete/secrets", "create/secrets"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			vPod := projectedTokenPod("pod-1", "default", "12345")
+			actions, reconcileErr, err := util.RunDownwardSync(NewPodController, testTenant,
+				[]runtime.Object{superService("kubernetes", superDefaultNSName, "12345", ""), tc.secret},
+				[]runtime.Object{vPod, tenantServiceAccount("default", "default", "12345")},
+				vPod,
+				issueToken)
+			if err != nil {
+				t.Fatalf("error running downward sync: %v", err)
+			}
+			if tc.expectedErr {
+				if reconcileErr == nil || !strings.Contains(reconcileErr.Error(), "conflicts with the projected token") {
+					t.Errorf("expected conflict error, got %v", reconcileErr)
+				}
+				for _, action := range actions {
+					if action.Matches("create", "pods") {
+						t.Errorf("expected pod not to be created")
+					}
+				}
+				return
+			}
+			if reconcileErr != nil {
+				t.Fatalf("expected no error, but got \"%v\"", reconcileErr)
+			}
+			if len(actions) != len(tc.expected) {
+				t.Fatalf("expected actions %v, got %#v", tc.expected, actions)
+			}
+			for i, expected := range tc.expected {
+				verbResource := strings.SplitN(expected, "/", 2)
+				if !actions[i].Matches(verbResource[0], verbResource[1]) {
+					t.Errorf("expected action %s, got %#v", expected, actions[i])
+				}
+			}
+		})
+	}
+}
@@ -21,9 +21,11 @@ import (
 	"fmt"
 
 	pkgerr "github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -34,6 +36,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/tracing"
 )
 
 // StartUWS starts the upward syncer
@@ -45,7 +48,7 @@ func (c *controller) StartUWS(stopCh <-chan struct{}) error {
 	return c.UpwardController.Start(stopCh)
 }
 
-func (c *controller) BackPopulate(key string) error {
+func (c *controller) BackPopulate(key string) (err error) {
 	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
@@ -66,6 +69,16 @@ func (c *controller) BackPopulate(key string) error {
 		return nil
 	}
 
+	// The annotation stays on pPod for its whole life, so each upward sync
+	// starts its own trace linked to the downward sync which created pPod.
+	ctx, span := tracing.Tracer().Start(context.Background(), "Pod uws",
+		tracing.LinkFrom(constants.LabelTraceContext, pPod.Annotations),
+		trace.WithAttributes(tracing.RequestAttributes("Pod", reconciler.Request{
+			ClusterName:    clusterName,
+			NamespacedName: types.NamespacedName{Namespace: vNamespace, Name: pName},
+		})...))
+	defer func() { tracing.End(span, err) }()
+
 	vPod := &corev1.Pod{}
 	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vPod); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -85,7 +98,10 @@ func (c *controller) BackPopulate(key string) error {
 
 	// If tenant Pod has not been assigned, bind to virtual Node.
 	if vPod.Spec.NodeName == "" {
-		if err := c.bindPodToNode(pPod, clusterName, tenantClient, vPod); err != nil {
+		bindCtx, bindSpan := tracing.Tracer().Start(ctx, "bind tenant pod")
+		err := c.bindPodToNode(bindCtx, pPod, clusterName, tenantClient, vPod)
+		tracing.End(bindSpan, err)
+		if err != nil {
 			return err
 		}
 		// virtual pod has been updated, refetch the latest version
@@ -127,7 +143,10 @@ func (c *controller) BackPopulate(key string) error {
 			}
 		}
 		newPod.Status = *newStatus
-		if _, err = tenantClient.CoreV1().Pods(vPod.Namespace).UpdateStatus(context.TODO(), newPod, metav1.UpdateOptions{}); err != nil {
+		statusCtx, statusSpan := tracing.Tracer().Start(ctx, "update tenant pod status")
+		_, err = tenantClient.CoreV1().Pods(vPod.Namespace).UpdateStatus(statusCtx, newPod, metav1.UpdateOptions{})
+		tracing.End(statusSpan, err)
+		if err != nil {
 			return fmt.Errorf("failed to back populate pod %s/%s status update for cluster %s: %v", vPod.Namespace, vPod.Name, clusterName, err)
 		}
 	}
@@ -160,7 +179,7 @@ func (c *controller) BackPopulate(key string) error {
 	return nil
 }
 
-func (c *controller) bindPodToNode(pPod *corev1.Pod, clusterName string, tenantClient clientset.Interface, vPod *corev1.Pod) error {
+func (c *controller) bindPodToNode(ctx context.Context, pPod *corev1.Pod, clusterName string, tenantClient clientset.Interface, vPod *corev1.Pod) error {
 	n, err := c.client.Nodes().Get(context.TODO(), pPod.Spec.NodeName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get node %s from super control plane: %v", pPod.Spec.NodeName, err)
@@ -193,7 +212,7 @@ func (c *controller) bindPodToNode(pPod *corev1.Pod, clusterName string, tenantC
 		}
 	}
 
-	err = tenantClient.CoreV1().Pods(vPod.Namespace).Bind(context.TODO(), &corev1.Binding{
+	err = tenantClient.CoreV1().Pods(vPod.Namespace).Bind(ctx, &corev1.Binding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      vPod.Name,
 			Namespace: vPod.Namespace,
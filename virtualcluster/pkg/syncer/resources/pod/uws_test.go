@@ -135,6 +135,19 @@ func TestUWPodUpdate(t *testing.T) {
 		Phase: "Running",
 	}
 
+	statusDebugging := &corev1.PodStatus{
+		Phase: "Running",
+		EphemeralContainerStatuses: []corev1.ContainerStatus{
+			{
+				Name:  "debugger",
+				Image: "busybox",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			},
+		},
+	}
+
 	defaultClusterKey := conversion.ToClusterKey(testTenant)
 	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
 
@@ -159,6 +172,20 @@ func TestUWPodUpdate(t *testing.T) {
 			},
 			ExpectedError: "",
 		},
+		"update vPod ephemeral container status": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPod(superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey), statusDebugging),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusRunning),
+				fakeNode("n1"),
+			},
+			EnquedKey: superDefaultNSName + "/pod-1",
+			ExpectedUpdatedPods: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusDebugging),
+			},
+			ExpectedError: "",
+		},
 		"update vPod metadata": {
 			ExistingObjectInSuper: []runtime.Object{
 				applyLabelToPod(applyStatusToPod(superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey), statusRunning), opaqueMetaPrefix+"/a", "b"),
@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+var numSpecMissMatchedPDBs = metrics.NewMissMatchCounter("SpecMissMatchedPDBs")
+var numStatusMissMatchedPDBs = metrics.NewMissMatchCounter("StatusMissMatchedPDBs")
+var numUWMetaMissMatchedPDBs = metrics.NewMissMatchCounter("UWMetaMissMatchedPDBs")
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.pdbSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting PodDisruptionBudget checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if pdbs keep consistency between super
+// control plane and tenant control planes.
+func (c *controller) PatrollerDo() {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.V(5).Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "poddisruptionbudget")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	numSpecMissMatchedPDBs.Reset()
+	numStatusMissMatchedPDBs.Reset()
+	numUWMetaMissMatchedPDBs.Reset()
+
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(clusterName string) {
+			defer wg.Done()
+			c.checkPDBsOfTenantCluster(clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	pPDBs, err := c.pdbLister.List(util.GetSuperClusterListerLabelsSelector())
+	if err != nil {
+		klog.Errorf("error listing pdbs from super control plane informer cache: %v", err)
+		return
+	}
+
+	for _, pPDB := range pPDBs {
+		clusterName, vNamespace := conversion.GetVirtualOwner(pPDB)
+		if len(clusterName) == 0 || len(vNamespace) == 0 {
+			continue
+		}
+		shouldDelete := false
+		vPDB := &policyv1.PodDisruptionBudget{}
+		err := c.MultiClusterController.Get(clusterName, vNamespace, pPDB.Name, vPDB)
+		if apierrors.IsNotFound(err) {
+			shouldDelete = true
+		}
+		if err == nil {
+			if pPDB.Annotations[constants.LabelUID] != string(vPDB.UID) {
+				shouldDelete = true
+				klog.Warningf("Found pPDB %s/%s delegated UID is different from tenant object.", pPDB.Namespace, pPDB.Name)
+			}
+		}
+		if shouldDelete {
+			deleteOptions := metav1.NewPreconditionDeleteOptions(string(pPDB.UID))
+			if err = c.pdbClient.PodDisruptionBudgets(pPDB.Namespace).Delete(context.TODO(), pPDB.Name, *deleteOptions); err != nil {
+				klog.Errorf("error deleting pPDB %s/%s in super control plane: %v", pPDB.Namespace, pPDB.Name, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperControlPlanePDBs").Inc()
+			}
+		}
+	}
+
+	numSpecMissMatchedPDBs.Record()
+	numStatusMissMatchedPDBs.Record()
+	numUWMetaMissMatchedPDBs.Record()
+}
+
+func (c *controller) checkPDBsOfTenantCluster(clusterName string) {
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := c.MultiClusterController.List(clusterName, pdbList); err != nil {
+		klog.Errorf("error listing pdbs from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check pdbs consistency in cluster %s", clusterName)
+
+	for i, vPDB := range pdbList.Items {
+		targetNamespace := conversion.ToSuperClusterNamespace(clusterName, vPDB.Namespace)
+		pPDB, err := c.pdbLister.PodDisruptionBudgets(targetNamespace).Get(vPDB.Name)
+		if apierrors.IsNotFound(err) {
+			if err := c.MultiClusterController.RequeueObject(clusterName, &pdbList.Items[i]); err != nil {
+				klog.Errorf("error requeue vpdb %v/%v in cluster %s: %v", vPDB.Namespace, vPDB.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantPDBs").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pPDB %s/%s from super control plane cache: %v", targetNamespace, vPDB.Name, err)
+			continue
+		}
+
+		if pPDB.Annotations[constants.LabelUID] != string(vPDB.UID) {
+			klog.Errorf("Found pPDB %s/%s delegated UID is different from tenant object.", targetNamespace, pPDB.Name)
+			continue
+		}
+
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+		if err != nil {
+			klog.Errorf("fail to get cluster spec : %s", clusterName)
+			continue
+		}
+		updatedPDB := conversion.Equality(c.Config, vc).CheckPDBEquality(pPDB, &pdbList.Items[i])
+		if updatedPDB != nil {
+			numSpecMissMatchedPDBs.Inc(clusterName)
+			klog.Warningf("spec of pdb %v/%v diff in super&tenant control plane", vPDB.Namespace, vPDB.Name)
+			if err := c.MultiClusterController.RequeueObject(clusterName, &pdbList.Items[i]); err != nil {
+				klog.Errorf("error requeue vpdb %v/%v in cluster %s: %v", vPDB.Namespace, vPDB.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantPDBs").Inc()
+			}
+		}
+
+		enqueue := false
+		updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pPDB.ObjectMeta, &pdbList.Items[i].ObjectMeta)
+		if updatedMeta != nil {
+			numUWMetaMissMatchedPDBs.Inc(clusterName)
+			enqueue = true
+			klog.Warningf("UWObjectMeta of vPDB %v/%v diff in super&tenant control plane", vPDB.Namespace, vPDB.Name)
+		}
+		if conversion.Equality(c.Config, vc).CheckUWPDBStatusEquality(pPDB, &pdbList.Items[i]) != nil {
+			enqueue = true
+			numStatusMissMatchedPDBs.Inc(clusterName)
+			klog.Warningf("Status of vPDB %v/%v diff in super&tenant control plane", vPDB.Namespace, vPDB.Name)
+		}
+		if enqueue {
+			c.enqueuePDB(pPDB)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1policy "k8s.io/client-go/kubernetes/typed/policy/v1"
+	listerspolicyv1 "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "poddisruptionbudget",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewPDBController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// super control plane pdb client
+	pdbClient v1policy.PodDisruptionBudgetsGetter
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
+	// super control plane informer/listers/synced functions
+	pdbLister listerspolicyv1.PodDisruptionBudgetLister
+	pdbSynced cache.InformerSynced
+}
+
+func NewPDBController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		pdbClient: client.PolicyV1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&policyv1.PodDisruptionBudget{}, &policyv1.PodDisruptionBudgetList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return c.pdbClient.PodDisruptionBudgets(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return c.pdbClient.PodDisruptionBudgets(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
+
+	c.pdbLister = informer.Policy().V1().PodDisruptionBudgets().Lister()
+	if options.IsFake {
+		c.pdbSynced = func() bool { return true }
+	} else {
+		c.pdbSynced = informer.Policy().V1().PodDisruptionBudgets().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&policyv1.PodDisruptionBudget{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&policyv1.PodDisruptionBudget{}, c, pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Policy().V1().PodDisruptionBudgets().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *policyv1.PodDisruptionBudget:
+					return true
+				case cache.DeletedFinalStateUnknown:
+					if _, ok := t.Obj.(*policyv1.PodDisruptionBudget); ok {
+						return true
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *policyv1.PodDisruptionBudget", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super control plane pdb controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueuePDB,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newPDB := newObj.(*policyv1.PodDisruptionBudget)
+					oldPDB := oldObj.(*policyv1.PodDisruptionBudget)
+					if newPDB.ResourceVersion != oldPDB.ResourceVersion {
+						c.enqueuePDB(newObj)
+					}
+				},
+				DeleteFunc: c.enqueuePDB,
+			},
+		})
+	return c, nil
+}
+
+func (c *controller) enqueuePDB(obj interface{}) {
+	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		return
+	}
+
+	clusterName, _ := conversion.GetVirtualOwner(pdb)
+	if clusterName == "" {
+		return
+	}
+
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+	c.UpwardController.AddToQueue(key)
+}
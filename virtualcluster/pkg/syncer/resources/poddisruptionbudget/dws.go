@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.pdbSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting PodDisruptionBudget dws")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile pdb %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
+	pPDB, err := c.pdbLister.PodDisruptionBudgets(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+	vExists := true
+	vPDB := &policyv1.PodDisruptionBudget{}
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vPDB); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	switch {
+	case vExists && !pExists:
+		err := c.reconcilePDBCreate(request.ClusterName, targetNamespace, request.UID, vPDB)
+		if err != nil {
+			klog.Errorf("failed reconcile pdb %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	case !vExists && pExists:
+		err := c.reconcilePDBRemove(targetNamespace, request.UID, request.Name, pPDB)
+		if err != nil {
+			klog.Errorf("failed reconcile pdb %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	case vExists && pExists:
+		err := c.reconcilePDBUpdate(request.ClusterName, targetNamespace, request.UID, pPDB, vPDB)
+		if err != nil {
+			klog.Errorf("failed reconcile pdb %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	default:
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcilePDBCreate(clusterName, targetNamespace, requestUID string, pdb *policyv1.PodDisruptionBudget) error {
+	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, pdb)
+	if err != nil {
+		return err
+	}
+
+	pPDB := newObj.(*policyv1.PodDisruptionBudget)
+
+	pPDB, err = c.pdbClient.PodDisruptionBudgets(targetNamespace).Create(context.TODO(), pPDB, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		if pPDB.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("pdb %s/%s of cluster %s already exist in super control plane", targetNamespace, pPDB.Name, clusterName)
+			return nil
+		}
+		return fmt.Errorf("pPDB %s/%s exists but its delegated object UID is different", targetNamespace, pPDB.Name)
+	}
+	return err
+}
+
+func (c *controller) reconcilePDBUpdate(clusterName, targetNamespace, requestUID string, pPDB, vPDB *policyv1.PodDisruptionBudget) error {
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vPDB, pPDB)
+	if err != nil {
+		return err
+	}
+	pPDB = pObj.(*policyv1.PodDisruptionBudget)
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	updated := conversion.Equality(c.Config, vc).CheckPDBEquality(pPDB, vPDB)
+	if updated != nil {
+		_, err = c.pdbClient.PodDisruptionBudgets(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcilePDBRemove(targetNamespace, requestUID, name string, pPDB *policyv1.PodDisruptionBudget) error {
+	if pPDB.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("to be deleted pPDB %s/%s delegated UID is different from deleted object", targetNamespace, name)
+	}
+
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(pPDB.UID)),
+	}
+	err := c.pdbClient.PodDisruptionBudgets(targetNamespace).Delete(context.TODO(), name, *opts)
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("To be deleted pdb %s/%s not found in super control plane", targetNamespace, name)
+		return nil
+	}
+	return err
+}
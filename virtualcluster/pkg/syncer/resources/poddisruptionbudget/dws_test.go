@@ -0,0 +1,356 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"strings"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/client-go/testing"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func tenantPDB(name, namespace, uid string) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func superPDB(name, namespace, uid, clusterKey string) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelNamespace: "default",
+				constants.LabelCluster:   clusterKey,
+			},
+		},
+	}
+}
+
+func TestDWPDBCreation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant *policyv1.PodDisruptionBudget
+
+		ExpectedCreatedPDBs []string
+		ExpectedError       string
+	}{
+		"new pdb": {
+			ExistingObjectInSuper:  []runtime.Object{},
+			ExistingObjectInTenant: tenantPDB("pdb-1", "default", "12345"),
+			ExpectedCreatedPDBs:    []string{superDefaultNSName + "/pdb-1"},
+		},
+		"new pdb but already exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: tenantPDB("pdb-1", "default", "12345"),
+			ExpectedCreatedPDBs:    []string{},
+			ExpectedError:          "",
+		},
+		"new pdb but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb-1", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: tenantPDB("pdb-1", "default", "12345"),
+			ExpectedCreatedPDBs:    []string{},
+			ExpectedError:          "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPDBController,
+				testTenant,
+				tc.ExistingObjectInSuper,
+				[]runtime.Object{tc.ExistingObjectInTenant},
+				tc.ExistingObjectInTenant,
+				nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedCreatedPDBs) != len(actions) {
+				t.Errorf("%s: Expected to create pdb %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPDBs, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedCreatedPDBs {
+				action := actions[i]
+				if !action.Matches("create", "poddisruptionbudgets") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				created := action.(core.CreateAction).GetObject().(*policyv1.PodDisruptionBudget)
+				fullName := created.Namespace + "/" + created.Name
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func TestDWPDBDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper []runtime.Object
+		EnqueueObject         *policyv1.PodDisruptionBudget
+
+		ExpectedDeletedPDBs []string
+		ExpectedError       string
+	}{
+		"delete pdb": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:       tenantPDB("pdb-1", "default", "12345"),
+			ExpectedDeletedPDBs: []string{superDefaultNSName + "/pdb-1"},
+		},
+		"delete pdb but already gone": {
+			ExistingObjectInSuper: []runtime.Object{},
+			EnqueueObject:         tenantPDB("pdb-1", "default", "12345"),
+			ExpectedDeletedPDBs:   []string{},
+			ExpectedError:         "",
+		},
+		"delete pdb but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb-1", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			EnqueueObject:       tenantPDB("pdb-1", "default", "12345"),
+			ExpectedDeletedPDBs: []string{},
+			ExpectedError:       "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPDBController, testTenant, tc.ExistingObjectInSuper, nil, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedDeletedPDBs) != len(actions) {
+				t.Errorf("%s: Expected to delete pdb %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPDBs, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedDeletedPDBs {
+				action := actions[i]
+				if !action.Matches("delete", "poddisruptionbudgets") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func applySpecToPDB(ing *policyv1.PodDisruptionBudget, spec *policyv1.PodDisruptionBudgetSpec) *policyv1.PodDisruptionBudget {
+	ing.Spec = *spec.DeepCopy()
+	return ing
+}
+
+func TestDWPDBUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	minAvailable1 := intstr.FromInt(1)
+	spec1 := &policyv1.PodDisruptionBudgetSpec{
+		MinAvailable: &minAvailable1,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "nginx"},
+		},
+	}
+
+	spec2 := &policyv1.PodDisruptionBudgetSpec{
+		MinAvailable: &minAvailable1,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "nginx"},
+		},
+	}
+
+	minAvailable2 := intstr.FromString("50%")
+	spec3 := &policyv1.PodDisruptionBudgetSpec{
+		MinAvailable: &minAvailable2,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "haproxy"},
+		},
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant *policyv1.PodDisruptionBudget
+
+		ExpectedUpdatedPDBs []runtime.Object
+		ExpectedError       string
+	}{
+		"no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPDB(superPDB("pdb-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
+			},
+			ExistingObjectInTenant: applySpecToPDB(tenantPDB("pdb-1", "default", "12345"), spec2),
+			ExpectedUpdatedPDBs:    []runtime.Object{},
+		},
+		"diff exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPDB(superPDB("pdb-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
+			},
+			ExistingObjectInTenant: applySpecToPDB(tenantPDB("pdb-1", "default", "12345"), spec3),
+			ExpectedUpdatedPDBs: []runtime.Object{
+				applySpecToPDB(superPDB("pdb-1", superDefaultNSName, "12345", defaultClusterKey), spec3),
+			},
+		},
+		"diff exists but uid is wrong": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPDB(superPDB("pdb-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
+			},
+			ExistingObjectInTenant: applySpecToPDB(tenantPDB("pdb-1", "default", "123456"), spec3),
+			ExpectedUpdatedPDBs:    []runtime.Object{},
+			ExpectedError:          "delegated UID is different",
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPDBController,
+				testTenant,
+				tc.ExistingObjectInSuper,
+				[]runtime.Object{tc.ExistingObjectInTenant},
+				tc.ExistingObjectInTenant,
+				nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedUpdatedPDBs) != len(actions) {
+				t.Errorf("%s: Expected to update pdb %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPDBs, actions)
+				return
+			}
+			for i, obj := range tc.ExpectedUpdatedPDBs {
+				action := actions[i]
+				if !action.Matches("update", "poddisruptionbudgets") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				actionObj := action.(core.UpdateAction).GetObject()
+				if !equality.Semantic.DeepEqual(obj, actionObj) {
+					t.Errorf("%s: Expected updated pdb is %v, got %v", k, obj, actionObj)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"context"
+	"fmt"
+
+	pkgerr "github.com/pkg/errors"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.pdbSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
+		return nil
+	}
+
+	pPDB, err := c.pdbLister.PodDisruptionBudgets(pNamespace).Get(pName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	clusterName, vNamespace := conversion.GetVirtualOwner(pPDB)
+	if clusterName == "" || vNamespace == "" {
+		klog.Infof("drop pdb %s/%s which is not belongs to any tenant", pNamespace, pName)
+		return nil
+	}
+
+	vPDB := &policyv1.PodDisruptionBudget{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vPDB); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return pkgerr.Wrapf(err, "could not find pPDB %s/%s's vPDB in controller cache", vNamespace, pName)
+	}
+	if pPDB.Annotations[constants.LabelUID] != string(vPDB.UID) {
+		return fmt.Errorf("backPopulated pPDB %s/%s delegated UID is different from updated object", pPDB.Namespace, pPDB.Name)
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
+	}
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to get spec of cluster %s", clusterName)
+	}
+
+	var newPDB *policyv1.PodDisruptionBudget
+	updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pPDB.ObjectMeta, &vPDB.ObjectMeta)
+	if updatedMeta != nil {
+		newPDB = vPDB.DeepCopy()
+		newPDB.ObjectMeta = *updatedMeta
+	}
+
+	// the super status is exposed under an annotation, the tenant disruption controller owns the
+	// status of vPDB.
+	updatedStatus := conversion.Equality(c.Config, vc).CheckUWPDBStatusEquality(pPDB, vPDB)
+	if updatedStatus != nil {
+		if newPDB == nil {
+			newPDB = vPDB.DeepCopy()
+		}
+		if newPDB.Annotations == nil {
+			newPDB.Annotations = make(map[string]string)
+		}
+		newPDB.Annotations[constants.LabelSuperPDBStatus] = *updatedStatus
+	}
+
+	if newPDB != nil {
+		if _, err = tenantClient.PolicyV1().PodDisruptionBudgets(vPDB.Namespace).Update(context.TODO(), newPDB, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to back populate pdb %s/%s update for cluster %s: %v", vPDB.Namespace, vPDB.Name, clusterName, err)
+		}
+	}
+	return nil
+}
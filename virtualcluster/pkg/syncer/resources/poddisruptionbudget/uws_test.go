@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func applyStatusToPDB(pdb *policyv1.PodDisruptionBudget, status *policyv1.PodDisruptionBudgetStatus) *policyv1.PodDisruptionBudget {
+	pdb.Status = *status.DeepCopy()
+	return pdb
+}
+
+func applySuperStatusToPDB(pdb *policyv1.PodDisruptionBudget, status string) *policyv1.PodDisruptionBudget {
+	if pdb.Annotations == nil {
+		pdb.Annotations = make(map[string]string)
+	}
+	pdb.Annotations[constants.LabelSuperPDBStatus] = status
+	return pdb
+}
+
+func TestUWPDB(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueuedKey            string
+		ExpectedUpdatedObject  []runtime.Object
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"pPDB not found": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPDB("pdb", "default", "12345"),
+			},
+			EnqueuedKey:         superDefaultNSName + "/pdb",
+			ExpectedNoOperation: true,
+		},
+		"pPDB not created by syncer": {
+			ExistingObjectInSuper: []runtime.Object{
+				tenantPDB("kubernetes", superDefaultNSName, "12345"),
+			},
+			EnqueuedKey:         superDefaultNSName + "/kubernetes",
+			ExpectedNoOperation: true,
+		},
+		"pPDB exists but vPDB does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueuedKey:   superDefaultNSName + "/pdb",
+			ExpectedError: "",
+		},
+		"pPDB exists, vPDB exists with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPDB("pdb", "default", "12345"),
+			},
+			EnqueuedKey:   superDefaultNSName + "/pdb",
+			ExpectedError: "delegated UID is different",
+		},
+		"pPDB exists, vPDB exists with no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPDB("pdb", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPDB("pdb", "default", "12345"),
+			},
+			EnqueuedKey:         superDefaultNSName + "/pdb",
+			ExpectedNoOperation: true,
+		},
+		"pPDB exists, vPDB exists with status diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPDB(superPDB("pdb", superDefaultNSName, "12345", defaultClusterKey), &policyv1.PodDisruptionBudgetStatus{
+					ObservedGeneration: 3,
+					CurrentHealthy:     2,
+					DesiredHealthy:     1,
+					DisruptionsAllowed: 1,
+					ExpectedPods:       2,
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPDB("pdb", "default", "12345"),
+			},
+			EnqueuedKey: superDefaultNSName + "/pdb",
+			ExpectedUpdatedObject: []runtime.Object{
+				applySuperStatusToPDB(tenantPDB("pdb", "default", "12345"), `{"disruptionsAllowed":1,"currentHealthy":2,"desiredHealthy":1,"expectedPods":2}`),
+			},
+		},
+		"pPDB exists, vPDB exists with the super status and its own status": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPDB(superPDB("pdb", superDefaultNSName, "12345", defaultClusterKey), &policyv1.PodDisruptionBudgetStatus{
+					ObservedGeneration: 3,
+					CurrentHealthy:     2,
+					DesiredHealthy:     1,
+					DisruptionsAllowed: 1,
+					ExpectedPods:       2,
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPDB(applySuperStatusToPDB(tenantPDB("pdb", "default", "12345"), `{"disruptionsAllowed":1,"currentHealthy":2,"desiredHealthy":1,"expectedPods":2}`), &policyv1.PodDisruptionBudgetStatus{
+					ObservedGeneration: 1,
+					CurrentHealthy:     1,
+					DesiredHealthy:     1,
+					ExpectedPods:       2,
+				}),
+			},
+			EnqueuedKey:         superDefaultNSName + "/pdb",
+			ExpectedNoOperation: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(NewPDBController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			for _, obj := range tc.ExpectedUpdatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("update", "poddisruptionbudgets") {
+						continue
+					}
+					actionObj := action.(core.UpdateAction).GetObject()
+					accessor, _ := meta.Accessor(obj)
+					accessor.SetResourceVersion("999")
+					if !equality.Semantic.DeepEqual(obj, actionObj) {
+						exp, _ := json.Marshal(obj)
+						got, _ := json.Marshal(actionObj)
+						t.Errorf("%s: Expected updated PDB is %v, got %v", k, string(exp), string(got))
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect updated PDB %+v but not found", k, obj)
+				}
+			}
+		})
+	}
+}
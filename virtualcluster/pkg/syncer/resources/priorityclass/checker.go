@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,9 +31,10 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numMissMatchedPriorityClasses uint64
+var numMissMatchedPriorityClasses = metrics.NewMissMatchCounter("MissMatchedPriorityClasses")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.priorityclassSynced) {
@@ -53,7 +53,7 @@ func (c *controller) PatrollerDo() {
 	}
 
 	wg := sync.WaitGroup{}
-	numMissMatchedPriorityClasses = 0
+	numMissMatchedPriorityClasses.Reset()
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -75,6 +75,14 @@ func (c *controller) PatrollerDo() {
 			continue
 		}
 		for _, clusterName := range clusterNames {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pPriorityClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of priorityclass %s selects cluster %s: %v", pPriorityClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				continue
+			}
 			if err := c.MultiClusterController.Get(clusterName, "", pPriorityClass.Name, &schedulingv1.PriorityClass{}); err != nil {
 				if apierrors.IsNotFound(err) {
 					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperControlPlanePriorityClasses").Inc()
@@ -85,7 +93,7 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedPriorityClasses").Set(float64(numMissMatchedPriorityClasses))
+	numMissMatchedPriorityClasses.Record()
 }
 
 func (c *controller) checkPriorityClassOfTenantCluster(clusterName string) {
@@ -123,9 +131,23 @@ func (c *controller) checkPriorityClassOfTenantCluster(clusterName string) {
 			continue
 		}
 
+		if util.IsPublicObject(&scList.Items[i]) {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pPriorityClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of priorityclass %s selects cluster %s: %v", pPriorityClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				// the tenant no longer matches the selector of pPriorityClass, retract it.
+				metrics.CheckerRemedyStats.WithLabelValues("RetractedTenantPriorityClasses").Inc()
+				c.UpwardController.AddToQueue(clusterName + "/" + pPriorityClass.Name)
+				continue
+			}
+		}
+
 		updatedPriorityClass := conversion.Equality(nil, nil).CheckPriorityClassEquality(pPriorityClass, &scList.Items[i])
 		if updatedPriorityClass != nil {
-			atomic.AddUint64(&numMissMatchedPriorityClasses, 1)
+			numMissMatchedPriorityClasses.Inc(clusterName)
 			klog.Warningf("spec of priorityClass %v diff in super&tenant control plane", vPriorityClass.Name)
 			if publicPriorityClass(pPriorityClass) {
 				c.UpwardController.AddToQueue(clusterName + "/" + pPriorityClass.Name)
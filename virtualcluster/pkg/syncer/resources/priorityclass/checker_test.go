@@ -37,6 +37,7 @@ func TestPriorityClassPatrol(t *testing.T) {
 			Name:      "test",
 			Namespace: "tenant-1",
 			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+			Labels:    map[string]string{"tier": "gold"},
 		},
 		Spec: v1alpha1.VirtualClusterSpec{},
 		Status: v1alpha1.VirtualClusterStatus{
@@ -84,6 +85,28 @@ func TestPriorityClassPatrol(t *testing.T) {
 				"sc",
 			},
 		},
+		"pPriorityClass does not select the tenant, vPriorityClass does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("sc", "12345", selectedBy("tier=silver")),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pPriorityClass no longer selects the tenant, vPriorityClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("sc", "12345", selectedBy("tier=silver")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("sc", "123456", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			WaitUWS: true,
+			ExpectedDeletedVObject: []string{
+				"sc",
+			},
+		},
 		"pPriorityClass not found, vPriorityClass exists": {
 			ExistingObjectInTenant: []runtime.Object{
 				makePriorityClass("sc", "12345", func(class *v1.PriorityClass) {
@@ -27,6 +27,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -50,6 +51,14 @@ func (c *controller) BackPopulate(key string) error {
 			return err
 		}
 		op = reconciler.DeleteEvent
+	} else {
+		selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pPriorityClass)
+		if err != nil {
+			return err
+		}
+		if !selected {
+			op = reconciler.DeleteEvent
+		}
 	}
 
 	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
@@ -74,6 +83,10 @@ func (c *controller) BackPopulate(key string) error {
 	}
 
 	if op == reconciler.DeleteEvent {
+		if pPriorityClass != nil && !util.IsPublicObject(vPriorityClass) {
+			// the priorityclass is created by the tenant, which is not retracted.
+			return nil
+		}
 		opts := &metav1.DeleteOptions{
 			PropagationPolicy: &constants.DefaultDeletionPolicy,
 		}
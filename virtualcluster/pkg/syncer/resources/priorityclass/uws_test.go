@@ -56,12 +56,24 @@ func makePriorityClass(name, uid string, mFuncs ...func(*v1.PriorityClass)) *v1.
 	return pc
 }
 
+func selectedBy(selector string) func(*v1.PriorityClass) {
+	return func(class *v1.PriorityClass) {
+		class.Labels = map[string]string{
+			constants.PublicObjectKey: "true",
+		}
+		class.Annotations = map[string]string{
+			constants.PublicObjectSelectorKey: selector,
+		}
+	}
+}
+
 func TestUWPCCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test",
 			Namespace: "tenant-1",
 			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+			Labels:    map[string]string{"tier": "gold"},
 		},
 		Status: v1alpha1.VirtualClusterStatus{
 			Phase: v1alpha1.ClusterRunning,
@@ -97,6 +109,22 @@ func TestUWPCCreation(t *testing.T) {
 			EnqueuedKey:         defaultClusterKey + "/pc",
 			ExpectedNoOperation: true,
 		},
+		"pPC selects the tenant, vPC not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", selectedBy("tier=gold")),
+			},
+			EnqueuedKey: defaultClusterKey + "/pc",
+			ExpectedCreatedObject: []string{
+				"pc",
+			},
+		},
+		"pPC does not select the tenant, vPC not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", selectedBy("tier=silver")),
+			},
+			EnqueuedKey:         defaultClusterKey + "/pc",
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
@@ -250,6 +278,7 @@ func TestUWPCDeletion(t *testing.T) {
 			Name:      "test",
 			Namespace: "tenant-1",
 			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+			Labels:    map[string]string{"tier": "gold"},
 		},
 		Status: v1alpha1.VirtualClusterStatus{
 			Phase: v1alpha1.ClusterRunning,
@@ -279,6 +308,32 @@ func TestUWPCDeletion(t *testing.T) {
 				"pc",
 			},
 		},
+		"pPC no longer selects the tenant, vPC exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", selectedBy("tier=silver")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			EnqueuedKey: defaultClusterKey + "/pc",
+			ExpectedDeletedObject: []string{
+				"pc",
+			},
+		},
+		"pPC does not select the tenant, vPC created by tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", selectedBy("tier=silver")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "123456"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/pc",
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
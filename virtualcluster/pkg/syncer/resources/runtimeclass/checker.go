@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	nodev1 "k8s.io/api/node/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+var numMissMatchedRuntimeClasses = metrics.NewMissMatchCounter("MissMatchedRuntimeClasses")
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.runtimeclassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting Service checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if RuntimeClass keeps consistency between super control plane and tenant control planes.
+func (c *controller) PatrollerDo() {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.V(5).Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "runtimeclass")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	numMissMatchedRuntimeClasses.Reset()
+
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(clusterName string) {
+			defer wg.Done()
+			c.checkRuntimeClassOfTenantCluster(clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	pRuntimeClassList, err := c.runtimeclassLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing runtimeclass from super control plane informer cache: %v", err)
+		return
+	}
+
+	for _, pRuntimeClass := range pRuntimeClassList {
+		if !publicRuntimeClass(pRuntimeClass) {
+			continue
+		}
+		for _, clusterName := range clusterNames {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pRuntimeClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of runtimeclass %s selects cluster %s: %v", pRuntimeClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				continue
+			}
+			if err := c.MultiClusterController.Get(clusterName, "", pRuntimeClass.Name, &nodev1.RuntimeClass{}); err != nil {
+				if apierrors.IsNotFound(err) {
+					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperControlPlaneRuntimeClasses").Inc()
+					c.UpwardController.AddToQueue(clusterName + "/" + pRuntimeClass.Name)
+				}
+				klog.Errorf("fail to get runtimeclass from cluster %s: %v", clusterName, err)
+			}
+		}
+	}
+
+	numMissMatchedRuntimeClasses.Record()
+}
+
+func (c *controller) checkRuntimeClassOfTenantCluster(clusterName string) {
+	rcList := &nodev1.RuntimeClassList{}
+	if err := c.MultiClusterController.List(clusterName, rcList); err != nil {
+		klog.Errorf("error listing runtimeclass from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+
+	for i, vRuntimeClass := range rcList.Items {
+		if !publicRuntimeClass(&rcList.Items[i]) {
+			continue
+		}
+		pRuntimeClass, err := c.runtimeclassLister.Get(vRuntimeClass.Name)
+		if apierrors.IsNotFound(err) {
+			// super control plane is the source of the truth for runtimeclass object, delete tenant control plane obj
+			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+			if err != nil {
+				klog.Errorf("error getting cluster %s clientset: %v", clusterName, err)
+				continue
+			}
+			opts := &metav1.DeleteOptions{
+				PropagationPolicy: &constants.DefaultDeletionPolicy,
+			}
+			if err := tenantClient.NodeV1().RuntimeClasses().Delete(context.TODO(), vRuntimeClass.Name, *opts); err != nil {
+				klog.Errorf("error deleting runtimeclass %v in cluster %s: %v", vRuntimeClass.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantRuntimeClasses").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pRuntimeClass %s from super control plane cache: %v", vRuntimeClass.Name, err)
+			continue
+		}
+
+		if util.IsPublicObject(&rcList.Items[i]) {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pRuntimeClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of runtimeclass %s selects cluster %s: %v", pRuntimeClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				// the tenant no longer matches the selector of pRuntimeClass, retract it.
+				metrics.CheckerRemedyStats.WithLabelValues("RetractedTenantRuntimeClasses").Inc()
+				c.UpwardController.AddToQueue(clusterName + "/" + pRuntimeClass.Name)
+				continue
+			}
+		}
+
+		updatedRuntimeClass := conversion.Equality(nil, nil).CheckRuntimeClassEquality(pRuntimeClass, &rcList.Items[i])
+		if updatedRuntimeClass != nil {
+			numMissMatchedRuntimeClasses.Inc(clusterName)
+			klog.Warningf("spec of runtimeClass %v diff in super&tenant control plane", vRuntimeClass.Name)
+			if publicRuntimeClass(pRuntimeClass) {
+				c.UpwardController.AddToQueue(clusterName + "/" + pRuntimeClass.Name)
+			}
+		}
+	}
+}
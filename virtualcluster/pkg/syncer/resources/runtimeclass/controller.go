@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/node/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	runtimeclassinformers "k8s.io/client-go/informers/node/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	v1runtimeclass "k8s.io/client-go/kubernetes/typed/node/v1"
+	listersv1 "k8s.io/client-go/listers/node/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "runtimeclass",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewRuntimeClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// super control plane runtimeclasses client
+	client v1runtimeclass.RuntimeClassesGetter
+	// super control plane runtimeclasses informer/lister/synced functions
+	informer           runtimeclassinformers.Interface
+	runtimeclassLister listersv1.RuntimeClassLister
+	runtimeclassSynced cache.InformerSynced
+}
+
+func NewRuntimeClassController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		client:   client.NodeV1(),
+		informer: informer.Node().V1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.RuntimeClass{}, &v1.RuntimeClassList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.runtimeclassLister = informer.Node().V1().RuntimeClasses().Lister()
+	if options.IsFake {
+		c.runtimeclassSynced = func() bool { return true }
+	} else {
+		c.runtimeclassSynced = informer.Node().V1().RuntimeClasses().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.RuntimeClass{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&v1.RuntimeClass{}, c, pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.informer.RuntimeClasses().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.RuntimeClass:
+					return publicRuntimeClass(t)
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*v1.RuntimeClass); ok {
+						return publicRuntimeClass(e)
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.RuntimeClass", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super control plane runtimeclass controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueRuntimeClass,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newRuntimeClass := newObj.(*v1.RuntimeClass)
+					oldRuntimeClass := oldObj.(*v1.RuntimeClass)
+					if newRuntimeClass.ResourceVersion != oldRuntimeClass.ResourceVersion {
+						c.enqueueRuntimeClass(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueRuntimeClass,
+			},
+		})
+	return c, nil
+}
+
+func publicRuntimeClass(e *v1.RuntimeClass) bool {
+	// We only backpopulate specific runtimeclass to tenant control planes
+	return e.Labels[constants.PublicObjectKey] == "true"
+}
+
+func (c *controller) enqueueRuntimeClass(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("No tenant control planes, stop backpopulate runtimeclass %v", key)
+		return
+	}
+
+	for _, clusterName := range clusterNames {
+		c.UpwardController.AddToQueue(clusterName + "/" + key)
+	}
+}
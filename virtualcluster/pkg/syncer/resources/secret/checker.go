@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -35,8 +34,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numMissMatchedOpaqueSecrets uint64
-var numMissMatchedSASecrets uint64
+var numMissMatchedOpaqueSecrets = metrics.NewMissMatchCounter("MissMatchedOpaqueSecrets")
+var numMissMatchedSASecrets = metrics.NewMissMatchCounter("MissMatchedSASecrets")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.secretSynced) {
@@ -55,8 +54,8 @@ func (c *controller) PatrollerDo() {
 	}
 
 	var wg sync.WaitGroup
-	numMissMatchedOpaqueSecrets = 0
-	numMissMatchedSASecrets = 0
+	numMissMatchedOpaqueSecrets.Reset()
+	numMissMatchedSASecrets.Reset()
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -116,8 +115,8 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedOpaqueSecrets").Set(float64(numMissMatchedOpaqueSecrets))
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedSASecrets").Set(float64(numMissMatchedSASecrets))
+	numMissMatchedOpaqueSecrets.Record()
+	numMissMatchedSASecrets.Record()
 }
 
 func (c *controller) checkSecretOfTenantCluster(clusterName string) {
@@ -164,7 +163,7 @@ func (c *controller) checkSecretOfTenantCluster(clusterName string) {
 
 		updatedSecret := conversion.Equality(c.Config, vc).CheckSecretEquality(pSecret, &secretList.Items[i])
 		if updatedSecret != nil {
-			atomic.AddUint64(&numMissMatchedOpaqueSecrets, 1)
+			numMissMatchedOpaqueSecrets.Inc(clusterName)
 			klog.Warningf("spec of secret %v/%v diff in super&tenant control plane", vSecret.Namespace, vSecret.Name)
 		}
 	}
@@ -204,7 +203,7 @@ func (c *controller) checkServiceAccountTokenTypeSecretOfTenantCluster(clusterNa
 
 	updatedSecret := conversion.Equality(c.Config, vc).CheckSecretEquality(secretList[0], vSecret)
 	if updatedSecret != nil {
-		atomic.AddUint64(&numMissMatchedSASecrets, 1)
+		numMissMatchedSASecrets.Inc(clusterName)
 		klog.Warningf("spec of service account token type secret %v/%v diff in super&tenant control plane", vSecret.Namespace, vSecret.Name)
 	}
 }
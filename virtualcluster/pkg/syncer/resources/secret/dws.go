@@ -179,9 +179,11 @@ func (c *controller) reconcileSecretUpdate(clusterName, targetNamespace, request
 }
 
 func (c *controller) reconcileNormalSecretUpdate(clusterName, targetNamespace, requestUID string, pSecret, vSecret *corev1.Secret) error {
-	if pSecret.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pEndpoints %s/%s delegated UID is different from updated object", targetNamespace, pSecret.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vSecret, pSecret)
+	if err != nil {
+		return err
 	}
+	pSecret = pObj.(*corev1.Secret)
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
 		return err
@@ -19,7 +19,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -35,9 +34,9 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numSpecMissMatchedServices uint64
-var numStatusMissMatchedServices uint64
-var numUWMetaMissMatchedServices uint64
+var numSpecMissMatchedServices = metrics.NewMissMatchCounter("SpecMissMatchedServices")
+var numStatusMissMatchedServices = metrics.NewMissMatchCounter("StatusMissMatchedServices")
+var numUWMetaMissMatchedServices = metrics.NewMissMatchCounter("UWMetaMissMatchedServices")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.serviceSynced) {
@@ -56,9 +55,9 @@ func (c *controller) PatrollerDo() {
 		return
 	}
 
-	numSpecMissMatchedServices = 0
-	numStatusMissMatchedServices = 0
-	numUWMetaMissMatchedServices = 0
+	numSpecMissMatchedServices.Reset()
+	numStatusMissMatchedServices.Reset()
+	numUWMetaMissMatchedServices.Reset()
 
 	pList, err := c.serviceLister.List(util.GetSuperClusterListerLabelsSelector())
 	if err != nil {
@@ -81,6 +80,9 @@ func (c *controller) PatrollerDo() {
 		}
 
 		for i := range vList.Items {
+			if util.IsImportedObject(&vList.Items[i]) {
+				continue
+			}
 			vSet.Insert(differ.ClusterObject{
 				Object:       &vList.Items[i],
 				OwnerCluster: cluster,
@@ -114,7 +116,7 @@ func (c *controller) PatrollerDo() {
 		}
 		updatedService := conversion.Equality(c.Config, vc).CheckServiceEquality(p, v)
 		if updatedService != nil {
-			atomic.AddUint64(&numSpecMissMatchedServices, 1)
+			numSpecMissMatchedServices.Inc(vObj.GetOwnerCluster())
 			klog.Warningf("spec of service %s diff in super&tenant control plane", pObj.Key)
 			d.OnAdd(vObj)
 			return
@@ -124,13 +126,13 @@ func (c *controller) PatrollerDo() {
 			enqueue := false
 			updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&p.ObjectMeta, &v.ObjectMeta)
 			if updatedMeta != nil {
-				atomic.AddUint64(&numUWMetaMissMatchedServices, 1)
+				numUWMetaMissMatchedServices.Inc(vObj.GetOwnerCluster())
 				enqueue = true
 				klog.Warningf("UWObjectMeta of service %s diff in super&tenant control plane", pObj.Key)
 			}
 			if !equality.Semantic.DeepEqual(p.Status, v.Status) {
 				enqueue = true
-				atomic.AddUint64(&numStatusMissMatchedServices, 1)
+				numStatusMissMatchedServices.Inc(vObj.GetOwnerCluster())
 				klog.Warningf("Status of service %s diff in super&tenant control plane", pObj)
 			}
 			if enqueue {
@@ -152,7 +154,11 @@ func (c *controller) PatrollerDo() {
 		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
 	})
 
-	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedServices").Set(float64(numSpecMissMatchedServices))
-	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedServices").Set(float64(numStatusMissMatchedServices))
-	metrics.CheckerMissMatchStats.WithLabelValues("UWMetaMissMatchedServices").Set(float64(numUWMetaMissMatchedServices))
+	numSpecMissMatchedServices.Record()
+	numStatusMissMatchedServices.Record()
+	numUWMetaMissMatchedServices.Record()
+
+	if c.exportController != nil {
+		c.patrolServiceExports(clusterNames)
+	}
 }
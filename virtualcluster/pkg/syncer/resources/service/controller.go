@@ -17,28 +17,40 @@ limitations under the License.
 package service
 
 import (
+	"context"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	listersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
+	mcsv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/multicluster/v1alpha1"
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conflict"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
 
 func init() {
+	_ = mcsv1alpha1.AddToScheme(scheme.Scheme)
+
 	plugin.SyncerResourceRegister.Register(&plugin.Registration{
 		ID: "service",
 		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
@@ -51,9 +63,18 @@ type controller struct {
 	manager.BaseResourceSyncer
 	// super control plane service client
 	serviceClient v1core.ServicesGetter
+	// conflicts resolves the conflicts with existing super control plane objects
+	conflicts *conflict.Resolver
 	// super control plane informer/listers/synced functions
 	serviceLister listersv1.ServiceLister
 	serviceSynced cache.InformerSynced
+	// quotaTracker admits services against the virtual cluster quota.
+	quotaTracker *quota.Tracker
+	// nodePorts assigns NodePorts from per tenant ranges, nil if disabled.
+	nodePorts *nodePortAllocator
+	// exportController watches tenant ServiceExports, nil if the ServiceExport
+	// feature is disabled.
+	exportController *mc.MultiClusterController
 }
 
 func NewServiceController(config *config.SyncerConfiguration,
@@ -67,13 +88,34 @@ func NewServiceController(config *config.SyncerConfiguration,
 			Config: config,
 		},
 		serviceClient: client.CoreV1(),
+		quotaTracker:  quota.NewTracker(informer),
 	}
 
 	var err error
+	c.nodePorts, err = newNodePortAllocator(config, vcClient, vcInformer.Lister())
+	if err != nil {
+		return nil, err
+	}
+
 	c.MultiClusterController, err = mc.NewMCController(&corev1.Service{}, &corev1.ServiceList{}, c, mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
+	c.conflicts = conflict.NewResolver(c.MultiClusterController, conflict.Funcs{
+		Patch: func(namespace, name string, data []byte) (runtime.Object, error) {
+			return c.serviceClient.Services(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+		},
+		Delete: func(namespace, name string, opts metav1.DeleteOptions) error {
+			return c.serviceClient.Services(namespace).Delete(context.TODO(), name, opts)
+		},
+	})
+
+	if featuregate.DefaultFeatureGate.Enabled(featuregate.ServiceExport) {
+		c.exportController, err = mc.NewMCController(&mcsv1alpha1.ServiceExport{}, &mcsv1alpha1.ServiceExportList{}, &exportReconciler{c: c})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	c.serviceLister = informer.Core().V1().Services().Lister()
 	if options.IsFake {
@@ -124,6 +166,16 @@ func NewServiceController(config *config.SyncerConfiguration,
 	return c, nil
 }
 
+func (c *controller) GetListener() listener.ClusterChangeListener {
+	if c.exportController == nil {
+		return c.BaseResourceSyncer.GetListener()
+	}
+	return listener.MultiListener{
+		c.BaseResourceSyncer.GetListener(),
+		listener.NewMCControllerListener(c.exportController, mc.WatchOptions{}),
+	}
+}
+
 func isBackPopulateService(svc *corev1.Service) bool {
 	return svc.Spec.Type == corev1.ServiceTypeLoadBalancer || svc.Spec.Type == corev1.ServiceTypeClusterIP
 }
@@ -134,10 +186,13 @@ func (c *controller) enqueueService(obj interface{}) {
 		return
 	}
 
-	clusterName, _ := conversion.GetVirtualOwner(svc)
+	clusterName, vNamespace := conversion.GetVirtualOwner(svc)
 	if clusterName == "" {
 		return
 	}
+	if c.exportController != nil {
+		c.requeueServiceExport(clusterName, vNamespace, svc.Name)
+	}
 
 	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 	if err != nil {
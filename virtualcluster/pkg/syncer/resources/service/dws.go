@@ -28,6 +28,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
@@ -36,6 +37,13 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.serviceSynced) {
 		return fmt.Errorf("failed to wait for caches to sync before starting Service dws")
 	}
+	if c.exportController != nil {
+		go func() {
+			if err := c.exportController.Start(stopCh); err != nil {
+				klog.Errorf("service export controller exited: %v", err)
+			}
+		}()
+	}
 	return c.MultiClusterController.Start(stopCh)
 }
 
@@ -58,10 +66,18 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 		}
 		vExists = false
 	}
+	if vExists && util.IsImportedObject(vService) {
+		// imported services only live in the tenant control plane.
+		return reconciler.Result{}, nil
+	}
 	switch {
 	case vExists && !pExists:
 		err := c.reconcileServiceCreate(request.ClusterName, targetNamespace, request.UID, vService)
 		if err != nil {
+			if quota.IsExceeded(err) {
+				// Retry until the quota frees up, the event is recorded already.
+				return reconciler.Result{RequeueAfter: quota.RetryPeriod}, nil
+			}
 			klog.Errorf("failed reconcile service %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
@@ -92,7 +108,34 @@ func (c *controller) reconcileServiceCreate(clusterName, targetNamespace, reques
 	pService := newObj.(*corev1.Service)
 	conversion.VC(nil, "").Service(pService).Mutate(service)
 
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	if err := c.assignNodePorts(vc, service, pService); err != nil {
+		if isRejected(err) {
+			return c.rejectService(clusterName, service, err.(*rejectedError))
+		}
+		return err
+	}
+
+	admitted, err := c.quotaTracker.Admit(vc, pService)
+	if err != nil {
+		if !quota.IsExceeded(err) {
+			return err
+		}
+		klog.Warningf("service %s/%s of cluster %s is not created: %v", service.Namespace, service.Name, clusterName, err)
+		c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+			Kind:      "Service",
+			Name:      service.Name,
+			Namespace: service.Namespace,
+			UID:       service.UID,
+		}, corev1.EventTypeWarning, "ExceededQuota", "Error creating: %v", err)
+		return err
+	}
+
 	pService, err = c.serviceClient.Services(targetNamespace).Create(context.TODO(), pService, metav1.CreateOptions{})
+	admitted(err == nil)
 	if apierrors.IsAlreadyExists(err) {
 		if pService.Annotations[constants.LabelUID] == requestUID {
 			klog.Infof("service %s/%s of cluster %s already exist in super control plane", targetNamespace, pService.Name, clusterName)
@@ -104,9 +147,11 @@ func (c *controller) reconcileServiceCreate(clusterName, targetNamespace, reques
 }
 
 func (c *controller) reconcileServiceUpdate(clusterName, targetNamespace, requestUID string, pService, vService *corev1.Service) error {
-	if pService.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pService %s/%s delegated UID is different from updated object", targetNamespace, pService.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vService, pService)
+	if err != nil {
+		return err
 	}
+	pService = pObj.(*corev1.Service)
 
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
@@ -114,6 +159,12 @@ func (c *controller) reconcileServiceUpdate(clusterName, targetNamespace, reques
 	}
 	updated := conversion.Equality(c.Config, vc).CheckServiceEquality(pService, vService)
 	if updated != nil {
+		if err := c.assignNodePorts(vc, vService, updated); err != nil {
+			if isRejected(err) {
+				return c.rejectService(clusterName, vService, err.(*rejectedError))
+			}
+			return err
+		}
 		_, err = c.serviceClient.Services(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
 		if err != nil {
 			return err
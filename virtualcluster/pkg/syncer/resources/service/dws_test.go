@@ -56,6 +56,11 @@ func applyClusterIPToService(service *corev1.Service, clusterIP string) *corev1.
 	return service
 }
 
+func importedService(service *corev1.Service, name string) *corev1.Service {
+	service.Labels = map[string]string{constants.LabelServiceImport: name}
+	return service
+}
+
 func superService(name, namespace, uid, clusterKey string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -111,6 +116,11 @@ func TestDWServiceCreation(t *testing.T) {
 			ExpectedCreatedServices: []string{},
 			ExpectedError:           "",
 		},
+		"imported service": {
+			ExistingObjectInSuper:   []runtime.Object{},
+			ExistingObjectInTenant:  importedService(tenantService("imported-svc-1", "default", "12345"), "svc-1"),
+			ExpectedCreatedServices: []string{},
+		},
 		"new serivce but existing different uid one": {
 			ExistingObjectInSuper: []runtime.Object{
 				superService("svc-1", superDefaultNSName, "123456", defaultClusterKey),
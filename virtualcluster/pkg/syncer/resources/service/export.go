@@ -0,0 +1,473 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcsv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/multicluster/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+const (
+	// importedServicePrefix prefixes the name of the headless Service
+	// synthesized for a ServiceImport, so that it does not clash with a tenant
+	// Service of the same name.
+	importedServicePrefix = "imported-"
+
+	reasonExported             = "Exported"
+	reasonServiceNotFound      = "ServiceNotFound"
+	reasonServiceNotExportable = "ServiceNotExportable"
+	reasonServicePending       = "ServicePending"
+	reasonExportNotAllowed     = "ExportNotAllowed"
+)
+
+// exporter is a tenant Service exported by a valid ServiceExport.
+type exporter struct {
+	cluster string
+	service *corev1.Service
+	// clusterIP is the ClusterIP of the super control plane Service.
+	clusterIP string
+	// importers selects the VirtualClusters allowed to import the Service.
+	importers labels.Selector
+}
+
+// exportReconciler reconciles the ServiceExports of tenant control planes. The
+// ServiceImports of the exported Service are resynced in every tenant control
+// plane, as consumers do not know which tenants export the Service.
+type exportReconciler struct {
+	c *controller
+}
+
+func (r *exportReconciler) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile service export %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	if err := r.c.reconcileServiceExport(request.Namespace, request.Name); err != nil {
+		klog.Errorf("failed reconcile service export %s/%s: %v", request.Namespace, request.Name, err)
+		return reconciler.Result{Requeue: true}, err
+	}
+	return reconciler.Result{}, nil
+}
+
+// reconcileServiceExport syncs the ServiceImport of namespace/name of every
+// tenant control plane with the exporters which allow the tenant to import.
+func (c *controller) reconcileServiceExport(namespace, name string) error {
+	exporters, err := c.listExporters(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, cluster := range c.MultiClusterController.GetClusterNames() {
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, cluster)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var imported []exporter
+		for _, e := range exporters {
+			if e.cluster != cluster && e.importers.Matches(labels.Set(vc.GetLabels())) {
+				imported = append(imported, e)
+			}
+		}
+		if err := c.syncServiceImport(cluster, namespace, name, imported); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync service import %s/%s of cluster %s: %v", namespace, name, cluster, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// listExporters returns the exporters of namespace/name ordered by cluster, and
+// records the validity of each ServiceExport in its status.
+func (c *controller) listExporters(namespace, name string) ([]exporter, error) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	sort.Strings(clusterNames)
+
+	var exporters []exporter
+	for _, cluster := range clusterNames {
+		export := &mcsv1alpha1.ServiceExport{}
+		if err := c.exportController.Get(cluster, namespace, name, export); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		e, condition, err := c.checkServiceExport(cluster, export)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.updateServiceExportCondition(cluster, export, condition); err != nil {
+			return nil, err
+		}
+		if e != nil {
+			exporters = append(exporters, *e)
+		}
+	}
+	return exporters, nil
+}
+
+// checkServiceExport returns the exporter of the ServiceExport and its Valid
+// condition. The exporter is nil if the Service cannot be exported.
+func (c *controller) checkServiceExport(cluster string, export *mcsv1alpha1.ServiceExport) (*exporter, mcsv1alpha1.ServiceExportCondition, error) {
+	vService := &corev1.Service{}
+	if err := c.MultiClusterController.Get(cluster, export.Namespace, export.Name, vService); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, exportCondition(corev1.ConditionFalse, reasonServiceNotFound, "service not found"), nil
+		}
+		return nil, mcsv1alpha1.ServiceExportCondition{}, err
+	}
+	if util.IsImportedObject(vService) {
+		return nil, exportCondition(corev1.ConditionFalse, reasonServiceNotExportable, "imported services cannot be exported"), nil
+	}
+	if vService.Spec.Type == corev1.ServiceTypeExternalName || vService.Spec.ClusterIP == corev1.ClusterIPNone {
+		return nil, exportCondition(corev1.ConditionFalse, reasonServiceNotExportable, "headless and ExternalName services cannot be exported"), nil
+	}
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, cluster)
+	if err != nil {
+		return nil, mcsv1alpha1.ServiceExportCondition{}, err
+	}
+	importers, err := exportPolicyImporters(vc.Spec.ServiceExportPolicy, export.Namespace)
+	if err != nil {
+		return nil, exportCondition(corev1.ConditionFalse, reasonExportNotAllowed, err.Error()), nil
+	}
+
+	pService, err := c.serviceLister.Services(conversion.ToSuperClusterNamespace(cluster, export.Namespace)).Get(export.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, mcsv1alpha1.ServiceExportCondition{}, err
+	}
+	if err != nil || pService.Annotations[constants.LabelUID] != string(vService.UID) || pService.Spec.ClusterIP == "" {
+		return nil, exportCondition(corev1.ConditionFalse, reasonServicePending, "service is not synced to super control plane yet"), nil
+	}
+
+	return &exporter{
+		cluster:   cluster,
+		service:   vService,
+		clusterIP: pService.Spec.ClusterIP,
+		importers: importers,
+	}, exportCondition(corev1.ConditionTrue, reasonExported, "service is exported"), nil
+}
+
+// exportPolicyImporters returns the selector of the VirtualClusters which can
+// import the Services of namespace exported under policy.
+func exportPolicyImporters(policy *v1alpha1.ServiceExportPolicy, namespace string) (labels.Selector, error) {
+	if policy == nil {
+		return nil, fmt.Errorf("the virtual cluster has no service export policy")
+	}
+	if len(policy.Namespaces) != 0 && !sets.NewString(policy.Namespaces...).Has(namespace) {
+		return nil, fmt.Errorf("the service export policy of the virtual cluster does not allow namespace %s", namespace)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(policy.ImporterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid importer selector of the service export policy: %v", err)
+	}
+	return selector, nil
+}
+
+func exportCondition(status corev1.ConditionStatus, reason, message string) mcsv1alpha1.ServiceExportCondition {
+	return mcsv1alpha1.ServiceExportCondition{
+		Type:    mcsv1alpha1.ServiceExportValid,
+		Status:  status,
+		Reason:  pointer.StringPtr(reason),
+		Message: pointer.StringPtr(message),
+	}
+}
+
+// updateServiceExportCondition sets condition in the status of the
+// ServiceExport, keeping the transition time if the status is unchanged.
+func (c *controller) updateServiceExportCondition(cluster string, export *mcsv1alpha1.ServiceExport, condition mcsv1alpha1.ServiceExportCondition) error {
+	now := metav1.Now()
+	condition.LastTransitionTime = &now
+
+	updated := export.DeepCopy()
+	i := 0
+	for ; i < len(updated.Status.Conditions); i++ {
+		if updated.Status.Conditions[i].Type == condition.Type {
+			break
+		}
+	}
+	if i == len(updated.Status.Conditions) {
+		updated.Status.Conditions = append(updated.Status.Conditions, condition)
+	} else {
+		current := updated.Status.Conditions[i]
+		if current.Status == condition.Status {
+			if equality.Semantic.DeepEqual(current.Reason, condition.Reason) && equality.Semantic.DeepEqual(current.Message, condition.Message) {
+				return nil
+			}
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+		updated.Status.Conditions[i] = condition
+	}
+
+	tenantClient, err := c.getTenantClient(cluster)
+	if err != nil {
+		return err
+	}
+	return tenantClient.Status().Update(context.TODO(), updated)
+}
+
+// syncServiceImport makes the ServiceImport of namespace/name in the tenant
+// control plane of cluster, and the headless Service resolving to the ClusterIPs
+// of the exporters, reflect exporters. They are removed if there is no exporter.
+func (c *controller) syncServiceImport(cluster, namespace, name string, exporters []exporter) error {
+	tenantClient, err := c.getTenantClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	if len(exporters) == 0 {
+		return removeServiceImport(tenantClient, namespace, name)
+	}
+
+	// Services are only imported to the namespaces of the same name.
+	if err := tenantClient.Get(context.TODO(), client.ObjectKey{Name: namespace}, &corev1.Namespace{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	serviceImport, service, endpoints := buildServiceImport(namespace, name, exporters)
+	if err := applyServiceImport(tenantClient, serviceImport); err != nil {
+		return err
+	}
+	if err := applyImportedService(tenantClient, service); err != nil {
+		return err
+	}
+	return applyImportedEndpoints(tenantClient, endpoints)
+}
+
+func (c *controller) getTenantClient(cluster string) (client.Client, error) {
+	tenantCluster := c.MultiClusterController.GetCluster(cluster)
+	if tenantCluster == nil {
+		return nil, errors.NewClusterNotFound(cluster)
+	}
+	return tenantCluster.GetDelegatingClient()
+}
+
+// importedServiceName returns the name of the Service synthesized for the
+// ServiceImport of name.
+func importedServiceName(name string) string {
+	serviceName := importedServicePrefix + name
+	if len(serviceName) > validation.DNS1035LabelMaxLength {
+		serviceName = strings.TrimRight(serviceName[:validation.DNS1035LabelMaxLength], "-")
+	}
+	return serviceName
+}
+
+// buildServiceImport returns the ServiceImport of namespace/name and the
+// headless Service and Endpoints resolving to the ClusterIPs of exporters. The
+// ports are taken from the first exporter.
+func buildServiceImport(namespace, name string, exporters []exporter) (*mcsv1alpha1.ServiceImport, *corev1.Service, *corev1.Endpoints) {
+	importLabels := map[string]string{constants.LabelServiceImport: name}
+
+	serviceImport := &mcsv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: importLabels},
+		Spec: mcsv1alpha1.ServiceImportSpec{
+			Type:                  mcsv1alpha1.Headless,
+			SessionAffinity:       exporters[0].service.Spec.SessionAffinity,
+			SessionAffinityConfig: exporters[0].service.Spec.SessionAffinityConfig.DeepCopy(),
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: importedServiceName(name), Namespace: namespace, Labels: importLabels},
+		Spec:       corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: importedServiceName(name), Namespace: namespace, Labels: importLabels},
+	}
+
+	subset := corev1.EndpointSubset{}
+	for _, p := range exporters[0].service.Spec.Ports {
+		serviceImport.Spec.Ports = append(serviceImport.Spec.Ports, mcsv1alpha1.ServicePort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			AppProtocol: p.AppProtocol,
+			Port:        p.Port,
+		})
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			AppProtocol: p.AppProtocol,
+			Port:        p.Port,
+			TargetPort:  intstr.FromInt(int(p.Port)),
+		})
+		subset.Ports = append(subset.Ports, corev1.EndpointPort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			AppProtocol: p.AppProtocol,
+			Port:        p.Port,
+		})
+	}
+	for _, e := range exporters {
+		serviceImport.Status.Clusters = append(serviceImport.Status.Clusters, mcsv1alpha1.ClusterStatus{Cluster: e.cluster})
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: e.clusterIP})
+	}
+	endpoints.Subsets = []corev1.EndpointSubset{subset}
+
+	return serviceImport, service, endpoints
+}
+
+func applyServiceImport(tenantClient client.Client, desired *mcsv1alpha1.ServiceImport) error {
+	current := &mcsv1alpha1.ServiceImport{}
+	err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(desired), current)
+	switch {
+	case apierrors.IsNotFound(err):
+		current = desired.DeepCopy()
+		if err := tenantClient.Create(context.TODO(), current); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case current.Labels[constants.LabelServiceImport] != desired.Name:
+		return fmt.Errorf("service import %s/%s is not managed by the syncer", desired.Namespace, desired.Name)
+	case !equality.Semantic.DeepEqual(current.Spec, desired.Spec):
+		current.Spec = desired.Spec
+		if err := tenantClient.Update(context.TODO(), current); err != nil {
+			return err
+		}
+	}
+
+	if equality.Semantic.DeepEqual(current.Status, desired.Status) {
+		return nil
+	}
+	current.Status = desired.Status
+	return tenantClient.Status().Update(context.TODO(), current)
+}
+
+func applyImportedService(tenantClient client.Client, desired *corev1.Service) error {
+	current := &corev1.Service{}
+	err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(desired), current)
+	switch {
+	case apierrors.IsNotFound(err):
+		return tenantClient.Create(context.TODO(), desired)
+	case err != nil:
+		return err
+	case current.Labels[constants.LabelServiceImport] != desired.Labels[constants.LabelServiceImport]:
+		return fmt.Errorf("service %s/%s already exists", desired.Namespace, desired.Name)
+	case equality.Semantic.DeepEqual(current.Spec.Ports, desired.Spec.Ports):
+		return nil
+	}
+	current.Spec.Ports = desired.Spec.Ports
+	return tenantClient.Update(context.TODO(), current)
+}
+
+func applyImportedEndpoints(tenantClient client.Client, desired *corev1.Endpoints) error {
+	current := &corev1.Endpoints{}
+	err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(desired), current)
+	switch {
+	case apierrors.IsNotFound(err):
+		return tenantClient.Create(context.TODO(), desired)
+	case err != nil:
+		return err
+	case current.Labels[constants.LabelServiceImport] != desired.Labels[constants.LabelServiceImport]:
+		return fmt.Errorf("endpoints %s/%s already exists", desired.Namespace, desired.Name)
+	case equality.Semantic.DeepEqual(current.Subsets, desired.Subsets):
+		return nil
+	}
+	current.Subsets = desired.Subsets
+	return tenantClient.Update(context.TODO(), current)
+}
+
+// removeServiceImport deletes the ServiceImport of namespace/name and the
+// objects synthesized for it. Objects not created by the syncer are kept.
+func removeServiceImport(tenantClient client.Client, namespace, name string) error {
+	for _, obj := range []client.Object{
+		&mcsv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: importedServiceName(name), Namespace: namespace}},
+		&corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: importedServiceName(name), Namespace: namespace}},
+	} {
+		err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if obj.GetLabels()[constants.LabelServiceImport] != name {
+			continue
+		}
+		if err := tenantClient.Delete(context.TODO(), obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// requeueServiceExport requeues the ServiceExport of namespace/name in cluster
+// if there is one, e.g. when the super control plane Service is changed.
+func (c *controller) requeueServiceExport(cluster, namespace, name string) {
+	export := &mcsv1alpha1.ServiceExport{}
+	if err := c.exportController.Get(cluster, namespace, name, export); err != nil {
+		return
+	}
+	if err := c.exportController.RequeueObject(cluster, export); err != nil {
+		klog.Errorf("error requeue service export %s/%s in cluster %s: %v", namespace, name, cluster, err)
+	}
+}
+
+// patrolServiceExports requeues the ServiceExports and the imported Services of
+// every cluster, so that changes of export policies and VirtualCluster labels
+// are applied and the imports of deleted exports are removed.
+func (c *controller) patrolServiceExports(clusterNames []string) {
+	for _, cluster := range clusterNames {
+		exports := &mcsv1alpha1.ServiceExportList{}
+		if err := c.exportController.List(cluster, exports); err != nil {
+			klog.Errorf("error listing service exports from cluster %s informer cache: %v", cluster, err)
+			continue
+		}
+		for i := range exports.Items {
+			if err := c.exportController.RequeueObject(cluster, &exports.Items[i]); err != nil {
+				klog.Errorf("error requeue service export %s/%s in cluster %s: %v", exports.Items[i].Namespace, exports.Items[i].Name, cluster, err)
+			}
+		}
+
+		imported := &corev1.ServiceList{}
+		if err := c.MultiClusterController.List(cluster, imported, client.HasLabels{constants.LabelServiceImport}); err != nil {
+			klog.Errorf("error listing imported services from cluster %s informer cache: %v", cluster, err)
+			continue
+		}
+		for _, s := range imported.Items {
+			export := &mcsv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: s.Namespace, Name: s.Labels[constants.LabelServiceImport]},
+			}
+			if err := c.exportController.RequeueObject(cluster, export); err != nil {
+				klog.Errorf("error requeue service import %s/%s in cluster %s: %v", export.Namespace, export.Name, cluster, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcsv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/multicluster/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+func TestExportPolicyImporters(t *testing.T) {
+	gold := labels.Set{"tier": "gold"}
+	testcases := map[string]struct {
+		policy        *v1alpha1.ServiceExportPolicy
+		namespace     string
+		expectedMatch bool
+		expectedError string
+	}{
+		"no policy": {
+			namespace:     "default",
+			expectedError: "no service export policy",
+		},
+		"selected importer": {
+			policy: &v1alpha1.ServiceExportPolicy{
+				ImporterSelector: &metav1.LabelSelector{MatchLabels: gold},
+			},
+			namespace:     "default",
+			expectedMatch: true,
+		},
+		"empty selector": {
+			policy: &v1alpha1.ServiceExportPolicy{
+				ImporterSelector: &metav1.LabelSelector{},
+			},
+			namespace:     "default",
+			expectedMatch: true,
+		},
+		"unselected importer": {
+			policy: &v1alpha1.ServiceExportPolicy{
+				ImporterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "silver"}},
+			},
+			namespace: "default",
+		},
+		"allowed namespace": {
+			policy: &v1alpha1.ServiceExportPolicy{
+				ImporterSelector: &metav1.LabelSelector{},
+				Namespaces:       []string{"shared"},
+			},
+			namespace:     "shared",
+			expectedMatch: true,
+		},
+		"disallowed namespace": {
+			policy: &v1alpha1.ServiceExportPolicy{
+				ImporterSelector: &metav1.LabelSelector{},
+				Namespaces:       []string{"shared"},
+			},
+			namespace:     "default",
+			expectedError: "does not allow namespace default",
+		},
+		"invalid selector": {
+			policy: &v1alpha1.ServiceExportPolicy{
+				ImporterSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bad"}}},
+			},
+			namespace:     "default",
+			expectedError: "invalid importer selector",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			selector, err := exportPolicyImporters(tc.policy, tc.namespace)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if match := selector.Matches(gold); match != tc.expectedMatch {
+				t.Errorf("expected match %v, got %v", tc.expectedMatch, match)
+			}
+		})
+	}
+}
+
+func exportedService(cluster, clusterIP string, ports ...corev1.ServicePort) exporter {
+	return exporter{
+		cluster:   cluster,
+		service:   &corev1.Service{Spec: corev1.ServiceSpec{Ports: ports}},
+		clusterIP: clusterIP,
+		importers: labels.Everything(),
+	}
+}
+
+func TestBuildServiceImport(t *testing.T) {
+	port := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	serviceImport, service, endpoints := buildServiceImport("default", "svc", []exporter{
+		exportedService("tenant-a", "10.0.0.1", port),
+		exportedService("tenant-b", "10.0.0.2", port),
+	})
+
+	if serviceImport.Name != "svc" || serviceImport.Spec.Type != mcsv1alpha1.Headless {
+		t.Errorf("unexpected service import %+v", serviceImport)
+	}
+	expectedClusters := []mcsv1alpha1.ClusterStatus{{Cluster: "tenant-a"}, {Cluster: "tenant-b"}}
+	if !equality.Semantic.DeepEqual(serviceImport.Status.Clusters, expectedClusters) {
+		t.Errorf("expected clusters %v, got %v", expectedClusters, serviceImport.Status.Clusters)
+	}
+
+	if service.Name != "imported-svc" || service.Spec.ClusterIP != corev1.ClusterIPNone || len(service.Spec.Selector) != 0 {
+		t.Errorf("expected headless service imported-svc without selector, got %+v", service)
+	}
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].TargetPort.IntValue() != 80 {
+		t.Errorf("unexpected service ports %v", service.Spec.Ports)
+	}
+	for _, obj := range []metav1.Object{serviceImport, service, endpoints} {
+		if obj.GetLabels()[constants.LabelServiceImport] != "svc" {
+			t.Errorf("expected %s to be labeled as imported", obj.GetName())
+		}
+	}
+
+	expectedSubsets := []corev1.EndpointSubset{{
+		Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+		Ports:     []corev1.EndpointPort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+	}}
+	if !equality.Semantic.DeepEqual(endpoints.Subsets, expectedSubsets) {
+		t.Errorf("expected subsets %v, got %v", expectedSubsets, endpoints.Subsets)
+	}
+
+	if name := importedServiceName(strings.Repeat("a", 60)); len(name) > 63 {
+		t.Errorf("expected imported service name to be truncated, got %s", name)
+	}
+}
+
+func newTenantClient(objs ...runtime.Object) client.Client {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = mcsv1alpha1.AddToScheme(s)
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+}
+
+func TestApplyAndRemoveServiceImport(t *testing.T) {
+	port := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	tenantClient := newTenantClient()
+
+	serviceImport, service, endpoints := buildServiceImport("default", "svc", []exporter{exportedService("tenant-a", "10.0.0.1", port)})
+	if err := applyServiceImport(tenantClient, serviceImport); err != nil {
+		t.Fatalf("failed to apply service import: %v", err)
+	}
+	if err := applyImportedService(tenantClient, service); err != nil {
+		t.Fatalf("failed to apply imported service: %v", err)
+	}
+	if err := applyImportedEndpoints(tenantClient, endpoints); err != nil {
+		t.Fatalf("failed to apply imported endpoints: %v", err)
+	}
+
+	// a second exporter is added.
+	serviceImport, service, endpoints = buildServiceImport("default", "svc", []exporter{
+		exportedService("tenant-a", "10.0.0.1", port),
+		exportedService("tenant-b", "10.0.0.2", port),
+	})
+	if err := applyServiceImport(tenantClient, serviceImport); err != nil {
+		t.Fatalf("failed to update service import: %v", err)
+	}
+	if err := applyImportedEndpoints(tenantClient, endpoints); err != nil {
+		t.Fatalf("failed to update imported endpoints: %v", err)
+	}
+	current := &corev1.Endpoints{}
+	if err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(endpoints), current); err != nil {
+		t.Fatalf("failed to get imported endpoints: %v", err)
+	}
+	if len(current.Subsets) != 1 || len(current.Subsets[0].Addresses) != 2 {
+		t.Errorf("expected endpoints of both exporters, got %v", current.Subsets)
+	}
+	currentImport := &mcsv1alpha1.ServiceImport{}
+	if err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(serviceImport), currentImport); err != nil {
+		t.Fatalf("failed to get service import: %v", err)
+	}
+	if len(currentImport.Status.Clusters) != 2 {
+		t.Errorf("expected both exporters in service import status, got %v", currentImport.Status.Clusters)
+	}
+
+	if err := removeServiceImport(tenantClient, "default", "svc"); err != nil {
+		t.Fatalf("failed to remove service import: %v", err)
+	}
+	for _, obj := range []client.Object{serviceImport, service, endpoints} {
+		if err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Errorf("expected %s to be removed, got %v", obj.GetName(), err)
+		}
+	}
+}
+
+func TestImportedServiceConflict(t *testing.T) {
+	port := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	local := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "imported-svc", Namespace: "default"}}
+	tenantClient := newTenantClient(local)
+
+	_, service, _ := buildServiceImport("default", "svc", []exporter{exportedService("tenant-a", "10.0.0.1", port)})
+	if err := applyImportedService(tenantClient, service); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected conflict with tenant service, got %v", err)
+	}
+
+	if err := removeServiceImport(tenantClient, "default", "svc"); err != nil {
+		t.Fatalf("failed to remove service import: %v", err)
+	}
+	if err := tenantClient.Get(context.TODO(), client.ObjectKeyFromObject(local), &corev1.Service{}); err != nil {
+		t.Errorf("expected tenant service to be kept, got %v", err)
+	}
+}
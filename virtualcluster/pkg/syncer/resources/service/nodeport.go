@@ -0,0 +1,329 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vclisters "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/listers/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+const (
+	reasonNodePortNotAllowed = "NodePortNotAllowed"
+	reasonNodePortConflict   = "NodePortConflict"
+)
+
+// rejectedError is returned when a tenant Service cannot be synced because
+// its NodePorts do not fit into the NodePort range of the VirtualCluster.
+type rejectedError struct {
+	reason  string
+	message string
+}
+
+func (e *rejectedError) Error() string {
+	return e.message
+}
+
+func isRejected(err error) bool {
+	_, ok := err.(*rejectedError)
+	return ok
+}
+
+// nodePortAllocator gives each VirtualCluster a disjoint slice of the super
+// cluster NodePort range and assigns the NodePorts of tenant services from it.
+// The slice of a VirtualCluster is recorded in Status.NodePortRange.
+type nodePortAllocator struct {
+	superRange utilnet.PortRange
+	size       int
+	vcClient   vcclient.Interface
+	vcLister   vclisters.VirtualClusterLister
+	// tenantRange is the range the tenant apiservers allocate NodePorts from.
+	tenantRange utilnet.PortRange
+
+	sync.Mutex
+	// assigned remembers the slices handed out by this allocator, which may
+	// not be observed by vcLister yet.
+	assigned map[types.UID]utilnet.PortRange
+}
+
+// newNodePortAllocator returns nil if per tenant NodePort ranges are disabled.
+func newNodePortAllocator(cfg *config.SyncerConfiguration, vcClient vcclient.Interface, vcLister vclisters.VirtualClusterLister) (*nodePortAllocator, error) {
+	if cfg.TenantNodePortRangeSize <= 0 {
+		return nil, nil
+	}
+	superRange, err := utilnet.ParsePortRange(cfg.SuperClusterNodePortRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid super cluster NodePort range: %v", err)
+	}
+	tenantRange, err := utilnet.ParsePortRange(cfg.TenantClusterNodePortRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant cluster NodePort range: %v", err)
+	}
+	if int(cfg.TenantNodePortRangeSize) > superRange.Size {
+		return nil, fmt.Errorf("tenant NodePort range size %d exceeds super cluster NodePort range %s", cfg.TenantNodePortRangeSize, superRange.String())
+	}
+	return &nodePortAllocator{
+		superRange:  *superRange,
+		size:        int(cfg.TenantNodePortRangeSize),
+		vcClient:    vcClient,
+		vcLister:    vcLister,
+		tenantRange: *tenantRange,
+		assigned:    make(map[types.UID]utilnet.PortRange),
+	}, nil
+}
+
+// rangeFor returns the NodePort range of vc, reserving a free slice of the
+// super cluster range if vc does not have one yet.
+func (a *nodePortAllocator) rangeFor(vc *v1alpha1.VirtualCluster) (*utilnet.PortRange, error) {
+	if vc.Status.NodePortRange != "" {
+		return utilnet.ParsePortRange(vc.Status.NodePortRange)
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	if r, ok := a.assigned[vc.UID]; ok {
+		return &r, nil
+	}
+
+	vcs, err := a.vcLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var used []utilnet.PortRange
+	exists := make(map[types.UID]struct{})
+	for _, each := range vcs {
+		exists[each.UID] = struct{}{}
+		if each.Status.NodePortRange == "" {
+			continue
+		}
+		r, err := utilnet.ParsePortRange(each.Status.NodePortRange)
+		if err != nil {
+			klog.Warningf("ignore invalid NodePort range of virtual cluster %s/%s: %v", each.Namespace, each.Name, err)
+			continue
+		}
+		used = append(used, *r)
+	}
+	for uid, r := range a.assigned {
+		if _, ok := exists[uid]; !ok {
+			delete(a.assigned, uid)
+			continue
+		}
+		used = append(used, r)
+	}
+
+	var free *utilnet.PortRange
+	end := a.superRange.Base + a.superRange.Size
+	for base := a.superRange.Base; base+a.size <= end && free == nil; base += a.size {
+		candidate := utilnet.PortRange{Base: base, Size: a.size}
+		if !overlaps(candidate, used) {
+			free = &candidate
+		}
+	}
+	if free == nil {
+		return nil, fmt.Errorf("no free NodePort range left in %s", a.superRange.String())
+	}
+
+	latest, err := a.vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Get(vc.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if latest.Status.NodePortRange != "" {
+		return utilnet.ParsePortRange(latest.Status.NodePortRange)
+	}
+	latest.Status.NodePortRange = free.String()
+	if _, err := a.vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).UpdateStatus(latest); err != nil {
+		return nil, fmt.Errorf("failed to record NodePort range of virtual cluster %s/%s: %v", vc.Namespace, vc.Name, err)
+	}
+	klog.Infof("reserved NodePort range %s for virtual cluster %s/%s", free.String(), vc.Namespace, vc.Name)
+	a.assigned[vc.UID] = *free
+	return free, nil
+}
+
+func overlaps(r utilnet.PortRange, ranges []utilnet.PortRange) bool {
+	for _, other := range ranges {
+		if r.Base < other.Base+other.Size && other.Base < r.Base+r.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// requested reports whether the tenant NodePort port was explicitly requested
+// rather than allocated by the tenant apiserver from tenantRange.
+func requested(tenantRange *utilnet.PortRange, port int32) bool {
+	return port != 0 && !tenantRange.Contains(int(port))
+}
+
+// assign sets the NodePorts of pService within r. NodePorts explicitly
+// requested by vService must fall into r and not be used by another super
+// service, otherwise vService is rejected. The NodePorts vService got from the
+// tenant apiserver are ignored, while those already assigned to pService are
+// kept if they fall into r and are free. Missing NodePorts are picked from the
+// free ports of r.
+func assign(r, tenantRange *utilnet.PortRange, serviceLister listersv1.ServiceLister, vService, pService *corev1.Service) error {
+	pServices, err := serviceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	used := sets.NewInt()
+	for _, each := range pServices {
+		if each.Namespace == pService.Namespace && each.Name == pService.Name {
+			continue
+		}
+		for _, port := range each.Spec.Ports {
+			used.Insert(int(port.NodePort))
+		}
+		used.Insert(int(each.Spec.HealthCheckNodePort))
+	}
+
+	reserve := func(requested int32, what string) error {
+		if !r.Contains(int(requested)) {
+			return &rejectedError{
+				reason:  reasonNodePortNotAllowed,
+				message: fmt.Sprintf("%s %d is not in the NodePort range %s of the virtual cluster", what, requested, r.String()),
+			}
+		}
+		if used.Has(int(requested)) {
+			return &rejectedError{
+				reason:  reasonNodePortConflict,
+				message: fmt.Sprintf("%s %d is already allocated", what, requested),
+			}
+		}
+		used.Insert(int(requested))
+		return nil
+	}
+	// keep reserves port if it is free in r, and returns 0 otherwise.
+	keep := func(port int32) int32 {
+		if port == 0 || !r.Contains(int(port)) || used.Has(int(port)) {
+			return 0
+		}
+		used.Insert(int(port))
+		return port
+	}
+	pick := func() (int32, error) {
+		for port := r.Base; port < r.Base+r.Size; port++ {
+			if !used.Has(port) {
+				used.Insert(port)
+				return int32(port), nil
+			}
+		}
+		return 0, fmt.Errorf("no free NodePort left in range %s", r.String())
+	}
+
+	// Reserve the requested NodePorts first so that they are not taken by other ports.
+	kept := make([]bool, len(pService.Spec.Ports))
+	for i := range pService.Spec.Ports {
+		port := &pService.Spec.Ports[i]
+		if !needsNodePort(pService) {
+			port.NodePort = 0
+			continue
+		}
+		if i < len(vService.Spec.Ports) && requested(tenantRange, vService.Spec.Ports[i].NodePort) {
+			port.NodePort = vService.Spec.Ports[i].NodePort
+			if err := reserve(port.NodePort, "nodePort"); err != nil {
+				return err
+			}
+			kept[i] = true
+		}
+	}
+	keptHealthCheck := false
+	if !needsHealthCheckNodePort(pService) {
+		pService.Spec.HealthCheckNodePort = 0
+	} else if requested(tenantRange, vService.Spec.HealthCheckNodePort) {
+		pService.Spec.HealthCheckNodePort = vService.Spec.HealthCheckNodePort
+		if err := reserve(pService.Spec.HealthCheckNodePort, "healthCheckNodePort"); err != nil {
+			return err
+		}
+		keptHealthCheck = true
+	}
+
+	// Then keep the NodePorts pService was assigned before.
+	for i := range pService.Spec.Ports {
+		if needsNodePort(pService) && !kept[i] {
+			pService.Spec.Ports[i].NodePort = keep(pService.Spec.Ports[i].NodePort)
+		}
+	}
+	if needsHealthCheckNodePort(pService) && !keptHealthCheck {
+		pService.Spec.HealthCheckNodePort = keep(pService.Spec.HealthCheckNodePort)
+	}
+
+	for i := range pService.Spec.Ports {
+		if needsNodePort(pService) && pService.Spec.Ports[i].NodePort == 0 {
+			if pService.Spec.Ports[i].NodePort, err = pick(); err != nil {
+				return err
+			}
+		}
+	}
+	if needsHealthCheckNodePort(pService) && pService.Spec.HealthCheckNodePort == 0 {
+		if pService.Spec.HealthCheckNodePort, err = pick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func needsNodePort(service *corev1.Service) bool {
+	switch service.Spec.Type {
+	case corev1.ServiceTypeNodePort:
+		return true
+	case corev1.ServiceTypeLoadBalancer:
+		return service.Spec.AllocateLoadBalancerNodePorts == nil || *service.Spec.AllocateLoadBalancerNodePorts
+	}
+	return false
+}
+
+func needsHealthCheckNodePort(service *corev1.Service) bool {
+	return service.Spec.Type == corev1.ServiceTypeLoadBalancer &&
+		service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal
+}
+
+// assignNodePorts assigns the NodePorts of pService if per tenant NodePort
+// ranges are enabled.
+func (c *controller) assignNodePorts(vc *v1alpha1.VirtualCluster, vService, pService *corev1.Service) error {
+	if c.nodePorts == nil {
+		return nil
+	}
+	r, err := c.nodePorts.rangeFor(vc)
+	if err != nil {
+		return err
+	}
+	return assign(r, &c.nodePorts.tenantRange, c.serviceLister, vService, pService)
+}
+
+// rejectService surfaces why vService is not synced with an event.
+func (c *controller) rejectService(clusterName string, vService *corev1.Service, rejected *rejectedError) error {
+	klog.Warningf("service %s/%s of cluster %s is rejected: %s", vService.Namespace, vService.Name, clusterName, rejected.message)
+	return c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:      "Service",
+		Name:      vService.Name,
+		Namespace: vService.Namespace,
+		UID:       vService.UID,
+	}, corev1.EventTypeWarning, rejected.reason, "Service is not synced: %s", rejected.message)
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	fakevcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned/fake"
+	vcinformerFactory "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+// defaultTenantNodePortRange is the range the tenant apiservers allocate
+// NodePorts from by default.
+const defaultTenantNodePortRange = "30000-32767"
+
+func newNodePortServiceController(tenantRange string) func(cfg *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	return func(cfg *config.SyncerConfiguration,
+		client clientset.Interface,
+		informer informers.SharedInformerFactory,
+		vcClient vcclient.Interface,
+		vcInformer vcinformers.VirtualClusterInformer,
+		options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+		cfg.SuperClusterNodePortRange = "30000-30999"
+		cfg.TenantNodePortRangeSize = 100
+		cfg.TenantClusterNodePortRange = tenantRange
+		return NewServiceController(cfg, client, informer, vcClient, vcInformer, options)
+	}
+}
+
+func applyNodePortsToService(svc *corev1.Service, nodePorts ...int32) *corev1.Service {
+	svc.Spec.Type = corev1.ServiceTypeNodePort
+	svc.Spec.Ports = nil
+	for i, nodePort := range nodePorts {
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+			Port:     int32(80 + i),
+			Protocol: corev1.ProtocolTCP,
+			NodePort: nodePort,
+		})
+	}
+	return svc
+}
+
+func TestDWServiceNodePorts(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase:         v1alpha1.ClusterRunning,
+			NodePortRange: "30100-30199",
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		TenantNodePortRange    string
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant *corev1.Service
+
+		ExpectedNodePorts []int32
+		ExpectedRejected  bool
+	}{
+		"allocated nodePort out of range": {
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 31234),
+			ExpectedNodePorts:      []int32{30100},
+		},
+		"allocated nodePorts picked from range": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyNodePortsToService(superService("svc-2", superDefaultNSName, "123456", defaultClusterKey), 30100),
+			},
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 31000, 30150),
+			ExpectedNodePorts:      []int32{30101, 30102},
+		},
+		"requested nodePort in range": {
+			TenantNodePortRange:    "32000-32767",
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 30150),
+			ExpectedNodePorts:      []int32{30150},
+		},
+		"requested nodePort out of range": {
+			TenantNodePortRange:    "32000-32767",
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 30050),
+			ExpectedRejected:       true,
+		},
+		"requested nodePort already allocated": {
+			TenantNodePortRange: "32000-32767",
+			ExistingObjectInSuper: []runtime.Object{
+				applyNodePortsToService(superService("svc-2", superDefaultNSName, "123456", defaultClusterKey), 30150),
+			},
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 30150),
+			ExpectedRejected:       true,
+		},
+		"requested nodePort reserved before allocated ones": {
+			TenantNodePortRange:    "32000-32767",
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 32001, 30100),
+			ExpectedNodePorts:      []int32{30101, 30100},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantRange := tc.TenantNodePortRange
+			if tenantRange == "" {
+				tenantRange = defaultTenantNodePortRange
+			}
+			actions, reconcileErr, err := util.RunDownwardSync(newNodePortServiceController(tenantRange),
+				testTenant,
+				tc.ExistingObjectInSuper,
+				[]runtime.Object{tc.ExistingObjectInTenant},
+				tc.ExistingObjectInTenant,
+				nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				return
+			}
+
+			if tc.ExpectedRejected {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expected service to be rejected, got actions %#v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("create", "services") {
+				t.Errorf("%s: Expected service to be created, got actions %#v", k, actions)
+				return
+			}
+			created := actions[0].(core.CreateAction).GetObject().(*corev1.Service)
+			for i, expected := range tc.ExpectedNodePorts {
+				if created.Spec.Ports[i].NodePort != expected {
+					t.Errorf("%s: Expected nodePort %d of port %d, got %d", k, expected, i, created.Spec.Ports[i].NodePort)
+				}
+			}
+		})
+	}
+}
+
+func TestDWServiceNodePortsUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase:         v1alpha1.ClusterRunning,
+			NodePortRange: "30100-30199",
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		TenantNodePortRange    string
+		ExistingObjectInSuper  *corev1.Service
+		ExistingObjectInTenant *corev1.Service
+
+		ExpectedNodePorts []int32
+	}{
+		"requested nodePort changed by the tenant": {
+			TenantNodePortRange:    "32000-32767",
+			ExistingObjectInSuper:  applyNodePortsToService(superService("svc-1", superDefaultNSName, "12345", defaultClusterKey), 30150),
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 30160),
+			ExpectedNodePorts:      []int32{30160},
+		},
+		"requested nodePort unchanged": {
+			TenantNodePortRange:    "32000-32767",
+			ExistingObjectInSuper:  applyNodePortsToService(superService("svc-1", superDefaultNSName, "12345", defaultClusterKey), 30150),
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 30150),
+		},
+		"nodePort allocated by the tenant apiserver": {
+			ExistingObjectInSuper:  applyNodePortsToService(superService("svc-1", superDefaultNSName, "12345", defaultClusterKey), 30150),
+			ExistingObjectInTenant: applyNodePortsToService(tenantService("svc-1", "default", "12345"), 31234),
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantRange := tc.TenantNodePortRange
+			if tenantRange == "" {
+				tenantRange = defaultTenantNodePortRange
+			}
+			actions, reconcileErr, err := util.RunDownwardSync(newNodePortServiceController(tenantRange),
+				testTenant,
+				[]runtime.Object{tc.ExistingObjectInSuper},
+				[]runtime.Object{tc.ExistingObjectInTenant},
+				tc.ExistingObjectInTenant,
+				nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				return
+			}
+
+			if tc.ExpectedNodePorts == nil {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expected no action, got %#v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("update", "services") {
+				t.Errorf("%s: Expected service to be updated, got actions %#v", k, actions)
+				return
+			}
+			updated := actions[0].(core.UpdateAction).GetObject().(*corev1.Service)
+			for i, expected := range tc.ExpectedNodePorts {
+				if updated.Spec.Ports[i].NodePort != expected {
+					t.Errorf("%s: Expected nodePort %d of port %d, got %d", k, expected, i, updated.Spec.Ports[i].NodePort)
+				}
+			}
+		})
+	}
+}
+
+func TestNodePortAllocatorRangeFor(t *testing.T) {
+	existing := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "tenant-1", UID: "uid-1"},
+		Status:     v1alpha1.VirtualClusterStatus{NodePortRange: "30000-30099"},
+	}
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "tenant-2", UID: "uid-2"},
+	}
+	vcClient := fakevcclient.NewSimpleClientset(existing, vc)
+	vcInformer := vcinformerFactory.NewSharedInformerFactory(vcClient, 0).Tenancy().V1alpha1().VirtualClusters()
+	for _, each := range []*v1alpha1.VirtualCluster{existing, vc} {
+		if err := vcInformer.Informer().GetStore().Add(each); err != nil {
+			t.Fatalf("failed to add virtual cluster to informer: %v", err)
+		}
+	}
+
+	allocator, err := newNodePortAllocator(&config.SyncerConfiguration{
+		SuperClusterNodePortRange:  "30000-30199",
+		TenantNodePortRangeSize:    100,
+		TenantClusterNodePortRange: defaultTenantNodePortRange,
+	}, vcClient, vcInformer.Lister())
+	if err != nil {
+		t.Fatalf("failed to create allocator: %v", err)
+	}
+
+	r, err := allocator.rangeFor(vc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.String() != "30100-30199" {
+		t.Errorf("expected range 30100-30199, got %s", r.String())
+	}
+	updated, err := vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Get(vc.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.NodePortRange != "30100-30199" {
+		t.Errorf("expected range to be recorded in status, got %q", updated.Status.NodePortRange)
+	}
+
+	another := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "another", Namespace: "tenant-3", UID: "uid-3"},
+	}
+	if err := vcInformer.Informer().GetStore().Add(another); err != nil {
+		t.Fatalf("failed to add virtual cluster to informer: %v", err)
+	}
+	if _, err := allocator.rangeFor(another); err == nil {
+		t.Errorf("expected exhausted NodePort range to be reported")
+	}
+}
+
+func TestUWServiceNodePorts(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase:         v1alpha1.ClusterRunning,
+			NodePortRange: "30100-30199",
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	actions, reconcileErr, err := util.RunUpwardSync(newNodePortServiceController(defaultTenantNodePortRange), testTenant,
+		[]runtime.Object{applyNodePortsToService(superService("svc", superDefaultNSName, "12345", defaultClusterKey), 30102)},
+		[]runtime.Object{applyNodePortsToService(tenantService("svc", "default", "12345"), 31000)},
+		superDefaultNSName+"/svc", nil)
+	if err != nil {
+		t.Fatalf("error running upward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Fatalf("expected no error, but got \"%v\"", reconcileErr)
+	}
+
+	for _, action := range actions {
+		if !action.Matches("update", "services") {
+			continue
+		}
+		updated := action.(core.UpdateAction).GetObject().(*corev1.Service)
+		if updated.Spec.Ports[0].NodePort != 30102 {
+			t.Errorf("expected nodePort 30102 to be back populated, got %d", updated.Spec.Ports[0].NodePort)
+		}
+		return
+	}
+	t.Errorf("expected vService to be updated, got actions %#v", actions)
+}
@@ -112,8 +112,17 @@ func (c *controller) BackPopulate(key string) error {
 			// Add clusterIP to ExternalIPs if it hasn't been set on purpose
 			newService.Spec.ExternalIPs = []string{updatedMeta.Annotations[constants.LabelSuperClusterIP]}
 		}
+	}
+	// Ports of pService and vService differ while a tenant update is being synced down.
+	if c.nodePorts != nil && len(pService.Spec.Ports) == len(vService.Spec.Ports) && !nodePortsEqual(pService, vService) {
+		if newService == nil {
+			newService = vService.DeepCopy()
+		}
+		copyNodePorts(pService, newService)
+	}
+	if newService != nil {
 		if _, err = tenantClient.CoreV1().Services(vService.Namespace).Update(context.TODO(), newService, metav1.UpdateOptions{}); err != nil {
-			return fmt.Errorf("failed to back populate service %s/%s meta update for cluster %s: %v", vService.Namespace, vService.Name, clusterName, err)
+			return fmt.Errorf("failed to back populate service %s/%s update for cluster %s: %v", vService.Namespace, vService.Name, clusterName, err)
 		}
 	}
 
@@ -133,3 +142,29 @@ func (c *controller) BackPopulate(key string) error {
 	}
 	return nil
 }
+
+// nodePortsEqual checks whether vService shows the NodePorts allocated to
+// pService. Both services must have the same number of ports.
+func nodePortsEqual(pService, vService *corev1.Service) bool {
+	if pService.Spec.HealthCheckNodePort != vService.Spec.HealthCheckNodePort {
+		return false
+	}
+	for i := range pService.Spec.Ports {
+		if pService.Spec.Ports[i].NodePort != vService.Spec.Ports[i].NodePort {
+			return false
+		}
+	}
+	return true
+}
+
+// copyNodePorts copies the NodePorts allocated to pService to the matching ports of
+// vService. Both services must have the same number of ports.
+func copyNodePorts(pService, vService *corev1.Service) {
+	for i := range vService.Spec.Ports {
+		pPort := pService.Spec.Ports[i]
+		if vService.Spec.Ports[i].Port == pPort.Port && vService.Spec.Ports[i].Protocol == pPort.Protocol {
+			vService.Spec.Ports[i].NodePort = pPort.NodePort
+		}
+	}
+	vService.Spec.HealthCheckNodePort = pService.Spec.HealthCheckNodePort
+}
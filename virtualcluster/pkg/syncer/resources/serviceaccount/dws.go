@@ -121,9 +121,11 @@ func (c *controller) reconcileServiceAccountUpdate(clusterName, targetNamespace,
 		return err
 	}
 
-	if pSa.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pServiceAccount %s/%s delegated UID is different from updated object", targetNamespace, pSa.Name)
+	pObj, err := c.conflicts.Check(clusterName, requestUID, vSa, pSa)
+	if err != nil {
+		return err
 	}
+	pSa = pObj.(*corev1.ServiceAccount)
 
 	// do nothing.
 	return nil
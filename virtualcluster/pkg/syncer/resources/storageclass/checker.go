@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
 
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,9 +31,10 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
-var numMissMatchedStorageClasses uint64
+var numMissMatchedStorageClasses = metrics.NewMissMatchCounter("MissMatchedStorageClasses")
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.storageclassSynced) {
@@ -53,7 +53,7 @@ func (c *controller) PatrollerDo() {
 	}
 
 	wg := sync.WaitGroup{}
-	numMissMatchedStorageClasses = 0
+	numMissMatchedStorageClasses.Reset()
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -75,6 +75,14 @@ func (c *controller) PatrollerDo() {
 			continue
 		}
 		for _, clusterName := range clusterNames {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pStorageClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of storageclass %s selects cluster %s: %v", pStorageClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				continue
+			}
 			if err := c.MultiClusterController.Get(clusterName, "", pStorageClass.Name, &storagev1.StorageClass{}); err != nil {
 				if apierrors.IsNotFound(err) {
 					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperControlPlaneStorageClasses").Inc()
@@ -85,7 +93,7 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedStorageClasses").Set(float64(numMissMatchedStorageClasses))
+	numMissMatchedStorageClasses.Record()
 }
 
 func (c *controller) checkStorageClassOfTenantCluster(clusterName string) {
@@ -121,9 +129,23 @@ func (c *controller) checkStorageClassOfTenantCluster(clusterName string) {
 			continue
 		}
 
+		if util.IsPublicObject(&scList.Items[i]) {
+			selected, err := util.PublicationSelectsCluster(c.MultiClusterController, clusterName, pStorageClass)
+			if err != nil {
+				klog.Errorf("failed to check whether the selector of storageclass %s selects cluster %s: %v", pStorageClass.Name, clusterName, err)
+				continue
+			}
+			if !selected {
+				// the tenant no longer matches the selector of pStorageClass, retract it.
+				metrics.CheckerRemedyStats.WithLabelValues("RetractedTenantStorageClasses").Inc()
+				c.UpwardController.AddToQueue(clusterName + "/" + pStorageClass.Name)
+				continue
+			}
+		}
+
 		updatedStorageClass := conversion.Equality(nil, nil).CheckStorageClassEquality(pStorageClass, &scList.Items[i])
 		if updatedStorageClass != nil {
-			atomic.AddUint64(&numMissMatchedStorageClasses, 1)
+			numMissMatchedStorageClasses.Inc(clusterName)
 			klog.Warningf("spec of storageClass %v diff in super&tenant control plane", vStorageClass.Name)
 			if publicStorageClass(pStorageClass) {
 				c.UpwardController.AddToQueue(clusterName + "/" + pStorageClass.Name)
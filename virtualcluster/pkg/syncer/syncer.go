@@ -50,11 +50,13 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/quota"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
 	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/naming"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
 
@@ -79,6 +81,8 @@ type Syncer struct {
 	// clusterSet holds the cluster collection in which cluster is running.
 	mu         sync.Mutex
 	clusterSet map[string]mc.ClusterInterface
+	// quotaReporter reports the aggregate quota usage of virtual clusters.
+	quotaReporter *quota.Reporter
 }
 
 type virtualclusterGetter struct {
@@ -110,6 +114,12 @@ func New(
 	superClusterInformers informers.SharedInformerFactory,
 	recorder record.EventRecorder,
 ) (*Syncer, error) {
+	metrics.ConfigureTenantLabels(metrics.TenantLabelOptions{
+		Enabled:    config.TenantMetrics,
+		AllowList:  config.TenantMetricsAllowList,
+		MaxTenants: config.TenantMetricsMaxTenants,
+	})
+
 	syncer := &Syncer{
 		config:      config,
 		metaClient:  metaClusterClient,
@@ -119,6 +129,7 @@ func New(
 		workers:     constants.UwsControllerWorkerLow,
 		clusterSet:  make(map[string]mc.ClusterInterface),
 	}
+	syncer.quotaReporter = quota.NewReporter(virtualClusterClient, virtualClusterInformer, superClusterInformers)
 
 	// Handle VirtualCluster add&delete
 	virtualClusterInformer.Informer().AddEventHandler(
@@ -227,12 +238,28 @@ func (s *Syncer) Run(stopChan <-chan struct{}) {
 			os.Exit(1)
 		}
 	}
+	if s.config.NamingRegistry != "" {
+		// The registry must be loaded before any namespace is named. The
+		// legacy naming would sync the tenants into other namespaces.
+		store, err := naming.NewStore(s.superClient, s.config.NamingRegistry)
+		if err == nil {
+			err = store.Start(naming.DefaultRegistry, stopChan)
+		}
+		if err == nil {
+			err = store.WatchNamespaces(naming.DefaultRegistry, stopChan)
+		}
+		if err != nil {
+			klog.Infof("Fail to load naming registry %s from super cluster: %v. Quit!", s.config.NamingRegistry, err)
+			os.Exit(1)
+		}
+	}
 	go func() {
 		if err := s.controllerManager.Start(stopChan); err != nil {
 			klog.V(1).Infof("controller manager exit: %v", err)
 		}
 	}()
 	go wait.Until(s.healthPatrol, 1*time.Minute, stopChan)
+	go wait.Until(s.quotaReporter.Report, 30*time.Second, stopChan)
 	go func() {
 		defer utilruntime.HandleCrash()
 		defer s.queue.ShutDown()
@@ -339,6 +366,7 @@ func (s *Syncer) removeCluster(key string) {
 	for _, clusterChangeListener := range listener.Listeners {
 		clusterChangeListener.RemoveCluster(vc)
 	}
+	metrics.RemoveTenant(vc.GetClusterName())
 
 	delete(s.clusterSet, key)
 }
@@ -365,6 +393,8 @@ func (s *Syncer) addCluster(key string, vc *v1alpha1.VirtualCluster) error {
 		return fmt.Errorf("failed to new tenant cluster %s/%s: %v", vc.Namespace, vc.Name, err)
 	}
 
+	metrics.AddTenant(clusterName, key)
+
 	// for each resource type of the newly added VirtualCluster, we add the object to informer cache.
 	for _, clusterChangeListener := range listener.Listeners {
 		clusterChangeListener.AddCluster(tenantCluster)
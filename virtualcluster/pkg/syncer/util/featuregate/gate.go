@@ -92,6 +92,12 @@ const (
 	// KubeApiAccessSupport is an experimental feature that allows clusters +1.21 to support
 	// kube-api-access volume mount
 	KubeApiAccessSupport = "KubeApiAccessSupport"
+
+	// ServiceExport is an experimental feature that allows tenants to share
+	// Services with other tenants by ServiceExports of the Multi-Cluster
+	// Services API. The multicluster.x-k8s.io CRDs must be installed in every
+	// tenant control plane.
+	ServiceExport = "ServiceExport"
 )
 
 var defaultFeatures = FeatureList{
@@ -108,6 +114,18 @@ var defaultFeatures = FeatureList{
 	RootCACertConfigMapSupport:      {Default: false},
 	VServiceExternalIP:              {Default: false},
 	KubeApiAccessSupport:            {Default: false},
+	ServiceExport:                   {Default: false},
+}
+
+// reloadableFeatures are checked on each sync, so that they can be changed
+// without restarting the syncer.
+var reloadableFeatures = map[Feature]struct{}{
+	TenantAllowDNSPolicy:            {},
+	TenantAllowResourceNoSync:       {},
+	DisableCRDPreserveUnknownFields: {},
+	RootCACertConfigMapSupport:      {},
+	VServiceExternalIP:              {},
+	KubeApiAccessSupport:            {},
 }
 
 type Feature string
@@ -131,6 +149,12 @@ func Supports(featureList FeatureList, featureName string) bool {
 	return false
 }
 
+// IsReloadable indicates whether a feature can be changed at runtime.
+func IsReloadable(key Feature) bool {
+	_, ok := reloadableFeatures[key]
+	return ok
+}
+
 // featureGate implements FeatureGate
 type featureGate struct {
 	mu sync.Mutex
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// IsPublicObject checks whether the object is marked to be populated to
+// tenant control planes. Objects populated by the syncer keep the mark in the
+// tenant control plane.
+func IsPublicObject(obj metav1.Object) bool {
+	return obj.GetLabels()[constants.PublicObjectKey] == "true"
+}
+
+// PublicationSelects checks whether the selector of the public super control
+// plane object selects vc. Public objects without a selector are populated to
+// every tenant control plane.
+func PublicationSelects(obj metav1.Object, vc *v1alpha1.VirtualCluster) (bool, error) {
+	value, ok := obj.GetAnnotations()[constants.PublicObjectSelectorKey]
+	if !ok {
+		return true, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation of %s: %v", constants.PublicObjectSelectorKey, obj.GetName(), err)
+	}
+	return selector.Matches(labels.Set(vc.GetLabels())), nil
+}
+
+// PublicationSelectsCluster checks whether the selector of the public super
+// control plane object selects the VirtualCluster of clusterName.
+func PublicationSelectsCluster(mc mc.MultiClusterInterface, clusterName string, obj metav1.Object) (bool, error) {
+	if _, ok := obj.GetAnnotations()[constants.PublicObjectSelectorKey]; !ok {
+		return true, nil
+	}
+	vc, err := GetVirtualClusterObject(mc, clusterName)
+	if err != nil {
+		return false, err
+	}
+	return PublicationSelects(obj, vc)
+}
@@ -27,6 +27,8 @@ import (
 	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	fakevcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned/fake"
+	vcinformerFactory "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
@@ -85,6 +87,10 @@ func RunUpwardSync(
 	}
 	superInformer := informers.NewSharedInformerFactory(superClient, 0)
 
+	// setup fake vc client
+	vcClient := fakevcclient.NewSimpleClientset()
+	vcInformer := vcinformerFactory.NewSharedInformerFactory(vcClient, 0).Tenancy().V1alpha1().VirtualClusters()
+
 	// setup fake controller
 	syncErr := make(chan error)
 	defer close(syncErr)
@@ -100,8 +106,8 @@ func RunUpwardSync(
 		},
 		superClient,
 		superInformer,
-		nil,
-		nil,
+		vcClient,
+		vcInformer,
 		rsOptions,
 	)
 	if err != nil {
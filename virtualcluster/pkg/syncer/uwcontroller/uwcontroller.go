@@ -24,6 +24,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
@@ -125,11 +126,13 @@ func (c *UpwardController) processNextWorkItem() bool {
 	}
 
 	defer metrics.RecordUWSOperationDuration(c.objectKind, time.Now())
+	// keys are either the super control plane namespace/name or cluster/name of the object.
+	namespace, _, _ := cache.SplitMetaNamespaceKey(key)
 
 	klog.V(4).Infof("%s back populate %+v", c.name, key)
 	err := c.Reconciler.BackPopulate(key)
 	if err == nil {
-		metrics.RecordUWSOperationStatus(c.objectKind, utilconstants.StatusCodeOK)
+		metrics.RecordUWSOperationStatus(c.objectKind, namespace, utilconstants.StatusCodeOK)
 		c.Queue.Forget(obj)
 		return true
 	}
@@ -143,12 +146,12 @@ func (c *UpwardController) processNextWorkItem() bool {
 
 	utilruntime.HandleError(fmt.Errorf("%s error processing %s (will retry): %v", c.name, key, err))
 	if c.Queue.NumRequeues(key) >= utilconstants.MaxReconcileRetryAttempts {
-		metrics.RecordUWSOperationStatus(c.objectKind, utilconstants.StatusCodeExceedMaxRetryAttempts)
+		metrics.RecordUWSOperationStatus(c.objectKind, namespace, utilconstants.StatusCodeExceedMaxRetryAttempts)
 		klog.Warningf("%s uws request is dropped due to reaching max retry limit: %s", c.name, key)
 		c.Queue.Forget(obj)
 		return true
 	}
-	metrics.RecordUWSOperationStatus(c.objectKind, utilconstants.StatusCodeError)
+	metrics.RecordUWSOperationStatus(c.objectKind, namespace, utilconstants.StatusCodeError)
 	c.Queue.AddRateLimited(obj)
 	return true
 }
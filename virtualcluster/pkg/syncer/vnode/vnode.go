@@ -39,20 +39,43 @@ import (
 )
 
 func GetNodeProvider(config *config.SyncerConfiguration, client clientset.Interface) provider.VirtualNodeProvider {
-	for _, labelKey := range config.ExtraNodeLabels {
-		defaultLabelsToSync[labelKey] = struct{}{}
+	r := &reloadableProvider{config: config}
+	labelsToSync, taintsToSync := r.GetLabelsToSync(), r.GetTaintsToSync()
+	switch {
+	case featuregate.DefaultFeatureGate.Enabled(featuregate.VNodeProviderService):
+		r.VirtualNodeProvider = service.NewServiceVirtualNodeProvider(config.VNAgentPort, config.VNAgentNamespacedName, client, labelsToSync, taintsToSync)
+	case featuregate.DefaultFeatureGate.Enabled(featuregate.VNodeProviderPodIP):
+		r.VirtualNodeProvider = pod.NewPodVirtualNodeProvider(config.VNAgentPort, config.VNAgentNamespacedName, config.VNAgentLabelSelector, client, labelsToSync, taintsToSync)
+	default:
+		r.VirtualNodeProvider = native.NewNativeVirtualNodeProvider(config.VNAgentPort, labelsToSync, taintsToSync)
 	}
-	taintsToSync := make(map[string]struct{})
-	for _, taintKey := range config.OpaqueTaintKeys {
-		taintsToSync[taintKey] = struct{}{}
+	return r
+}
+
+// reloadableProvider reads the labels and taints to sync from the syncer
+// configuration on each call, as these can be reloaded.
+type reloadableProvider struct {
+	provider.VirtualNodeProvider
+	config *config.SyncerConfiguration
+}
+
+func (r *reloadableProvider) GetLabelsToSync() map[string]struct{} {
+	labelsToSync := make(map[string]struct{}, len(defaultLabelsToSync))
+	for labelKey := range defaultLabelsToSync {
+		labelsToSync[labelKey] = struct{}{}
 	}
-	if featuregate.DefaultFeatureGate.Enabled(featuregate.VNodeProviderService) {
-		return service.NewServiceVirtualNodeProvider(config.VNAgentPort, config.VNAgentNamespacedName, client, defaultLabelsToSync, taintsToSync)
+	for _, labelKey := range r.config.GetExtraNodeLabels() {
+		labelsToSync[labelKey] = struct{}{}
 	}
-	if featuregate.DefaultFeatureGate.Enabled(featuregate.VNodeProviderPodIP) {
-		return pod.NewPodVirtualNodeProvider(config.VNAgentPort, config.VNAgentNamespacedName, config.VNAgentLabelSelector, client, defaultLabelsToSync, taintsToSync)
+	return labelsToSync
+}
+
+func (r *reloadableProvider) GetTaintsToSync() map[string]struct{} {
+	taintsToSync := make(map[string]struct{})
+	for _, taintKey := range r.config.GetOpaqueTaintKeys() {
+		taintsToSync[taintKey] = struct{}{}
 	}
-	return native.NewNativeVirtualNodeProvider(config.VNAgentPort, defaultLabelsToSync, taintsToSync)
+	return taintsToSync
 }
 
 func NewVirtualNode(vNodeProvider provider.VirtualNodeProvider, node *corev1.Node) (vnode *corev1.Node, err error) {
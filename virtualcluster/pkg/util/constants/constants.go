@@ -39,6 +39,23 @@ const (
 
 	// LabelNamespaceSlice is the scheduled slice size of the namespace.
 	LabelNamespaceSlice = "scheduler.virtualcluster.io/slice"
+
+	// LabelSchedulerProfile is the scheduler profile used to place the namespace.
+	LabelSchedulerProfile = "scheduler.virtualcluster.io/profile"
+
+	// LabelScheduledScores records the cluster scores of the last scheduling decision of the namespace.
+	LabelScheduledScores = "scheduler.virtualcluster.io/scores"
+
+	// LabelClusterSelector is the label selector, in the kubectl selector syntax, that the super clusters
+	// have to match to host the namespace. It can be set on the VirtualCluster and on the tenant namespace.
+	LabelClusterSelector = "scheduler.virtualcluster.io/cluster-selector"
+
+	// LabelClusterTolerations is the json encoded list of tolerations of the super cluster taints. It can be set
+	// on the VirtualCluster and on the tenant namespace.
+	LabelClusterTolerations = "scheduler.virtualcluster.io/tolerations"
+
+	// LabelClusterTaints is the json encoded list of taints of a super cluster.
+	LabelClusterTaints = "scheduler.virtualcluster.io/taints"
 )
 
 var DefaultNamespaceSlice = corev1.ResourceList{
@@ -53,3 +53,27 @@ func (m MCControllerListener) WatchCluster(cluster mc.ClusterInterface) {
 		klog.Errorf("failed to watch cluster %s %s event: %v", cluster.GetClusterName(), m.c.GetObjectKind(), err)
 	}
 }
+
+// MultiListener passes the cluster changes to every listener in order, for the
+// resource syncers which watch more than one resource.
+type MultiListener []ClusterChangeListener
+
+var _ ClusterChangeListener = MultiListener{}
+
+func (m MultiListener) AddCluster(cluster mc.ClusterInterface) {
+	for _, l := range m {
+		l.AddCluster(cluster)
+	}
+}
+
+func (m MultiListener) RemoveCluster(cluster mc.ClusterInterface) {
+	for _, l := range m {
+		l.RemoveCluster(cluster)
+	}
+}
+
+func (m MultiListener) WatchCluster(cluster mc.ClusterInterface) {
+	for _, l := range m {
+		l.WatchCluster(cluster)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -49,8 +50,13 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/handler"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/record"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/tracing"
 )
 
+// syncedObjectsPeriod is how often the objects watched in tenant clusters are
+// counted, if tenant metrics are enabled.
+const syncedObjectsPeriod = 60 * time.Second
+
 // Cache is the interface used by Controller to start and wait for caches to sync.
 type Cache interface {
 	Start() error
@@ -107,9 +113,15 @@ type MultiClusterController struct {
 	// objectKind is the kind of target object this controller watched.
 	objectKind string
 
+	// objectListType is the list type of objectType, used to count the watched objects.
+	objectListType client.ObjectList
+
 	// clusters is the internal cluster set this controller watches.
 	clusters map[string]ClusterInterface
 
+	// queue wraps Options.Queue to trace the time requests wait in it.
+	queue *timedQueue
+
 	Options
 }
 
@@ -138,9 +150,10 @@ func NewMCController(objectType client.Object, objectListType client.ObjectList,
 	}
 
 	c := &MultiClusterController{
-		objectType: objectType,
-		objectKind: kinds[0].Kind,
-		clusters:   make(map[string]ClusterInterface),
+		objectType:     objectType,
+		objectKind:     kinds[0].Kind,
+		objectListType: objectListType,
+		clusters:       make(map[string]ClusterInterface),
 		Options: Options{
 			name:                    fmt.Sprintf("%s-mccontroller", strings.ToLower(kinds[0].Kind)),
 			JitterPeriod:            1 * time.Second,
@@ -158,6 +171,9 @@ func NewMCController(objectType client.Object, objectListType client.ObjectList,
 		return nil, fmt.Errorf("mccontroller %q: must specify DW Reconciler", c.objectKind)
 	}
 
+	c.queue = newTimedQueue(c.Queue, c.objectKind)
+	c.Queue = c.queue
+
 	return c, nil
 }
 
@@ -222,10 +238,28 @@ func (c *MultiClusterController) Start(stop <-chan struct{}) error {
 		go wait.Until(c.worker, c.JitterPeriod, stop)
 	}
 
+	if metrics.TenantLabelsEnabled() && c.objectListType != nil {
+		go wait.Until(c.recordSyncedObjects, syncedObjectsPeriod, stop)
+	}
+
 	<-stop
 	return nil
 }
 
+// recordSyncedObjects counts the objects in the informer cache of each tenant cluster.
+func (c *MultiClusterController) recordSyncedObjects() {
+	counts := make(map[string]int)
+	for _, clusterName := range c.GetClusterNames() {
+		list := c.objectListType.DeepCopyObject().(client.ObjectList)
+		if err := c.List(clusterName, list); err != nil {
+			klog.V(4).Infof("failed to count %s of cluster %s: %v", c.objectKind, clusterName, err)
+			continue
+		}
+		counts[clusterName] = meta.LenList(list)
+	}
+	metrics.RecordSyncedObjects(c.objectKind, counts)
+}
+
 // GetControllerName get the mccontroller name, is used to uniquely identify the Controller in tracing, logging and monitoring.
 func (c *MultiClusterController) GetControllerName() string {
 	return c.name
@@ -418,6 +452,7 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 		// Return true, don't take a break
 		return true
 	}
+	addedAt := c.queue.addedAt(req)
 	if c.GetCluster(req.ClusterName) == nil {
 		// The virtual cluster has been removed, do not reconcile for its dws requests.
 		klog.Warningf("The cluster %s has been removed, drop the dws request %v", req.ClusterName, req)
@@ -438,7 +473,7 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 
 	// RunInformersAndControllers the syncHandler, passing it the cluster/namespace/Name
 	// string of the resource to be synced.
-	result, err := c.Reconciler.Reconcile(req)
+	result, err := c.reconcile(req, addedAt)
 	if err == nil {
 		metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeOK)
 		if result.RequeueAfter > 0 {
@@ -477,6 +512,29 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 	return true
 }
 
+// reconcile runs the Reconciler on req within a trace of the downward sync,
+// which starts when req was added to the queue.
+func (c *MultiClusterController) reconcile(req reconciler.Request, addedAt time.Time) (reconciler.Result, error) {
+	tracer := tracing.Tracer()
+	ctx, root := tracer.Start(context.Background(), c.objectKind+" dws",
+		trace.WithTimestamp(addedAt),
+		trace.WithAttributes(tracing.RequestAttributes(c.objectKind, req)...))
+	defer root.End()
+	_, queued := tracer.Start(ctx, "queue wait", trace.WithTimestamp(addedAt))
+	queued.End()
+
+	ctx, span := tracer.Start(ctx, "reconcile")
+	var result reconciler.Result
+	var err error
+	if r, ok := c.Reconciler.(reconciler.ContextDWReconciler); ok {
+		result, err = r.ReconcileContext(ctx, req)
+	} else {
+		result, err = c.Reconciler.Reconcile(req)
+	}
+	tracing.End(span, err)
+	return result, err
+}
+
 func (c *MultiClusterController) FilterObjectFromSchedulingResult(req reconciler.Request) bool {
 	var nsName string
 	if c.objectKind == "Namespace" {
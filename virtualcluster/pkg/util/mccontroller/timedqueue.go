@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// timedQueue remembers when items were first added to the queue, so that
+// the time requests wait in the queue, including backoff, can be traced.
+// The waiting requests are also counted by tenant in the DWSQueueDepth metric.
+type timedQueue struct {
+	workqueue.RateLimitingInterface
+
+	kind string
+
+	mu    sync.Mutex
+	added map[interface{}]queuedItem
+	depth map[string]int
+}
+
+type queuedItem struct {
+	time time.Time
+	// label is the vc_name the item is counted under, if counted is set.
+	label   string
+	counted bool
+}
+
+func newTimedQueue(q workqueue.RateLimitingInterface, kind string) *timedQueue {
+	return &timedQueue{
+		RateLimitingInterface: q,
+		kind:                  kind,
+		added:                 make(map[interface{}]queuedItem),
+		depth:                 make(map[string]int),
+	}
+}
+
+func (q *timedQueue) mark(item interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.added[item]; ok {
+		return
+	}
+	queued := queuedItem{time: time.Now()}
+	if req, ok := item.(reconciler.Request); ok && metrics.TenantLabelsEnabled() {
+		queued.label, queued.counted = metrics.TenantLabel(req.ClusterName), true
+		q.depth[queued.label]++
+		metrics.DWSQueueDepth.WithLabelValues(q.kind, queued.label).Set(float64(q.depth[queued.label]))
+	}
+	q.added[item] = queued
+}
+
+func (q *timedQueue) Add(item interface{}) {
+	q.mark(item)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *timedQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.mark(item)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q *timedQueue) AddRateLimited(item interface{}) {
+	q.mark(item)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+// addedAt returns when item was added to the queue and forgets it, so that
+// adding item again while it is processed starts a new wait.
+func (q *timedQueue) addedAt(item interface{}) time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queued, ok := q.added[item]
+	if !ok {
+		return time.Now()
+	}
+	delete(q.added, item)
+	if queued.counted {
+		if q.depth[queued.label]--; q.depth[queued.label] > 0 {
+			metrics.DWSQueueDepth.WithLabelValues(q.kind, queued.label).Set(float64(q.depth[queued.label]))
+		} else {
+			delete(q.depth, queued.label)
+			metrics.DWSQueueDepth.DeleteLabelValues(q.kind, queued.label)
+		}
+	}
+	return queued.time
+}
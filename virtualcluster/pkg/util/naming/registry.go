@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Entry is the naming of a VirtualCluster recorded in the registry, by cluster key.
+type Entry struct {
+	Spec `json:",inline"`
+	// VirtualCluster is the namespace/name of the VirtualCluster owning the cluster key.
+	VirtualCluster string `json:"virtualCluster"`
+}
+
+// tenantNamespace is the reverse mapping of a super control plane namespace.
+type tenantNamespace struct {
+	clusterKey string
+	namespace  string
+}
+
+type registryEntry struct {
+	Entry
+	strategy Strategy
+}
+
+// Registry maps the namespaces of the VirtualClusters to the super control
+// plane namespaces, using the strategy registered for their cluster key.
+// Cluster keys without an entry use the legacy strategy, so that the tenants
+// created before the registry keep their namespaces.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]registryEntry
+	// namespaces maps the cluster keys to their super control plane
+	// namespaces and the tenant namespace of each. The syncer records them
+	// when it creates the namespaces.
+	namespaces map[string]map[string]string
+	// tenants is the reverse mapping of namespaces.
+	tenants map[string]tenantNamespace
+	// store persists the entries, if the registry is loaded from one.
+	store *Store
+}
+
+// DefaultRegistry is the registry used by conversion and the vn-agent.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		entries:    make(map[string]registryEntry),
+		namespaces: make(map[string]map[string]string),
+		tenants:    make(map[string]tenantNamespace),
+	}
+}
+
+// Load replaces the entries of the registry by data, the content of the
+// registry ConfigMap. Invalid entries are reported and keep their previous
+// value, if any, rather than silently falling back to the legacy strategy.
+func (r *Registry) Load(data map[string]string) error {
+	var errs []error
+	entries := make(map[string]registryEntry, len(data))
+	r.mu.RLock()
+	for clusterKey, value := range data {
+		entry, err := parseEntry(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid naming registry entry %s: %v", clusterKey, err))
+			if old, ok := r.entries[clusterKey]; ok {
+				entries[clusterKey] = old
+			}
+			continue
+		}
+		entries[clusterKey] = entry
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = entries
+	return utilerrors.NewAggregate(errs)
+}
+
+// LoadNamespaces replaces the namespaces of clusterKey by data, the content of
+// its namespaces ConfigMap.
+func (r *Registry) LoadNamespaces(clusterKey string, data map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for superNs := range r.namespaces[clusterKey] {
+		if tenant, ok := r.tenants[superNs]; ok && tenant.clusterKey == clusterKey {
+			delete(r.tenants, superNs)
+		}
+	}
+	if len(data) == 0 {
+		delete(r.namespaces, clusterKey)
+		return
+	}
+	namespaces := make(map[string]string, len(data))
+	for superNs, ns := range data {
+		namespaces[superNs] = ns
+		r.tenants[superNs] = tenantNamespace{clusterKey: clusterKey, namespace: ns}
+	}
+	r.namespaces[clusterKey] = namespaces
+}
+
+// Strategy returns the strategy of clusterKey.
+func (r *Registry) Strategy(clusterKey string) Strategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if entry, ok := r.entries[clusterKey]; ok {
+		return entry.strategy
+	}
+	return Legacy
+}
+
+// VirtualCluster returns the namespace/name of the VirtualCluster registered
+// for clusterKey.
+func (r *Registry) VirtualCluster(clusterKey string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[clusterKey]
+	return entry.VirtualCluster, ok
+}
+
+// SuperNamespace returns the super control plane namespace of the tenant
+// namespace ns of the VirtualCluster of clusterKey.
+func (r *Registry) SuperNamespace(clusterKey, ns string) string {
+	return r.Strategy(clusterKey).SuperNamespace(clusterKey, ns)
+}
+
+// RecordNamespace persists the reverse mapping of the tenant namespace ns of
+// clusterKey in the store of the registry. Cluster keys without an entry are
+// skipped, their super control plane namespaces are mapped back by their
+// annotations.
+func (r *Registry) RecordNamespace(ctx context.Context, clusterKey, ns string) error {
+	r.mu.RLock()
+	entry, ok := r.entries[clusterKey]
+	store := r.store
+	r.mu.RUnlock()
+	if !ok || store == nil {
+		return nil
+	}
+	superNs := entry.strategy.SuperNamespace(clusterKey, ns)
+	r.mu.RLock()
+	recorded, isRecorded := r.namespaces[clusterKey][superNs]
+	r.mu.RUnlock()
+	if isRecorded && recorded == ns {
+		return nil
+	}
+	return store.recordNamespace(ctx, clusterKey, superNs, ns)
+}
+
+// ForgetNamespace removes the reverse mapping of the tenant namespace ns of
+// clusterKey from the store of the registry.
+func (r *Registry) ForgetNamespace(ctx context.Context, clusterKey, ns string) error {
+	r.mu.RLock()
+	entry, ok := r.entries[clusterKey]
+	store := r.store
+	r.mu.RUnlock()
+	if !ok || store == nil {
+		return nil
+	}
+	superNs := entry.strategy.SuperNamespace(clusterKey, ns)
+	r.mu.RLock()
+	_, isRecorded := r.namespaces[clusterKey][superNs]
+	r.mu.RUnlock()
+	if !isRecorded {
+		return nil
+	}
+	return store.forgetNamespace(ctx, clusterKey, superNs)
+}
+
+// TenantNamespace returns the cluster key and the tenant namespace of a super
+// control plane namespace, if it is recorded in the registry.
+func (r *Registry) TenantNamespace(superNs string) (clusterKey, namespace string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenant, ok := r.tenants[superNs]
+	return tenant.clusterKey, tenant.namespace, ok
+}
+
+func parseEntry(value string) (registryEntry, error) {
+	var entry Entry
+	if err := json.Unmarshal([]byte(value), &entry); err != nil {
+		return registryEntry{}, err
+	}
+	strategy, err := New(entry.Spec)
+	if err != nil {
+		return registryEntry{}, err
+	}
+	return registryEntry{Entry: entry, strategy: strategy}, nil
+}
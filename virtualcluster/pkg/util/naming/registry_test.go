@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func registryData(t *testing.T, entries map[string]Entry) map[string]string {
+	data := make(map[string]string)
+	for key, entry := range entries {
+		value, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data[key] = string(value)
+	}
+	return data
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	recordedNs := "vc-0123456789-" + hashOf("kube-system", 10)
+	if err := r.Load(registryData(t, map[string]Entry{
+		"vc-0123456789": {
+			Spec:           Spec{Strategy: StrategyHash},
+			VirtualCluster: "tenant/vc",
+		},
+	})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.LoadNamespaces("vc-0123456789", map[string]string{recordedNs: "kube-system"})
+
+	if ns := r.SuperNamespace("tenant-abcdef-legacy", "default"); ns != "tenant-abcdef-legacy-default" {
+		t.Errorf("expected cluster keys without entry to use the legacy strategy, got %s", ns)
+	}
+	superNs := r.SuperNamespace("vc-0123456789", "default")
+	if superNs != "vc-0123456789-"+hashOf("default", 10) {
+		t.Errorf("unexpected super namespace %s", superNs)
+	}
+	if _, _, ok := r.TenantNamespace(superNs); ok {
+		t.Errorf("expected lookups not to record the reverse mapping of %s", superNs)
+	}
+	if key, ns, ok := r.TenantNamespace(recordedNs); !ok || key != "vc-0123456789" || ns != "kube-system" {
+		t.Errorf("unexpected tenant namespace of %s: %s %s %v", recordedNs, key, ns, ok)
+	}
+	if vc, ok := r.VirtualCluster("vc-0123456789"); !ok || vc != "tenant/vc" {
+		t.Errorf("unexpected VirtualCluster %s %v", vc, ok)
+	}
+
+	// Invalid entries keep their previous value, removed entries are forgotten.
+	if err := r.Load(map[string]string{"vc-0123456789": "{"}); err == nil {
+		t.Errorf("expected invalid entries to be reported")
+	}
+	if s := r.Strategy("vc-0123456789"); s.Name() != StrategyHash {
+		t.Errorf("expected the invalid entry to keep the hash strategy, got %s", s.Name())
+	}
+	if err := r.Load(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := r.VirtualCluster("vc-0123456789"); ok {
+		t.Errorf("expected the removed entry to be forgotten")
+	}
+
+	// The namespaces of a cluster key are replaced as a whole.
+	r.LoadNamespaces("vc-0123456789", map[string]string{"vc-0123456789-other": "other"})
+	if _, _, ok := r.TenantNamespace(recordedNs); ok {
+		t.Errorf("expected the reverse mapping of %s to be forgotten", recordedNs)
+	}
+	if key, ns, ok := r.TenantNamespace("vc-0123456789-other"); !ok || key != "vc-0123456789" || ns != "other" {
+		t.Errorf("unexpected tenant namespace of vc-0123456789-other: %s %s %v", key, ns, ok)
+	}
+	r.LoadNamespaces("vc-0123456789", nil)
+	if _, _, ok := r.TenantNamespace("vc-0123456789-other"); ok {
+		t.Errorf("expected the reverse mappings of removed namespaces to be forgotten")
+	}
+}
+
+func TestStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	if _, err := NewStore(client, "vc-naming-registry"); err == nil {
+		t.Errorf("expected names without namespace to be rejected")
+	}
+	store, err := NewStore(client, DefaultRegistryName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.TODO()
+
+	entry := Entry{Spec: Spec{Strategy: StrategyShortID}, VirtualCluster: "tenant/vc"}
+	if err := store.Register(ctx, "vc-abcde", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Register(ctx, "vc-abcde", entry); err != nil {
+		t.Errorf("expected registering the same VirtualCluster again to succeed: %v", err)
+	}
+	if err := store.Register(ctx, "vc-abcde", Entry{Spec: Spec{Strategy: StrategyShortID}, VirtualCluster: "other/vc"}); err == nil {
+		t.Errorf("expected registering another VirtualCluster for the same cluster key to fail")
+	}
+	if err := store.Register(ctx, "vc-fghij", Entry{Spec: Spec{Strategy: "unknown"}, VirtualCluster: "tenant/other"}); err == nil {
+		t.Errorf("expected invalid specs to be rejected")
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	r := NewRegistry()
+	if err := store.Start(r, stopCh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.WatchNamespaces(r, stopCh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := r.Strategy("vc-abcde"); s.Name() != StrategyShortID {
+		t.Errorf("expected the registry to be loaded on start, got strategy %s", s.Name())
+	}
+
+	// The reverse mappings are persisted in a ConfigMap per cluster key.
+	if err := r.RecordNamespace(ctx, "vc-abcde", "default"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.RecordNamespace(ctx, "vc-abcde", "kube-system"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.RecordNamespace(ctx, "tenant-abcdef-legacy", "default"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		key, ns, ok := r.TenantNamespace("vc-abcde-default")
+		return ok && key == "vc-abcde" && ns == "default", nil
+	}); err != nil {
+		t.Errorf("expected the reverse mapping to be recorded: %v", err)
+	}
+	if _, _, ok := r.TenantNamespace("tenant-abcdef-legacy-default"); ok {
+		t.Errorf("expected cluster keys without entry not to be recorded")
+	}
+	namespaces, err := client.CoreV1().ConfigMaps("vc-manager").Get(ctx, "vc-naming-registry-vc-abcde", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the namespaces ConfigMap of vc-abcde: %v", err)
+	}
+	if len(namespaces.Data) != 2 || namespaces.Labels[LabelClusterKey] != "vc-abcde" || namespaces.Labels[LabelNamingRegistry] != "vc-naming-registry" {
+		t.Errorf("unexpected namespaces ConfigMap %v", namespaces)
+	}
+	if err := r.ForgetNamespace(ctx, "vc-abcde", "default"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, _, ok := r.TenantNamespace("vc-abcde-default")
+		return !ok, nil
+	}); err != nil {
+		t.Errorf("expected the reverse mapping to be forgotten: %v", err)
+	}
+	if _, _, ok := r.TenantNamespace("vc-abcde-kube-system"); !ok {
+		t.Errorf("expected the other reverse mappings to be kept")
+	}
+
+	if err := store.Register(ctx, "vc-fghij", Entry{Spec: Spec{Strategy: StrategyHash}, VirtualCluster: "tenant/other"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Unregister(ctx, "vc-fghij", "tenant/vc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Unregister(ctx, "vc-abcde", "tenant/vc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, removed := r.VirtualCluster("vc-abcde")
+		_, _, recorded := r.TenantNamespace("vc-abcde-kube-system")
+		return !removed && !recorded && r.Strategy("vc-fghij").Name() == StrategyHash, nil
+	}); err != nil {
+		t.Errorf("expected the registry to follow the ConfigMaps: %v", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("vc-manager").Get(ctx, "vc-naming-registry-vc-abcde", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the namespaces ConfigMap of vc-abcde to be deleted, got %v", err)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps("vc-manager").Get(ctx, "vc-naming-registry", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cm.Data) != 1 {
+		t.Errorf("unexpected registry content %v", cm.Data)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// DefaultRegistryName is the namespace/name of the registry ConfigMap in the
+// super control plane.
+const DefaultRegistryName = "vc-manager/vc-naming-registry"
+
+// Labels of the ConfigMaps holding the namespaces of a cluster key.
+const (
+	LabelNamingRegistry = "tenancy.x-k8s.io/naming-registry"
+	LabelClusterKey     = "tenancy.x-k8s.io/cluster-key"
+)
+
+// Store persists the registry in a ConfigMap of the super control plane,
+// holding the JSON Entry of each cluster key. The vc-manager registers the
+// entries, the syncer and the vn-agent watch them. The syncer records the
+// namespaces of each cluster key in a ConfigMap of its own, named after the
+// registry and the cluster key, so that the registry stays small and the
+// tenants do not contend for it.
+type Store struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewStore returns the store of the ConfigMap namespacedName.
+func NewStore(client kubernetes.Interface, namespacedName string) (*Store, error) {
+	parts := strings.Split(namespacedName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("naming registry %q must be in the form namespace/name", namespacedName)
+	}
+	return &Store{client: client, namespace: parts[0], name: parts[1]}, nil
+}
+
+// Register records entry for clusterKey. Registering the same VirtualCluster
+// again is a no-op, so that its naming never changes once registered.
+func (s *Store) Register(ctx context.Context, clusterKey string, entry Entry) error {
+	if _, err := New(entry.Spec); err != nil {
+		return err
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name},
+				Data:       map[string]string{clusterKey: string(value)},
+			}
+			_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if old, ok := cm.Data[clusterKey]; ok {
+			var registered Entry
+			if err := json.Unmarshal([]byte(old), &registered); err == nil && registered.VirtualCluster == entry.VirtualCluster {
+				return nil
+			}
+			return fmt.Errorf("cluster key %s is already registered: %s", clusterKey, old)
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[clusterKey] = string(value)
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Unregister removes the entry of clusterKey and its namespaces, if it is
+// registered for virtualCluster.
+func (s *Store) Unregister(ctx context.Context, clusterKey, virtualCluster string) error {
+	owned := true
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value, ok := cm.Data[clusterKey]
+		if !ok {
+			return nil
+		}
+		var registered Entry
+		if err := json.Unmarshal([]byte(value), &registered); err == nil && registered.VirtualCluster != virtualCluster {
+			owned = false
+			return nil
+		}
+		delete(cm.Data, clusterKey)
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil || !owned {
+		return err
+	}
+	err = s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, s.namespacesName(clusterKey), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// namespacesName returns the name of the ConfigMap holding the namespaces of clusterKey.
+func (s *Store) namespacesName(clusterKey string) string {
+	return s.name + "-" + clusterKey
+}
+
+// recordNamespace records the tenant namespace ns of superNs in the namespaces
+// ConfigMap of clusterKey, creating it if needed. The ConfigMap is patched, so
+// that the namespaces of a tenant are recorded without conflicts.
+func (s *Store) recordNamespace(ctx context.Context, clusterKey, superNs, ns string) error {
+	patch, err := json.Marshal(map[string]interface{}{"data": map[string]string{superNs: ns}})
+	if err != nil {
+		return err
+	}
+	name := s.namespacesName(clusterKey)
+	_, err = s.client.CoreV1().ConfigMaps(s.namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      name,
+			Labels: map[string]string{
+				LabelNamingRegistry: s.name,
+				LabelClusterKey:     clusterKey,
+			},
+		},
+		Data: map[string]string{superNs: ns},
+	}
+	_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return err
+}
+
+// forgetNamespace removes superNs from the namespaces ConfigMap of clusterKey.
+func (s *Store) forgetNamespace(ctx context.Context, clusterKey, superNs string) error {
+	patch, err := json.Marshal(map[string]interface{}{"data": map[string]interface{}{superNs: nil}})
+	if err != nil {
+		return err
+	}
+	_, err = s.client.CoreV1().ConfigMaps(s.namespace).Patch(ctx, s.namespacesName(clusterKey), types.MergePatchType, patch, metav1.PatchOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Start loads the registry ConfigMap into registry and keeps it up to date
+// until stopCh is closed. It returns once the registry is loaded, so that no
+// namespace is named before the entries are known. A missing ConfigMap is an
+// empty registry.
+func (s *Store) Start(registry *Registry, stopCh <-chan struct{}) error {
+	// Fail fast on errors such as a missing permission, which the informer
+	// would retry forever.
+	if _, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.client, 0,
+		informers.WithNamespace(s.namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", s.name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps()
+	informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		// Only the registry is watched, filter out the other ConfigMaps of the
+		// namespace in case the field selector is not enforced.
+		FilterFunc: func(obj interface{}) bool {
+			cm, ok := obj.(*corev1.ConfigMap)
+			return !ok || cm.Name == s.name
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				load(registry, obj.(*corev1.ConfigMap).Data)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				load(registry, newObj.(*corev1.ConfigMap).Data)
+			},
+			DeleteFunc: func(obj interface{}) {
+				// Keep the last known entries, falling back to the legacy
+				// strategy would move the tenants to other namespaces.
+				klog.Warningf("naming registry %s/%s is deleted, keeping the last known entries", s.namespace, s.name)
+			},
+		},
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.Informer().HasSynced) {
+		return fmt.Errorf("failed to sync naming registry %s/%s", s.namespace, s.name)
+	}
+
+	registry.mu.Lock()
+	registry.store = s
+	registry.mu.Unlock()
+
+	cm, err := informer.Lister().ConfigMaps(s.namespace).Get(s.name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	load(registry, cm.Data)
+	return nil
+}
+
+// WatchNamespaces loads the namespaces ConfigMaps of the cluster keys into
+// registry and keeps them up to date until stopCh is closed. It returns once
+// they are loaded. Only the syncer maps the super control plane namespaces
+// back, so the vn-agent does not need to read them.
+func (s *Store) WatchNamespaces(registry *Registry, stopCh <-chan struct{}) error {
+	labelSelector := labels.SelectorFromSet(labels.Set{LabelNamingRegistry: s.name}).String()
+	// Fail fast on errors such as a missing permission, which the informer
+	// would retry forever.
+	if _, err := s.client.CoreV1().ConfigMaps(s.namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector, Limit: 1}); err != nil {
+		return err
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.client, 0,
+		informers.WithNamespace(s.namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cm := obj.(*corev1.ConfigMap)
+			registry.LoadNamespaces(cm.Labels[LabelClusterKey], cm.Data)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			cm := newObj.(*corev1.ConfigMap)
+			registry.LoadNamespaces(cm.Labels[LabelClusterKey], cm.Data)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cm, ok := obj.(*corev1.ConfigMap); ok {
+				registry.LoadNamespaces(cm.Labels[LabelClusterKey], nil)
+			}
+		},
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return fmt.Errorf("failed to sync the namespaces of naming registry %s/%s", s.namespace, s.name)
+	}
+	return nil
+}
+
+// load loads data into registry and reports the invalid entries.
+func load(registry *Registry, data map[string]string) {
+	if err := registry.Load(data); err != nil {
+		klog.Errorf("failed to load the naming registry: %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package naming maps VirtualClusters and their namespaces to the namespaces
+// of the super control plane.
+package naming
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+)
+
+const (
+	// StrategyLegacy names the root namespace <namespace>-<hash>-<name> and the
+	// tenant namespaces <cluster key>-<namespace>. Tenants without a registry
+	// entry use it.
+	StrategyLegacy = "legacy"
+	// StrategyHash names the root namespace vc-<hash of uid> and the tenant
+	// namespaces <cluster key>-<hash of namespace>.
+	StrategyHash = "hash"
+	// StrategyShortID names the root namespace <name>-<short id> and the
+	// tenant namespaces <cluster key>-<namespace>.
+	StrategyShortID = "shortid"
+	// StrategyTemplate names the root namespace and the tenant namespaces
+	// with the Go templates of the Spec.
+	StrategyTemplate = "template"
+)
+
+const (
+	// DefaultClusterKeyTemplate is the cluster key template of the template
+	// strategy if none is given.
+	DefaultClusterKeyTemplate = "{{.Name}}-{{.ShortID}}"
+	// DefaultNamespaceTemplate is the namespace template of the template
+	// strategy if none is given.
+	DefaultNamespaceTemplate = "{{.ClusterKey}}-{{.Namespace}}"
+
+	// shortIDLength is the number of hex digits of the uid hash in short ids.
+	shortIDLength = 5
+	// hashLength is the number of hex digits of the hashes of the hash strategy.
+	hashLength = 10
+	// maxShortIDNameLength keeps the cluster keys of the shortid strategy short
+	// enough to leave room for the tenant namespaces.
+	maxShortIDNameLength = 20
+)
+
+// Spec selects the strategy naming the super control plane namespaces of a
+// VirtualCluster.
+type Spec struct {
+	// Strategy is one of legacy, hash, shortid or template.
+	Strategy string `json:"strategy"`
+	// ClusterKeyTemplate is the template of the root namespace, given the
+	// Name, Namespace, UID and ShortID of the VirtualCluster. Only used by
+	// the template strategy.
+	ClusterKeyTemplate string `json:"clusterKeyTemplate,omitempty"`
+	// NamespaceTemplate is the template of the tenant namespaces, given the
+	// ClusterKey, the Namespace and its Hash. Only used by the template strategy.
+	NamespaceTemplate string `json:"namespaceTemplate,omitempty"`
+}
+
+// Strategy names the namespaces of a VirtualCluster in the super control plane.
+// Both names must be stable, as they are computed again on each sync.
+type Strategy interface {
+	// Name returns the name of the strategy.
+	Name() string
+	// ClusterKey returns the root namespace of the VirtualCluster.
+	ClusterKey(vc *v1alpha1.VirtualCluster) string
+	// SuperNamespace returns the super control plane namespace of the tenant
+	// namespace ns of the VirtualCluster of clusterKey.
+	SuperNamespace(clusterKey, ns string) string
+}
+
+// New returns the strategy of spec.
+func New(spec Spec) (Strategy, error) {
+	switch spec.Strategy {
+	case "", StrategyLegacy:
+		return legacyStrategy{}, nil
+	case StrategyHash:
+		return hashStrategy{}, nil
+	case StrategyShortID:
+		return shortIDStrategy{}, nil
+	case StrategyTemplate:
+		return newTemplateStrategy(spec)
+	default:
+		return nil, fmt.Errorf("unknown naming strategy %q", spec.Strategy)
+	}
+}
+
+// Legacy is the strategy of the tenants created before naming strategies.
+var Legacy Strategy = legacyStrategy{}
+
+type legacyStrategy struct{}
+
+func (legacyStrategy) Name() string {
+	return StrategyLegacy
+}
+
+func (legacyStrategy) ClusterKey(vc *v1alpha1.VirtualCluster) string {
+	return vc.GetNamespace() + "-" + hashOf(string(vc.GetUID()), 6) + "-" + vc.GetName()
+}
+
+func (legacyStrategy) SuperNamespace(clusterKey, ns string) string {
+	return truncate(clusterKey + "-" + ns)
+}
+
+type hashStrategy struct{}
+
+func (hashStrategy) Name() string {
+	return StrategyHash
+}
+
+func (hashStrategy) ClusterKey(vc *v1alpha1.VirtualCluster) string {
+	return "vc-" + hashOf(string(vc.GetUID()), hashLength)
+}
+
+func (hashStrategy) SuperNamespace(clusterKey, ns string) string {
+	return truncate(clusterKey + "-" + hashOf(ns, hashLength))
+}
+
+type shortIDStrategy struct{}
+
+func (shortIDStrategy) Name() string {
+	return StrategyShortID
+}
+
+func (shortIDStrategy) ClusterKey(vc *v1alpha1.VirtualCluster) string {
+	name := vc.GetName()
+	if len(name) > maxShortIDNameLength {
+		name = strings.TrimRight(name[:maxShortIDNameLength], "-.")
+	}
+	return sanitize(name) + "-" + ShortID(vc)
+}
+
+func (shortIDStrategy) SuperNamespace(clusterKey, ns string) string {
+	return truncate(clusterKey + "-" + ns)
+}
+
+// clusterKeyData is given to the cluster key template.
+type clusterKeyData struct {
+	Name      string
+	Namespace string
+	UID       string
+	ShortID   string
+}
+
+// namespaceData is given to the namespace template.
+type namespaceData struct {
+	ClusterKey string
+	Namespace  string
+	Hash       string
+}
+
+type templateStrategy struct {
+	clusterKey *template.Template
+	namespace  *template.Template
+}
+
+func newTemplateStrategy(spec Spec) (Strategy, error) {
+	clusterKeyTemplate, namespaceTemplate := spec.ClusterKeyTemplate, spec.NamespaceTemplate
+	if clusterKeyTemplate == "" {
+		clusterKeyTemplate = DefaultClusterKeyTemplate
+	}
+	if namespaceTemplate == "" {
+		namespaceTemplate = DefaultNamespaceTemplate
+	}
+	clusterKey, err := template.New("clusterKey").Option("missingkey=error").Parse(clusterKeyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster key template: %v", err)
+	}
+	namespace, err := template.New("namespace").Option("missingkey=error").Parse(namespaceTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace template: %v", err)
+	}
+	s := &templateStrategy{clusterKey: clusterKey, namespace: namespace}
+
+	// The templates only see fixed fields, so that executing them on samples
+	// catches every runtime error. Namespaces of different tenants or of
+	// different tenant namespaces must not collide.
+	if _, err := s.execute(s.clusterKey, clusterKeyData{Name: "sample", Namespace: "default", UID: "uid", ShortID: "abcde"}); err != nil {
+		return nil, fmt.Errorf("invalid cluster key template: %v", err)
+	}
+	samples := sets.NewString()
+	for _, key := range []string{"key-a", "key-b"} {
+		for _, ns := range []string{"ns-a", "ns-b"} {
+			name, err := s.execute(s.namespace, namespaceData{ClusterKey: key, Namespace: ns, Hash: hashOf(ns, hashLength)})
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace template: %v", err)
+			}
+			if samples.Has(name) {
+				return nil, fmt.Errorf("invalid namespace template: it must depend on both the cluster key and the namespace")
+			}
+			samples.Insert(name)
+		}
+	}
+	return s, nil
+}
+
+func (s *templateStrategy) Name() string {
+	return StrategyTemplate
+}
+
+func (s *templateStrategy) ClusterKey(vc *v1alpha1.VirtualCluster) string {
+	name, err := s.execute(s.clusterKey, clusterKeyData{
+		Name:      vc.GetName(),
+		Namespace: vc.GetNamespace(),
+		UID:       string(vc.GetUID()),
+		ShortID:   ShortID(vc),
+	})
+	if err != nil {
+		// Unreachable as the template is checked in New.
+		return Legacy.ClusterKey(vc)
+	}
+	return name
+}
+
+func (s *templateStrategy) SuperNamespace(clusterKey, ns string) string {
+	name, err := s.execute(s.namespace, namespaceData{ClusterKey: clusterKey, Namespace: ns, Hash: hashOf(ns, hashLength)})
+	if err != nil {
+		// Unreachable as the template is checked in New.
+		return Legacy.SuperNamespace(clusterKey, ns)
+	}
+	return name
+}
+
+func (s *templateStrategy) execute(t *template.Template, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	name := sanitize(buf.String())
+	if name == "" {
+		return "", fmt.Errorf("template %s renders an empty name", t.Name())
+	}
+	return truncate(name), nil
+}
+
+// ShortID returns the stable short id of a VirtualCluster, derived from its uid.
+func ShortID(vc *v1alpha1.VirtualCluster) string {
+	return hashOf(string(vc.GetUID()), shortIDLength)
+}
+
+// hashOf returns the first n hex digits of the sha256 of s.
+func hashOf(s string, n int) string {
+	digest := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(digest[0:])[0:n]
+}
+
+// truncate shortens names longer than a DNS label, keeping them unique with
+// a hash of the full name.
+func truncate(name string) string {
+	if len(name) > validation.DNS1123LabelMaxLength {
+		return name[0:57] + "-" + hashOf(name, 5)
+	}
+	return name
+}
+
+// sanitize lowercases name and replaces the characters not allowed in a DNS
+// label by dashes.
+func sanitize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
+	return strings.Trim(name, "-")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+)
+
+func newVirtualCluster(namespace, name, uid string) *v1alpha1.VirtualCluster {
+	return &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(uid)},
+	}
+}
+
+func TestLegacyStrategy(t *testing.T) {
+	vc := newVirtualCluster("tenant", "vc", "0a8f6a5c-1c53-4a24-9b1d-6a8a3ef1a001")
+	key := Legacy.ClusterKey(vc)
+	if key != "tenant-"+hashOf(string(vc.UID), 6)+"-vc" {
+		t.Errorf("unexpected cluster key %s", key)
+	}
+	if ns := Legacy.SuperNamespace(key, "default"); ns != key+"-default" {
+		t.Errorf("unexpected super namespace %s", ns)
+	}
+	long := Legacy.SuperNamespace(key, strings.Repeat("n", 60))
+	if len(long) != validation.DNS1123LabelMaxLength {
+		t.Errorf("expected super namespace %s to be truncated", long)
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	vc := newVirtualCluster("tenant", "a-rather-long-virtualcluster-name", "0a8f6a5c-1c53-4a24-9b1d-6a8a3ef1a001")
+	other := newVirtualCluster("tenant", "a-rather-long-virtualcluster-name", "7d6a1f83-4c5e-4c25-8d5f-2b0b8e5a0b02")
+
+	for _, tc := range []struct {
+		spec       Spec
+		clusterKey string
+		namespace  string
+	}{
+		{
+			spec:       Spec{Strategy: StrategyHash},
+			clusterKey: "vc-" + hashOf(string(vc.UID), 10),
+			namespace:  "vc-" + hashOf(string(vc.UID), 10) + "-" + hashOf("default", 10),
+		},
+		{
+			spec:       Spec{Strategy: StrategyShortID},
+			clusterKey: "a-rather-long-virtua-" + ShortID(vc),
+			namespace:  "a-rather-long-virtua-" + ShortID(vc) + "-default",
+		},
+		{
+			spec:       Spec{Strategy: StrategyTemplate},
+			clusterKey: "a-rather-long-virtualcluster-name-" + ShortID(vc),
+			namespace:  "a-rather-long-virtualcluster-name-" + ShortID(vc) + "-default",
+		},
+		{
+			spec: Spec{
+				Strategy:           StrategyTemplate,
+				ClusterKeyTemplate: "{{.Namespace}}.{{.ShortID}}",
+				NamespaceTemplate:  "{{.Namespace}}--{{.ClusterKey}}",
+			},
+			clusterKey: "tenant-" + ShortID(vc),
+			namespace:  "default--tenant-" + ShortID(vc),
+		},
+	} {
+		t.Run(tc.spec.Strategy, func(t *testing.T) {
+			s, err := New(tc.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.Name() != tc.spec.Strategy {
+				t.Errorf("unexpected strategy %s", s.Name())
+			}
+			key := s.ClusterKey(vc)
+			if key != tc.clusterKey {
+				t.Errorf("expected cluster key %s, got %s", tc.clusterKey, key)
+			}
+			if otherKey := s.ClusterKey(other); otherKey == key {
+				t.Errorf("expected VirtualClusters with different uids to get different cluster keys, got %s", key)
+			}
+			if ns := s.SuperNamespace(key, "default"); ns != tc.namespace {
+				t.Errorf("expected super namespace %s, got %s", tc.namespace, ns)
+			}
+			long := s.SuperNamespace(key, strings.Repeat("n", 63))
+			if errs := validation.IsDNS1123Label(long); len(errs) != 0 {
+				t.Errorf("expected super namespace %s to be a DNS label: %v", long, errs)
+			}
+		})
+	}
+}
+
+func TestInvalidSpecs(t *testing.T) {
+	for _, spec := range []Spec{
+		{Strategy: "unknown"},
+		{Strategy: StrategyTemplate, ClusterKeyTemplate: "{{.Name"},
+		{Strategy: StrategyTemplate, ClusterKeyTemplate: "{{.Cluster}}"},
+		{Strategy: StrategyTemplate, ClusterKeyTemplate: "---"},
+		{Strategy: StrategyTemplate, NamespaceTemplate: "{{.Namespace}}"},
+		{Strategy: StrategyTemplate, NamespaceTemplate: "{{.ClusterKey}}"},
+	} {
+		if _, err := New(spec); err == nil {
+			t.Errorf("expected spec %+v to be invalid", spec)
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package reconciler
 
 import (
+	"context"
+
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -55,6 +57,13 @@ type DWReconciler interface {
 	Reconcile(Request) (Result, error)
 }
 
+// ContextDWReconciler is implemented by DWReconcilers that take the context of
+// the request, which carries the trace of the downward sync.
+type ContextDWReconciler interface {
+	DWReconciler
+	ReconcileContext(context.Context, Request) (Result, error)
+}
+
 // UWReconciler is the interface used by a Controller to do upward reconcile (super->tenant).
 type UWReconciler interface {
 	BackPopulate(string) error
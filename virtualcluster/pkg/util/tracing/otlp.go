@@ -0,0 +1,265 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// otlpExporter sends spans to an OTLP/HTTP collector using the JSON encoding
+// of OTLP. The upstream OTLP exporters are not used as they need a grpc
+// release which the etcd client required by k8s.io/apiserver does not build with.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+var _ sdktrace.SpanExporter = &otlpExporter{}
+
+func newOTLPExporter(endpoint string, insecure bool) *otlpExporter {
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+	return &otlpExporter{
+		url:    fmt.Sprintf("%s://%s/v1/traces", scheme, endpoint),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// otlpLink carries the span context a span is linked to, such as the downward
+// sync span an upward sync span continues from.
+type otlpLink struct {
+	TraceID    string         `json:"traceId"`
+	SpanID     string         `json:"spanId"`
+	TraceState string         `json:"traceState,omitempty"`
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// OTLP status codes, which differ from the otel codes.
+const (
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+)
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(toOTLP(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to export %d spans to %s: %s: %s", len(spans), e.url, resp.Status, msg)
+	}
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// toOTLP groups spans by resource and instrumentation library.
+func toOTLP(spans []sdktrace.ReadOnlySpan) *otlpRequest {
+	type scopeKey struct {
+		resource attribute.Distinct
+		name     string
+		version  string
+	}
+	request := &otlpRequest{}
+	resourceIndex := make(map[attribute.Distinct]int)
+	scopeIndex := make(map[scopeKey]int)
+	for _, span := range spans {
+		res := span.Resource()
+		var resKey attribute.Distinct
+		if res != nil {
+			resKey = res.Equivalent()
+		}
+		ri, ok := resourceIndex[resKey]
+		if !ok {
+			ri = len(request.ResourceSpans)
+			resourceIndex[resKey] = ri
+			rs := otlpResourceSpans{}
+			if res != nil {
+				rs.Resource.Attributes = toOTLPAttributes(res.Attributes())
+			}
+			request.ResourceSpans = append(request.ResourceSpans, rs)
+		}
+		library := span.InstrumentationLibrary()
+		key := scopeKey{resource: resKey, name: library.Name, version: library.Version}
+		si, ok := scopeIndex[key]
+		if !ok {
+			si = len(request.ResourceSpans[ri].ScopeSpans)
+			scopeIndex[key] = si
+			request.ResourceSpans[ri].ScopeSpans = append(request.ResourceSpans[ri].ScopeSpans, otlpScopeSpans{
+				Scope: otlpScope{Name: library.Name, Version: library.Version},
+			})
+		}
+		scope := &request.ResourceSpans[ri].ScopeSpans[si]
+		scope.Spans = append(scope.Spans, toOTLPSpan(span))
+	}
+	return request
+}
+
+func toOTLPSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	s := otlpSpan{
+		TraceID:           span.SpanContext().TraceID().String(),
+		SpanID:            span.SpanContext().SpanID().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: unixNano(span.StartTime()),
+		EndTimeUnixNano:   unixNano(span.EndTime()),
+		Attributes:        toOTLPAttributes(span.Attributes()),
+	}
+	if span.Parent().HasSpanID() {
+		s.ParentSpanID = span.Parent().SpanID().String()
+	}
+	for _, event := range span.Events() {
+		s.Events = append(s.Events, otlpEvent{
+			TimeUnixNano: unixNano(event.Time),
+			Name:         event.Name,
+			Attributes:   toOTLPAttributes(event.Attributes),
+		})
+	}
+	for _, link := range span.Links() {
+		s.Links = append(s.Links, otlpLink{
+			TraceID:    link.SpanContext.TraceID().String(),
+			SpanID:     link.SpanContext.SpanID().String(),
+			TraceState: link.SpanContext.TraceState().String(),
+			Attributes: toOTLPAttributes(link.Attributes),
+		})
+	}
+	switch span.Status().Code {
+	case codes.Ok:
+		s.Status.Code = otlpStatusOk
+	case codes.Error:
+		s.Status.Code = otlpStatusError
+		s.Status.Message = span.Status().Description
+	}
+	return s
+}
+
+func toOTLPAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	var kvs []otlpKeyValue
+	for _, attr := range attrs {
+		kv := otlpKeyValue{Key: string(attr.Key)}
+		switch attr.Value.Type() {
+		case attribute.BOOL:
+			v := attr.Value.AsBool()
+			kv.Value.BoolValue = &v
+		case attribute.INT64:
+			v := strconv.FormatInt(attr.Value.AsInt64(), 10)
+			kv.Value.IntValue = &v
+		case attribute.FLOAT64:
+			v := attr.Value.AsFloat64()
+			kv.Value.DoubleValue = &v
+		default:
+			v := attr.Value.Emit()
+			kv.Value.StringValue = &v
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing wires OpenTelemetry into the syncer. Until Setup is called
+// with an endpoint, spans are recorded by the no-op tracer provider of otel.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+const instrumentationName = "sigs.k8s.io/cluster-api-provider-nested/virtualcluster"
+
+// Attribute keys identifying the tenant object a span works on.
+const (
+	ClusterKey   = attribute.Key("virtualcluster.cluster")
+	KindKey      = attribute.Key("virtualcluster.kind")
+	NamespaceKey = attribute.Key("virtualcluster.namespace")
+	NameKey      = attribute.Key("virtualcluster.name")
+)
+
+// traceParentHeader is the only field of the W3C trace context propagated
+// between objects.
+const traceParentHeader = "traceparent"
+
+var propagator = propagation.TraceContext{}
+
+// Options configures the exporter of the traces.
+type Options struct {
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Endpoint is the host:port of the OTLP/HTTP collector, which is sent
+	// JSON encoded spans. Tracing is disabled if it is empty.
+	Endpoint string
+	// Insecure disables TLS towards the collector.
+	Insecure bool
+	// SamplingRatio is the fraction of traces sampled, between 0 and 1.
+	SamplingRatio float64
+}
+
+// Setup installs the global tracer provider exporting spans to the OTLP
+// collector in opts. The returned function flushes and stops the exporter.
+func Setup(opts Options) func(context.Context) error {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(newOTLPExporter(opts.Endpoint, opts.Insecure)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SamplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(opts.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown
+}
+
+// Tracer returns the tracer of the syncer.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// RequestAttributes returns the attributes identifying the tenant object of req.
+func RequestAttributes(kind string, req reconciler.Request) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		ClusterKey.String(req.ClusterName),
+		KindKey.String(kind),
+		NamespaceKey.String(req.Namespace),
+		NameKey.String(req.Name),
+	}
+}
+
+// End records err on span, if any, and ends span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// annotationCarrier carries the W3C traceparent in an annotation of an object.
+type annotationCarrier struct {
+	key         string
+	annotations map[string]string
+}
+
+var _ propagation.TextMapCarrier = annotationCarrier{}
+
+func (c annotationCarrier) Get(key string) string {
+	if key != traceParentHeader {
+		return ""
+	}
+	return c.annotations[c.key]
+}
+
+func (c annotationCarrier) Set(key, value string) {
+	if key == traceParentHeader {
+		c.annotations[c.key] = value
+	}
+}
+
+func (c annotationCarrier) Keys() []string {
+	return []string{traceParentHeader}
+}
+
+// Inject stores the span context of ctx in annotations under key, so that
+// the trace can be continued from the annotated object. Nothing is stored if
+// ctx is not sampled.
+func Inject(ctx context.Context, key string, annotations map[string]string) {
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		return
+	}
+	propagator.Inject(ctx, annotationCarrier{key: key, annotations: annotations})
+}
+
+// LinkFrom returns the option linking a new span to the span context stored in
+// annotations under key by Inject, if any. Unlike Extract, the new span starts
+// its own trace, which suits the work done long after the annotated object was
+// created.
+func LinkFrom(key string, annotations map[string]string) trace.SpanStartOption {
+	sc := trace.SpanContextFromContext(Extract(context.Background(), key, annotations))
+	if !sc.IsValid() {
+		return trace.WithLinks()
+	}
+	return trace.WithLinks(trace.Link{SpanContext: sc})
+}
+
+// Extract returns ctx carrying the span context stored in annotations under
+// key by Inject.
+func Extract(ctx context.Context, key string, annotations map[string]string) context.Context {
+	if _, ok := annotations[key]; !ok {
+		return ctx
+	}
+	return propagator.Extract(ctx, annotationCarrier{key: key, annotations: annotations})
+}
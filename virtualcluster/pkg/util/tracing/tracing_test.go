@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+const testAnnotation = "tenancy.x-k8s.io/trace-context"
+
+func TestInjectExtract(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "dws")
+	defer span.End()
+
+	annotations := map[string]string{}
+	Inject(ctx, testAnnotation, annotations)
+	if !strings.Contains(annotations[testAnnotation], span.SpanContext().TraceID().String()) {
+		t.Fatalf("expected trace context to be injected, got %v", annotations)
+	}
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), testAnnotation, annotations))
+	if extracted.TraceID() != span.SpanContext().TraceID() || extracted.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected span context %v to be extracted, got %v", span.SpanContext(), extracted)
+	}
+	if !extracted.IsRemote() {
+		t.Errorf("expected extracted span context to be remote")
+	}
+}
+
+func TestInjectNotSampled(t *testing.T) {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "dws")
+	defer span.End()
+
+	annotations := map[string]string{}
+	Inject(ctx, testAnnotation, annotations)
+	if len(annotations) != 0 {
+		t.Errorf("expected no trace context for unsampled span, got %v", annotations)
+	}
+	if ctx := Extract(context.Background(), testAnnotation, annotations); trace.SpanContextFromContext(ctx).IsValid() {
+		t.Errorf("expected no span context to be extracted")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "Pod dws",
+		trace.WithAttributes(RequestAttributes("Pod", reconciler.Request{ClusterName: "tenant"})...))
+	_, child := provider.Tracer("test").Start(ctx, "create super pod")
+	End(child, errors.New("forbidden"))
+	parent.End()
+
+	exporter := newOTLPExporter(strings.TrimPrefix(server.URL, "http://"), true)
+	if err := exporter.ExportSpans(context.Background(), recorder.Ended()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, _ := json.Marshal(received)
+	for _, expected := range []string{
+		`"name":"create super pod"`,
+		`"parentSpanId":"` + parent.SpanContext().SpanID().String() + `"`,
+		`"traceId":"` + parent.SpanContext().TraceID().String() + `"`,
+		`"key":"virtualcluster.cluster","value":{"stringValue":"tenant"}`,
+		`"status":{"code":2,"message":"forbidden"}`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected %s in exported spans %s", expected, body)
+		}
+	}
+}
+
+func TestOTLPExporterLinks(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, dws := provider.Tracer("test").Start(context.Background(), "Pod dws")
+	dws.End()
+	annotations := map[string]string{}
+	Inject(ctx, testAnnotation, annotations)
+	_, uws := provider.Tracer("test").Start(context.Background(), "Pod uws", LinkFrom(testAnnotation, annotations))
+	uws.End()
+
+	exporter := newOTLPExporter(strings.TrimPrefix(server.URL, "http://"), true)
+	if err := exporter.ExportSpans(context.Background(), recorder.Ended()[1:]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, _ := json.Marshal(received)
+	expected := `"links":[{"spanId":"` + dws.SpanContext().SpanID().String() + `","traceId":"` + dws.SpanContext().TraceID().String() + `"}]`
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("expected %s in exported spans %s", expected, body)
+	}
+}
+
+func TestLinkFrom(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, dws := provider.Tracer("test").Start(context.Background(), "dws")
+	dws.End()
+
+	annotations := map[string]string{}
+	Inject(ctx, testAnnotation, annotations)
+	_, uws := provider.Tracer("test").Start(context.Background(), "uws", LinkFrom(testAnnotation, annotations))
+	uws.End()
+	_, other := provider.Tracer("test").Start(context.Background(), "other", LinkFrom(testAnnotation, map[string]string{}))
+	other.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(ended))
+	}
+	linked := ended[1]
+	if linked.SpanContext().TraceID() == dws.SpanContext().TraceID() || linked.Parent().IsValid() {
+		t.Errorf("expected the linked span to start its own trace")
+	}
+	if links := linked.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != dws.SpanContext().SpanID() {
+		t.Errorf("expected a link to the span %v, got %v", dws.SpanContext(), links)
+	}
+	if links := ended[2].Links(); len(links) != 0 {
+		t.Errorf("expected no link without trace context, got %v", links)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the interactive sessions proxied by the vn-agent.
+package audit
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+)
+
+// Stage is the stage of a session an audit record is written at.
+type Stage string
+
+const (
+	// StageSessionStarted is written when the session is received, before it
+	// is proxied.
+	StageSessionStarted Stage = "SessionStarted"
+	// StageSessionCompleted is written once the session is closed.
+	StageSessionCompleted Stage = "SessionCompleted"
+)
+
+// Record is the audit record of a session.
+type Record struct {
+	ID         string     `json:"id"`
+	Stage      Stage      `json:"stage"`
+	Tenant     string     `json:"tenant"`
+	Action     string     `json:"action"`
+	Namespace  string     `json:"namespace"`
+	Pod        string     `json:"pod"`
+	Container  string     `json:"container,omitempty"`
+	Command    []string   `json:"command,omitempty"`
+	Ports      []string   `json:"ports,omitempty"`
+	RemoteAddr string     `json:"remoteAddr"`
+	StartTime  time.Time  `json:"startTime"`
+	EndTime    *time.Time `json:"endTime,omitempty"`
+	// BytesIn is the number of bytes received from the tenant.
+	BytesIn int64 `json:"bytesIn"`
+	// BytesOut is the number of bytes sent to the tenant.
+	BytesOut   int64  `json:"bytesOut"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Recording is the file the streams of the session are recorded in.
+	Recording string `json:"recording,omitempty"`
+}
+
+// Sink writes the audit records.
+type Sink interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// auditedActions are the actions of the sessions audited.
+var auditedActions = map[string]bool{
+	"exec":        true,
+	"attach":      true,
+	"portForward": true,
+}
+
+// recordedActions are the actions of the sessions whose streams are recorded.
+var recordedActions = map[string]bool{
+	"exec":   true,
+	"attach": true,
+}
+
+// RecordingConfig sets where the streams of the sessions are recorded and how
+// much of them is kept.
+type RecordingConfig struct {
+	// Dir is the directory of the recordings, the streams are not recorded if empty.
+	Dir string
+	// MaxSize is the size in bytes a recording is cut at, 0 for no limit.
+	MaxSize int64
+	// MaxFiles is the number of recordings kept for each tenant, 0 to keep them all.
+	MaxFiles int
+}
+
+// Auditor writes the audit records of the exec, attach and port-forward
+// sessions to its sinks, and records the streams of the exec and attach
+// sessions if a recording directory is set.
+type Auditor struct {
+	sinks     []Sink
+	recording RecordingConfig
+}
+
+// NewAuditor returns an auditor writing to sinks. The streams are not
+// recorded if recording.Dir is empty.
+func NewAuditor(recording RecordingConfig, sinks ...Sink) *Auditor {
+	return &Auditor{sinks: sinks, recording: recording}
+}
+
+// Audited returns whether the sessions of action are audited.
+func (a *Auditor) Audited(action string) bool {
+	return auditedActions[action]
+}
+
+// StartSession writes the record of a session started by req and returns the
+// session. pathParams are the parameters of the path of the tenant request.
+func (a *Auditor) StartSession(req *http.Request, tenantName, action string, pathParams map[string]string) *Session {
+	query := req.URL.Query()
+	s := &Session{
+		auditor: a,
+		record: Record{
+			ID:         string(uuid.NewUUID()),
+			Tenant:     tenantName,
+			Action:     action,
+			Namespace:  pathParams["podNamespace"],
+			Pod:        pathParams["podID"],
+			Container:  pathParams["containerName"],
+			Command:    query["command"],
+			Ports:      query["port"],
+			RemoteAddr: req.RemoteAddr,
+			StartTime:  time.Now(),
+		},
+	}
+	if a.recording.Dir != "" && recordedActions[action] {
+		r, err := newRecorder(a.recording, tenantName, s.record.ID, s.record.StartTime)
+		if err != nil {
+			klog.Errorf("failed to record session %s of tenant %s: %v", s.record.ID, tenantName, err)
+		} else {
+			s.recorder = r
+			s.record.Recording = r.path
+		}
+	}
+
+	record := s.record
+	record.Stage = StageSessionStarted
+	a.write(&record)
+	return s
+}
+
+// Close closes the sinks.
+func (a *Auditor) Close() {
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil {
+			klog.Errorf("failed to close audit sink: %v", err)
+		}
+	}
+}
+
+func (a *Auditor) write(record *Record) {
+	for _, sink := range a.sinks {
+		if err := sink.Write(record); err != nil {
+			klog.Errorf("failed to write audit record %s of tenant %s: %v", record.ID, record.Tenant, err)
+		}
+	}
+}
+
+// Session is an audited session.
+type Session struct {
+	auditor  *Auditor
+	recorder *recorder
+
+	bytesIn  int64
+	bytesOut int64
+
+	mu     sync.Mutex
+	record Record
+}
+
+// ResponseWriter returns w counting the bytes sent to the tenant, and the
+// bytes of the connection once it is upgraded.
+func (s *Session) ResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	return &responseWriter{ResponseWriter: w, session: s}
+}
+
+// Fail records the error the session failed with.
+func (s *Session) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record.Error = err.Error()
+}
+
+// End writes the record of the completed session.
+func (s *Session) End() {
+	if s.recorder != nil {
+		if err := s.recorder.close(); err != nil {
+			klog.Errorf("failed to close the recording of session %s: %v", s.record.ID, err)
+		}
+	}
+
+	s.mu.Lock()
+	record := s.record
+	s.mu.Unlock()
+	now := time.Now()
+	record.Stage = StageSessionCompleted
+	record.EndTime = &now
+	record.BytesIn = atomic.LoadInt64(&s.bytesIn)
+	record.BytesOut = atomic.LoadInt64(&s.bytesOut)
+	s.auditor.write(&record)
+}
+
+func (s *Session) setStatusCode(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.record.StatusCode == 0 {
+		s.record.StatusCode = code
+	}
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (s *memorySink) Write(record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, *record)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+// hijackableWriter is a response writer whose connection is one end of a
+// pipe.
+type hijackableWriter struct {
+	http.ResponseWriter
+	conn net.Conn
+}
+
+func (w *hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, nil, nil
+}
+
+func TestSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := &memorySink{}
+	auditor := NewAuditor(RecordingConfig{Dir: dir}, sink)
+	req := httptest.NewRequest(http.MethodPost, "/exec/default/pod-1/nginx?command=sh&command=-c&command=ls&input=1", nil)
+	session := auditor.StartSession(req, "tenant-a", "exec", map[string]string{
+		"podNamespace":  "default",
+		"podID":         "pod-1",
+		"containerName": "nginx",
+	})
+
+	server, client := net.Pipe()
+	w := session.ResponseWriter(&hijackableWriter{ResponseWriter: httptest.NewRecorder(), conn: server})
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatalf("failed to hijack: %v", err)
+	}
+	go func() {
+		client.Write([]byte("ls\n"))
+		ioutil.ReadAll(client)
+	}()
+	buf := make([]byte, 3)
+	if _, err := conn.Read(buf); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n\r\n"))
+	conn.Write([]byte("file\n"))
+	conn.Close()
+	session.End()
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(sink.records))
+	}
+	started, completed := sink.records[0], sink.records[1]
+	if started.Stage != StageSessionStarted || started.EndTime != nil {
+		t.Errorf("unexpected started record %+v", started)
+	}
+	if completed.Stage != StageSessionCompleted || completed.EndTime == nil || completed.ID != started.ID {
+		t.Errorf("unexpected completed record %+v", completed)
+	}
+	if completed.Tenant != "tenant-a" || completed.Namespace != "default" || completed.Pod != "pod-1" || completed.Container != "nginx" {
+		t.Errorf("unexpected session target %+v", completed)
+	}
+	if !reflect.DeepEqual(completed.Command, []string{"sh", "-c", "ls"}) {
+		t.Errorf("expected command [sh -c ls], got %v", completed.Command)
+	}
+	if completed.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("expected status code 101, got %d", completed.StatusCode)
+	}
+	if completed.BytesIn != 3 || completed.BytesOut != 41 {
+		t.Errorf("expected 3 bytes in and 41 bytes out, got %d and %d", completed.BytesIn, completed.BytesOut)
+	}
+
+	if completed.Recording != filepath.Join(dir, "tenant-a", completed.ID+".rec") {
+		t.Fatalf("unexpected recording %q", completed.Recording)
+	}
+	data, err := ioutil.ReadFile(completed.Recording)
+	if err != nil {
+		t.Fatalf("failed to read the recording: %v", err)
+	}
+	var streams []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var frame Frame
+		if err := json.Unmarshal([]byte(line), &frame); err != nil {
+			t.Fatalf("invalid frame %q: %v", line, err)
+		}
+		streams = append(streams, frame.Stream+":"+string(frame.Data))
+	}
+	expected := []string{"in:ls\n", "out:HTTP/1.1 101 Switching Protocols\r\n\r\n", "out:file\n"}
+	if !reflect.DeepEqual(streams, expected) {
+		t.Errorf("expected frames %q, got %q", expected, streams)
+	}
+}
+
+func TestSessionNotRecorded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := &memorySink{}
+	auditor := NewAuditor(RecordingConfig{Dir: dir}, sink)
+	req := httptest.NewRequest(http.MethodPost, "/portForward/default/pod-1?port=8080", nil)
+	session := auditor.StartSession(req, "tenant-a", "portForward", map[string]string{
+		"podNamespace": "default",
+		"podID":        "pod-1",
+	})
+	w := session.ResponseWriter(httptest.NewRecorder())
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write([]byte("bad request"))
+	session.End()
+
+	completed := sink.records[1]
+	if completed.Recording != "" {
+		t.Errorf("expected port-forward not to be recorded, got %q", completed.Recording)
+	}
+	if !reflect.DeepEqual(completed.Ports, []string{"8080"}) {
+		t.Errorf("expected ports [8080], got %v", completed.Ports)
+	}
+	if completed.StatusCode != http.StatusBadRequest || completed.BytesOut != 11 {
+		t.Errorf("unexpected completed record %+v", completed)
+	}
+}
+
+func TestRecordingLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	frame, _ := json.Marshal(&Frame{Stream: StreamIn, Data: []byte("ls\n")})
+	// Cut the recordings after 2 frames, whose time takes a few more bytes,
+	// keeping 2 recordings per tenant.
+	config := RecordingConfig{Dir: dir, MaxSize: int64(2 * (len(frame) + 20)), MaxFiles: 2}
+	start := time.Now()
+	for i, id := range []string{"session-1", "session-2", "session-3"} {
+		r, err := newRecorder(config, "tenant-a", id, start)
+		if err != nil {
+			t.Fatalf("failed to create recorder: %v", err)
+		}
+		for j := 0; j < 4; j++ {
+			r.record(StreamIn, []byte("ls\n"))
+		}
+		r.close()
+		// The recordings are removed from the least recently modified.
+		modTime := start.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(r.path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recordings, _ := filepath.Glob(filepath.Join(dir, "tenant-a", "*.rec"))
+	expected := []string{filepath.Join(dir, "tenant-a", "session-2.rec"), filepath.Join(dir, "tenant-a", "session-3.rec")}
+	if !reflect.DeepEqual(recordings, expected) {
+		t.Errorf("expected recordings %v, got %v", expected, recordings)
+	}
+	data, err := ioutil.ReadFile(expected[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streams []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var frame Frame
+		if err := json.Unmarshal([]byte(line), &frame); err != nil {
+			t.Fatalf("invalid frame %q: %v", line, err)
+		}
+		streams = append(streams, frame.Stream)
+	}
+	if expectedStreams := []string{StreamIn, StreamIn, StreamTruncated}; !reflect.DeepEqual(streams, expectedStreams) {
+		t.Errorf("expected streams %v, got %v", expectedStreams, streams)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	record := &Record{ID: "id", Tenant: "tenant-a", Action: "exec"}
+	line, _ := json.Marshal(record)
+	// Rotate after every 2 records, keeping 2 backups.
+	sink, err := NewFileSink(path, int64(2*(len(line)+1)), 2)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	for i := 0; i < 9; i++ {
+		if err := sink.Write(record); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("expected 1 record in the current file, got %d", lines)
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("expected 2 backups, got %v", backups)
+	}
+}
+
+func TestFileSinkRemoved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	record := &Record{ID: "id", Tenant: "tenant-a", Action: "exec"}
+	line, _ := json.Marshal(record)
+	sink, err := NewFileSink(path, int64(2*(len(line)+1)), 2)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	defer sink.Close()
+	for i := 0; i < 2; i++ {
+		if err := sink.Write(record); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+	// The rotation cannot rename the removed file, the records keep being
+	// written to a new file.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sink.Write(record); err != nil {
+			t.Fatalf("failed to write record after the file is removed: %v", err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("expected 1 record in the current file, got %d", lines)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record Record
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, record)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	for _, id := range []string{"1", "2", "3"} {
+		if err := sink.Write(&Record{ID: id, Tenant: "tenant-a"}); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+	sink.Close()
+	if err := sink.Write(&Record{ID: "4"}); err == nil {
+		t.Errorf("expected writing to a closed sink to fail")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 || received[0].ID != "1" || received[2].ID != "3" {
+		t.Errorf("unexpected records %+v", received)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// FileSink writes the audit records to a local file, one JSON record per
+// line. The file is rotated once it grows larger than maxSize.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink returns a sink appending to the file path. The file is rotated
+// when it reaches maxSize bytes, keeping maxBackups rotated files. The file is
+// never rotated if maxSize is 0, and all the rotated files are kept if
+// maxBackups is 0.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write appends record to the file.
+func (s *FileSink) Write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			klog.Errorf("failed to rotate audit log %s: %v", s.path, err)
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate renames the file after the current time, opens a new one and removes
+// the oldest rotated files. The current file is kept open until the new one is
+// opened, so that the records are still written if the rotation fails, and the
+// rotation is tried again on the next write. A file removed by someone else is
+// replaced by a new one.
+func (s *FileSink) rotate() error {
+	base := s.path + "." + time.Now().UTC().Format(backupTimeFormat)
+	backup := base
+	// Do not overwrite a file rotated within the same millisecond, the suffix
+	// keeps the backups sorted.
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s-%d", base, i)
+	}
+	renamed := true
+	if err := os.Rename(s.path, backup); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		renamed = false
+	}
+	file := s.file
+	if err := s.open(); err != nil {
+		if renamed {
+			if renameErr := os.Rename(backup, s.path); renameErr != nil {
+				klog.Errorf("failed to restore audit log %s from %s: %v", s.path, backup, renameErr)
+			}
+		}
+		return err
+	}
+	if err := file.Close(); err != nil {
+		klog.Errorf("failed to close rotated audit log %s: %v", backup, err)
+	}
+	if s.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return err
+	}
+	// The backups are named after their rotation time, so that they are
+	// sorted from the oldest.
+	sort.Strings(backups)
+	for len(backups) > s.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
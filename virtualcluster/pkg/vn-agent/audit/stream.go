@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// StreamIn is the stream of the bytes received from the tenant.
+	StreamIn = "in"
+	// StreamOut is the stream of the bytes sent to the tenant.
+	StreamOut = "out"
+	// StreamTruncated is the stream of the last frame of a recording cut at
+	// its maximum size.
+	StreamTruncated = "truncated"
+)
+
+// responseWriter counts the bytes of a session.
+type responseWriter struct {
+	http.ResponseWriter
+	session *Session
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.session.setStatusCode(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.session.setStatusCode(http.StatusOK)
+	n, err := w.ResponseWriter.Write(b)
+	atomic.AddInt64(&w.session.bytesOut, int64(n))
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack returns the connection of the upgraded session, counting and
+// recording its bytes.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	c, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &conn{Conn: c, session: w.session}, rw, nil
+}
+
+// conn is the connection of an upgraded session.
+type conn struct {
+	net.Conn
+	session *Session
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(&c.session.bytesIn, int64(n))
+	if c.session.recorder != nil && n > 0 {
+		c.session.recorder.record(StreamIn, b[:n])
+	}
+	return n, err
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	// The proxy writes the upgrade response of the backend first.
+	if code := parseStatusCode(b); code != 0 {
+		c.session.setStatusCode(code)
+	}
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(&c.session.bytesOut, int64(n))
+	if c.session.recorder != nil && n > 0 {
+		c.session.recorder.record(StreamOut, b[:n])
+	}
+	return n, err
+}
+
+// parseStatusCode returns the status code of the response starting b, or 0.
+func parseStatusCode(b []byte) int {
+	if !bytes.HasPrefix(b, []byte("HTTP/")) {
+		return 0
+	}
+	line := b
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		line = b[:i]
+	}
+	fields := bytes.Fields(line)
+	if len(fields) < 2 {
+		return 0
+	}
+	code, err := strconv.Atoi(string(fields[1]))
+	if err != nil {
+		return 0
+	}
+	return code
+}
+
+// Frame is a chunk of a recorded stream. A recording is a file of JSON
+// frames, one per line.
+type Frame struct {
+	// Time is the number of seconds since the session started.
+	Time   float64 `json:"time"`
+	Stream string  `json:"stream"`
+	Data   []byte  `json:"data"`
+}
+
+// recorder records the streams of a session.
+type recorder struct {
+	path    string
+	start   time.Time
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// newRecorder creates the recording of the session id of tenantName, and
+// removes the oldest recordings of the tenant beyond config.MaxFiles.
+func newRecorder(config RecordingConfig, tenantName, id string, start time.Time) (*recorder, error) {
+	tenantDir := filepath.Join(config.Dir, safeName(tenantName))
+	if err := os.MkdirAll(tenantDir, 0700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(tenantDir, id+".rec")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxFiles > 0 {
+		if err := removeOldRecordings(tenantDir, config.MaxFiles); err != nil {
+			klog.Errorf("failed to remove the old recordings of tenant %s: %v", tenantName, err)
+		}
+	}
+	return &recorder{path: path, start: start, maxSize: config.MaxSize, file: file}, nil
+}
+
+// removeOldRecordings removes the oldest recordings of dir, keeping maxFiles.
+func removeOldRecordings(dir string, maxFiles int) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.rec"))
+	if err != nil || len(paths) <= maxFiles {
+		return err
+	}
+	type recording struct {
+		path    string
+		modTime time.Time
+	}
+	recordings := make([]recording, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		recordings = append(recordings, recording{path: path, modTime: info.ModTime()})
+	}
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].modTime.Before(recordings[j].modTime)
+	})
+	for len(recordings) > maxFiles {
+		if err := os.Remove(recordings[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		recordings = recordings[1:]
+	}
+	return nil
+}
+
+func (r *recorder) record(stream string, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	frame := &Frame{Time: time.Since(r.start).Seconds(), Stream: stream, Data: data}
+	line, err := json.Marshal(frame)
+	if err == nil && r.maxSize > 0 && r.size+int64(len(line))+1 > r.maxSize {
+		klog.Warningf("recording %s reached its maximum size of %d bytes, the recording is stopped", r.path, r.maxSize)
+		// The last frame tells the readers that the recording is cut.
+		if err := r.write(&Frame{Time: frame.Time, Stream: StreamTruncated}); err != nil {
+			klog.Errorf("failed to mark %s as truncated: %v", r.path, err)
+		}
+		r.stop()
+		return
+	}
+	if err == nil {
+		err = r.writeLine(line)
+	}
+	if err != nil {
+		klog.Errorf("failed to record %s, the recording is stopped: %v", r.path, err)
+		r.stop()
+	}
+}
+
+func (r *recorder) write(frame *Frame) error {
+	line, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	return r.writeLine(line)
+}
+
+func (r *recorder) writeLine(line []byte) error {
+	n, err := r.file.Write(append(line, '\n'))
+	r.size += int64(n)
+	return err
+}
+
+func (r *recorder) stop() {
+	r.file.Close()
+	r.file = nil
+}
+
+func (r *recorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// safeName returns name usable as a file name.
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
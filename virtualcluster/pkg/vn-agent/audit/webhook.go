@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	webhookTimeout    = 10 * time.Second
+	webhookBufferSize = 1000
+)
+
+// WebhookSink posts the audit records to a webhook, one JSON record per
+// request. The records are posted in the background, so that the sessions are
+// not delayed by the webhook. The records are dropped if the webhook falls
+// behind by more than the buffer.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+
+	records chan *Record
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWebhookSink returns a sink posting to url.
+func NewWebhookSink(url string) *WebhookSink {
+	s := &WebhookSink{
+		url:     url,
+		client:  &http.Client{Timeout: webhookTimeout},
+		records: make(chan *Record, webhookBufferSize),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write queues record to be posted.
+func (s *WebhookSink) Write(record *Record) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("audit webhook is closed")
+	}
+	select {
+	case s.records <- record:
+		return nil
+	default:
+		return fmt.Errorf("audit webhook buffer is full, dropping the record")
+	}
+}
+
+// Close posts the queued records and stops the sink.
+func (s *WebhookSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.records)
+	}
+	s.mu.Unlock()
+	<-s.done
+	return nil
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	for record := range s.records {
+		if err := s.post(record); err != nil {
+			klog.Errorf("failed to post audit record %s of tenant %s: %v", record.ID, record.Tenant, err)
+		}
+	}
+}
+
+func (s *WebhookSink) post(record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clientca verifies the client certificates of the tenant apiservers
+// against the CA of their own virtual cluster.
+package clientca
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+// DefaultConfigMapName is the name of the ConfigMap publishing the root CA
+// certificate of a virtual cluster in its root namespace.
+const DefaultConfigMapName = constants.VCRootCACertConfigMapName
+
+// Store holds the CA of each tenant, read from the CA ConfigMap in the root
+// namespace of its virtual cluster in the super cluster. The root namespace is
+// named by the cluster key of the virtual cluster, which is the common name of
+// the client certificate of its apiserver. The ConfigMap holds the certificate
+// only, the key of the CA stays in the root-ca secret the vn-agent cannot read.
+type Store struct {
+	client        kubernetes.Interface
+	configMapName string
+
+	mu sync.RWMutex
+	// pools maps the cluster key of the tenants to their CA.
+	pools map[string]*x509.CertPool
+}
+
+// NewStore returns the store of the CAs held in the ConfigMaps configMapName.
+func NewStore(client kubernetes.Interface, configMapName string) *Store {
+	return &Store{
+		client:        client,
+		configMapName: configMapName,
+		pools:         make(map[string]*x509.CertPool),
+	}
+}
+
+// Start loads the CA ConfigMaps and keeps them up to date until stopCh is
+// closed. It returns once the ConfigMaps are loaded.
+func (s *Store) Start(stopCh <-chan struct{}) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", s.configMapName).String()
+	// Fail fast on errors such as a missing permission, which the informer
+	// would retry forever.
+	if _, err := s.client.CoreV1().ConfigMaps(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{FieldSelector: fieldSelector, Limit: 1}); err != nil {
+		return err
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.client, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fieldSelector
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			s.set(obj.(*corev1.ConfigMap))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			s.set(newObj.(*corev1.ConfigMap))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cm, ok := obj.(*corev1.ConfigMap); ok {
+				s.remove(cm.Namespace)
+			}
+		},
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return fmt.Errorf("failed to sync tenant CA configmaps %s", s.configMapName)
+	}
+	return nil
+}
+
+// Verify verifies that certs, the certificate chain presented by the tenant
+// clusterKey, is signed by the CA of the tenant.
+func (s *Store) Verify(clusterKey string, certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return fmt.Errorf("no client certificate")
+	}
+	s.mu.RLock()
+	pool, ok := s.pools[clusterKey]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no CA of tenant %q", clusterKey)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		CurrentTime:   time.Now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// set loads the CA of the tenant owning the root namespace of cm. Invalid
+// CAs are dropped, so that the tenant is rejected rather than verified by a
+// stale CA.
+func (s *Store) set(cm *corev1.ConfigMap) {
+	if err := s.checkPublisher(cm); err != nil {
+		klog.Warningf("security event: ignoring CA configmap %s/%s: %v", cm.Namespace, cm.Name, err)
+		s.remove(cm.Namespace)
+		return
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(cm.Data[corev1.ServiceAccountRootCAKey]))
+	if err != nil {
+		klog.Errorf("invalid CA configmap %s/%s, rejecting tenant %s: %v", cm.Namespace, cm.Name, cm.Namespace, err)
+		s.remove(cm.Namespace)
+		return
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pools[cm.Namespace] = pool
+	klog.Infof("loaded CA of tenant %s", cm.Namespace)
+}
+
+// checkPublisher checks that cm is created by the vc-manager in the root
+// namespace of a virtual cluster. The tenants can create configmaps of the same
+// name, which the syncer copies to their super cluster namespaces, annotated
+// with the cluster they belong to.
+func (s *Store) checkPublisher(cm *corev1.ConfigMap) error {
+	if cluster, ok := cm.Annotations[constants.LabelCluster]; ok {
+		return fmt.Errorf("synced from tenant %s", cluster)
+	}
+	var namespace *corev1.Namespace
+	err := retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		return !apierrors.IsNotFound(err)
+	}, func() error {
+		var err error
+		namespace, err = s.client.CoreV1().Namespaces().Get(context.TODO(), cm.Namespace, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if cluster, ok := namespace.Annotations[constants.LabelCluster]; ok {
+		return fmt.Errorf("namespace %s is synced from tenant %s", namespace.Name, cluster)
+	}
+	if namespace.Annotations[constants.LabelVCRootNS] != "true" {
+		return fmt.Errorf("namespace %s is not the root namespace of a virtual cluster", namespace.Name)
+	}
+	return nil
+}
+
+// remove forgets the CA of the tenant clusterKey.
+func (s *Store) remove(clusterKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pools[clusterKey]; ok {
+		delete(s.pools, clusterKey)
+		klog.Infof("removed CA of tenant %s", clusterKey)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientca
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	certutil "k8s.io/client-go/util/cert"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	pkiutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/pki"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	cert, key, err := pkiutil.NewCertificateAuthority(&pkiutil.CertConfig{
+		Config:             certutil.Config{CommonName: name},
+		PublicKeyAlgorithm: x509.ECDSA,
+	})
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) clientCert(t *testing.T, commonName string) []*x509.Certificate {
+	cert, _, err := pkiutil.NewCertAndKey(ca.cert, ca.key, &pkiutil.CertConfig{
+		Config: certutil.Config{
+			CommonName: commonName,
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		PublicKeyAlgorithm: x509.ECDSA,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client cert: %v", err)
+	}
+	return []*x509.Certificate{cert}
+}
+
+func (ca *testCA) configMap(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: DefaultConfigMapName},
+		Data:       map[string]string{corev1.ServiceAccountRootCAKey: string(pkiutil.EncodeCertPEM(ca.cert))},
+	}
+}
+
+// rootNamespace returns the root namespace of a virtual cluster created by the vc-manager.
+func rootNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{constants.LabelVCRootNS: "true"},
+		},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	caA, caB := newTestCA(t, "tenant-a"), newTestCA(t, "tenant-b")
+	// tenant-b creates a CA configmap in its namespace default, which the
+	// syncer copies to tenant-b-default with the annotations of the tenant
+	// namespace.
+	syncedNamespace := rootNamespace("tenant-b-default")
+	syncedNamespace.Annotations[constants.LabelCluster] = "tenant-b"
+	syncedConfigMap := caB.configMap("tenant-b-default")
+	syncedConfigMap.Annotations = map[string]string{constants.LabelCluster: "tenant-b"}
+	// a configmap forging the annotations of the vc-manager outside of a root namespace.
+	otherNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-c"}}
+	client := fake.NewSimpleClientset(rootNamespace("tenant-a"), rootNamespace("tenant-b"), syncedNamespace, otherNamespace,
+		caA.configMap("tenant-a"), caB.configMap("tenant-b"), syncedConfigMap, caB.configMap("tenant-c"))
+	store := NewStore(client, DefaultConfigMapName)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := store.Start(stopCh); err != nil {
+		t.Fatalf("failed to start store: %v", err)
+	}
+
+	testcases := map[string]struct {
+		clusterKey  string
+		certs       []*x509.Certificate
+		expectedErr bool
+	}{
+		"signed by tenant CA": {
+			clusterKey: "tenant-a",
+			certs:      caA.clientCert(t, "tenant-a"),
+		},
+		"signed by another tenant CA": {
+			clusterKey:  "tenant-a",
+			certs:       caB.clientCert(t, "tenant-a"),
+			expectedErr: true,
+		},
+		"unknown tenant": {
+			clusterKey:  "tenant-d",
+			certs:       caA.clientCert(t, "tenant-d"),
+			expectedErr: true,
+		},
+		"CA synced from a tenant": {
+			clusterKey:  "tenant-b-default",
+			certs:       caB.clientCert(t, "tenant-b-default"),
+			expectedErr: true,
+		},
+		"CA outside of a root namespace": {
+			clusterKey:  "tenant-c",
+			certs:       caB.clientCert(t, "tenant-c"),
+			expectedErr: true,
+		},
+		"no certificate": {
+			clusterKey:  "tenant-a",
+			expectedErr: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			err := store.Verify(tc.clusterKey, tc.certs)
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	caA, caB := newTestCA(t, "tenant-a"), newTestCA(t, "tenant-b")
+	client := fake.NewSimpleClientset(rootNamespace("tenant-a"))
+	store := NewStore(client, DefaultConfigMapName)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := store.Start(stopCh); err != nil {
+		t.Fatalf("failed to start store: %v", err)
+	}
+	certs := caA.clientCert(t, "tenant-a")
+	waitFor := func(verified bool) {
+		t.Helper()
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return (store.Verify("tenant-a", certs) == nil) == verified, nil
+		}); err != nil {
+			t.Fatalf("expected verified %v: %v", verified, err)
+		}
+	}
+
+	waitFor(false)
+
+	if _, err := client.CoreV1().ConfigMaps("tenant-a").Create(context.TODO(), caA.configMap("tenant-a"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create configmap: %v", err)
+	}
+	waitFor(true)
+
+	if _, err := client.CoreV1().ConfigMaps("tenant-a").Update(context.TODO(), caB.configMap("tenant-a"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	waitFor(false)
+
+	if _, err := client.CoreV1().ConfigMaps("tenant-a").Update(context.TODO(), caA.configMap("tenant-a"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	waitFor(true)
+
+	if err := client.CoreV1().ConfigMaps("tenant-a").Delete(context.TODO(), DefaultConfigMapName, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete configmap: %v", err)
+	}
+	waitFor(false)
+}
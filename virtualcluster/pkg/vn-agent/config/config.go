@@ -18,6 +18,11 @@ package config
 
 import (
 	"crypto/tls"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/audit"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/clientca"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/dynamiccert"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/ratelimit"
 )
 
 // TLSOptions holds the TLS options.
@@ -31,5 +36,15 @@ type TLSOptions struct {
 // Config holds the config of the server.
 type Config struct {
 	KubeletClientCert *tls.Certificate
-	KubeletServerHost string
+	// KubeletClientKeyPair reloads the kubelet client certificate from its files, if set.
+	// It is used instead of KubeletClientCert, which is its initial certificate.
+	KubeletClientKeyPair *dynamiccert.KeyPair
+	KubeletServerHost    string
+	// TenantCAs verifies the client certificate of each tenant against the CA of
+	// its virtual cluster, if set.
+	TenantCAs *clientca.Store
+	// Auditor audits the exec, attach and port-forward sessions, if set.
+	Auditor *audit.Auditor
+	// RateLimiter limits the requests of each tenant, if set.
+	RateLimiter *ratelimit.Limiter
 }
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dynamiccert reloads the certificates of the vn-agent when their
+// files change, so that they are rotated without a restart.
+package dynamiccert
+
+import (
+	"crypto/tls"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/klog/v2"
+)
+
+// Serving is the serving TLS config of the vn-agent, whose serving
+// certificate and client CA are reloaded from their files. The reloaded
+// config is used by the new connections only.
+type Serving struct {
+	servingCert *dynamiccertificates.DynamicCertKeyPairContent
+	clientCA    *dynamiccertificates.DynamicFileCAContent
+	controller  *dynamiccertificates.DynamicServingCertificateController
+	tlsConfig   *tls.Config
+}
+
+// NewServing returns the serving TLS config of baseTLSConfig, with the key
+// pair of certFile and keyFile, and the client CA of clientCAFile if it is
+// not empty.
+func NewServing(baseTLSConfig *tls.Config, certFile, keyFile, clientCAFile string) (*Serving, error) {
+	servingCert, err := dynamiccertificates.NewDynamicServingContentFromFiles("serving-cert", certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	s := &Serving{servingCert: servingCert}
+
+	// The client CA provider interface must stay nil without a client CA.
+	var clientCAProvider dynamiccertificates.CAContentProvider
+	if clientCAFile != "" {
+		s.clientCA, err = dynamiccertificates.NewDynamicCAContentFromFile("client-ca", clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		clientCAProvider = s.clientCA
+	}
+
+	s.controller = dynamiccertificates.NewDynamicServingCertificateController(baseTLSConfig, clientCAProvider, servingCert, nil, nil)
+	servingCert.AddListener(s.controller)
+	if s.clientCA != nil {
+		s.clientCA.AddListener(s.controller)
+	}
+	if err := s.controller.RunOnce(); err != nil {
+		return nil, err
+	}
+
+	s.tlsConfig = &tls.Config{
+		MinVersion:         baseTLSConfig.MinVersion,
+		NextProtos:         baseTLSConfig.NextProtos,
+		GetConfigForClient: s.controller.GetConfigForClient,
+	}
+	return s, nil
+}
+
+// TLSConfig returns the TLS config serving the current certificates.
+func (s *Serving) TLSConfig() *tls.Config {
+	return s.tlsConfig
+}
+
+// RunOnce reloads the files once.
+func (s *Serving) RunOnce() error {
+	if err := s.servingCert.RunOnce(); err != nil {
+		return err
+	}
+	if s.clientCA != nil {
+		if err := s.clientCA.RunOnce(); err != nil {
+			return err
+		}
+	}
+	return s.controller.RunOnce()
+}
+
+// Run reloads the files until stopCh is closed.
+func (s *Serving) Run(stopCh <-chan struct{}) {
+	go s.servingCert.Run(1, stopCh)
+	if s.clientCA != nil {
+		go s.clientCA.Run(1, stopCh)
+	}
+	s.controller.Run(1, stopCh)
+}
+
+// KeyPair is a client certificate and key pair, reloaded from their files.
+type KeyPair struct {
+	content *dynamiccertificates.DynamicCertKeyPairContent
+	cert    atomic.Value
+
+	mu        sync.Mutex
+	listeners []func()
+}
+
+var _ dynamiccertificates.Listener = &KeyPair{}
+
+// NewKeyPair returns the key pair of certFile and keyFile, named purpose.
+func NewKeyPair(purpose, certFile, keyFile string) (*KeyPair, error) {
+	content, err := dynamiccertificates.NewDynamicServingContentFromFiles(purpose, certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	kp := &KeyPair{content: content}
+	if err := kp.load(); err != nil {
+		return nil, err
+	}
+	content.AddListener(kp)
+	return kp, nil
+}
+
+// Current returns the current certificate.
+func (kp *KeyPair) Current() *tls.Certificate {
+	return kp.cert.Load().(*tls.Certificate)
+}
+
+// GetClientCertificate returns the current certificate, it is meant to be the
+// GetClientCertificate of a tls.Config.
+func (kp *KeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return kp.Current(), nil
+}
+
+// AddListener adds a func called once the certificate is reloaded.
+func (kp *KeyPair) AddListener(listener func()) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	kp.listeners = append(kp.listeners, listener)
+}
+
+// Enqueue is called by the content when the files change.
+func (kp *KeyPair) Enqueue() {
+	if err := kp.load(); err != nil {
+		klog.Errorf("failed to reload %s: %v", kp.content.Name(), err)
+		return
+	}
+	klog.Infof("reloaded %s", kp.content.Name())
+
+	kp.mu.Lock()
+	listeners := kp.listeners
+	kp.mu.Unlock()
+	for _, listener := range listeners {
+		listener()
+	}
+}
+
+// RunOnce reloads the files once.
+func (kp *KeyPair) RunOnce() error {
+	return kp.content.RunOnce()
+}
+
+// Run reloads the files until stopCh is closed.
+func (kp *KeyPair) Run(stopCh <-chan struct{}) {
+	kp.content.Run(1, stopCh)
+}
+
+func (kp *KeyPair) load() error {
+	certPEM, keyPEM := kp.content.CurrentCertKeyContent()
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	kp.cert.Store(&cert)
+	return nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccert
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	pkiutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/pki"
+)
+
+// files are the certificate files of the vn-agent under test.
+type files struct {
+	dir string
+}
+
+func (f *files) path(name string) string {
+	return filepath.Join(f.dir, name)
+}
+
+func (f *files) write(t *testing.T, name string, data []byte) {
+	if err := ioutil.WriteFile(f.path(name), data, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// rotate writes a new serving key pair and client CA, and returns the pool of
+// the serving certificate and a client certificate signed by the client CA.
+func (f *files) rotate(t *testing.T) (*x509.CertPool, tls.Certificate) {
+	servingCert, servingKey, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create serving cert: %v", err)
+	}
+	f.write(t, "serving.crt", servingCert)
+	f.write(t, "serving.key", servingKey)
+	servingPool, err := certutil.NewPoolFromBytes(servingCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert, caKey, err := pkiutil.NewCertificateAuthority(&pkiutil.CertConfig{
+		Config:             certutil.Config{CommonName: "client-ca"},
+		PublicKeyAlgorithm: x509.ECDSA,
+	})
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	f.write(t, "client-ca.crt", pkiutil.EncodeCertPEM(caCert))
+	clientCert, clientKey, err := pkiutil.NewCertAndKey(caCert, caKey, &pkiutil.CertConfig{
+		Config: certutil.Config{
+			CommonName: "tenant",
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		PublicKeyAlgorithm: x509.ECDSA,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client cert: %v", err)
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := tls.X509KeyPair(pkiutil.EncodeCertPEM(clientCert), keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return servingPool, client
+}
+
+func dial(addr string, servingPool *x509.CertPool, clientCert tls.Certificate) (*tls.Conn, error) {
+	// TLS 1.2 reports the rejected client certificates in the handshake.
+	return tls.Dial("tcp", addr, &tls.Config{
+		ServerName:   "localhost",
+		RootCAs:      servingPool,
+		Certificates: []tls.Certificate{clientCert},
+		MaxVersion:   tls.VersionTLS12,
+	})
+}
+
+func get(t *testing.T, conn *tls.Conn) {
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")); err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestServing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamiccert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := &files{dir: dir}
+
+	oldServingPool, oldClientCert := f.rotate(t)
+	serving, err := NewServing(&tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		MinVersion: tls.VersionTLS12,
+	}, f.path("serving.crt"), f.path("serving.key"), f.path("client-ca.crt"))
+	if err != nil {
+		t.Fatalf("failed to create serving config: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	go server.Serve(tls.NewListener(listener, serving.TLSConfig()))
+	defer server.Close()
+	addr := listener.Addr().String()
+
+	established, err := dial(addr, oldServingPool, oldClientCert)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer established.Close()
+	get(t, established)
+
+	newServingPool, newClientCert := f.rotate(t)
+	if err := serving.RunOnce(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	if conn, err := dial(addr, oldServingPool, newClientCert); err == nil {
+		conn.Close()
+		t.Errorf("expected the old serving certificate to be replaced")
+	}
+	if conn, err := dial(addr, newServingPool, oldClientCert); err == nil {
+		conn.Close()
+		t.Errorf("expected the client certificate of the old CA to be rejected")
+	}
+	conn, err := dial(addr, newServingPool, newClientCert)
+	if err != nil {
+		t.Fatalf("failed to connect with the new certificates: %v", err)
+	}
+	conn.Close()
+
+	// The established connection is left alone.
+	get(t, established)
+}
+
+func TestKeyPair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamiccert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := &files{dir: dir}
+
+	f.rotate(t)
+	keyPair, err := NewKeyPair("kubelet-client", f.path("serving.crt"), f.path("serving.key"))
+	if err != nil {
+		t.Fatalf("failed to load key pair: %v", err)
+	}
+	reloaded := 0
+	keyPair.AddListener(func() { reloaded++ })
+	old := keyPair.Current()
+
+	if err := keyPair.RunOnce(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if reloaded != 0 {
+		t.Errorf("expected unchanged files not to be reloaded")
+	}
+
+	f.rotate(t)
+	if err := keyPair.RunOnce(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	current, _ := keyPair.GetClientCertificate(nil)
+	if reloaded != 1 || string(current.Certificate[0]) == string(old.Certificate[0]) {
+		t.Errorf("expected the certificate to be reloaded")
+	}
+
+	f.write(t, "serving.key", []byte("invalid"))
+	if err := keyPair.RunOnce(); err == nil {
+		t.Errorf("expected an invalid key to fail")
+	}
+	if keyPair.Current() != current || reloaded != 1 {
+		t.Errorf("expected the invalid key pair not to be loaded")
+	}
+}
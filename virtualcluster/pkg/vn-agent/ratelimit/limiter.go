@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimit limits the requests each tenant sends through the vn-agent.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Reason is the reason a request is rejected for.
+type Reason string
+
+const (
+	// ReasonRateLimited rejects the requests exceeding the rate of the tenant.
+	ReasonRateLimited Reason = "rate_limited"
+	// ReasonTooManySessions rejects the sessions exceeding the concurrent
+	// sessions of the tenant.
+	ReasonTooManySessions Reason = "too_many_sessions"
+)
+
+// sessionRetryAfter is the delay the rejected sessions are retried after, as
+// the end of the running sessions cannot be predicted.
+const sessionRetryAfter = time.Second
+
+// streamingActions are the actions of the long running sessions.
+var streamingActions = map[string]bool{
+	"exec":        true,
+	"attach":      true,
+	"portForward": true,
+}
+
+// logActions are the actions whose responses are limited by the log bandwidth.
+var logActions = map[string]bool{
+	"containerLogs": true,
+	"logs":          true,
+}
+
+// Config is the limits of each tenant, a zero limit is unlimited.
+type Config struct {
+	// QPS is the requests per second of each action.
+	QPS float64
+	// ActionQPS overrides QPS for the actions it holds.
+	ActionQPS map[string]float64
+	// Burst is the requests of each action allowed above the rate.
+	Burst int
+	// MaxSessions is the concurrent streaming sessions, i.e. exec, attach,
+	// port-forward and followed logs.
+	MaxSessions int
+	// LogBytesPerSecond is the bandwidth of the logs.
+	LogBytesPerSecond int64
+}
+
+// Limiter limits the requests of each tenant.
+type Limiter struct {
+	config Config
+
+	mu      sync.Mutex
+	tenants map[string]*tenantLimiter
+}
+
+type tenantLimiter struct {
+	actions  map[string]*rate.Limiter
+	sessions int
+	logs     *rate.Limiter
+}
+
+// NewLimiter returns a limiter of config.
+func NewLimiter(config Config) *Limiter {
+	return &Limiter{config: config, tenants: make(map[string]*tenantLimiter)}
+}
+
+// IsStreaming returns whether the request of action is a streaming session.
+func IsStreaming(req *http.Request, action string) bool {
+	if streamingActions[action] {
+		return true
+	}
+	return action == "containerLogs" && req.URL.Query().Get("follow") == "true"
+}
+
+// Allow returns whether a request of tenantName for action is allowed, or the
+// delay it may be retried after.
+func (l *Limiter) Allow(tenantName, action string) (bool, time.Duration) {
+	qps := l.config.QPS
+	if actionQPS, ok := l.config.ActionQPS[action]; ok {
+		qps = actionQPS
+	}
+	if qps <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	t := l.tenant(tenantName)
+	limiter, ok := t.actions[action]
+	if !ok {
+		burst := l.config.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(qps), burst)
+		t.actions[action] = limiter
+	}
+	l.mu.Unlock()
+
+	r := limiter.Reserve()
+	if delay := r.Delay(); delay > 0 {
+		r.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+// StartSession starts a streaming session of tenantName, and returns the func
+// ending it if it is allowed, or the delay it may be retried after.
+func (l *Limiter) StartSession(tenantName string) (func(), time.Duration) {
+	if l.config.MaxSessions <= 0 {
+		return func() {}, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.tenant(tenantName)
+	if t.sessions >= l.config.MaxSessions {
+		return nil, sessionRetryAfter
+	}
+	t.sessions++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			t.sessions--
+		})
+	}, 0
+}
+
+// ResponseWriter returns w limiting the bandwidth of the response to req if
+// it is a log of tenantName.
+func (l *Limiter) ResponseWriter(w http.ResponseWriter, req *http.Request, tenantName, action string) http.ResponseWriter {
+	if l.config.LogBytesPerSecond <= 0 || !logActions[action] {
+		return w
+	}
+	l.mu.Lock()
+	t := l.tenant(tenantName)
+	if t.logs == nil {
+		// The bandwidth is shared by all the logs of the tenant, the burst
+		// allows a second of logs at once.
+		t.logs = rate.NewLimiter(rate.Limit(l.config.LogBytesPerSecond), int(l.config.LogBytesPerSecond))
+	}
+	limiter := t.logs
+	l.mu.Unlock()
+	return &limitedWriter{ResponseWriter: w, ctx: req.Context(), limiter: limiter}
+}
+
+// tenant returns the limiter of tenantName. l.mu must be held.
+func (l *Limiter) tenant(tenantName string) *tenantLimiter {
+	t, ok := l.tenants[tenantName]
+	if !ok {
+		t = &tenantLimiter{actions: make(map[string]*rate.Limiter)}
+		l.tenants[tenantName] = t
+	}
+	return t
+}
+
+// limitedWriter waits for the bandwidth of the bytes it writes.
+type limitedWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (w *limitedWriter) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		n := len(b) - written
+		if n > w.limiter.Burst() {
+			n = w.limiter.Burst()
+		}
+		if err := w.limiter.WaitN(w.ctx, n); err != nil {
+			return written, err
+		}
+		m, err := w.ResponseWriter.Write(b[written : written+n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (w *limitedWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
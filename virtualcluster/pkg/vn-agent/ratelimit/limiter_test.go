@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	limiter := NewLimiter(Config{
+		QPS:       1,
+		ActionQPS: map[string]float64{"containerLogs": 0},
+		Burst:     2,
+	})
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.Allow("tenant-a", "exec"); !allowed {
+			t.Fatalf("expected request %d within the burst to be allowed", i)
+		}
+	}
+	allowed, retryAfter := limiter.Allow("tenant-a", "exec")
+	if allowed {
+		t.Fatalf("expected request above the burst to be rejected")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("expected to retry within a second, got %v", retryAfter)
+	}
+
+	if allowed, _ := limiter.Allow("tenant-a", "attach"); !allowed {
+		t.Errorf("expected the actions to be limited separately")
+	}
+	if allowed, _ := limiter.Allow("tenant-b", "exec"); !allowed {
+		t.Errorf("expected the tenants to be limited separately")
+	}
+	for i := 0; i < 10; i++ {
+		if allowed, _ := limiter.Allow("tenant-a", "containerLogs"); !allowed {
+			t.Fatalf("expected the action overridden with 0 qps to be unlimited")
+		}
+	}
+}
+
+func TestStartSession(t *testing.T) {
+	limiter := NewLimiter(Config{MaxSessions: 2})
+
+	end1, _ := limiter.StartSession("tenant-a")
+	end2, _ := limiter.StartSession("tenant-a")
+	if end1 == nil || end2 == nil {
+		t.Fatalf("expected the sessions within the limit to be allowed")
+	}
+	end3, retryAfter := limiter.StartSession("tenant-a")
+	if end3 != nil || retryAfter != sessionRetryAfter {
+		t.Fatalf("expected the session above the limit to be rejected")
+	}
+	if end, _ := limiter.StartSession("tenant-b"); end == nil {
+		t.Errorf("expected the tenants to be limited separately")
+	}
+
+	end1()
+	end1()
+	if end, _ := limiter.StartSession("tenant-a"); end == nil {
+		t.Errorf("expected a session to be allowed once another ended")
+	}
+	if end, _ := limiter.StartSession("tenant-a"); end != nil {
+		t.Errorf("expected ending a session twice to release it once")
+	}
+}
+
+func TestIsStreaming(t *testing.T) {
+	for _, tc := range []struct {
+		url      string
+		action   string
+		expected bool
+	}{
+		{"/exec/default/pod/c?command=ls", "exec", true},
+		{"/portForward/default/pod", "portForward", true},
+		{"/containerLogs/default/pod/c?follow=true", "containerLogs", true},
+		{"/containerLogs/default/pod/c", "containerLogs", false},
+		{"/pods", "pods", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		if got := IsStreaming(req, tc.action); got != tc.expected {
+			t.Errorf("expected IsStreaming of %s to be %v, got %v", tc.url, tc.expected, got)
+		}
+	}
+}
+
+func TestResponseWriter(t *testing.T) {
+	limiter := NewLimiter(Config{LogBytesPerSecond: 10})
+
+	req := httptest.NewRequest(http.MethodGet, "/exec/default/pod/c", nil)
+	recorder := httptest.NewRecorder()
+	if w := limiter.ResponseWriter(recorder, req, "tenant-a", "exec"); w != recorder {
+		t.Errorf("expected exec not to be limited by the log bandwidth")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req = httptest.NewRequest(http.MethodGet, "/containerLogs/default/pod/c", nil).WithContext(ctx)
+	w := limiter.ResponseWriter(recorder, req, "tenant-a", "containerLogs")
+	if n, err := w.Write([]byte("0123456789")); n != 10 || err != nil {
+		t.Fatalf("expected a second of logs to be written at once, got %d bytes: %v", n, err)
+	}
+	// The bandwidth is shared by the logs of the tenant, the next bytes are
+	// only allowed after the deadline.
+	w = limiter.ResponseWriter(httptest.NewRecorder(), req, "tenant-a", "containerLogs")
+	if n, err := w.Write([]byte("0123456789")); n != 0 || err == nil {
+		t.Errorf("expected the logs above the bandwidth to wait, got %d bytes written", n)
+	}
+	w = limiter.ResponseWriter(httptest.NewRecorder(), req, "tenant-b", "containerLogs")
+	if n, err := w.Write([]byte("0123456789")); n != 10 || err != nil {
+		t.Errorf("expected the tenants to be limited separately, got %d bytes: %v", n, err)
+	}
+}
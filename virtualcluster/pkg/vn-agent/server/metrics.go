@@ -32,8 +32,10 @@ const (
 	metricNameInFlightRequests           = "in_flight_requests"
 	metricNameTotalRequests              = "total_requests"
 	metricNameRequestLatency             = "request_latencies"
+	metricNameStreamingSessions          = "streaming_sessions"
 	errorProxyingRequest                 = "error_proxying_request"
 	errorTranslatingPath                 = "error_translating_path"
+	errorVerifyingClientCert             = "error_verifying_client_cert"
 )
 
 var (
@@ -70,6 +72,12 @@ var (
 		},
 		[]string{},
 	)
+
+	streamingSessions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: resourceVNAgentSubsystem,
+		Name:      metricNameStreamingSessions,
+		Help:      "streaming sessions by tenants",
+	}, []string{"action", "tenantName"})
 )
 
 var registerMetrics sync.Once
@@ -80,7 +88,8 @@ func register() {
 			failureCounter,
 			inFlightRequests,
 			totalRequests,
-			requestLatency)
+			requestLatency,
+			streamingSessions)
 	})
 }
 
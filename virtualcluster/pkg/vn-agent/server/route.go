@@ -23,8 +23,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/proxy"
 	"k8s.io/klog/v2"
 
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/audit"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/ratelimit"
 )
 
 // InstallHandlers set router and handlers.
@@ -129,6 +136,50 @@ func (s *Server) proxy(req *restful.Request, resp *restful.Response) {
 	action, podNamespace := extractFromPath(req)
 	tenantName := req.Request.TLS.PeerCertificates[0].Subject.CommonName
 
+	if s.config.TenantCAs != nil {
+		if err := s.config.TenantCAs.Verify(tenantName, req.Request.TLS.PeerCertificates); err != nil {
+			klog.Warningf("security event: rejected client certificate of tenant %q (serial %s, issuer %q) from %s: %v",
+				tenantName, req.Request.TLS.PeerCertificates[0].SerialNumber, req.Request.TLS.PeerCertificates[0].Issuer.CommonName, req.Request.RemoteAddr, err)
+			if s.enableMetrics {
+				failureCounter.WithLabelValues("", action, tenantName, podNamespace, errorVerifyingClientCert).Inc()
+			}
+			resp.ResponseWriter.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	w := resp.ResponseWriter
+	if s.config.RateLimiter != nil {
+		if allowed, retryAfter := s.config.RateLimiter.Allow(tenantName, action); !allowed {
+			s.reject(w, action, tenantName, podNamespace, ratelimit.ReasonRateLimited, retryAfter)
+			return
+		}
+		if ratelimit.IsStreaming(req.Request, action) {
+			endSession, retryAfter := s.config.RateLimiter.StartSession(tenantName)
+			if endSession == nil {
+				s.reject(w, action, tenantName, podNamespace, ratelimit.ReasonTooManySessions, retryAfter)
+				return
+			}
+			defer endSession()
+		}
+		w = s.config.RateLimiter.ResponseWriter(w, req.Request, tenantName, action)
+	}
+
+	if s.enableMetrics && ratelimit.IsStreaming(req.Request, action) {
+		sessions := streamingSessions.WithLabelValues(action, tenantName)
+		sessions.Inc()
+		defer sessions.Dec()
+	}
+
+	// The session is started before the request is translated, so that it
+	// is audited with the tenant namespace and query.
+	var session *audit.Session
+	if s.config.Auditor != nil && s.config.Auditor.Audited(action) {
+		session = s.config.Auditor.StartSession(req.Request, tenantName, action, req.PathParameters())
+		defer session.End()
+		w = session.ResponseWriter(w)
+	}
+
 	if s.config.KubeletClientCert != nil {
 		klog.Info("will forward request to kubelet")
 		host = s.config.KubeletServerHost
@@ -143,8 +194,11 @@ func (s *Server) proxy(req *restful.Request, resp *restful.Response) {
 		err := TranslatePathForSuper(req, tenantName)
 		if err != nil {
 			klog.Errorf("fail to translate url path for super control plane: %s", err)
-			resp.ResponseWriter.WriteHeader(http.StatusNotFound)
-			resp.ResponseWriter.Write([]byte(err.Error()))
+			if session != nil {
+				session.Fail(err)
+			}
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(err.Error()))
 			if s.enableMetrics {
 				failureCounter.WithLabelValues(
 					s.superAPIServerAddress.Host, action, tenantName, podNamespace, errorTranslatingPath).
@@ -167,6 +221,7 @@ func (s *Server) proxy(req *restful.Request, resp *restful.Response) {
 		podNamespace:  podNamespace,
 		host:          host,
 		enableMetrics: s.enableMetrics,
+		session:       session,
 	}
 
 	if s.enableMetrics {
@@ -178,7 +233,17 @@ func (s *Server) proxy(req *restful.Request, resp *restful.Response) {
 			httpstream.IsUpgradeRequest(req.Request) /*upgradeRequired*/, httpResponder)
 	}
 
-	handler.ServeHTTP(resp.ResponseWriter, req.Request)
+	handler.ServeHTTP(w, req.Request)
+}
+
+// reject responds 429 to a request rejected by the rate limiter.
+func (s *Server) reject(w http.ResponseWriter, action, tenantName, podNamespace string, reason ratelimit.Reason, retryAfter time.Duration) {
+	klog.V(4).Infof("rejected %s request of tenant %s: %s", action, tenantName, reason)
+	if s.enableMetrics {
+		failureCounter.WithLabelValues("", action, tenantName, podNamespace, string(reason)).Inc()
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, fmt.Sprintf("too many requests of tenant %s: %s", tenantName, reason), http.StatusTooManyRequests)
 }
 
 type responder struct {
@@ -187,6 +252,7 @@ type responder struct {
 	podNamespace  string
 	host          string
 	enableMetrics bool
+	session       *audit.Session
 }
 
 func (r *responder) Error(w http.ResponseWriter, req *http.Request, err error) {
@@ -195,6 +261,9 @@ func (r *responder) Error(w http.ResponseWriter, req *http.Request, err error) {
 			r.podNamespace, errorProxyingRequest).
 			Inc()
 	}
+	if r.session != nil {
+		r.session.Fail(err)
+	}
 	klog.Errorf("Error while proxying request: %v", err)
 	http.Error(w, err.Error(), http.StatusInternalServerError)
 }
@@ -70,6 +70,14 @@ func NewServer(cfg *config.Config, serverOption *options.ServerOption) (*Server,
 				Certificates:       []tls.Certificate{*server.config.KubeletClientCert},
 			},
 		}
+		if keyPair := server.config.KubeletClientKeyPair; keyPair != nil {
+			server.transport.TLSClientConfig.Certificates = nil
+			server.transport.TLSClientConfig.GetClientCertificate = keyPair.GetClientCertificate
+			// The idle connections are closed, so that the new requests are sent
+			// with the reloaded certificate. The active ones, such as the exec
+			// and logs streams, are left alone.
+			keyPair.AddListener(server.transport.CloseIdleConnections)
+		}
 	} else {
 		var restConfig *rest.Config
 		var caCrtPool *x509.CertPool
@@ -46,6 +46,7 @@ import (
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/tools/remotecommand"
 	utiltesting "k8s.io/client-go/util/testing"
@@ -64,7 +65,10 @@ import (
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/vn-agent/app/options"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/clientca"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/ratelimit"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/server"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/vn-agent/testcerts"
 )
@@ -107,6 +111,11 @@ func newServerTest() *serverTestFramework {
 }
 
 func newServerTestWithDebug(enableDebugging bool, streamingServer streaming.Server) *serverTestFramework {
+	return newServerTestWithConfig(enableDebugging, streamingServer, &config.Config{})
+}
+
+// newServerTestWithConfig starts a vn-agent with cfg, forwarding to the kubelet under test.
+func newServerTestWithConfig(enableDebugging bool, streamingServer streaming.Server, cfg *config.Config) *serverTestFramework {
 	fv := &serverTestFramework{}
 	kubeCfg := &kubeletconfiginternal.KubeletConfiguration{
 		EnableDebuggingHandlers: enableDebugging,
@@ -130,10 +139,9 @@ func newServerTestWithDebug(enableDebugging bool, streamingServer streaming.Serv
 		panic(errors.Wrap(err, "load kubelet client cert"))
 	}
 
-	server, err := server.NewServer(&config.Config{
-		KubeletClientCert: &kubeletClientCert,
-		KubeletServerHost: fv.kubeletServer.testHTTPServer.URL,
-	}, &options.ServerOption{})
+	cfg.KubeletClientCert = &kubeletClientCert
+	cfg.KubeletServerHost = fv.kubeletServer.testHTTPServer.URL
+	server, err := server.NewServer(cfg, &options.ServerOption{})
 	if err != nil {
 		panic(errors.Wrap(err, "new server"))
 	}
@@ -476,6 +484,85 @@ func TestServeLogs(t *testing.T) {
 	}
 }
 
+func TestServeLogsWithTenantCAs(t *testing.T) {
+	testcases := map[string]struct {
+		caNamespace  string
+		expectedCode int
+	}{
+		"signed by tenant CA": {
+			caNamespace:  "tenantA",
+			expectedCode: http.StatusOK,
+		},
+		"signed by another tenant CA": {
+			caNamespace:  "tenantB",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.caNamespace, Annotations: map[string]string{constants.LabelVCRootNS: "true"}},
+			}, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tc.caNamespace, Name: clientca.DefaultConfigMapName},
+				Data:       map[string]string{v1.ServiceAccountRootCAKey: string(testcerts.CACert)},
+			})
+			tenantCAs := clientca.NewStore(client, clientca.DefaultConfigMapName)
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			if err := tenantCAs.Start(stopCh); err != nil {
+				t.Fatalf("failed to start tenant CAs: %v", err)
+			}
+
+			fv := newServerTestWithConfig(true, nil, &config.Config{TenantCAs: tenantCAs})
+			defer fv.Close()
+			fv.kubeletServer.fakeKubelet.logFunc = func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}
+
+			tenantClient, err := newTenantClient()
+			if err != nil {
+				t.Fatalf("Got tenant client: %v", err)
+			}
+			resp, err := tenantClient.Get(fv.testHTTPServer.URL + "/logs/")
+			if err != nil {
+				t.Fatalf("Got Error GETing: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServeLogsRateLimited(t *testing.T) {
+	fv := newServerTestWithConfig(true, nil, &config.Config{
+		RateLimiter: ratelimit.NewLimiter(ratelimit.Config{QPS: 0.1, Burst: 1}),
+	})
+	defer fv.Close()
+	fv.kubeletServer.fakeKubelet.logFunc = func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	tenantClient, err := newTenantClient()
+	if err != nil {
+		t.Fatalf("Got tenant client: %v", err)
+	}
+	for i, expectedCode := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := tenantClient.Get(fv.testHTTPServer.URL + "/logs/")
+		if err != nil {
+			t.Fatalf("Got Error GETing: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expectedCode {
+			t.Errorf("expected status %d of request %d, got %d", expectedCode, i, resp.StatusCode)
+		}
+		if expectedCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "10" {
+			t.Errorf("expected to retry after 10 seconds, got %q", resp.Header.Get("Retry-After"))
+		}
+	}
+}
+
 func TestServeRunInContainer(t *testing.T) {
 	fv := newServerTest()
 	defer fv.Close()